@@ -1,323 +1,126 @@
 package gapriori
 
 import (
-	"bufio"
-	"io"
-	"log"
-	"os"
-	"strconv"
-	"strings"
+	"slices"
+	"sync"
 )
 
-// - 2019 author: https://github.com/liuxp0827/goApriori
+// - 2019 author: https://github.com/liuxp0827/goApriori, rewritten as level-wise search on item ids.
 
-const (
-	MIN_SUPPORT float64 = 0.2 // 最小支持度
-	MIN_CONF    float64 = 0.8 // 最小置信度
-)
-
-type Apriori struct {
-	endTag      bool
-	dCountMap   map[int]int // k-1 频繁集的记数表
-	dKCountMap  map[int]int // k 频繁集的记数表
-	record      [][]string  // 数据记录表
-	lable       int         // 用于输出时的一个标记, 记录当前在打印第几级关联集
-	confCount   []float64   // 置信度记录表
-	confItemSet [][]string  // 满足支持度的集合
-}
-
-func NewApriori() *Apriori {
-	return &Apriori{
-		endTag:      false,
-		dCountMap:   make(map[int]int),
-		dKCountMap:  make(map[int]int),
-		record:      make([][]string, 0),
-		lable:       1,
-		confCount:   make([]float64, 0),
-		confItemSet: make([][]string, 0),
-	}
-}
-
-func (a *Apriori) result(confItemset2 [][]string) {
-	log.Println("********* 频繁模式挖掘结果 ***********")
-	for i := 0; i < len(confItemset2); i++ {
-		var ret string
-		j := 0
-		for j = 0; j < len(confItemset2[i])-3; j++ {
-			ret += confItemset2[i][j] + " "
-		}
-
-		ret += "---> "
-		ret += confItemset2[i][j] + " "
-		j++
-		ret += "相对支持度: " + confItemset2[i][j]
-		j++
-		ret += ", 自信度: " + confItemset2[i][j]
-		j++
-		log.Println(ret)
-	}
-
-}
-
-// 根据lkItemset，lItemset，dkCountMap2，dCountMap2求出满足自信度的集合
-func (a *Apriori) getConfidencedItemset(lkItemSet, lItemSet [][]string, dkCountMap2, dCountMap2 map[int]int) {
-	for i := 0; i < len(lkItemSet); i++ {
-		a.getConfItem(lkItemSet[i], lItemSet, dkCountMap2[i], dCountMap2)
-	}
-}
-
-// 检验集合 list 是否满足最低自信度要求
-// 若满足则在全局变量 confItemset 添加 list
-func (a *Apriori) getConfItem(list []string, lItemSet [][]string, count int, dCountMap2 map[int]int) {
-	for i := 0; i < len(list); i++ {
-		testList := make([]string, 0)
-		for j := 0; j < len(list); j++ {
-			if i != j {
-				testList = append(testList, list[j])
-			}
-		}
-		index := a.findConf(testList, lItemSet) //查找testList中的内容在lItemset的位置
-		conf := float64(count) * 1.0 / float64(dCountMap2[index])
-		if conf > MIN_CONF { //满足自信度要求
-			testList = append(testList, list[i])
-			relativeSupport := float64(count) * 1.0 / float64(len(a.record)-1)
-			testList = append(testList, strconv.FormatFloat(relativeSupport, 'f', -1, 64))
-			testList = append(testList, strconv.FormatFloat(conf, 'f', -1, 64))
-			a.confItemSet = append(a.confItemSet, testList)
-		}
-	}
-
-}
-
-// 查找testList中的内容在lItemset的位置
-func (a *Apriori) findConf(testList []string, lItemSet [][]string) int {
-	for i := 0; i < len(lItemSet); i++ {
-		notHaveTag := false
-		for j := 0; j < len(testList); j++ {
-			if !a.haveThisItem(testList[j], lItemSet[i]) {
-				notHaveTag = true
-				break
-			}
-		}
-
-		if !notHaveTag {
-			return i
+// mineApriori finds frequent itemsets level by level, candidates of level k are joined from frequent itemsets of level k-1,
+// and counted by scanning transactions with opts.Workers goroutines.
+func mineApriori(txs [][]int, nItems, minCount int, opts Options) []frequent {
+	counts := make([]int, nItems)
+	for _, tx := range txs {
+		for _, id := range tx {
+			counts[id]++
 		}
 	}
-	return -1
-}
-
-//检验 list 中是否包含 str
-func (a *Apriori) haveThisItem(str string, list []string) bool {
-	for i := 0; i < len(list); i++ {
-		if str == list[i] {
-			return true
+	var level []frequent
+	for id, c := range counts {
+		if c >= minCount {
+			level = append(level, frequent{ids: []int{id}, count: c})
 		}
 	}
-	return false
-}
-
-// 获取数据库记录
-func (a *Apriori) getRecord(filename string) ([][]string, error) {
-	record := make([][]string, 0)
-
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	reader := bufio.NewReader(file)
-	for {
-		line, _, err := reader.ReadLine()
-		if err != nil || err == io.EOF {
+	var all []frequent
+	for k := 1; len(level) > 0; k++ {
+		all = append(all, level...)
+		if opts.MaxLength > 0 && k >= opts.MaxLength {
 			break
 		}
-		log.Println(string(line))
-		lineStr := strings.Split(string(line), "  ")
-		lineList := make([]string, 0)
-		for i := 0; i < len(lineStr); i++ {
-			if strings.HasSuffix(lineStr[i], "T") || strings.HasSuffix(lineStr[i], "YES") {
-				lineList = append(lineList, record[0][i])
-			} else if strings.HasSuffix(lineStr[i], "F") || strings.HasSuffix(lineStr[i], "NO") {
-
-			} else {
-				lineList = append(lineList, lineStr[i])
+		cands := aprioriCandidates(level)
+		cnt := countCandidates(txs, cands, opts.Workers)
+		level = nil
+		for i, c := range cands {
+			if cnt[i] >= minCount {
+				level = append(level, frequent{ids: c, count: cnt[i]})
 			}
 		}
-		record = append(record, lineList)
 	}
-
-	file.Close()
-	return record, nil
+	return all
 }
 
-// 求出cItemset中满足最低支持度集合
-func (a *Apriori) getSupportedItemSet(cItemSet [][]string) [][]string {
-	end := true
-	supportedItemset := make([][]string, 0)
-	k := 0
-	for i := 0; i < len(cItemSet); i++ {
-		count := a.countFrequent(cItemSet[i])                   //统计记录数
-		if count >= int(MIN_SUPPORT*float64(len(a.record)-1)) { // count值大于支持度与记录数的乘积，即满足支持度要求
-			if len(cItemSet[0]) == 1 {
-				a.dCountMap[k] = count
-				k++
-			} else {
-				a.dKCountMap[k] = count
-				k++
-			}
-			supportedItemset = append(supportedItemset, cItemSet[i])
-			end = false
-		}
+// aprioriCandidates joins itemsets which share all items except the last one,
+// candidates with any infrequent subset are pruned.
+func aprioriCandidates(level []frequent) [][]int {
+	slices.SortFunc(level, func(a, b frequent) int { return slices.Compare(a.ids, b.ids) })
+	known := make(map[string]bool, len(level))
+	for _, f := range level {
+		known[keyOf(f.ids)] = true
 	}
-	a.endTag = end
-	return supportedItemset
-}
-
-// 统计数据库记录record中出现list中的集合的个数
-func (a *Apriori) countFrequent(list []string) int {
-	count := 0
-	for i := 1; i < len(a.record); i++ {
-		notHavaThisList := false
-		for k := 0; k < len(list); k++ {
-			thisRecordHave := false
-			for j := 1; j < len(a.record[i]); j++ {
-				if list[k] == a.record[i][j] {
-					thisRecordHave = true
-				}
-			}
-			if !thisRecordHave { // 扫描一遍记录表的一行,
-				// 发现 list[i] 不在记录表的第j行中，即list不可能在 j 行中
-				notHavaThisList = true
+	var cands [][]int
+	sub := make([]int, 0)
+	for i := range level {
+		a := level[i].ids
+		k := len(a)
+		for j := i + 1; j < len(level); j++ {
+			b := level[j].ids
+			if !slices.Equal(a[:k-1], b[:k-1]) {
 				break
 			}
-		}
-		if !notHavaThisList {
-			count++
-		}
-	}
-	return count
-}
-
-// 根据cItemset求出下一级的备选集合组,
-// 求出的备选集合组中的每个集合的元素的个数比cItemset中的集合的元素大1
-func (a *Apriori) getNextCandidate(cItemSet [][]string) [][]string {
-	nextItemSet := make([][]string, 0)
-	for i := 0; i < len(cItemSet); i++ {
-		tempList := make([]string, 0)
-		for k := 0; k < len(cItemSet[i]); k++ {
-			tempList = append(tempList, cItemSet[i][k])
-		}
-
-		for h := i + 1; h < len(cItemSet); h++ {
-			for j := 0; j < len(cItemSet[h]); j++ {
-
-				tempList = append(tempList, cItemSet[h][j])
-				if a.isSubSetInc(tempList, cItemSet) { // tempList的子集全部在cItemset中
-					copyValueHelpList := make([]string, 0)
-					for p := 0; p < len(tempList); p++ {
-						copyValueHelpList = append(copyValueHelpList, tempList[p])
-					}
-					if a.isHave(copyValueHelpList, nextItemSet) {
-						nextItemSet = append(nextItemSet, copyValueHelpList)
-					}
-				}
-				tempList = tempList[:len(tempList)-1]
-			}
-		}
-	}
-	return nextItemSet
-}
-
-// 检验 nextItemset 中是否包含 copyValueHelpList
-func (a *Apriori) isHave(copyValueHelpList []string, nextItemset [][]string) bool {
-	for i := 0; i < len(nextItemset); i++ {
-		length := len(copyValueHelpList)
-		if length == len(nextItemset[i]) {
-			ii := 0
-			for ; ii < length; ii++ {
-				if copyValueHelpList[ii] != nextItemset[i][ii] {
-					break
-				}
+			cand := append(slices.Clone(a), b[k-1])
+			pruned := false
+			// subsets without one of the first k-1 items, the two others are a and b
+			for skip := 0; skip < k-1 && !pruned; skip++ {
+				sub = append(append(sub[:0], cand[:skip]...), cand[skip+1:]...)
+				pruned = !known[keyOf(sub)]
 			}
-
-			if ii == length {
-				return false
+			if !pruned {
+				cands = append(cands, cand)
 			}
 		}
 	}
-	return true
+	return cands
 }
 
-// 检验 tempList 是不是 cItemset 的子集
-func (a *Apriori) isSubSetInc(tempList []string, cItemSet [][]string) bool {
-	haveTag := false
-	for i := 0; i < len(tempList); i++ { // k 集合 tempList 的子集是否都在 k-1 级频繁级中
-		testList := make([]string, 0)
-		for j := 0; j < len(tempList); j++ {
-			if i != j {
-				testList = append(testList, tempList[j])
-			}
-		}
-
-		length := len(testList)
-		for k := 0; k < len(cItemSet); k++ {
-			/***** 子集存在于k-1频繁集中 *****/
-			if length == len(cItemSet[k]) {
-				ii := 0
-				for ; ii < length; ii++ {
-					if testList[ii] != cItemSet[k][ii] {
-						break
-					}
-				}
-
-				if ii == length {
-					haveTag = true
-					// 子集存在于k-1频繁集中
-					break
-				}
-			}
-			/***** 子集存在于k-1频繁集中 *****/
+// containsAll reports whether sorted sub is a subset of sorted set.
+func containsAll(set, sub []int) bool {
+	i := 0
+	for _, v := range sub {
+		for i < len(set) && set[i] < v {
+			i++
 		}
-
-		if !haveTag {
+		if i == len(set) || set[i] != v {
 			return false
 		}
+		i++
 	}
-
-	return haveTag
+	return true
 }
 
-// 根据数据库记录求出第一级备选集
-func (a *Apriori) findFirstCandidate() [][]string {
-	tableList := make([][]string, 0)
-	lineList := make([]string, 0)
-	size := 0
-	for i := 1; i < len(a.record); i++ {
-		for j := 1; j < len(a.record[i]); j++ {
-			if len(lineList) == 0 {
-				lineList = append(lineList, a.record[i][j])
-			} else {
-				haveThisItem := false
-				size = len(lineList)
-				for k := 0; k < size; k++ {
-					if lineList[k] == a.record[i][j] {
-						haveThisItem = true
-						break
-					}
+// countCandidates counts transactions containing each candidate, transactions are split to workers.
+func countCandidates(txs [][]int, cands [][]int, workers int) []int {
+	counts := make([]int, len(cands))
+	if len(cands) == 0 {
+		return counts
+	}
+	k := len(cands[0])
+	chunk := (len(txs) + workers - 1) / workers
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for start := 0; start < len(txs); start += chunk {
+		part := txs[start:min(start+chunk, len(txs))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]int, len(cands))
+			for _, tx := range part {
+				if len(tx) < k {
+					continue
 				}
-
-				if !haveThisItem {
-					lineList = append(lineList, a.record[i][j])
+				for i, c := range cands {
+					if containsAll(tx, c) {
+						local[i]++
+					}
 				}
 			}
-		}
-	}
-
-	for i := 0; i < len(lineList); i++ {
-		helpList := make([]string, 0)
-		helpList = append(helpList, lineList[i])
-		tableList = append(tableList, helpList)
+			mu.Lock()
+			defer mu.Unlock()
+			for i, c := range local {
+				counts[i] += c
+			}
+		}()
 	}
-
-	return tableList
+	wg.Wait()
+	return counts
 }
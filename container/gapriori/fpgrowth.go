@@ -0,0 +1,146 @@
+package gapriori
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+/*
+FP-Growth compresses transactions into a prefix tree (FP-tree) whose paths are items ordered by frequency,
+then mines frequent itemsets recursively from conditional trees of every item, without generating candidates.
+https://en.wikipedia.org/wiki/Association_rule_learning#FP-growth_algorithm
+*/
+
+type (
+	fpNode struct {
+		item     int
+		count    int
+		parent   *fpNode
+		children map[int]*fpNode
+		next     *fpNode // next node of the same item
+	}
+
+	fpTree struct {
+		root   *fpNode
+		heads  map[int]*fpNode
+		counts map[int]int // frequent item -> count
+		items  []int       // frequent items, least frequent first
+	}
+)
+
+// newFPTree builds FP-tree of paths, every path has weight of counts[i], infrequent items are dropped.
+func newFPTree(paths [][]int, weights []int, minCount int) *fpTree {
+	t := &fpTree{root: &fpNode{item: -1}, heads: make(map[int]*fpNode), counts: make(map[int]int)}
+	for i, p := range paths {
+		for _, item := range p {
+			t.counts[item] += weights[i]
+		}
+	}
+	for item, c := range t.counts {
+		if c < minCount {
+			delete(t.counts, item)
+		} else {
+			t.items = append(t.items, item)
+		}
+	}
+	// frequent items first in paths, so paths share more prefixes
+	order := func(a, b int) int {
+		if c := cmp.Compare(t.counts[b], t.counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	}
+	slices.SortFunc(t.items, func(a, b int) int { return order(b, a) })
+	var buf []int
+	for i, p := range paths {
+		buf = buf[:0]
+		for _, item := range p {
+			if _, ok := t.counts[item]; ok {
+				buf = append(buf, item)
+			}
+		}
+		slices.SortFunc(buf, order)
+		t.insert(buf, weights[i])
+	}
+	return t
+}
+
+func (t *fpTree) insert(path []int, weight int) {
+	n := t.root
+	for _, item := range path {
+		child, ok := n.children[item]
+		if !ok {
+			child = &fpNode{item: item, parent: n, next: t.heads[item]}
+			if n.children == nil {
+				n.children = make(map[int]*fpNode)
+			}
+			n.children[item] = child
+			t.heads[item] = child
+		}
+		child.count += weight
+		n = child
+	}
+}
+
+// mine appends frequent itemsets which end with item of t and suffix.
+func (t *fpTree) mine(item int, suffix []int, minCount, maxLength int, out []frequent) []frequent {
+	ids := append([]int{item}, suffix...)
+	out = append(out, frequent{ids: ids, count: t.counts[item]})
+	if maxLength > 0 && len(ids) >= maxLength {
+		return out
+	}
+	// conditional pattern base: prefix paths of item with its counts
+	var paths [][]int
+	var weights []int
+	for n := t.heads[item]; n != nil; n = n.next {
+		var p []int
+		for a := n.parent; a != t.root; a = a.parent {
+			p = append(p, a.item)
+		}
+		if len(p) > 0 {
+			paths = append(paths, p)
+			weights = append(weights, n.count)
+		}
+	}
+	cond := newFPTree(paths, weights, minCount)
+	for _, ci := range cond.items {
+		out = cond.mine(ci, ids, minCount, maxLength, out)
+	}
+	return out
+}
+
+// mineFPGrowth mines conditional trees of frequent items with opts.Workers goroutines.
+func mineFPGrowth(txs [][]int, minCount int, opts Options) []frequent {
+	weights := make([]int, len(txs))
+	for i := range weights {
+		weights[i] = 1
+	}
+	t := newFPTree(txs, weights, minCount)
+	items := make(chan int)
+	var mu sync.Mutex
+	var all []frequent
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var local []frequent
+			for item := range items {
+				local = t.mine(item, nil, minCount, opts.MaxLength, local)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			all = append(all, local...)
+		}()
+	}
+	for _, item := range t.items {
+		items <- item
+	}
+	close(items)
+	wg.Wait()
+	for _, f := range all {
+		slices.Sort(f.ids)
+	}
+	return all
+}
@@ -0,0 +1,233 @@
+package gapriori
+
+import (
+	"cmp"
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"iter"
+	"math"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+type (
+	Algorithm int
+
+	Options struct {
+		Algorithm     Algorithm
+		MinSupport    float64 // 最小支持度, relative support in (0, 1], default 0.2
+		MinConfidence float64 // 最小置信度, in (0, 1], default 0.8
+		MaxLength     int     // max items of an itemset, 0 means no limit, an itemset of k items generates 2^k-2 rules
+		Workers       int     // goroutines to count or mine in parallel, default runtime.NumCPU()
+	}
+
+	// Itemset is a frequent itemset, Items are sorted.
+	Itemset struct {
+		Items   []string
+		Count   int     // transactions containing all Items
+		Support float64 // Count / transactions
+	}
+
+	// Rule is an association rule Antecedent -> Consequent, items are sorted.
+	Rule struct {
+		Antecedent []string
+		Consequent []string
+		Support    float64 // support of Antecedent ∪ Consequent
+		Confidence float64 // P(Consequent | Antecedent)
+		Lift       float64 // Confidence / support of Consequent, > 1 means positive correlation
+		Conviction float64 // (1 - support of Consequent) / (1 - Confidence), +Inf if Confidence is 1
+	}
+
+	Result struct {
+		Transactions int
+		Itemsets     []Itemset // ordered by length, then items
+		Rules        []Rule    // ordered by confidence desc, lift desc, then items
+	}
+
+	// dataset is transactions encoded as sorted distinct item ids.
+	dataset struct {
+		items []string       // id -> item
+		ids   map[string]int // item -> id
+		txs   [][]int
+	}
+
+	// frequent is a frequent itemset of sorted item ids.
+	frequent struct {
+		ids   []int
+		count int
+	}
+)
+
+const (
+	FPGrowth Algorithm = iota // faster, default
+	Apriori
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case FPGrowth:
+		return "fp-growth"
+	case Apriori:
+		return "apriori"
+	}
+	return "unknown"
+}
+
+func (o *Options) normalize() error {
+	if o.MinSupport == 0 {
+		o.MinSupport = 0.2
+	}
+	if o.MinConfidence == 0 {
+		o.MinConfidence = 0.8
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if o.MinSupport < 0 || o.MinSupport > 1 || o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return gerrors.New("invalid min support %v or min confidence %v", o.MinSupport, o.MinConfidence)
+	}
+	if o.MaxLength < 0 {
+		return gerrors.New("invalid max length %d", o.MaxLength)
+	}
+	if o.Algorithm != FPGrowth && o.Algorithm != Apriori {
+		return gerrors.New("unknown algorithm %d", o.Algorithm)
+	}
+	return nil
+}
+
+// Mine finds frequent itemsets and association rules of transactions, duplicate items in a transaction count once.
+func Mine(transactions [][]string, opts Options) (*Result, error) {
+	return MineSeq(slices.Values(transactions), opts)
+}
+
+// MineSeq is like Mine but reads transactions from seq once, transactions are kept in memory as item ids,
+// so the slices yielded by seq can be reused.
+func MineSeq(transactions iter.Seq[[]string], opts Options) (*Result, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	ds := &dataset{ids: make(map[string]int)}
+	for tx := range transactions {
+		ds.add(tx)
+	}
+	r := &Result{Transactions: len(ds.txs)}
+	if len(ds.txs) == 0 {
+		return r, nil
+	}
+	minCount := max(1, int(math.Ceil(opts.MinSupport*float64(len(ds.txs))-1e-9)))
+	var fs []frequent
+	if opts.Algorithm == Apriori {
+		fs = mineApriori(ds.txs, len(ds.items), minCount, opts)
+	} else {
+		fs = mineFPGrowth(ds.txs, minCount, opts)
+	}
+	r.Itemsets, r.Rules = ds.report(fs, opts.MinConfidence)
+	return r, nil
+}
+
+func (ds *dataset) add(tx []string) {
+	ids := make([]int, 0, len(tx))
+	for _, item := range tx {
+		id, ok := ds.ids[item]
+		if !ok {
+			id = len(ds.items)
+			ds.ids[item] = id
+			ds.items = append(ds.items, item)
+		}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	ds.txs = append(ds.txs, slices.Compact(ids))
+}
+
+func (ds *dataset) names(ids []int) []string {
+	r := make([]string, len(ids))
+	for i, id := range ids {
+		r[i] = ds.items[id]
+	}
+	slices.Sort(r)
+	return r
+}
+
+// keyOf returns map key of sorted ids.
+func keyOf(ids []int) string {
+	b := make([]byte, 0, len(ids)*2)
+	for _, id := range ids {
+		b = binary.AppendUvarint(b, uint64(id))
+	}
+	return string(b)
+}
+
+// report converts frequent itemsets to Itemset and generates rules from every split of them.
+func (ds *dataset) report(fs []frequent, minConfidence float64) ([]Itemset, []Rule) {
+	n := float64(len(ds.txs))
+	counts := make(map[string]int, len(fs))
+	for _, f := range fs {
+		counts[keyOf(f.ids)] = f.count
+	}
+	itemsets := make([]Itemset, 0, len(fs))
+	var rules []Rule
+	for _, f := range fs {
+		itemsets = append(itemsets, Itemset{Items: ds.names(f.ids), Count: f.count, Support: float64(f.count) / n})
+		k := len(f.ids)
+		if k < 2 {
+			continue
+		}
+		// mask selects the antecedent, subsets of frequent itemsets are frequent too
+		for mask := 1; mask < 1<<k-1; mask++ {
+			var ante, cons []int
+			for i, id := range f.ids {
+				if mask&(1<<i) != 0 {
+					ante = append(ante, id)
+				} else {
+					cons = append(cons, id)
+				}
+			}
+			conf := float64(f.count) / float64(counts[keyOf(ante)])
+			if conf < minConfidence {
+				continue
+			}
+			consSupport := float64(counts[keyOf(cons)]) / n
+			conviction := math.Inf(1)
+			if conf < 1 {
+				conviction = (1 - consSupport) / (1 - conf)
+			}
+			rules = append(rules, Rule{
+				Antecedent: ds.names(ante),
+				Consequent: ds.names(cons),
+				Support:    float64(f.count) / n,
+				Confidence: conf,
+				Lift:       conf / consSupport,
+				Conviction: conviction,
+			})
+		}
+	}
+	slices.SortFunc(itemsets, func(a, b Itemset) int {
+		if c := cmp.Compare(len(a.Items), len(b.Items)); c != 0 {
+			return c
+		}
+		return slices.Compare(a.Items, b.Items)
+	})
+	slices.SortFunc(rules, func(a, b Rule) int {
+		if c := cmp.Compare(b.Confidence, a.Confidence); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Lift, a.Lift); c != 0 {
+			return c
+		}
+		if c := slices.Compare(a.Antecedent, b.Antecedent); c != 0 {
+			return c
+		}
+		return slices.Compare(a.Consequent, b.Consequent)
+	})
+	return itemsets, rules
+}
+
+func (is Itemset) String() string {
+	return "{" + strings.Join(is.Items, ", ") + "}"
+}
+
+func (r Rule) String() string {
+	return "{" + strings.Join(r.Antecedent, ", ") + "} -> {" + strings.Join(r.Consequent, ", ") + "}"
+}
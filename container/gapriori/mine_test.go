@@ -0,0 +1,133 @@
+package gapriori
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// readTable reads simple.txt, a table of TID and T/F columns of items.
+func readTable(t *testing.T, filename string) [][]string {
+	f, err := os.Open(filename)
+	gtest.Assert(t, err)
+	defer f.Close()
+	var header []string
+	var txs [][]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if header == nil {
+			header = fields
+			continue
+		}
+		var tx []string
+		for i := 1; i < len(fields); i++ {
+			if fields[i] == "T" {
+				tx = append(tx, header[i])
+			}
+		}
+		txs = append(txs, tx)
+	}
+	gtest.Assert(t, scanner.Err())
+	return txs
+}
+
+// bruteForce counts every subset of items.
+func bruteForce(txs [][]string, items []string, minCount, maxLength int) map[string]int {
+	r := map[string]int{}
+	for mask := 1; mask < 1<<len(items); mask++ {
+		var set []string
+		for i, item := range items {
+			if mask&(1<<i) != 0 {
+				set = append(set, item)
+			}
+		}
+		if maxLength > 0 && len(set) > maxLength {
+			continue
+		}
+		count := 0
+		for _, tx := range txs {
+			all := true
+			for _, item := range set {
+				all = all && slices.Contains(tx, item)
+			}
+			if all {
+				count++
+			}
+		}
+		if count >= minCount {
+			slices.Sort(set)
+			r[strings.Join(set, ",")] = count
+		}
+	}
+	return r
+}
+
+func checkItemsets(t *testing.T, r *Result, want map[string]int, name string) {
+	gtest.AssertTrue(t, len(r.Itemsets) == len(want), "%s: %d itemsets, want %d", name, len(r.Itemsets), len(want))
+	for _, is := range r.Itemsets {
+		c, ok := want[strings.Join(is.Items, ",")]
+		gtest.AssertTrue(t, ok && c == is.Count, "%s: %s count %d, want %d", name, is, is.Count, c)
+	}
+}
+
+func TestMine(t *testing.T) {
+	txs := readTable(t, "simple.txt")
+	gtest.AssertTrue(t, len(txs) == 10, "transactions %d", len(txs))
+	want := bruteForce(txs, []string{"I1", "I2", "I3", "I4", "I5"}, 2, 0)
+	for _, algo := range []Algorithm{FPGrowth, Apriori} {
+		r, err := Mine(txs, Options{Algorithm: algo, MinSupport: 0.2, MinConfidence: 0.6})
+		gtest.Assert(t, err)
+		checkItemsets(t, r, want, algo.String())
+
+		// I5 -> I2: I5 appears 3 times, always with I2 which appears 8 times
+		idx := slices.IndexFunc(r.Rules, func(rule Rule) bool { return rule.String() == "{I5} -> {I2}" })
+		gtest.AssertTrue(t, idx >= 0, "%s: rule not found in %v", algo, r.Rules)
+		rule := r.Rules[idx]
+		gtest.AssertTrue(t, rule.Support == 0.3 && rule.Confidence == 1 && math.Abs(rule.Lift-1.25) < 1e-9 && math.IsInf(rule.Conviction, 1), "%s: %+v", algo, rule)
+		for i, rule := range r.Rules {
+			gtest.AssertTrue(t, rule.Confidence >= 0.6 && (i == 0 || r.Rules[i-1].Confidence >= rule.Confidence), "%s: rule order %+v", algo, rule)
+		}
+	}
+}
+
+// TestMine_Random compares both algorithms with brute force on random transactions.
+func TestMine_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(47))
+	items := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	for round := 0; round < 20; round++ {
+		txs := make([][]string, 50+rnd.Intn(100))
+		for i := range txs {
+			for _, item := range items {
+				if rnd.Intn(3) != 0 {
+					txs[i] = append(txs[i], item)
+				}
+			}
+		}
+		maxLength := rnd.Intn(4)
+		want := bruteForce(txs, items, int(math.Ceil(0.3*float64(len(txs)))), maxLength)
+		for _, algo := range []Algorithm{FPGrowth, Apriori} {
+			r, err := MineSeq(slices.Values(txs), Options{Algorithm: algo, MinSupport: 0.3, MaxLength: maxLength, Workers: 1 + round%4})
+			gtest.Assert(t, err)
+			checkItemsets(t, r, want, fmt.Sprintf("%s round %d", algo, round))
+		}
+	}
+}
+
+func TestMine_Options(t *testing.T) {
+	_, err := Mine(nil, Options{MinSupport: 1.5})
+	gtest.AssertTrue(t, err != nil, "invalid support")
+	r, err := Mine(nil, Options{})
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, r.Transactions == 0 && len(r.Itemsets) == 0, "empty")
+	// duplicate items count once
+	r, err = Mine([][]string{{"x", "x"}, {"x"}}, Options{MinSupport: 1})
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, len(r.Itemsets) == 1 && r.Itemsets[0].Count == 2, "duplicates %v", r.Itemsets)
+}
@@ -1,148 +1,178 @@
 package gbimap
 
 import (
-	"errors"
-	"reflect"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"iter"
+	"maps"
+	"slices"
 )
 
 type (
-	// BiMap is a bi-directional map
-	BiMap struct {
-		keys        map[any]any
-		values      map[any]any
-		orderedKeys []any
+	// BiMap is a bi-directional one-to-one map, keys are kept in insertion order.
+	// It is not safe for concurrent use, see SyncBiMap.
+	BiMap[K, V comparable] struct {
+		keys        map[K]V
+		values      map[V]K
+		orderedKeys []K
 	}
 
 	// Tuple is a key-value pair used to initialized a new BiMap with values
-	Tuple struct {
-		key, value any
+	Tuple[K, V comparable] struct {
+		Key   K
+		Value V
 	}
 )
 
-// Set sets a key-value pair on the map. Returns an error if key or value is duplicate
-func (bm *BiMap) Set(key any, value any) error {
-	if bm.keys[key] != nil {
-		return errors.New("BiMap can\\'t have duplicate key")
+// NewBiMap creates a new bi-directional map
+func NewBiMap[K, V comparable](initialValues ...Tuple[K, V]) (*BiMap[K, V], error) {
+	bm := &BiMap[K, V]{
+		keys:        make(map[K]V, len(initialValues)),
+		values:      make(map[V]K, len(initialValues)),
+		orderedKeys: make([]K, 0, len(initialValues)),
 	}
-
-	if bm.values[value] != nil {
-		return errors.New("BiMap can\\'t have duplicate value")
+	for _, tuple := range initialValues {
+		if bm.HasKey(tuple.Key) {
+			return nil, gerrors.New("Initial values contain duplicated keys")
+		}
+		if bm.HasVal(tuple.Value) {
+			return nil, gerrors.New("Initial values contain duplicated values")
+		}
+		bm.set(tuple.Key, tuple.Value)
 	}
+	return bm, nil
+}
 
+func (bm *BiMap[K, V]) set(key K, value V) {
 	bm.keys[key] = value
 	bm.values[value] = key
 	bm.orderedKeys = append(bm.orderedKeys, key)
+}
 
+// Set sets a key-value pair on the map. Returns an error if key or value is duplicate
+func (bm *BiMap[K, V]) Set(key K, value V) error {
+	if bm.HasKey(key) {
+		return gerrors.New("BiMap can't have duplicate key %v", key)
+	}
+	if bm.HasVal(value) {
+		return gerrors.New("BiMap can't have duplicate value %v", value)
+	}
+	bm.set(key, value)
 	return nil
 }
 
-// GetValByKey gets a value from a key
-func (bm *BiMap) GetValByKey(key any) any {
+// ForceSet sets a key-value pair on the map, existing pairs which have the same key or value are removed first.
+// It returns true if any pair is removed.
+func (bm *BiMap[K, V]) ForceSet(key K, value V) bool {
+	if oldValue, ok := bm.keys[key]; ok && oldValue == value {
+		return false
+	}
+	replaced := bm.DelByKey(key) == nil
+	replaced = bm.DelByVal(value) == nil || replaced
+	bm.set(key, value)
+	return replaced
+}
+
+// GetValByKey gets a value from a key, it returns zero value if key doesn't exist.
+func (bm *BiMap[K, V]) GetValByKey(key K) V {
 	return bm.keys[key]
 }
 
-// GetKeyByVal gets a key from a value
-func (bm *BiMap) GetKeyByVal(value any) any {
+// GetKeyByVal gets a key from a value, it returns zero key if value doesn't exist.
+func (bm *BiMap[K, V]) GetKeyByVal(value V) K {
 	return bm.values[value]
 }
 
-func (bm *BiMap) getIndexOfKey(key any) int {
-	for i, k := range bm.orderedKeys {
-		if key == k {
-			return i
-		}
-	}
+// LookupVal gets a value from a key and reports whether key exists.
+func (bm *BiMap[K, V]) LookupVal(key K) (V, bool) {
+	value, ok := bm.keys[key]
+	return value, ok
+}
 
-	return -1
+// LookupKey gets a key from a value and reports whether value exists.
+func (bm *BiMap[K, V]) LookupKey(value V) (K, bool) {
+	key, ok := bm.values[value]
+	return key, ok
 }
 
-func (bm *BiMap) deletePair(key any, value any) {
+func (bm *BiMap[K, V]) HasKey(key K) bool {
+	_, ok := bm.keys[key]
+	return ok
+}
+
+func (bm *BiMap[K, V]) HasVal(value V) bool {
+	_, ok := bm.values[value]
+	return ok
+}
+
+func (bm *BiMap[K, V]) deletePair(key K, value V) {
 	delete(bm.keys, key)
 	delete(bm.values, value)
-	keyIndex := bm.getIndexOfKey(key)
-	bm.orderedKeys = append(bm.orderedKeys[:keyIndex], bm.orderedKeys[:keyIndex+1])
+	if i := slices.Index(bm.orderedKeys, key); i >= 0 {
+		bm.orderedKeys = slices.Delete(bm.orderedKeys, i, i+1)
+	}
 }
 
 // DelByVal deletes a key-value pair from a value. Returns an error if provided argument is not a value
-func (bm *BiMap) DelByVal(value any) error {
-	key := bm.GetKeyByVal(value)
-	if key == nil {
-		return errors.New("Key does not exist in BiMap")
+func (bm *BiMap[K, V]) DelByVal(value V) error {
+	key, ok := bm.values[value]
+	if !ok {
+		return gerrors.New("Value does not exist in BiMap")
 	}
-
 	bm.deletePair(key, value)
 	return nil
 }
 
 // DelByKey deletes a key-value pair from a key. Returns an error if provided argument is not a key
-func (bm *BiMap) DelByKey(key any) error {
-	value := bm.GetValByKey(key)
-	if value == nil {
-		return errors.New("Value does not exist in BiMap")
+func (bm *BiMap[K, V]) DelByKey(key K) error {
+	value, ok := bm.keys[key]
+	if !ok {
+		return gerrors.New("Key does not exist in BiMap")
 	}
-
 	bm.deletePair(key, value)
 	return nil
 }
 
 // Size returns the size of the map
-func (bm *BiMap) Size() int {
+func (bm *BiMap[K, V]) Size() int {
 	return len(bm.keys)
 }
 
-// Left returns the "key: value" mapping of the BiMap
-func (bm *BiMap) Left() map[any]any {
-	return bm.keys
+// Left returns a copy of the "key: value" mapping of the BiMap
+func (bm *BiMap[K, V]) Left() map[K]V {
+	return maps.Clone(bm.keys)
 }
 
-// Right returns the "value: key" mapping of the BiMap
-func (bm *BiMap) Right() map[any]any {
-	return bm.values
+// Right returns a copy of the "value: key" mapping of the BiMap
+func (bm *BiMap[K, V]) Right() map[V]K {
+	return maps.Clone(bm.values)
 }
 
-// Keys returns a slice with all the BiMap keys
-func (bm *BiMap) Keys() []any {
-	return bm.orderedKeys
+// Keys returns a slice with all the BiMap keys in insertion order
+func (bm *BiMap[K, V]) Keys() []K {
+	return slices.Clone(bm.orderedKeys)
 }
 
-// Vals returns a slice with all the BiMap values
-func (bm *BiMap) Vals() []any {
-	slice := make([]any, 0, len(bm.orderedKeys))
+// Vals returns a slice with all the BiMap values in insertion order of keys
+func (bm *BiMap[K, V]) Vals() []V {
+	slice := make([]V, 0, len(bm.orderedKeys))
 	for _, key := range bm.orderedKeys {
-		slice = append(slice, bm.GetValByKey(key))
+		slice = append(slice, bm.keys[key])
 	}
 	return slice
 }
 
-// IsEqual checks if a BiMap is equal to another
-func (bm *BiMap) IsEqual(otherBm BiMap) bool {
-	return reflect.DeepEqual(bm.values, otherBm.Right()) && reflect.DeepEqual(bm.keys, otherBm.Left())
-}
-
-// NewBiMap creates a new bi-directional map
-func NewBiMap(initialValues ...Tuple) (*BiMap, error) {
-	keys := make(map[any]any, len(initialValues))
-	values := make(map[any]any, len(initialValues))
-	orderedKeys := make([]any, 0, len(initialValues))
-
-	for _, tuple := range initialValues {
-		if keys[tuple.key] != nil {
-			return nil, errors.New("Initial values contain duplicated keys")
-		}
-
-		if values[tuple.value] != nil {
-			return nil, errors.New("Initial values contain duplicated values")
+// All iterates key-value pairs in insertion order, the map must not be modified during iteration.
+func (bm *BiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, key := range bm.orderedKeys {
+			if !yield(key, bm.keys[key]) {
+				return
+			}
 		}
-
-		keys[tuple.key] = tuple.value
-		values[tuple.value] = tuple.key
-		orderedKeys = append(orderedKeys, tuple.key)
 	}
+}
 
-	bm := &BiMap{
-		keys:        keys,
-		values:      values,
-		orderedKeys: orderedKeys,
-	}
-	return bm, nil
+// IsEqual checks if a BiMap has the same pairs with another, insertion order is ignored.
+func (bm *BiMap[K, V]) IsEqual(otherBm *BiMap[K, V]) bool {
+	return maps.Equal(bm.keys, otherBm.keys)
 }
@@ -6,7 +6,7 @@ import (
 )
 
 func TestBiMap(t *testing.T) {
-	initialValues := []Tuple{
+	initialValues := []Tuple[any, any]{
 		{"a", 1},
 		{"b", 2},
 		{true, false},
@@ -36,7 +36,7 @@ func TestBiMap(t *testing.T) {
 
 func TestBiMapEmpty(t *testing.T) {
 	expectedMaps := map[any]any{}
-	bm, _ := NewBiMap()
+	bm, _ := NewBiMap[any, any]()
 
 	keys := bm.Left()
 	if !reflect.DeepEqual(keys, expectedMaps) {
@@ -51,18 +51,18 @@ func TestBiMapEmpty(t *testing.T) {
 
 func TestBiMapDuplicate(t *testing.T) {
 	testCases := []struct {
-		input  []Tuple
+		input  []Tuple[any, any]
 		errMsg string
 	}{
 		{
-			input: []Tuple{
+			input: []Tuple[any, any]{
 				{"a", 1},
 				{"a", 2},
 			},
 			errMsg: "Initial values contain duplicated keys",
 		},
 		{
-			input: []Tuple{
+			input: []Tuple[any, any]{
 				{"a", 1},
 				{"b", 1},
 			},
@@ -91,7 +91,7 @@ func TestBiMap_Set(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		bm, err := NewBiMap()
+		bm, err := NewBiMap[any, any]()
 		if err != nil {
 			t.Error(err)
 			return
@@ -132,7 +132,7 @@ func TestBiMap_GetValByKey(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		bm, _ := NewBiMap()
+		bm, _ := NewBiMap[any, any]()
 		bm.Set(testCase.key, testCase.value)
 
 		if bm.GetValByKey(testCase.key) != testCase.value {
@@ -153,7 +153,7 @@ func TestBiMap_GetKeyByVal(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		bm, _ := NewBiMap()
+		bm, _ := NewBiMap[any, any]()
 		bm.Set(testCase.key, testCase.value)
 
 		if bm.GetKeyByVal(testCase.value) != testCase.key {
@@ -174,7 +174,7 @@ func TestBiMap_DelByVal(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		bm, _ := NewBiMap()
+		bm, _ := NewBiMap[any, any]()
 		bm.Set(testCase.key, testCase.value)
 		bm.DelByVal(testCase.value)
 
@@ -196,7 +196,7 @@ func TestBiMap_DelByKey(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		bm, _ := NewBiMap()
+		bm, _ := NewBiMap[any, any]()
 		bm.Set(testCase.key, testCase.value)
 		bm.DelByKey(testCase.key)
 
@@ -217,7 +217,7 @@ func TestBiMap_Size(t *testing.T) {
 		{true, false},
 	}
 
-	bm, _ := NewBiMap()
+	bm, _ := NewBiMap[any, any]()
 	for _, val := range valuesToInsert {
 		bm.Set(val.key, val.value)
 	}
@@ -238,7 +238,7 @@ func TestBiMap_Left(t *testing.T) {
 		{true, false},
 	}
 
-	bm, _ := NewBiMap()
+	bm, _ := NewBiMap[any, any]()
 	for _, val := range valuesToInsert {
 		bm.Set(val.key, val.value)
 	}
@@ -266,7 +266,7 @@ func TestBiMap_Right(t *testing.T) {
 		{true, false},
 	}
 
-	bm, _ := NewBiMap()
+	bm, _ := NewBiMap[any, any]()
 	for _, val := range valuesToInsert {
 		bm.Set(val.key, val.value)
 	}
@@ -294,7 +294,7 @@ func TestBiMap_Keys(t *testing.T) {
 		{true, false},
 	}
 
-	bm, _ := NewBiMap()
+	bm, _ := NewBiMap[any, any]()
 	for _, val := range valuesToInsert {
 		bm.Set(val.key, val.value)
 	}
@@ -320,7 +320,7 @@ func TestBiMap_Vals(t *testing.T) {
 		{true, false},
 	}
 
-	bm, _ := NewBiMap()
+	bm, _ := NewBiMap[any, any]()
 	for _, val := range valuesToInsert {
 		bm.Set(val.key, val.value)
 	}
@@ -337,27 +337,27 @@ func TestBiMap_Vals(t *testing.T) {
 
 func TestBiMap_IsEqual(t *testing.T) {
 	testCases := []struct {
-		firstBmValues  []Tuple
-		secondBmValues []Tuple
+		firstBmValues  []Tuple[any, any]
+		secondBmValues []Tuple[any, any]
 		expected       bool
 	}{
 		{
-			firstBmValues: []Tuple{
+			firstBmValues: []Tuple[any, any]{
 				{"a", 1},
 				{"b", 2},
 			},
-			secondBmValues: []Tuple{
+			secondBmValues: []Tuple[any, any]{
 				{"a", 1},
 				{"b", 2},
 			},
 			expected: true,
 		},
 		{
-			firstBmValues: []Tuple{
+			firstBmValues: []Tuple[any, any]{
 				{"a", 1},
 				{"b", 2},
 			},
-			secondBmValues: []Tuple{
+			secondBmValues: []Tuple[any, any]{
 				{"a", 1},
 				{"c", 3},
 			},
@@ -369,8 +369,37 @@ func TestBiMap_IsEqual(t *testing.T) {
 		firstBm, _ := NewBiMap(testCase.firstBmValues...)
 		secondBm, _ := NewBiMap(testCase.secondBmValues...)
 
-		if firstBm.IsEqual(*secondBm) != testCase.expected {
-			t.Fatalf("IsEqual result %v is different from %v for maps %v and %v", firstBm.IsEqual(*secondBm), testCase.expected, firstBm, secondBm)
+		if firstBm.IsEqual(secondBm) != testCase.expected {
+			t.Fatalf("IsEqual result %v is different from %v for maps %v and %v", firstBm.IsEqual(secondBm), testCase.expected, firstBm, secondBm)
 		}
 	}
 }
+
+func TestBiMap_ZeroValue(t *testing.T) {
+	bm, _ := NewBiMap[string, int]()
+	if err := bm.Set("", 0); err != nil {
+		t.Fatal(err)
+	}
+	if bm.Set("", 1) == nil || bm.Set("a", 0) == nil {
+		t.Fatalf("Zero key or value is treated as absent")
+	}
+	if _, ok := bm.LookupVal(""); !ok {
+		t.Fatalf("Zero key not found")
+	}
+	if err := bm.DelByVal(0); err != nil || bm.Size() != 0 {
+		t.Fatalf("Zero value not deleted: %v", err)
+	}
+}
+
+func TestBiMap_ForceSet(t *testing.T) {
+	bm, _ := NewBiMap(Tuple[string, int]{"a", 1}, Tuple[string, int]{"b", 2}, Tuple[string, int]{"c", 3})
+	if bm.ForceSet("c", 3) {
+		t.Fatalf("Same pair is not a conflict")
+	}
+	if !bm.ForceSet("a", 2) {
+		t.Fatalf("Conflicting pairs not replaced")
+	}
+	if !reflect.DeepEqual(bm.Keys(), []string{"c", "a"}) || !reflect.DeepEqual(bm.Right(), map[int]string{2: "a", 3: "c"}) {
+		t.Fatalf("Unexpected pairs %v %v", bm.Keys(), bm.Right())
+	}
+}
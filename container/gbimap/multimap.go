@@ -0,0 +1,216 @@
+package gbimap
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+type (
+	// MultiMap is a one-to-many map, every key has a set of distinct values.
+	// It is not safe for concurrent use.
+	MultiMap[K, V comparable] struct {
+		m    map[K]map[V]struct{}
+		size int
+	}
+
+	// BiMultiMap is a many-to-many relation, values of a key and keys of a value are both O(1) to find.
+	// It is not safe for concurrent use.
+	BiMultiMap[K, V comparable] struct {
+		forward  *MultiMap[K, V]
+		backward *MultiMap[V, K]
+	}
+)
+
+func NewMultiMap[K, V comparable]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{m: make(map[K]map[V]struct{})}
+}
+
+// Add adds value to key, it returns false if the pair already exists.
+func (mm *MultiMap[K, V]) Add(key K, value V) bool {
+	vs, ok := mm.m[key]
+	if !ok {
+		vs = make(map[V]struct{})
+		mm.m[key] = vs
+	}
+	if _, ok := vs[value]; ok {
+		return false
+	}
+	vs[value] = struct{}{}
+	mm.size++
+	return true
+}
+
+// Remove removes value from key, key is removed if it has no values left.
+func (mm *MultiMap[K, V]) Remove(key K, value V) bool {
+	vs, ok := mm.m[key]
+	if !ok {
+		return false
+	}
+	if _, ok := vs[value]; !ok {
+		return false
+	}
+	delete(vs, value)
+	mm.size--
+	if len(vs) == 0 {
+		delete(mm.m, key)
+	}
+	return true
+}
+
+// RemoveKey removes key and returns its values.
+func (mm *MultiMap[K, V]) RemoveKey(key K) []V {
+	vs := mm.Get(key)
+	mm.size -= len(vs)
+	delete(mm.m, key)
+	return vs
+}
+
+// Get returns values of key in random order.
+func (mm *MultiMap[K, V]) Get(key K) []V {
+	return slices.Collect(maps.Keys(mm.m[key]))
+}
+
+// Values iterates values of key, values can be removed during iteration.
+func (mm *MultiMap[K, V]) Values(key K) iter.Seq[V] {
+	return maps.Keys(mm.m[key])
+}
+
+func (mm *MultiMap[K, V]) Has(key K, value V) bool {
+	_, ok := mm.m[key][value]
+	return ok
+}
+
+func (mm *MultiMap[K, V]) HasKey(key K) bool {
+	_, ok := mm.m[key]
+	return ok
+}
+
+// Count returns count of values of key.
+func (mm *MultiMap[K, V]) Count(key K) int {
+	return len(mm.m[key])
+}
+
+// Len returns count of key-value pairs.
+func (mm *MultiMap[K, V]) Len() int {
+	return mm.size
+}
+
+// KeyLen returns count of keys.
+func (mm *MultiMap[K, V]) KeyLen() int {
+	return len(mm.m)
+}
+
+// Keys returns keys in random order.
+func (mm *MultiMap[K, V]) Keys() []K {
+	return slices.Collect(maps.Keys(mm.m))
+}
+
+// All iterates all key-value pairs in random order.
+func (mm *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, vs := range mm.m {
+			for value := range vs {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (mm *MultiMap[K, V]) Clear() {
+	clear(mm.m)
+	mm.size = 0
+}
+
+func NewBiMultiMap[K, V comparable]() *BiMultiMap[K, V] {
+	return &BiMultiMap[K, V]{forward: NewMultiMap[K, V](), backward: NewMultiMap[V, K]()}
+}
+
+// Add adds the relation between key and value, it returns false if it already exists.
+func (bm *BiMultiMap[K, V]) Add(key K, value V) bool {
+	if !bm.forward.Add(key, value) {
+		return false
+	}
+	bm.backward.Add(value, key)
+	return true
+}
+
+// Remove removes the relation between key and value.
+func (bm *BiMultiMap[K, V]) Remove(key K, value V) bool {
+	if !bm.forward.Remove(key, value) {
+		return false
+	}
+	bm.backward.Remove(value, key)
+	return true
+}
+
+// RemoveKey removes key and all its relations in O(k), k is count of its values.
+func (bm *BiMultiMap[K, V]) RemoveKey(key K) {
+	for _, value := range bm.forward.RemoveKey(key) {
+		bm.backward.Remove(value, key)
+	}
+}
+
+// RemoveVal removes value and all its relations in O(k), k is count of its keys.
+func (bm *BiMultiMap[K, V]) RemoveVal(value V) {
+	for _, key := range bm.backward.RemoveKey(value) {
+		bm.forward.Remove(key, value)
+	}
+}
+
+// GetVals returns values related to key.
+func (bm *BiMultiMap[K, V]) GetVals(key K) []V {
+	return bm.forward.Get(key)
+}
+
+// GetKeys returns keys related to value.
+func (bm *BiMultiMap[K, V]) GetKeys(value V) []K {
+	return bm.backward.Get(value)
+}
+
+func (bm *BiMultiMap[K, V]) Has(key K, value V) bool {
+	return bm.forward.Has(key, value)
+}
+
+func (bm *BiMultiMap[K, V]) HasKey(key K) bool {
+	return bm.forward.HasKey(key)
+}
+
+func (bm *BiMultiMap[K, V]) HasVal(value V) bool {
+	return bm.backward.HasKey(value)
+}
+
+// Len returns count of relations.
+func (bm *BiMultiMap[K, V]) Len() int {
+	return bm.forward.Len()
+}
+
+func (bm *BiMultiMap[K, V]) Keys() []K {
+	return bm.forward.Keys()
+}
+
+func (bm *BiMultiMap[K, V]) Vals() []V {
+	return bm.backward.Keys()
+}
+
+// Left returns the key to values view, it must not be modified.
+func (bm *BiMultiMap[K, V]) Left() *MultiMap[K, V] {
+	return bm.forward
+}
+
+// Right returns the value to keys view, it must not be modified.
+func (bm *BiMultiMap[K, V]) Right() *MultiMap[V, K] {
+	return bm.backward
+}
+
+// All iterates all relations in random order.
+func (bm *BiMultiMap[K, V]) All() iter.Seq2[K, V] {
+	return bm.forward.All()
+}
+
+func (bm *BiMultiMap[K, V]) Clear() {
+	bm.forward.Clear()
+	bm.backward.Clear()
+}
@@ -0,0 +1,57 @@
+package gbimap
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestMultiMap(t *testing.T) {
+	mm := NewMultiMap[string, int]()
+	gtest.AssertTrue(t, mm.Add("a", 1) && mm.Add("a", 2) && !mm.Add("a", 1) && mm.Add("b", 0), "add")
+	gtest.AssertTrue(t, mm.Len() == 3 && mm.KeyLen() == 2 && mm.Count("a") == 2, "len %d", mm.Len())
+	gtest.AssertTrue(t, mm.Has("b", 0) && !mm.Has("b", 1), "has zero value")
+	vs := mm.Get("a")
+	slices.Sort(vs)
+	gtest.AssertTrue(t, slices.Equal(vs, []int{1, 2}), "get %v", vs)
+	gtest.AssertTrue(t, mm.Remove("b", 0) && !mm.HasKey("b") && !mm.Remove("b", 0), "remove")
+	gtest.AssertTrue(t, len(mm.RemoveKey("a")) == 2 && mm.Len() == 0, "remove key")
+}
+
+func TestBiMultiMap(t *testing.T) {
+	// users and groups
+	bm := NewBiMultiMap[string, string]()
+	bm.Add("alice", "admin")
+	bm.Add("alice", "dev")
+	bm.Add("bob", "dev")
+	bm.Add("carol", "ops")
+	users := bm.GetKeys("dev")
+	slices.Sort(users)
+	gtest.AssertTrue(t, slices.Equal(users, []string{"alice", "bob"}), "keys of dev %v", users)
+	gtest.AssertTrue(t, bm.Len() == 4 && bm.Has("bob", "dev") && !bm.Has("bob", "ops"), "has")
+
+	bm.RemoveVal("dev")
+	gtest.AssertTrue(t, slices.Equal(bm.GetVals("alice"), []string{"admin"}) && !bm.HasKey("bob"), "remove value")
+	bm.RemoveKey("carol")
+	gtest.AssertTrue(t, !bm.HasVal("ops") && bm.Len() == 1 && bm.Right().Len() == 1, "remove key")
+	gtest.AssertTrue(t, bm.Remove("alice", "admin") && bm.Len() == 0 && bm.Right().Len() == 0, "remove")
+}
+
+func TestSyncBiMap(t *testing.T) {
+	s, err := NewSyncBiMap[int, int]()
+	gtest.Assert(t, err)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.ForceSet(i, i*10+g%2)
+				_, _ = s.LookupKey(i * 10)
+			}
+		}(g)
+	}
+	wg.Wait()
+	gtest.AssertTrue(t, s.Size() == 100 && len(s.Right()) == 100, "size %d", s.Size())
+}
@@ -0,0 +1,92 @@
+package gbimap
+
+import (
+	"sync"
+)
+
+// SyncBiMap is a BiMap protected by RWMutex.
+type SyncBiMap[K, V comparable] struct {
+	mu sync.RWMutex
+	bm *BiMap[K, V]
+}
+
+func NewSyncBiMap[K, V comparable](initialValues ...Tuple[K, V]) (*SyncBiMap[K, V], error) {
+	bm, err := NewBiMap(initialValues...)
+	if err != nil {
+		return nil, err
+	}
+	return &SyncBiMap[K, V]{bm: bm}, nil
+}
+
+func (s *SyncBiMap[K, V]) Set(key K, value V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bm.Set(key, value)
+}
+
+func (s *SyncBiMap[K, V]) ForceSet(key K, value V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bm.ForceSet(key, value)
+}
+
+func (s *SyncBiMap[K, V]) LookupVal(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm.LookupVal(key)
+}
+
+func (s *SyncBiMap[K, V]) LookupKey(value V) (K, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm.LookupKey(value)
+}
+
+func (s *SyncBiMap[K, V]) DelByKey(key K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bm.DelByKey(key)
+}
+
+func (s *SyncBiMap[K, V]) DelByVal(value V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bm.DelByVal(value)
+}
+
+func (s *SyncBiMap[K, V]) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm.Size()
+}
+
+func (s *SyncBiMap[K, V]) Keys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm.Keys()
+}
+
+func (s *SyncBiMap[K, V]) Vals() []V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm.Vals()
+}
+
+func (s *SyncBiMap[K, V]) Left() map[K]V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm.Left()
+}
+
+func (s *SyncBiMap[K, V]) Right() map[V]K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm.Right()
+}
+
+// Update calls fn with the underlying BiMap under write lock, so several operations are atomic.
+func (s *SyncBiMap[K, V]) Update(fn func(bm *BiMap[K, V]) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.bm)
+}
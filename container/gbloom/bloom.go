@@ -1,79 +1,77 @@
 package gbloom
 
 import (
-	"bufio"
 	"github.com/davidforest123/goutil/basic/gerrors"
 	"github.com/davidforest123/goutil/sys/gfs"
 	"github.com/willf/bloom"
+	"io"
 	"os"
 )
 
+// BloomFilter is a classic fixed capacity bloom filter, optionally persisted to savePath.
 type BloomFilter struct {
 	filter        *bloom.BloomFilter
+	count         uint
 	autoSave      bool
 	savePath      string
 	writeDiskFlag int
-	file          *os.File
 }
 
+// autoSaveInterval is the count of Add calls between two automatic saves.
+const autoSaveInterval = 100
+
+// New creates a bloom filter for maxCount items with false positive rate maxFp.
+// If savePath is not empty and the file exists, the filter is loaded from it.
+// If autoSave is true, the filter is saved to savePath every autoSaveInterval additions and on Close.
 func New(maxCount uint, maxFp float64, autoSave bool, savePath string) (*BloomFilter, error) {
-	if maxCount < 2 || maxFp < 0 || maxFp > 1 {
+	if maxCount < 2 || maxFp <= 0 || maxFp >= 1 {
 		return nil, gerrors.New("invalid bloom filter parameters")
 	}
-	var bf BloomFilter
-	bf.autoSave = autoSave
-	bf.savePath = savePath
-	bf.writeDiskFlag = 0
-	var err error
-	bf.file, err = os.Open(savePath)
-	if err != nil {
-		return nil, err
+	if autoSave && savePath == "" {
+		return nil, gerrors.New("autoSave requires savePath")
 	}
 
-	pi, err := gfs.GetPathInfo(savePath)
-	if err != nil {
-		return nil, err
-	}
-	fileSize, err := gfs.GetFileByteSize(savePath)
-	if err != nil {
-		return nil, err
-	}
-	if pi.Exist {
-		total := int64(0)
-		for total < fileSize {
-			n, err := bf.filter.ReadFrom(bufio.NewReader(bf.file))
-			if err != nil {
-				return nil, err
-			}
-			total += n
+	if savePath != "" && gfs.FileExits(savePath) {
+		f, err := LoadFile(savePath)
+		if err != nil {
+			return nil, err
 		}
+		bf, ok := f.(*BloomFilter)
+		if !ok {
+			return nil, gerrors.New("file %s contains %T, not a classic bloom filter", savePath, f)
+		}
+		bf.autoSave = autoSave
+		bf.savePath = savePath
+		return bf, nil
+	}
 
-	} else {
-		bf.filter = bloom.NewWithEstimates(maxCount, maxFp)
+	bf := &BloomFilter{
+		filter:   bloom.NewWithEstimates(maxCount, maxFp),
+		autoSave: autoSave,
+		savePath: savePath,
 	}
 	if bf.filter == nil {
 		return nil, gerrors.New("bloom filter create fail")
 	}
-
-	return &bf, nil
+	return bf, nil
 }
 
-func (bf *BloomFilter) Add(data []byte) {
+func (bf *BloomFilter) Add(data []byte) error {
 	bf.filter.Add(data)
-	bf.writeDiskFlag++
-	if bf.autoSave && bf.writeDiskFlag > 100 {
-		bf.filter.WriteTo(bufio.NewWriter(bf.file))
-		bf.writeDiskFlag = 0
-	}
+	bf.count++
+	return bf.afterWrite()
+}
+
+func (bf *BloomFilter) AddStr(str string) error {
+	return bf.Add([]byte(str))
 }
 
-func (bf *BloomFilter) AddStr(str string) {
-	bf.filter.AddString(str)
+func (bf *BloomFilter) afterWrite() error {
 	bf.writeDiskFlag++
-	if bf.autoSave && bf.writeDiskFlag > 100 {
-		bf.filter.WriteTo(bufio.NewWriter(bf.file))
-		bf.writeDiskFlag = 0
+	if bf.autoSave && bf.writeDiskFlag >= autoSaveInterval {
+		return bf.Save()
 	}
+	return nil
 }
 
 func (bf *BloomFilter) MightContain(data []byte) bool {
@@ -84,6 +82,64 @@ func (bf *BloomFilter) MightContainStr(str string) bool {
 	return bf.filter.TestString(str)
 }
 
+// Count returns the count of added items, duplicates included.
+func (bf *BloomFilter) Count() uint {
+	return bf.count
+}
+
+// EstimateFalsePositiveRate returns the theoretical false positive rate with current count.
+func (bf *BloomFilter) EstimateFalsePositiveRate() float64 {
+	return estimateFpRate(bf.filter.Cap(), bf.filter.K(), bf.count)
+}
+
+// Save writes the filter to savePath atomically.
+func (bf *BloomFilter) Save() error {
+	if bf.savePath == "" {
+		return gerrors.New("bloom filter has no savePath")
+	}
+	if err := SaveFile(bf, bf.savePath); err != nil {
+		return err
+	}
+	bf.writeDiskFlag = 0
+	return nil
+}
+
+// Close saves pending changes if autoSave is enabled.
+func (bf *BloomFilter) Close() error {
+	if bf.autoSave && bf.writeDiskFlag > 0 {
+		return bf.Save()
+	}
+	return nil
+}
+
+// Reset clears the filter and removes its save file.
 func (bf *BloomFilter) Reset() error {
-	return os.Remove(bf.savePath)
+	bf.filter.ClearAll()
+	bf.count = 0
+	bf.writeDiskFlag = 0
+	if bf.savePath != "" && gfs.FileExits(bf.savePath) {
+		return os.Remove(bf.savePath)
+	}
+	return nil
+}
+
+func (bf *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	cw.write(uint64(bf.count))
+	cw.writeFrom(bf.filter)
+	return cw.n, cw.err
+}
+
+func (bf *BloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countReader{r: r}
+	count := uint64(0)
+	cr.read(&count)
+	filter := &bloom.BloomFilter{}
+	cr.readFrom(filter)
+	if cr.err != nil {
+		return cr.n, cr.err
+	}
+	bf.filter = filter
+	bf.count = uint(count)
+	return cr.n, nil
 }
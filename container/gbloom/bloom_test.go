@@ -0,0 +1,148 @@
+package gbloom
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bf.bin")
+	bf, err := New(1000, 0.01, true, path)
+	gtest.Assert(t, err)
+	for i := 0; i < 250; i++ {
+		gtest.Assert(t, bf.AddStr("item"+strconv.Itoa(i)))
+	}
+	gtest.Assert(t, bf.Close())
+
+	bf2, err := New(1000, 0.01, false, path)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, bf2.Count() == 250, "count should be 250 but got %d", bf2.Count())
+	for i := 0; i < 250; i++ {
+		gtest.AssertTrue(t, bf2.MightContainStr("item"+strconv.Itoa(i)), "item%d lost after reload", i)
+	}
+
+	gtest.Assert(t, bf2.Reset())
+	gtest.AssertTrue(t, !bf2.MightContainStr("item0"), "filter should be empty after Reset")
+	_, err = os.Stat(path)
+	gtest.AssertTrue(t, os.IsNotExist(err), "save file should be removed after Reset")
+}
+
+func TestLoadFile_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bf.bin")
+	bf, err := New(100, 0.01, false, path)
+	gtest.Assert(t, err)
+	gtest.Assert(t, bf.AddStr("abc"))
+	gtest.Assert(t, bf.Save())
+
+	b, err := os.ReadFile(path)
+	gtest.Assert(t, err)
+	b[headerSize+3] ^= 0xff
+	_, err = Unmarshal(b)
+	gtest.AssertTrue(t, err == ErrChecksum, "should be checksum error but got %v", err)
+	_, err = Unmarshal(b[:len(b)-1])
+	gtest.AssertTrue(t, err == ErrInvalidFile, "should be invalid file error but got %v", err)
+}
+
+func TestScalableBloomFilter(t *testing.T) {
+	sbf, err := NewScalable(100, 0.01)
+	gtest.Assert(t, err)
+	for i := 0; i < 5000; i++ {
+		gtest.Assert(t, sbf.AddStr("in"+strconv.Itoa(i)))
+	}
+	gtest.AssertTrue(t, sbf.Layers() > 1, "filter should grow, layers %d", sbf.Layers())
+	for i := 0; i < 5000; i++ {
+		gtest.AssertTrue(t, sbf.MightContainStr("in"+strconv.Itoa(i)), "false negative in%d", i)
+	}
+	fp := 0
+	for i := 0; i < 10000; i++ {
+		if sbf.MightContainStr("out" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	gtest.AssertTrue(t, fp < 200, "false positive rate too high: %d/10000", fp)
+
+	b, err := Marshal(sbf)
+	gtest.Assert(t, err)
+	f, err := Unmarshal(b)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, f.Count() == sbf.Count(), "count mismatch after unmarshal")
+	gtest.AssertTrue(t, f.MightContain([]byte("in4999")), "item lost after unmarshal")
+}
+
+func TestCountingBloomFilter(t *testing.T) {
+	cbf, err := NewCounting(1000, 0.01)
+	gtest.Assert(t, err)
+	gtest.Assert(t, cbf.AddStr("a"))
+	gtest.Assert(t, cbf.AddStr("b"))
+	gtest.Assert(t, cbf.AddStr("b"))
+	gtest.AssertTrue(t, cbf.RemoveStr("a"), "remove a should succeed")
+	gtest.AssertTrue(t, !cbf.MightContainStr("a"), "a should be removed")
+	gtest.AssertTrue(t, cbf.RemoveStr("b"), "remove b should succeed")
+	gtest.AssertTrue(t, cbf.MightContainStr("b"), "b was added twice, should still exist")
+	gtest.AssertTrue(t, !cbf.RemoveStr("c"), "remove c should fail")
+	gtest.AssertTrue(t, cbf.Count() == 1, "count should be 1 but got %d", cbf.Count())
+}
+
+func TestCuckooFilter(t *testing.T) {
+	cf, err := NewCuckoo(1000)
+	gtest.Assert(t, err)
+	for i := 0; i < 1000; i++ {
+		gtest.Assert(t, cf.AddStr(strconv.Itoa(i)))
+	}
+	for i := 0; i < 1000; i++ {
+		gtest.AssertTrue(t, cf.MightContainStr(strconv.Itoa(i)), "false negative %d", i)
+	}
+	for i := 0; i < 500; i++ {
+		gtest.AssertTrue(t, cf.RemoveStr(strconv.Itoa(i)), "remove %d should succeed", i)
+	}
+	gtest.AssertTrue(t, cf.Count() == 500, "count should be 500 but got %d", cf.Count())
+	for i := 500; i < 1000; i++ {
+		gtest.AssertTrue(t, cf.MightContainStr(strconv.Itoa(i)), "false negative %d after remove", i)
+	}
+
+	full, err := NewCuckoo(4)
+	gtest.Assert(t, err)
+	for i := 0; ; i++ {
+		if err := full.AddStr(strconv.Itoa(i)); err != nil {
+			gtest.AssertTrue(t, err == ErrFilterFull, "should be full error but got %v", err)
+			gtest.AssertTrue(t, full.Count() == uint(i), "count should be %d but got %d", i, full.Count())
+			for j := 0; j < i; j++ {
+				gtest.AssertTrue(t, full.MightContainStr(strconv.Itoa(j)), "item %d lost when filter full", j)
+			}
+			break
+		}
+	}
+}
+
+func TestSyncFilter(t *testing.T) {
+	cf, err := NewCuckoo(10000)
+	gtest.Assert(t, err)
+	sf := NewSync(cf)
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := strconv.Itoa(g*1000 + i)
+				_ = sf.AddStr(key)
+				sf.MightContainStr(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+	gtest.AssertTrue(t, sf.Count() == 4000, "count should be 4000 but got %d", sf.Count())
+	gtest.AssertTrue(t, sf.Remove([]byte("0")), "remove through SyncFilter should succeed")
+
+	path := filepath.Join(t.TempDir(), "cf.bin")
+	gtest.Assert(t, SaveFile(sf, path))
+	f, err := LoadFile(path)
+	gtest.Assert(t, err)
+	_, ok := f.(*CuckooFilter)
+	gtest.AssertTrue(t, ok, "should load a *CuckooFilter but got %T", f)
+	gtest.AssertTrue(t, f.Count() == 3999, "count should be 3999 but got %d", f.Count())
+}
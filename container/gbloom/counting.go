@@ -0,0 +1,128 @@
+package gbloom
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/willf/bloom"
+	"io"
+	"math"
+)
+
+// CountingBloomFilter replaces every bit of a classic bloom filter with an 8-bit counter, so items can be removed.
+// A counter which reaches 255 sticks there, it is never decreased again to avoid false negatives.
+type CountingBloomFilter struct {
+	counters []uint8
+	m        uint
+	k        uint
+	count    uint
+}
+
+const counterMax = math.MaxUint8
+
+// NewCounting creates a counting bloom filter for maxCount items with false positive rate maxFp.
+func NewCounting(maxCount uint, maxFp float64) (*CountingBloomFilter, error) {
+	if maxCount < 2 || maxFp <= 0 || maxFp >= 1 {
+		return nil, gerrors.New("invalid counting bloom filter parameters")
+	}
+	m, k := bloom.EstimateParameters(maxCount, maxFp)
+	return &CountingBloomFilter{
+		counters: make([]uint8, m),
+		m:        m,
+		k:        k,
+	}, nil
+}
+
+func (cbf *CountingBloomFilter) locations(data []byte) []uint64 {
+	locs := bloom.Locations(data, cbf.k)
+	for i := range locs {
+		locs[i] %= uint64(cbf.m)
+	}
+	return locs
+}
+
+func (cbf *CountingBloomFilter) Add(data []byte) error {
+	for _, loc := range cbf.locations(data) {
+		if cbf.counters[loc] < counterMax {
+			cbf.counters[loc]++
+		}
+	}
+	cbf.count++
+	return nil
+}
+
+func (cbf *CountingBloomFilter) AddStr(str string) error {
+	return cbf.Add([]byte(str))
+}
+
+// Remove deletes one occurrence of data, it returns false if data is definitely not in the filter.
+// Removing an item which was never added may introduce false negatives for other items.
+func (cbf *CountingBloomFilter) Remove(data []byte) bool {
+	locs := cbf.locations(data)
+	for _, loc := range locs {
+		if cbf.counters[loc] == 0 {
+			return false
+		}
+	}
+	for _, loc := range locs {
+		if cbf.counters[loc] < counterMax {
+			cbf.counters[loc]--
+		}
+	}
+	if cbf.count > 0 {
+		cbf.count--
+	}
+	return true
+}
+
+func (cbf *CountingBloomFilter) RemoveStr(str string) bool {
+	return cbf.Remove([]byte(str))
+}
+
+func (cbf *CountingBloomFilter) MightContain(data []byte) bool {
+	for _, loc := range cbf.locations(data) {
+		if cbf.counters[loc] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (cbf *CountingBloomFilter) MightContainStr(str string) bool {
+	return cbf.MightContain([]byte(str))
+}
+
+func (cbf *CountingBloomFilter) Count() uint {
+	return cbf.count
+}
+
+func (cbf *CountingBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	cw.write(uint64(cbf.m))
+	cw.write(uint64(cbf.k))
+	cw.write(uint64(cbf.count))
+	cw.write(cbf.counters)
+	return cw.n, cw.err
+}
+
+func (cbf *CountingBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countReader{r: r}
+	m, k, count := uint64(0), uint64(0), uint64(0)
+	cr.read(&m)
+	cr.read(&k)
+	cr.read(&count)
+	if cr.err != nil {
+		return cr.n, cr.err
+	}
+	if m == 0 || k == 0 || m > math.MaxInt32 {
+		return cr.n, ErrInvalidFile
+	}
+	counters := make([]uint8, m)
+	cr.read(counters)
+	if cr.err != nil {
+		return cr.n, cr.err
+	}
+	cbf.m = uint(m)
+	cbf.k = uint(k)
+	cbf.count = uint(count)
+	cbf.counters = counters
+	return cr.n, nil
+}
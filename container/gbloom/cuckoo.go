@@ -0,0 +1,187 @@
+package gbloom
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/willf/bloom"
+	"io"
+	"math/bits"
+	"math/rand"
+)
+
+// CuckooFilter is an alternative to counting bloom filter which supports deletion with less space,
+// every item is stored as a 16-bit fingerprint in one of its two candidate buckets.
+//
+// Reference
+// Fan, Andersen, Kaminsky, Mitzenmacher: Cuckoo Filter: Practically Better Than Bloom, 2014.
+type CuckooFilter struct {
+	slots      []uint16 // numBuckets * cuckooBucketSize fingerprints, 0 means empty
+	numBuckets uint64   // always power of 2
+	count      uint
+}
+
+const (
+	cuckooBucketSize = 4
+	cuckooMaxKicks   = 500
+)
+
+// NewCuckoo creates a cuckoo filter which holds at least capacity items,
+// the false positive rate is about 8/65536 at full load.
+func NewCuckoo(capacity uint) (*CuckooFilter, error) {
+	if capacity < 1 {
+		return nil, gerrors.New("invalid cuckoo filter capacity")
+	}
+	nb := uint64(capacity+cuckooBucketSize-1) / cuckooBucketSize
+	// Keep load factor below 95% which is the practical limit of 4-way buckets.
+	nb = nb + nb/16 + 1
+	nb = uint64(1) << (64 - bits.LeadingZeros64(nb-1))
+	return &CuckooFilter{
+		slots:      make([]uint16, nb*cuckooBucketSize),
+		numBuckets: nb,
+	}, nil
+}
+
+func (cf *CuckooFilter) indexAndFingerprint(data []byte) (uint64, uint16) {
+	h := bloom.Locations(data, 1)[0]
+	fp := uint16(h >> 48)
+	if fp == 0 {
+		fp = 1
+	}
+	return h & (cf.numBuckets - 1), fp
+}
+
+func (cf *CuckooFilter) altIndex(i uint64, fp uint16) uint64 {
+	// XOR with the hash of fingerprint, so altIndex(altIndex(i, fp), fp) == i.
+	return (i ^ (uint64(fp) * 0x5bd1e995)) & (cf.numBuckets - 1)
+}
+
+func (cf *CuckooFilter) bucket(i uint64) []uint16 {
+	return cf.slots[i*cuckooBucketSize : (i+1)*cuckooBucketSize]
+}
+
+func (cf *CuckooFilter) insertInto(i uint64, fp uint16) bool {
+	b := cf.bucket(i)
+	for j := range b {
+		if b[j] == 0 {
+			b[j] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// Add inserts data, it returns ErrFilterFull if no slot could be found after cuckooMaxKicks relocations.
+// The filter is unchanged when ErrFilterFull is returned.
+func (cf *CuckooFilter) Add(data []byte) error {
+	i1, fp := cf.indexAndFingerprint(data)
+	i2 := cf.altIndex(i1, fp)
+	if cf.insertInto(i1, fp) || cf.insertInto(i2, fp) {
+		cf.count++
+		return nil
+	}
+
+	type kick struct {
+		slot uint64
+		old  uint16
+	}
+	var history []kick
+	i := i1
+	if rand.Intn(2) == 1 {
+		i = i2
+	}
+	for n := 0; n < cuckooMaxKicks; n++ {
+		slot := i*cuckooBucketSize + uint64(rand.Intn(cuckooBucketSize))
+		history = append(history, kick{slot: slot, old: cf.slots[slot]})
+		fp, cf.slots[slot] = cf.slots[slot], fp
+		i = cf.altIndex(i, fp)
+		if cf.insertInto(i, fp) {
+			cf.count++
+			return nil
+		}
+	}
+	// Roll back relocations, so no item already stored gets lost.
+	for n := len(history) - 1; n >= 0; n-- {
+		cf.slots[history[n].slot] = history[n].old
+	}
+	return ErrFilterFull
+}
+
+func (cf *CuckooFilter) AddStr(str string) error {
+	return cf.Add([]byte(str))
+}
+
+func (cf *CuckooFilter) MightContain(data []byte) bool {
+	i1, fp := cf.indexAndFingerprint(data)
+	i2 := cf.altIndex(i1, fp)
+	for _, i := range []uint64{i1, i2} {
+		for _, v := range cf.bucket(i) {
+			if v == fp {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (cf *CuckooFilter) MightContainStr(str string) bool {
+	return cf.MightContain([]byte(str))
+}
+
+// Remove deletes one occurrence of data, only items which were really added should be removed.
+func (cf *CuckooFilter) Remove(data []byte) bool {
+	i1, fp := cf.indexAndFingerprint(data)
+	i2 := cf.altIndex(i1, fp)
+	for _, i := range []uint64{i1, i2} {
+		b := cf.bucket(i)
+		for j := range b {
+			if b[j] == fp {
+				b[j] = 0
+				cf.count--
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (cf *CuckooFilter) RemoveStr(str string) bool {
+	return cf.Remove([]byte(str))
+}
+
+func (cf *CuckooFilter) Count() uint {
+	return cf.count
+}
+
+// LoadFactor returns the ratio of used slots.
+func (cf *CuckooFilter) LoadFactor() float64 {
+	return float64(cf.count) / float64(len(cf.slots))
+}
+
+func (cf *CuckooFilter) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	cw.write(cf.numBuckets)
+	cw.write(uint64(cf.count))
+	cw.write(cf.slots)
+	return cw.n, cw.err
+}
+
+func (cf *CuckooFilter) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countReader{r: r}
+	nb, count := uint64(0), uint64(0)
+	cr.read(&nb)
+	cr.read(&count)
+	if cr.err != nil {
+		return cr.n, cr.err
+	}
+	if nb == 0 || nb&(nb-1) != 0 || nb > 1<<28 {
+		return cr.n, ErrInvalidFile
+	}
+	slots := make([]uint16, nb*cuckooBucketSize)
+	cr.read(slots)
+	if cr.err != nil {
+		return cr.n, cr.err
+	}
+	cf.numBuckets = nb
+	cf.count = uint(count)
+	cf.slots = slots
+	return cr.n, nil
+}
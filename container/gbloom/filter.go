@@ -0,0 +1,93 @@
+package gbloom
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"io"
+	"sync"
+)
+
+type (
+	// Filter is the common interface of all probabilistic membership filters in this package.
+	Filter interface {
+		Add(data []byte) error
+		MightContain(data []byte) bool
+		Count() uint
+		WriteTo(w io.Writer) (int64, error)
+		ReadFrom(r io.Reader) (int64, error)
+	}
+
+	// Remover is implemented by filters which support deletion, like CountingBloomFilter and CuckooFilter.
+	Remover interface {
+		Remove(data []byte) bool
+	}
+
+	// SyncFilter wraps a Filter to make it safe for concurrent use.
+	SyncFilter struct {
+		mu     sync.RWMutex
+		filter Filter
+	}
+)
+
+var (
+	ErrFilterFull = gerrors.New("filter is full")
+	ErrNotRemover = gerrors.New("filter doesn't support remove")
+)
+
+// NewSync returns a concurrency-safe wrapper of f.
+func NewSync(f Filter) *SyncFilter {
+	return &SyncFilter{filter: f}
+}
+
+func (sf *SyncFilter) Add(data []byte) error {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	return sf.filter.Add(data)
+}
+
+func (sf *SyncFilter) AddStr(str string) error {
+	return sf.Add([]byte(str))
+}
+
+func (sf *SyncFilter) MightContain(data []byte) bool {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.filter.MightContain(data)
+}
+
+func (sf *SyncFilter) MightContainStr(str string) bool {
+	return sf.MightContain([]byte(str))
+}
+
+// Remove deletes data if the wrapped filter implements Remover, otherwise it returns false.
+func (sf *SyncFilter) Remove(data []byte) bool {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	r, ok := sf.filter.(Remover)
+	if !ok {
+		return false
+	}
+	return r.Remove(data)
+}
+
+func (sf *SyncFilter) Count() uint {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.filter.Count()
+}
+
+func (sf *SyncFilter) WriteTo(w io.Writer) (int64, error) {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.filter.WriteTo(w)
+}
+
+func (sf *SyncFilter) ReadFrom(r io.Reader) (int64, error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	return sf.filter.ReadFrom(r)
+}
+
+// Unwrap returns the wrapped filter, callers must not use it concurrently with sf.
+func (sf *SyncFilter) Unwrap() Filter {
+	return sf.filter
+}
@@ -0,0 +1,188 @@
+package gbloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/sys/gfs"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// Persistent file layout, all integers are big endian:
+//
+//	magic "GBLM" | version u8 | kind u8 | payload length u64 | payload | crc32(IEEE) of all previous bytes u32
+//
+// The file is written by gfs.BytesToFileAtomic, so a crash during SaveFile never leaves a half-written
+// filter behind.
+
+const (
+	fileMagic   = "GBLM"
+	fileVersion = 1
+	headerSize  = len(fileMagic) + 1 + 1 + 8
+)
+
+const (
+	kindClassic byte = iota + 1
+	kindScalable
+	kindCounting
+	kindCuckoo
+)
+
+var (
+	ErrInvalidFile = gerrors.New("invalid bloom filter file")
+	ErrChecksum    = gerrors.New("bloom filter file checksum mismatch")
+)
+
+func filterKind(f Filter) (byte, error) {
+	switch f.(type) {
+	case *BloomFilter:
+		return kindClassic, nil
+	case *ScalableBloomFilter:
+		return kindScalable, nil
+	case *CountingBloomFilter:
+		return kindCounting, nil
+	case *CuckooFilter:
+		return kindCuckoo, nil
+	case *SyncFilter:
+		return filterKind(f.(*SyncFilter).Unwrap())
+	default:
+		return 0, gerrors.New("unsupported filter type %T", f)
+	}
+}
+
+func newFilterOfKind(kind byte) (Filter, error) {
+	switch kind {
+	case kindClassic:
+		return &BloomFilter{}, nil
+	case kindScalable:
+		return &ScalableBloomFilter{}, nil
+	case kindCounting:
+		return &CountingBloomFilter{}, nil
+	case kindCuckoo:
+		return &CuckooFilter{}, nil
+	default:
+		return nil, gerrors.New("unknown filter kind %d", kind)
+	}
+}
+
+// Marshal encodes f into the persistent file format.
+func Marshal(f Filter) ([]byte, error) {
+	kind, err := filterKind(f)
+	if err != nil {
+		return nil, err
+	}
+	payload := bytes.Buffer{}
+	if _, err := f.WriteTo(&payload); err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+	buf.Grow(headerSize + payload.Len() + 4)
+	buf.WriteString(fileMagic)
+	buf.WriteByte(fileVersion)
+	buf.WriteByte(kind)
+	_ = binary.Write(&buf, binary.BigEndian, uint64(payload.Len()))
+	buf.Write(payload.Bytes())
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a filter from the persistent file format and verifies its checksum.
+func Unmarshal(b []byte) (Filter, error) {
+	if len(b) < headerSize+4 || string(b[:len(fileMagic)]) != fileMagic {
+		return nil, ErrInvalidFile
+	}
+	if b[len(fileMagic)] != fileVersion {
+		return nil, gerrors.New("unsupported bloom filter file version %d", b[len(fileMagic)])
+	}
+	kind := b[len(fileMagic)+1]
+	payloadLen := binary.BigEndian.Uint64(b[len(fileMagic)+2 : headerSize])
+	if uint64(len(b)-headerSize-4) != payloadLen {
+		return nil, ErrInvalidFile
+	}
+	body := b[:len(b)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(b[len(b)-4:]) {
+		return nil, ErrChecksum
+	}
+
+	f, err := newFilterOfKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.ReadFrom(bytes.NewReader(body[headerSize:])); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SaveFile writes f to path atomically.
+func SaveFile(f Filter, path string) error {
+	b, err := Marshal(f)
+	if err != nil {
+		return err
+	}
+	return gfs.BytesToFileAtomic(b, path)
+}
+
+// LoadFile reads a filter saved by SaveFile.
+func LoadFile(path string) (Filter, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(b)
+}
+
+// binary helpers shared by filter implementations.
+
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) write(v any) {
+	if cw.err != nil {
+		return
+	}
+	cw.err = binary.Write(cw.w, binary.BigEndian, v)
+	if cw.err == nil {
+		cw.n += int64(binary.Size(v))
+	}
+}
+
+func (cw *countWriter) writeFrom(wt io.WriterTo) {
+	if cw.err != nil {
+		return
+	}
+	n, err := wt.WriteTo(cw.w)
+	cw.n += n
+	cw.err = err
+}
+
+type countReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (cr *countReader) read(v any) {
+	if cr.err != nil {
+		return
+	}
+	cr.err = binary.Read(cr.r, binary.BigEndian, v)
+	if cr.err == nil {
+		cr.n += int64(binary.Size(v))
+	}
+}
+
+func (cr *countReader) readFrom(rf io.ReaderFrom) {
+	if cr.err != nil {
+		return
+	}
+	n, err := rf.ReadFrom(cr.r)
+	cr.n += n
+	cr.err = err
+}
@@ -0,0 +1,181 @@
+package gbloom
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/willf/bloom"
+	"io"
+	"math"
+)
+
+// ScalableBloomFilter grows by adding layers as it fills, every new layer has a larger capacity and
+// a tighter false positive rate, so the compound false positive rate stays bounded by fpRate.
+//
+// Reference
+// Almeida, Baquero, Preguiça, Hutchison: Scalable Bloom Filters, 2007.
+type ScalableBloomFilter struct {
+	layers     []*scalableLayer
+	initCap    uint
+	fpRate     float64
+	growth     uint
+	tightening float64
+	count      uint
+}
+
+type scalableLayer struct {
+	filter *bloom.BloomFilter
+	cap    uint
+	count  uint
+}
+
+const (
+	defaultGrowth     = 2
+	defaultTightening = 0.8
+)
+
+// NewScalable creates a scalable bloom filter whose first layer holds initCap items.
+func NewScalable(initCap uint, fpRate float64) (*ScalableBloomFilter, error) {
+	return NewScalableEx(initCap, fpRate, defaultGrowth, defaultTightening)
+}
+
+// NewScalableEx is like NewScalable, with custom layer capacity growth factor and
+// false positive rate tightening ratio (0 < tightening < 1).
+func NewScalableEx(initCap uint, fpRate float64, growth uint, tightening float64) (*ScalableBloomFilter, error) {
+	if initCap < 2 || fpRate <= 0 || fpRate >= 1 || growth < 1 || tightening <= 0 || tightening >= 1 {
+		return nil, gerrors.New("invalid scalable bloom filter parameters")
+	}
+	sbf := &ScalableBloomFilter{
+		initCap:    initCap,
+		fpRate:     fpRate,
+		growth:     growth,
+		tightening: tightening,
+	}
+	sbf.addLayer()
+	return sbf, nil
+}
+
+func (sbf *ScalableBloomFilter) addLayer() {
+	n := len(sbf.layers)
+	capacity := sbf.initCap * uint(math.Pow(float64(sbf.growth), float64(n)))
+	// Layer i gets fpRate * (1 - r) * r^i, the sum of the geometric series is fpRate.
+	fp := sbf.fpRate * (1 - sbf.tightening) * math.Pow(sbf.tightening, float64(n))
+	sbf.layers = append(sbf.layers, &scalableLayer{
+		filter: bloom.NewWithEstimates(capacity, fp),
+		cap:    capacity,
+	})
+}
+
+// Add inserts data, items which might already exist are not inserted again so they don't waste capacity.
+func (sbf *ScalableBloomFilter) Add(data []byte) error {
+	if sbf.MightContain(data) {
+		return nil
+	}
+	last := sbf.layers[len(sbf.layers)-1]
+	if last.count >= last.cap {
+		sbf.addLayer()
+		last = sbf.layers[len(sbf.layers)-1]
+	}
+	last.filter.Add(data)
+	last.count++
+	sbf.count++
+	return nil
+}
+
+func (sbf *ScalableBloomFilter) AddStr(str string) error {
+	return sbf.Add([]byte(str))
+}
+
+func (sbf *ScalableBloomFilter) MightContain(data []byte) bool {
+	// Newer layers are larger and contain more items, check them first.
+	for i := len(sbf.layers) - 1; i >= 0; i-- {
+		if sbf.layers[i].filter.Test(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sbf *ScalableBloomFilter) MightContainStr(str string) bool {
+	return sbf.MightContain([]byte(str))
+}
+
+func (sbf *ScalableBloomFilter) Count() uint {
+	return sbf.count
+}
+
+// Layers returns the count of layers.
+func (sbf *ScalableBloomFilter) Layers() int {
+	return len(sbf.layers)
+}
+
+// EstimateFalsePositiveRate returns the theoretical compound false positive rate with current counts.
+func (sbf *ScalableBloomFilter) EstimateFalsePositiveRate() float64 {
+	pass := 1.0
+	for _, l := range sbf.layers {
+		pass *= 1 - estimateFpRate(l.filter.Cap(), l.filter.K(), l.count)
+	}
+	return 1 - pass
+}
+
+func (sbf *ScalableBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	cw.write(uint64(sbf.initCap))
+	cw.write(sbf.fpRate)
+	cw.write(uint64(sbf.growth))
+	cw.write(sbf.tightening)
+	cw.write(uint64(sbf.count))
+	cw.write(uint32(len(sbf.layers)))
+	for _, l := range sbf.layers {
+		cw.write(uint64(l.cap))
+		cw.write(uint64(l.count))
+		cw.writeFrom(l.filter)
+	}
+	return cw.n, cw.err
+}
+
+func (sbf *ScalableBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countReader{r: r}
+	initCap, growth, count := uint64(0), uint64(0), uint64(0)
+	fpRate, tightening := 0.0, 0.0
+	layerCount := uint32(0)
+	cr.read(&initCap)
+	cr.read(&fpRate)
+	cr.read(&growth)
+	cr.read(&tightening)
+	cr.read(&count)
+	cr.read(&layerCount)
+	if cr.err != nil {
+		return cr.n, cr.err
+	}
+	if layerCount == 0 {
+		return cr.n, ErrInvalidFile
+	}
+
+	layers := make([]*scalableLayer, 0, layerCount)
+	for i := uint32(0); i < layerCount; i++ {
+		capacity, lc := uint64(0), uint64(0)
+		cr.read(&capacity)
+		cr.read(&lc)
+		filter := &bloom.BloomFilter{}
+		cr.readFrom(filter)
+		if cr.err != nil {
+			return cr.n, cr.err
+		}
+		layers = append(layers, &scalableLayer{filter: filter, cap: uint(capacity), count: uint(lc)})
+	}
+
+	sbf.initCap = uint(initCap)
+	sbf.fpRate = fpRate
+	sbf.growth = uint(growth)
+	sbf.tightening = tightening
+	sbf.count = uint(count)
+	sbf.layers = layers
+	return cr.n, nil
+}
+
+// estimateFpRate returns (1 - e^(-kn/m))^k.
+func estimateFpRate(m, k, n uint) float64 {
+	if m == 0 {
+		return 1
+	}
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
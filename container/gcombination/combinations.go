@@ -29,61 +29,22 @@ For input slice {"A", "B", "C", "D"} there are 2^4 - 1 = 15 binary combinations,
 
 package gcombination
 
-import (
-	"github.com/davidforest123/goutil/container/gbit"
-)
-
 // All returns all combinations for a given interface array.
 // This is essentially a powerset of the given set except that the empty set is disregarded.
+// It materialises every subset, use Subsets or Combinations to iterate lazily.
 func All(set []interface{}) (subsets [][]interface{}) {
-	length := uint(len(set))
-
-	// Go through all possible combinations of objects
-	// from 1 (only first object in subset) to 2^length (all objects in subset)
-	for subsetBits := 1; subsetBits < (1 << length); subsetBits++ {
-		var subset []interface{}
-
-		for object := uint(0); object < length; object++ {
-			// checks if object is contained in subset
-			// by checking if bit 'object' is set in subsetBits
-			if (subsetBits>>object)&1 == 1 {
-				// add object to subset
-				subset = append(subset, set[object])
-			}
-		}
-		// add subset to subsets
-		subsets = append(subsets, subset)
-	}
-	return subsets
+	return AllWithLen(set, 1, len(set))
 }
 
-// All returns all combinations for a given interface array and fixed set length.
-// This is essentially a powerset of the given set except that the empty set is disregarded.
+// AllWithLen returns all combinations for a given interface array whose length is in [minLen, maxLen].
 func AllWithLen(set []interface{}, minLen, maxLen int) (subsets [][]interface{}) {
 	if maxLen < minLen || minLen > len(set) || maxLen <= 0 {
 		return nil
 	}
-	length := uint(len(set))
-
-	// Go through all possible combinations of objects
-	// from 1 (only first object in subset) to 2^length (all objects in subset)
-	for subsetBits := 1; subsetBits < (1 << length); subsetBits++ {
-		bit1Count := int(gbit.Count1BitsHamming32(uint32(subsetBits)))
-		if bit1Count < minLen || bit1Count > maxLen {
-			continue
-		}
-
-		var subset []interface{}
-		for object := uint(0); object < length; object++ {
-			// checks if object is contained in subset
-			// by checking if bit 'object' is set in subsetBits
-			if (subsetBits>>object)&1 == 1 {
-				// add object to subset
-				subset = append(subset, set[object])
-			}
+	for subset := range Subsets(set) {
+		if len(subset) >= minLen && len(subset) <= maxLen {
+			subsets = append(subsets, subset)
 		}
-		// add subset to subsets
-		subsets = append(subsets, subset)
 	}
 	return subsets
 }
@@ -0,0 +1,196 @@
+package gcombination
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"iter"
+	"math/big"
+)
+
+// Lazy generic iterators, every yielded slice is newly allocated so it can be kept by caller.
+// Combinations are yielded in lexicographic order of indexes, so the n-th one can be computed directly
+// by NthCombination, and CombinationsFrom lets goroutines start from different ranks.
+
+// pick returns items of set at indexes.
+func pick[T any](set []T, indexes []int) []T {
+	r := make([]T, len(indexes))
+	for i, idx := range indexes {
+		r[i] = set[idx]
+	}
+	return r
+}
+
+// Combinations iterates all k-combinations of set, C(len(set), k) in total.
+func Combinations[T any](set []T, k int) iter.Seq[[]T] {
+	return CombinationsFrom(set, k, nil)
+}
+
+// CombinationsFrom iterates k-combinations of set from the one of rank start, nil start means 0.
+// Nothing is yielded if start is out of range.
+func CombinationsFrom[T any](set []T, k int, start *big.Int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		n := len(set)
+		if k < 0 || k > n {
+			return
+		}
+		var idx []int
+		if start == nil {
+			idx = make([]int, k)
+			for i := range idx {
+				idx[i] = i
+			}
+		} else {
+			var err error
+			if idx, err = unrankCombination(n, k, start); err != nil {
+				return
+			}
+		}
+		for {
+			if !yield(pick(set, idx)) {
+				return
+			}
+			// rightmost index which can move right
+			i := k - 1
+			for i >= 0 && idx[i] == n-k+i {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+			idx[i]++
+			for j := i + 1; j < k; j++ {
+				idx[j] = idx[j-1] + 1
+			}
+		}
+	}
+}
+
+// CombinationsWithReplacement iterates k-multisets of set, items are picked in non-decreasing index order,
+// C(len(set)+k-1, k) in total.
+func CombinationsWithReplacement[T any](set []T, k int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		n := len(set)
+		if k < 0 || (n == 0 && k > 0) {
+			return
+		}
+		idx := make([]int, k)
+		for {
+			if !yield(pick(set, idx)) {
+				return
+			}
+			i := k - 1
+			for i >= 0 && idx[i] == n-1 {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+			idx[i]++
+			for j := i + 1; j < k; j++ {
+				idx[j] = idx[i]
+			}
+		}
+	}
+}
+
+// Subsets iterates all 2^len(set) subsets including the empty one, in the binary counting order of All,
+// bit i of the counter selects set[i].
+func Subsets[T any](set []T) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		selected := make([]bool, len(set))
+		var subset []T
+		for {
+			subset = subset[:0]
+			for i, ok := range selected {
+				if ok {
+					subset = append(subset, set[i])
+				}
+			}
+			if !yield(append([]T(nil), subset...)) {
+				return
+			}
+			// binary increment
+			i := 0
+			for i < len(selected) && selected[i] {
+				selected[i] = false
+				i++
+			}
+			if i == len(selected) {
+				return
+			}
+			selected[i] = true
+		}
+	}
+}
+
+// CountCombinations returns C(n, k).
+func CountCombinations(n, k int) *big.Int {
+	if k < 0 || n < 0 || k > n {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Binomial(int64(n), int64(k))
+}
+
+// CountCombinationsWithReplacement returns C(n+k-1, k).
+func CountCombinationsWithReplacement(n, k int) *big.Int {
+	if k == 0 {
+		return big.NewInt(1)
+	}
+	return CountCombinations(n+k-1, k)
+}
+
+// CountSubsets returns 2^n.
+func CountSubsets(n int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n))
+}
+
+func unrankCombination(n, k int, rank *big.Int) ([]int, error) {
+	total := CountCombinations(n, k)
+	if rank.Sign() < 0 || rank.Cmp(total) >= 0 {
+		return nil, gerrors.New("combination rank %s out of range [0, %s)", rank.String(), total.String())
+	}
+	r := new(big.Int).Set(rank)
+	idx := make([]int, k)
+	v := 0
+	for i := 0; i < k; i++ {
+		// combinations starting with v at position i
+		for ; ; v++ {
+			c := CountCombinations(n-1-v, k-1-i)
+			if r.Cmp(c) < 0 {
+				break
+			}
+			r.Sub(r, c)
+		}
+		idx[i] = v
+		v++
+	}
+	return idx, nil
+}
+
+// NthCombination returns the k-combination of rank in lexicographic order of indexes, rank starts from 0.
+func NthCombination[T any](set []T, k int, rank *big.Int) ([]T, error) {
+	if k < 0 || k > len(set) {
+		return nil, gerrors.New("invalid k %d of %d items", k, len(set))
+	}
+	idx, err := unrankCombination(len(set), k, rank)
+	if err != nil {
+		return nil, err
+	}
+	return pick(set, idx), nil
+}
+
+// RankCombination returns the rank of combination of strictly increasing indexes of n items.
+func RankCombination(n int, indexes []int) (*big.Int, error) {
+	k := len(indexes)
+	rank := new(big.Int)
+	prev := -1
+	for i, idx := range indexes {
+		if idx <= prev || idx >= n {
+			return nil, gerrors.New("invalid combination indexes %v of %d items", indexes, n)
+		}
+		for v := prev + 1; v < idx; v++ {
+			rank.Add(rank, CountCombinations(n-1-v, k-1-i))
+		}
+		prev = idx
+	}
+	return rank, nil
+}
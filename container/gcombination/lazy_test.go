@@ -0,0 +1,99 @@
+package gcombination
+
+import (
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func join(ss [][]string) string {
+	var r []string
+	for _, s := range ss {
+		r = append(r, strings.Join(s, ""))
+	}
+	return strings.Join(r, " ")
+}
+
+func TestCombinations(t *testing.T) {
+	set := []string{"a", "b", "c", "d"}
+	got := join(slices.Collect(Combinations(set, 2)))
+	gtest.AssertTrue(t, got == "ab ac ad bc bd cd", "got %s", got)
+	got = join(slices.Collect(CombinationsWithReplacement([]string{"a", "b", "c"}, 2)))
+	gtest.AssertTrue(t, got == "aa ab ac bb bc cc", "with replacement %s", got)
+	gtest.AssertTrue(t, len(slices.Collect(Combinations(set, 0))) == 1 && len(slices.Collect(Combinations(set, 5))) == 0, "edge k")
+	got = join(slices.Collect(Subsets([]string{"a", "b", "c"})))
+	gtest.AssertTrue(t, got == " a b ab c ac bc abc", "subsets %q", got)
+
+	// 40 items are far too many to materialise, but iteration stops early
+	big40 := make([]int, 40)
+	n := 0
+	for range Subsets(big40) {
+		if n++; n == 1000 {
+			break
+		}
+	}
+	gtest.AssertTrue(t, n == 1000 && CountSubsets(40).String() == "1099511627776", "lazy")
+}
+
+func TestNthCombination(t *testing.T) {
+	set := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	rank := 0
+	for c := range Combinations(set, 4) {
+		nth, err := NthCombination(set, 4, big.NewInt(int64(rank)))
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, slices.Equal(nth, c), "nth %d: %v != %v", rank, nth, c)
+		r, err := RankCombination(len(set), c)
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, r.Int64() == int64(rank), "rank of %v: %s != %d", c, r, rank)
+		rank++
+	}
+	gtest.AssertTrue(t, CountCombinations(9, 4).Int64() == int64(rank) && rank == 126, "count %d", rank)
+	_, err := NthCombination(set, 4, big.NewInt(126))
+	gtest.AssertTrue(t, err != nil, "rank out of range")
+	_, err = RankCombination(9, []int{3, 2})
+	gtest.AssertTrue(t, err != nil, "invalid indexes")
+	gtest.AssertTrue(t, CountCombinations(100, 50).String() == "100891344545564193334812497256", "big count")
+	gtest.AssertTrue(t, CountCombinationsWithReplacement(3, 2).Int64() == 6, "count with replacement")
+}
+
+// TestCombinationsFrom splits combinations across goroutines by rank.
+func TestCombinationsFrom(t *testing.T) {
+	set := make([]int, 20)
+	for i := range set {
+		set[i] = i
+	}
+	const k, workers = 6, 7
+	total := CountCombinations(len(set), k).Int64()
+	chunk := (total + workers - 1) / workers
+	parts := make([][][]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for c := range CombinationsFrom(set, k, big.NewInt(int64(w)*chunk)) {
+				if int64(len(parts[w])) == chunk {
+					break
+				}
+				parts[w] = append(parts[w], c)
+			}
+		}(w)
+	}
+	wg.Wait()
+	all := slices.Collect(Combinations(set, k))
+	gtest.AssertTrue(t, slices.EqualFunc(slices.Concat(parts...), all, func(a, b []int) bool { return slices.Equal(a, b) }), "parts differ")
+}
+
+func ExampleCombinations() {
+	for c := range Combinations([]string{"A", "B", "C"}, 2) {
+		fmt.Println(c)
+	}
+	// Output:
+	// [A B]
+	// [A C]
+	// [B C]
+}
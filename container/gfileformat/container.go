@@ -0,0 +1,124 @@
+package gfileformat
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// Zip based formats are told apart by their entries, e.g. docx has "word/", jar has "META-INF/MANIFEST.MF",
+// epub and OpenDocument store their MIME in the first entry "mimetype".
+
+var zipMimetypes = map[string]Format{
+	"application/epub+zip":                            {Name: "EPUB", MIME: "application/epub+zip", Extension: "epub"},
+	"application/vnd.oasis.opendocument.text":         {Name: "OpenDocument text", MIME: "application/vnd.oasis.opendocument.text", Extension: "odt"},
+	"application/vnd.oasis.opendocument.spreadsheet":  {Name: "OpenDocument spreadsheet", MIME: "application/vnd.oasis.opendocument.spreadsheet", Extension: "ods"},
+	"application/vnd.oasis.opendocument.presentation": {Name: "OpenDocument presentation", MIME: "application/vnd.oasis.opendocument.presentation", Extension: "odp"},
+	"application/vnd.oasis.opendocument.graphics":     {Name: "OpenDocument graphics", MIME: "application/vnd.oasis.opendocument.graphics", Extension: "odg"},
+}
+
+var (
+	formatDocx = Format{Name: "Office Open XML document", MIME: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Extension: "docx"}
+	formatXlsx = Format{Name: "Office Open XML workbook", MIME: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx"}
+	formatPptx = Format{Name: "Office Open XML presentation", MIME: "application/vnd.openxmlformats-officedocument.presentationml.presentation", Extension: "pptx"}
+	formatApk  = Format{Name: "Android package", MIME: "application/vnd.android.package-archive", Extension: "apk"}
+	formatJar  = Format{Name: "Java archive", MIME: "application/java-archive", Extension: "jar"}
+	formatWar  = Format{Name: "Web application archive", MIME: "application/java-archive", Extension: "war"}
+)
+
+// classifyZip decides zip based format by entry names and content of "mimetype" entry,
+// ok is false if no known format matches.
+func classifyZip(names []string, mimetype string) (Format, bool) {
+	if f, ok := zipMimetypes[strings.TrimSpace(mimetype)]; ok {
+		return f, true
+	}
+	var manifest, dex, class, webInf, contentTypes bool
+	for _, name := range names {
+		switch {
+		case name == "AndroidManifest.xml":
+			manifest = true
+		case name == "classes.dex" || name == "resources.arsc":
+			dex = true
+		case strings.HasPrefix(name, "word/"):
+			return formatDocx, true
+		case strings.HasPrefix(name, "xl/"):
+			return formatXlsx, true
+		case strings.HasPrefix(name, "ppt/"):
+			return formatPptx, true
+		case name == "[Content_Types].xml":
+			contentTypes = true
+		case strings.HasPrefix(name, "WEB-INF/"):
+			webInf = true
+		case name == "META-INF/MANIFEST.MF" || strings.HasSuffix(name, ".class"):
+			class = true
+		}
+	}
+	switch {
+	case manifest && dex:
+		return formatApk, true
+	case webInf:
+		return formatWar, true
+	case class && !contentTypes:
+		return formatJar, true
+	}
+	return Format{}, false
+}
+
+// inspectZipLocal walks local file headers in sniffed bytes, it stops at the end of b or
+// at an entry whose size is only known from data descriptor.
+func inspectZipLocal(b []byte, f Format) (Format, bool) {
+	var names []string
+	mimetype := ""
+	for off := 0; off+30 <= len(b) && string(b[off:off+4]) == "PK\x03\x04"; {
+		flags := binary.LittleEndian.Uint16(b[off+6:])
+		method := binary.LittleEndian.Uint16(b[off+8:])
+		csize := int(binary.LittleEndian.Uint32(b[off+18:]))
+		nameLen := int(binary.LittleEndian.Uint16(b[off+26:]))
+		extraLen := int(binary.LittleEndian.Uint16(b[off+28:]))
+		start := off + 30
+		if start+nameLen > len(b) {
+			break
+		}
+		name := string(b[start : start+nameLen])
+		names = append(names, name)
+		data := start + nameLen + extraLen
+		if name == "mimetype" && method == zip.Store && data+csize <= len(b) {
+			mimetype = string(b[data : data+csize])
+		}
+		if flags&0x8 != 0 {
+			break
+		}
+		off = data + csize
+	}
+	if cf, ok := classifyZip(names, mimetype); ok {
+		cf.Confidence = 0.9
+		return cf, true
+	}
+	return f, true
+}
+
+// inspectZipFile reads central directory of zip file r, which lists all entries.
+func inspectZipFile(r io.ReaderAt, size int64) (Format, bool) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return Format{}, false
+	}
+	names := make([]string, len(zr.File))
+	mimetype := ""
+	for i, zf := range zr.File {
+		names[i] = zf.Name
+		if zf.Name == "mimetype" && zf.UncompressedSize64 < 256 {
+			if rc, err := zf.Open(); err == nil {
+				b, _ := io.ReadAll(rc)
+				_ = rc.Close()
+				mimetype = string(b)
+			}
+		}
+	}
+	if f, ok := classifyZip(names, mimetype); ok {
+		f.Confidence = 1
+		return f, true
+	}
+	return Format{Name: "ZIP", MIME: "application/zip", Extension: "zip", Confidence: 1}, true
+}
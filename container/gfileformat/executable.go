@@ -0,0 +1,201 @@
+package gfileformat
+
+import (
+	"encoding/binary"
+)
+
+// Header parsers of ELF / PE / Mach-O, they decide whether a binary is a runnable program,
+// a shared library or an object file.
+
+const (
+	elfTypeRel  = 1
+	elfTypeExec = 2
+	elfTypeDyn  = 3
+	elfTypeCore = 4
+	elfPtInterp = 3
+
+	peFileExecutableImage = 0x0002
+	peFileDll             = 0x2000
+
+	machoObject  = 0x1
+	machoExecute = 0x2
+	machoDylib   = 0x6
+	machoBundle  = 0x8
+)
+
+func byteOrder(bigEndian bool) binary.ByteOrder {
+	if bigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+func inspectELF(b []byte, f Format) (Format, bool) {
+	if len(b) < 52 {
+		return f, false
+	}
+	class, data, version := b[4], b[5], b[6]
+	if (class != 1 && class != 2) || (data != 1 && data != 2) || version != 1 {
+		return f, false
+	}
+	bo := byteOrder(data == 2)
+	if bo.Uint32(b[20:]) != 1 { // e_version
+		return f, false
+	}
+	is64 := class == 2
+	if is64 && len(b) < 64 {
+		return f, false
+	}
+
+	f.Confidence = 1
+	switch bo.Uint16(b[16:]) {
+	case elfTypeRel:
+		f.Name, f.MIME, f.Extension = "ELF relocatable object", "application/x-object", "o"
+	case elfTypeExec:
+		f.Name, f.MIME, f.Executable = "ELF executable", "application/x-executable", true
+	case elfTypeDyn:
+		// position independent executables are ET_DYN too, they are told from shared libraries by PT_INTERP
+		interp, ok := elfHasInterp(b, bo, is64)
+		if !ok {
+			f.Confidence = 0.8
+		}
+		if interp {
+			f.Name, f.MIME, f.Executable = "ELF executable", "application/x-pie-executable", true
+		} else {
+			f.Name, f.MIME, f.Extension = "ELF shared library", "application/x-sharedlib", "so"
+		}
+	case elfTypeCore:
+		f.Name, f.MIME, f.Extension = "ELF core dump", "application/x-coredump", "core"
+	default:
+		f.Confidence = 0.9
+	}
+	return f, true
+}
+
+// elfHasInterp reports whether program headers contain PT_INTERP, ok is false if program headers are out of b.
+func elfHasInterp(b []byte, bo binary.ByteOrder, is64 bool) (interp, ok bool) {
+	var phoff uint64
+	var phentsize, phnum int
+	if is64 {
+		phoff, phentsize, phnum = bo.Uint64(b[32:]), int(bo.Uint16(b[54:])), int(bo.Uint16(b[56:]))
+	} else {
+		phoff, phentsize, phnum = uint64(bo.Uint32(b[28:])), int(bo.Uint16(b[42:])), int(bo.Uint16(b[44:]))
+	}
+	if phnum == 0 {
+		return false, true
+	}
+	if phentsize < 4 || phoff > uint64(len(b)) {
+		return false, false
+	}
+	for i := 0; i < phnum; i++ {
+		off := int(phoff) + i*phentsize
+		if off+4 > len(b) {
+			return false, false
+		}
+		if bo.Uint32(b[off:]) == elfPtInterp {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func inspectPE(b []byte, f Format) (Format, bool) {
+	if len(b) < 0x40 {
+		return f, false
+	}
+	lfanew := int(binary.LittleEndian.Uint32(b[0x3c:]))
+	if lfanew < 0x40 || lfanew+24 > len(b) || string(b[lfanew:lfanew+4]) != "PE\x00\x00" {
+		// MZ without PE header, it is a DOS program or the PE header is out of sniffed bytes
+		if !plausibleDOSHeader(b) {
+			return f, false
+		}
+		f.Name, f.MIME, f.Executable, f.Confidence = "DOS executable", "application/x-dosexec", true, 0.5
+		return f, true
+	}
+	coff := lfanew + 4
+	characteristics := binary.LittleEndian.Uint16(b[coff+18:])
+	f.Confidence = 1
+	switch {
+	case characteristics&peFileDll != 0:
+		f.Name, f.Extension = "Windows dynamic-link library", "dll"
+	case characteristics&peFileExecutableImage != 0:
+		f.Name, f.Executable = "Windows executable", true
+	default:
+		f.Name, f.Extension = "Windows object", "obj"
+	}
+	return f, true
+}
+
+// plausibleDOSHeader checks the fields every DOS program has, so text which happens to start with MZ is not a program.
+func plausibleDOSHeader(b []byte) bool {
+	lastPage := int(binary.LittleEndian.Uint16(b[2:]))  // e_cblp, bytes used in the last page, 0 is a full page
+	pages := int(binary.LittleEndian.Uint16(b[4:]))     // e_cp
+	relocs := int(binary.LittleEndian.Uint16(b[0x18:])) // e_lfarlc
+	if lastPage >= 512 || pages == 0 {
+		return false
+	}
+	size := pages * 512
+	if lastPage != 0 {
+		size -= 512 - lastPage
+	}
+	return relocs >= 0x1c && relocs < size
+}
+
+func inspectMachO(b []byte, f Format) (Format, bool) {
+	if len(b) < 28 {
+		return f, false
+	}
+	// FEEDFACE / FEEDFACF is big endian, CEFAEDFE / CFFAEDFE is little endian
+	switch binary.BigEndian.Uint32(b) {
+	case 0xfeedface, 0xfeedfacf, 0xcefaedfe, 0xcffaedfe:
+	default:
+		return f, false
+	}
+	bo := byteOrder(b[0] == 0xfe)
+	if bo.Uint32(b[4:]) == 0 { // cputype
+		return f, false
+	}
+	f.Executable, f.Confidence = false, 1
+	switch bo.Uint32(b[12:]) {
+	case machoObject:
+		f.Name, f.Extension = "Mach-O object", "o"
+	case machoExecute:
+		f.Name, f.Executable = "Mach-O executable", true
+	case machoDylib:
+		f.Name, f.Extension = "Mach-O dynamic library", "dylib"
+	case machoBundle:
+		f.Name, f.Extension = "Mach-O bundle", "bundle"
+	default:
+		f.Confidence = 0.9
+	}
+	return f, true
+}
+
+// inspectCafeBabe tells Java class from Mach-O universal binary, both start with CAFEBABE.
+// Java class has major version >= 45 at the same place where universal binary has its arch count.
+func inspectCafeBabe(b []byte, f Format) (Format, bool) {
+	if len(b) < 8 {
+		return f, false
+	}
+	n := binary.BigEndian.Uint32(b[4:])
+	if n >= 45 {
+		f.Confidence = 1
+		return f, true
+	}
+	if n == 0 {
+		return f, false
+	}
+	f.Name, f.MIME, f.Extension = "Mach-O universal binary", "application/x-mach-binary", ""
+	f.Executable, f.Confidence = true, 0.8
+	// the first fat_arch is at 8, its offset field points to a thin Mach-O header
+	if len(b) >= 28 {
+		off := int(binary.BigEndian.Uint32(b[16:]))
+		if off > 0 && off < len(b) {
+			if thin, ok := inspectMachO(b[off:], f); ok {
+				thin.Name = "Mach-O universal binary"
+				return thin, true
+			}
+		}
+	}
+	return f, true
+}
@@ -1,121 +1,143 @@
 package gfileformat
 
-// github.com/richardlehane/siegfried is a great and professional go library to detect file format
-// much more better than any known library
-
 import (
-	"fmt"
-	"github.com/davidforest123/goutil/basic/gerrors"
-	"github.com/davidforest123/goutil/container/gvolume"
-	"github.com/davidforest123/goutil/sys/gfs"
-
-	"github.com/gabriel-vasile/mimetype"
-	"github.com/h2non/filetype"
-	"github.com/ross-spencer/sfclassic"
+	"bytes"
+	"encoding/json"
+	"github.com/davidforest123/goutil/sys/gio"
+	"io"
+	"os"
+	"unicode/utf8"
 )
 
-// 不太准，比如ttf、Log4j2Plugins.dat都被认为是unix平台的可执行程序
-func IsExecutableUnstable(filename string) (bool, error) {
-
-	mime, err := mimetype.DetectFile(filename)
-	if err != nil {
-		return false, err
-	}
-	fmt.Println(fmt.Sprintf("%s, mime:%s.", filename, mime))
-	if mime.String() == "application/x-executable" || // linux executable file
-		mime.String() == "application/octet-stream" || // unix(including darwin, plan9) executable file
-		mime.String() == "application/vnd.microsoft.portable-executable" { // windows executable file
-		return true, nil
-	}
+// Pure go file format detection by a single signature database, see signatures.
+// Magic bytes are matched first, then the structure of containers and executables are parsed
+// to refine the result, e.g. docx / jar / apk are all zip, ELF / PE / Mach-O headers tell
+// executables from libraries, so TTF or random .dat files are no longer reported as executables.
 
-	// linux executable file
-	if mime.String() == "application/x-executable" {
-		return true, nil
-	}
+type Format struct {
+	Name       string
+	MIME       string
+	Extension  string  // extension without dot, empty if the format has no usual extension, e.g. ELF executable
+	Confidence float64 // 1: structure verified, 0.9: long magic, 0.6: short magic, 0.5: text heuristics, 0: unknown
+	Executable bool    // native program which can be run directly, shared libraries and objects are not
+}
 
-	// windows executable file
-	if mime.String() == "application/vnd.microsoft.portable-executable" {
-		return true, nil
-	}
+// SniffLen is the count of leading bytes to detect with, it covers ISO 9660 signature at 32769.
+const SniffLen = 32774
 
-	// unix(including darwin, plan9) executable file
-	if mime.String() == "application/octet-stream" {
-		return true, nil
-	}
+var (
+	formatUnknown = Format{Name: "Binary", MIME: "application/octet-stream", Extension: "bin"}
+	formatText    = Format{Name: "Text", MIME: "text/plain", Extension: "txt", Confidence: 0.5}
+	utf8BOM       = []byte{0xef, 0xbb, 0xbf}
+)
 
-	return false, nil
+// Detect detects format of data which begins with b, b is treated as complete data.
+func Detect(b []byte) Format {
+	return detect(b, true)
 }
 
-// 不太准，哪些不准忘记了，反正肯定不太准
-func IsExecutableUnstable2(filename string) (bool, error) {
-	/*sz, err := xfs.GetFileSize(filename)
-	if err != nil {
-		return false, err
+// detect detects b, complete is false if b is only the leading part of data.
+func detect(b []byte, complete bool) Format {
+	for _, s := range signatures {
+		if !s.match(b) {
+			continue
+		}
+		if s.inspect == nil {
+			return s.format
+		}
+		if f, ok := s.inspect(b, s.format); ok {
+			return f
+		}
 	}
-	if sz > errIfBiggerThan {
-		return false, gerrors.Errorf("%s it bigger than %s", filename, errIfBiggerThan.String())
-	}*/
-	b, err := gfs.FileToBytes(filename)
-	if err != nil {
-		return false, err
+	return detectText(b, complete)
+}
+
+func detectText(b []byte, complete bool) Format {
+	b = bytes.TrimPrefix(b, utf8BOM)
+	if len(b) == 0 || bytes.IndexByte(b, 0) >= 0 {
+		return formatUnknown
 	}
-	t, err := filetype.Get(b)
-	if err != nil {
-		return false, err
+	valid := b
+	if !complete {
+		// the last rune may be cut off by sniffing
+		for i := 1; i < utf8.UTFMax && len(valid) > 1 && !utf8.Valid(valid); i++ {
+			valid = valid[:len(valid)-1]
+		}
 	}
-	fmt.Println(filename, t.MIME, t.Extension)
-	if t.Extension == "exe" || t.Extension == "elf" {
-		return true, nil
+	if !utf8.Valid(valid) {
+		return formatUnknown
 	}
-	return false, nil
-}
 
-// WARN: 无法识别plan9平台下的可执行程序，但已经是最目前靠谱的识别了
-func IsExecutable(filename string) (bool, error) {
-	ff, err := DetectFileFormat(filename)
-	if err != nil {
-		if vol, err2 := gfs.GetFileSize(filename); err2 == nil && vol == gvolume.Volume(0) {
-			return false, nil
+	t := bytes.TrimLeft(b, " \t\r\n")
+	head := bytes.ToLower(t[:min(len(t), 512)])
+	switch {
+	case bytes.HasPrefix(t, []byte("#!")):
+		line, _, _ := bytes.Cut(t, []byte("\n"))
+		if bytes.Contains(line, []byte("python")) {
+			return Format{Name: "Python script", MIME: "text/x-python", Extension: "py", Confidence: 0.6}
 		}
-		return false, err
-	}
-	if ff.Name == "Mach-O" ||
-		ff.Name == "Executable and Linkable Format" ||
-		ff.Name == "Windows Portable Executable" {
-		return true, nil
+		return Format{Name: "Shell script", MIME: "text/x-shellscript", Extension: "sh", Confidence: 0.6}
+	case bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html")):
+		return Format{Name: "HTML", MIME: "text/html", Extension: "html", Confidence: 0.6}
+	case bytes.HasPrefix(head, []byte("<?xml")):
+		if bytes.Contains(head, []byte("<svg")) {
+			return Format{Name: "SVG", MIME: "image/svg+xml", Extension: "svg", Confidence: 0.6}
+		}
+		return Format{Name: "XML", MIME: "application/xml", Extension: "xml", Confidence: 0.6}
+	case bytes.HasPrefix(head, []byte("<svg")):
+		return Format{Name: "SVG", MIME: "image/svg+xml", Extension: "svg", Confidence: 0.6}
+	case complete && (t[0] == '{' || t[0] == '[') && json.Valid(t):
+		return Format{Name: "JSON", MIME: "application/json", Extension: "json", Confidence: 0.6}
 	}
-	return false, nil
+	return formatText
 }
 
-type FileFormat struct {
-	NameSpace string
-	Name      string
-	Version   string
-	MIME      string
+// DetectReader detects format of r by sniffing at most SniffLen bytes, the returned reader
+// yields all data of r including sniffed bytes.
+func DetectReader(r io.Reader) (Format, io.Reader, error) {
+	sb := gio.NewSniffBuf(r)
+	rr := sb.RewindReader()
+	buf := make([]byte, SniffLen)
+	n, err := io.ReadFull(rr, buf)
+	complete := err == io.EOF || err == io.ErrUnexpectedEOF
+	if err != nil && !complete {
+		return Format{}, nil, err
+	}
+	rr.Rewind()
+	return detect(buf[:n], complete), sb.NormalReader(), nil
 }
 
-// WARN: 无法识别plan9平台下的可执行程序，但已经是最目前靠谱的识别了
-// DetectFileFormat返回了多个命名空间下的识别结果，而sf程序只选取了pronom命名空间
-// if file is empty, returns error
-func DetectFileFormat(filename string) (*FileFormat, error) {
-	rd, fd, err := gfs.FilenameToReader(filename)
+// DetectFile detects format of file, zip files are inspected through central directory,
+// so all entries are taken into account.
+func DetectFile(filename string) (Format, error) {
+	fd, err := os.Open(filename)
 	if err != nil {
-		return nil, err
+		return Format{}, err
 	}
 	defer fd.Close()
-	ids, err := sfclassic.New().Identify(rd, "classic.sig")
+	fi, err := fd.Stat()
 	if err != nil {
-		return nil, err
+		return Format{}, err
 	}
-
-	var r []FileFormat
-	for _, v := range ids {
-		if len(v.Values()) == 7 {
-			r = append(r, FileFormat{NameSpace: v.Values()[0], Name: v.Values()[2], Version: v.Values()[3], MIME: v.Values()[4]})
+	buf := make([]byte, min(fi.Size(), SniffLen))
+	if _, err := io.ReadFull(fd, buf); err != nil {
+		return Format{}, err
+	}
+	f := detect(buf, int64(len(buf)) == fi.Size())
+	if bytes.HasPrefix(buf, []byte("PK\x03\x04")) || bytes.HasPrefix(buf, []byte("PK\x05\x06")) {
+		if zf, ok := inspectZipFile(fd, fi.Size()); ok {
+			f = zf
 		}
 	}
-	if len(r) == 0 {
-		return nil, gerrors.Errorf("detect failed")
+	return f, nil
+}
+
+// IsExecutable reports whether filename is a native program of ELF, PE, Mach-O or DOS format,
+// empty file is not executable.
+func IsExecutable(filename string) (bool, error) {
+	f, err := DetectFile(filename)
+	if err != nil {
+		return false, err
 	}
-	return &r[0], nil
+	return f.Executable, nil
 }
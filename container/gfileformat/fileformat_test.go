@@ -0,0 +1,130 @@
+package gfileformat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixtures in testdata, MIME / extension / executable expected for each file.
+var fixtures = []struct {
+	file       string
+	mime       string
+	ext        string
+	executable bool
+}{
+	{"image.png", "image/png", "png", false},
+	{"image.gif", "image/gif", "gif", false},
+	{"image.jpg", "image/jpeg", "jpg", false},
+	{"image.webp", "image/webp", "webp", false},
+	{"image.bmp", "image/bmp", "bmp", false},
+	{"image.heic", "image/heic", "heic", false},
+	{"image.svg", "image/svg+xml", "svg", false},
+	{"video.mp4", "video/mp4", "mp4", false},
+	{"doc.pdf", "application/pdf", "pdf", false},
+	{"db.sqlite", "application/vnd.sqlite3", "sqlite", false},
+	{"module.wasm", "application/wasm", "wasm", false},
+	{"archive.gz", "application/gzip", "gz", false},
+	{"archive.tar", "application/x-tar", "tar", false},
+	{"plain.zip", "application/zip", "zip", false},
+	{"doc.docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", "docx", false},
+	{"book.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", false},
+	{"slides.pptx", "application/vnd.openxmlformats-officedocument.presentationml.presentation", "pptx", false},
+	{"lib.jar", "application/java-archive", "jar", false},
+	{"app.apk", "application/vnd.android.package-archive", "apk", false},
+	{"book.epub", "application/epub+zip", "epub", false},
+	{"doc.odt", "application/vnd.oasis.opendocument.text", "odt", false},
+	{"font.ttf", "font/ttf", "ttf", false},
+	{"font.otf", "font/otf", "otf", false},
+	{"page.html", "text/html", "html", false},
+	{"data.json", "application/json", "json", false},
+	{"notes.txt", "text/plain", "txt", false},
+	{"script.sh", "text/x-shellscript", "sh", false},
+	{"Hello.class", "application/java-vm", "class", false},
+	{"exec.elf", "application/x-executable", "", true},
+	{"pie.elf", "application/x-pie-executable", "", true},
+	{"lib.so", "application/x-sharedlib", "so", false},
+	{"object.o", "application/x-object", "o", false},
+	{"app.exe", "application/vnd.microsoft.portable-executable", "exe", true},
+	{"lib.dll", "application/vnd.microsoft.portable-executable", "dll", false},
+	{"exec.macho", "application/x-mach-binary", "", true},
+	{"lib.dylib", "application/x-mach-binary", "dylib", false},
+	{"universal.macho", "application/x-mach-binary", "", true},
+	{"random.dat", "application/octet-stream", "bin", false},
+	{"empty.dat", "application/octet-stream", "bin", false},
+}
+
+func TestDetectFile(t *testing.T) {
+	for _, fx := range fixtures {
+		f, err := DetectFile(filepath.Join("testdata", fx.file))
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, f.MIME == fx.mime && f.Extension == fx.ext && f.Executable == fx.executable,
+			"%s: got %+v", fx.file, f)
+		exe, err := IsExecutable(filepath.Join("testdata", fx.file))
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, exe == fx.executable, "%s: IsExecutable %v", fx.file, exe)
+	}
+
+	// the test binary itself is a real executable
+	exe, err := IsExecutable(os.Args[0])
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, exe, "test binary is not executable")
+}
+
+func TestDetectReader(t *testing.T) {
+	for _, fx := range fixtures {
+		b, err := os.ReadFile(filepath.Join("testdata", fx.file))
+		gtest.Assert(t, err)
+		f, r, err := DetectReader(bytes.NewReader(b))
+		gtest.Assert(t, err)
+		// zip entries after the sniffed part are unknown to DetectReader, but all fixtures are small
+		gtest.AssertTrue(t, f.MIME == fx.mime && f.Executable == fx.executable, "%s: got %+v", fx.file, f)
+		all, err := io.ReadAll(r)
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, bytes.Equal(all, b), "%s: reader lost data", fx.file)
+	}
+
+	// data longer than SniffLen must be replayed completely
+	b := append([]byte("%PDF-1.7\n"), bytes.Repeat([]byte("x"), SniffLen*3)...)
+	f, r, err := DetectReader(bytes.NewReader(b))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, f.MIME == "application/pdf" && f.Confidence == 0.9, "got %+v", f)
+	all, err := io.ReadAll(r)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, bytes.Equal(all, b), "reader lost data")
+}
+
+func TestDetect_Confidence(t *testing.T) {
+	gtest.AssertTrue(t, Detect(nil).Confidence == 0, "empty data")
+	gtest.AssertTrue(t, Detect([]byte("hello")).Confidence == 0.5, "text")
+	elf, err := os.ReadFile(filepath.Join("testdata", "exec.elf"))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, Detect(elf).Confidence == 1, "parsed ELF")
+
+	// sfnt magic without valid table directory is not a font
+	gtest.AssertTrue(t, Detect([]byte("\x00\x01\x00\x00\xff\xff\x00\x00\x00\x00\x00\x00")).MIME == "application/octet-stream", "fake ttf")
+	// MZ without PE header
+	dos := append([]byte("MZ"), make([]byte, 62)...)
+	binary.LittleEndian.PutUint16(dos[2:], 0x90)
+	binary.LittleEndian.PutUint16(dos[4:], 3)
+	binary.LittleEndian.PutUint16(dos[0x18:], 0x40)
+	f := Detect(dos)
+	gtest.AssertTrue(t, f.MIME == "application/x-dosexec" && f.Executable && f.Confidence == 0.5, "got %+v", f)
+	// MZ with implausible DOS header is not a program
+	f = Detect(append([]byte("MZ"), make([]byte, 62)...))
+	gtest.AssertTrue(t, !f.Executable && f.MIME == "application/octet-stream", "zeroed header got %+v", f)
+	f = Detect([]byte("MZ is the IATA code of Malaysia, the rest of this line is plain text.\n"))
+	gtest.AssertTrue(t, !f.Executable && f.MIME == "text/plain", "text got %+v", f)
+	binary.LittleEndian.PutUint16(dos[0x18:], 0x800)
+	f = Detect(dos)
+	gtest.AssertTrue(t, !f.Executable, "relocations out of file got %+v", f)
+	// truncated multi-byte rune at the end of sniffed text
+	text := bytes.Repeat([]byte("文"), SniffLen)
+	f, _, err = DetectReader(bytes.NewReader(text))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, f.MIME == "text/plain", "got %+v", f)
+}
@@ -0,0 +1,178 @@
+package gfileformat
+
+import (
+	"bytes"
+)
+
+type (
+	magic struct {
+		offset int
+		bytes  []byte
+	}
+
+	// signature matches if all magics match, inspect refines the result by parsing the structure,
+	// it returns false if the structure is invalid and the next signature should be tried.
+	signature struct {
+		format  Format
+		magics  []magic
+		inspect func(b []byte, f Format) (Format, bool)
+	}
+)
+
+func m(offset int, s string) magic {
+	return magic{offset: offset, bytes: []byte(s)}
+}
+
+// sig creates signature, confidence is decided by total length of magic bytes if not set.
+func sig(name, mime, ext string, magics ...magic) signature {
+	total := 0
+	for _, mg := range magics {
+		total += len(mg.bytes)
+	}
+	conf := 0.9
+	if total < 4 {
+		conf = 0.6
+	}
+	return signature{format: Format{Name: name, MIME: mime, Extension: ext, Confidence: conf}, magics: magics}
+}
+
+func (s signature) with(inspect func(b []byte, f Format) (Format, bool)) signature {
+	s.inspect = inspect
+	return s
+}
+
+func (s signature) match(b []byte) bool {
+	for _, mg := range s.magics {
+		if mg.offset+len(mg.bytes) > len(b) || !bytes.Equal(b[mg.offset:mg.offset+len(mg.bytes)], mg.bytes) {
+			return false
+		}
+	}
+	return true
+}
+
+// signatures is the signature database, more specific signatures go first.
+var signatures = []signature{
+	// executables and objects
+	sig("Executable and Linkable Format", "application/x-elf", "", m(0, "\x7fELF")).with(inspectELF),
+	sig("Mach-O", "application/x-mach-binary", "", m(0, "\xfe\xed\xfa\xce")).with(inspectMachO),
+	sig("Mach-O", "application/x-mach-binary", "", m(0, "\xfe\xed\xfa\xcf")).with(inspectMachO),
+	sig("Mach-O", "application/x-mach-binary", "", m(0, "\xce\xfa\xed\xfe")).with(inspectMachO),
+	sig("Mach-O", "application/x-mach-binary", "", m(0, "\xcf\xfa\xed\xfe")).with(inspectMachO),
+	sig("Java class", "application/java-vm", "class", m(0, "\xca\xfe\xba\xbe")).with(inspectCafeBabe),
+	sig("Windows Portable Executable", "application/vnd.microsoft.portable-executable", "exe", m(0, "MZ")).with(inspectPE),
+	sig("WebAssembly", "application/wasm", "wasm", m(0, "\x00asm")),
+	sig("Dalvik executable", "application/vnd.android.dex", "dex", m(0, "dex\n")),
+
+	// archives
+	sig("ZIP", "application/zip", "zip", m(0, "PK\x03\x04")).with(inspectZipLocal),
+	sig("ZIP", "application/zip", "zip", m(0, "PK\x05\x06")),
+	sig("GZIP", "application/gzip", "gz", m(0, "\x1f\x8b\x08")),
+	sig("BZIP2", "application/x-bzip2", "bz2", m(0, "BZh")),
+	sig("XZ", "application/x-xz", "xz", m(0, "\xfd7zXZ\x00")),
+	sig("7-Zip", "application/x-7z-compressed", "7z", m(0, "7z\xbc\xaf\x27\x1c")),
+	sig("RAR", "application/vnd.rar", "rar", m(0, "Rar!\x1a\x07")),
+	sig("Zstandard", "application/zstd", "zst", m(0, "\x28\xb5\x2f\xfd")),
+	sig("TAR", "application/x-tar", "tar", m(257, "ustar")),
+	sig("Microsoft Cabinet", "application/vnd.ms-cab-compressed", "cab", m(0, "MSCF\x00\x00\x00\x00")),
+	sig("Debian package", "application/vnd.debian.binary-package", "deb", m(0, "!<arch>\ndebian")),
+	sig("AR archive", "application/x-archive", "a", m(0, "!<arch>\n")),
+	sig("RPM package", "application/x-rpm", "rpm", m(0, "\xed\xab\xee\xdb")),
+	sig("ISO 9660", "application/x-iso9660-image", "iso", m(32769, "CD001")),
+	sig("Compound File Binary", "application/x-ole-storage", "cfb", m(0, "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")),
+
+	// documents
+	sig("Portable Document Format", "application/pdf", "pdf", m(0, "%PDF-")),
+	sig("PostScript", "application/postscript", "ps", m(0, "%!PS")),
+	sig("Rich Text Format", "application/rtf", "rtf", m(0, "{\\rtf")),
+	sig("SQLite", "application/vnd.sqlite3", "sqlite", m(0, "SQLite format 3\x00")),
+
+	// images
+	sig("Portable Network Graphics", "image/png", "png", m(0, "\x89PNG\r\n\x1a\n")),
+	sig("JPEG", "image/jpeg", "jpg", m(0, "\xff\xd8\xff")),
+	sig("GIF", "image/gif", "gif", m(0, "GIF87a")),
+	sig("GIF", "image/gif", "gif", m(0, "GIF89a")),
+	sig("WebP", "image/webp", "webp", m(0, "RIFF"), m(8, "WEBP")),
+	sig("TIFF", "image/tiff", "tif", m(0, "II*\x00")),
+	sig("TIFF", "image/tiff", "tif", m(0, "MM\x00*")),
+	sig("Windows icon", "image/vnd.microsoft.icon", "ico", m(0, "\x00\x00\x01\x00")),
+	sig("Photoshop", "image/vnd.adobe.photoshop", "psd", m(0, "8BPS")),
+	sig("Bitmap", "image/bmp", "bmp", m(0, "BM")).with(inspectBMP),
+	sig("ISO base media", "video/mp4", "mp4", m(4, "ftyp")).with(inspectFtyp),
+
+	// audio and video
+	sig("WAVE", "audio/wav", "wav", m(0, "RIFF"), m(8, "WAVE")),
+	sig("AVI", "video/x-msvideo", "avi", m(0, "RIFF"), m(8, "AVI ")),
+	sig("MP3", "audio/mpeg", "mp3", m(0, "ID3")),
+	sig("Ogg", "audio/ogg", "ogg", m(0, "OggS")),
+	sig("FLAC", "audio/flac", "flac", m(0, "fLaC")),
+	sig("MIDI", "audio/midi", "mid", m(0, "MThd")),
+	sig("Matroska", "video/x-matroska", "mkv", m(0, "\x1a\x45\xdf\xa3")).with(inspectMatroska),
+	sig("Flash video", "video/x-flv", "flv", m(0, "FLV\x01")),
+
+	// fonts
+	sig("TrueType font", "font/ttf", "ttf", m(0, "\x00\x01\x00\x00")).with(inspectSfnt),
+	sig("TrueType font", "font/ttf", "ttf", m(0, "true")).with(inspectSfnt),
+	sig("OpenType font", "font/otf", "otf", m(0, "OTTO")).with(inspectSfnt),
+	sig("TrueType collection", "font/collection", "ttc", m(0, "ttcf")),
+	sig("WOFF", "font/woff", "woff", m(0, "wOFF")),
+	sig("WOFF2", "font/woff2", "woff2", m(0, "wOF2")),
+}
+
+func inspectBMP(b []byte, f Format) (Format, bool) {
+	// the 4 bytes after file size are reserved and zero
+	if len(b) >= 10 && bytes.Equal(b[6:10], []byte{0, 0, 0, 0}) {
+		f.Confidence = 0.8
+		return f, true
+	}
+	return f, false
+}
+
+func inspectFtyp(b []byte, f Format) (Format, bool) {
+	if len(b) < 12 {
+		return f, true
+	}
+	brand := string(b[8:12])
+	switch {
+	case brand == "heic" || brand == "heix" || brand == "mif1" || brand == "msf1":
+		f.Name, f.MIME, f.Extension = "HEIF", "image/heic", "heic"
+	case brand == "avif" || brand == "avis":
+		f.Name, f.MIME, f.Extension = "AVIF", "image/avif", "avif"
+	case brand == "qt  ":
+		f.Name, f.MIME, f.Extension = "QuickTime", "video/quicktime", "mov"
+	case brand == "M4A " || brand == "M4B ":
+		f.Name, f.MIME, f.Extension = "MPEG-4 audio", "audio/mp4", "m4a"
+	case brand[:3] == "3gp":
+		f.Name, f.MIME, f.Extension = "3GPP", "video/3gpp", "3gp"
+	}
+	return f, true
+}
+
+func inspectMatroska(b []byte, f Format) (Format, bool) {
+	if bytes.Contains(b[:min(len(b), 64)], []byte("webm")) {
+		f.Name, f.MIME, f.Extension = "WebM", "video/webm", "webm"
+	}
+	return f, true
+}
+
+// inspectSfnt checks table count of TrueType / OpenType header, so random data starting with
+// 00 01 00 00 is not reported as font.
+func inspectSfnt(b []byte, f Format) (Format, bool) {
+	if len(b) < 12 {
+		return f, false
+	}
+	numTables := int(b[4])<<8 | int(b[5])
+	searchRange := int(b[6])<<8 | int(b[7])
+	if numTables == 0 || numTables > 100 {
+		return f, false
+	}
+	// searchRange = (max power of 2 <= numTables) * 16
+	pow := 1
+	for pow*2 <= numTables {
+		pow *= 2
+	}
+	if searchRange != pow*16 {
+		return f, false
+	}
+	f.Confidence = 1
+	return f, true
+}
@@ -0,0 +1,43 @@
+package ggeo
+
+import (
+	"math"
+)
+
+// BBox is a bounding box, MinLng > MaxLng means it crosses the antimeridian.
+type BBox struct {
+	MinLat, MinLng float64
+	MaxLat, MaxLng float64
+}
+
+// BoundingBox returns the smallest box containing all points within radius meters of center.
+func BoundingBox(center LatLng, radius float64) BBox {
+	delta := radius / EarthRadius
+	lat := toRadians(center.Lat)
+	minLat, maxLat := lat-delta, lat+delta
+	if maxLat >= math.Pi/2 || minLat <= -math.Pi/2 {
+		// a pole is inside, all longitudes are covered
+		return BBox{MinLat: toDegrees(max(minLat, -math.Pi/2)), MinLng: -180, MaxLat: toDegrees(min(maxLat, math.Pi/2)), MaxLng: 180}
+	}
+	// the widest longitude is not at center latitude but at the tangent point
+	dLng := toDegrees(math.Asin(math.Sin(delta) / math.Cos(lat)))
+	return BBox{MinLat: toDegrees(minLat), MinLng: normalizeLng(center.Lng - dLng), MaxLat: toDegrees(maxLat), MaxLng: normalizeLng(center.Lng + dLng)}
+}
+
+func (b BBox) Contains(p LatLng) bool {
+	if p.Lat < b.MinLat || p.Lat > b.MaxLat {
+		return false
+	}
+	if b.MinLng <= b.MaxLng {
+		return p.Lng >= b.MinLng && p.Lng <= b.MaxLng
+	}
+	return p.Lng >= b.MinLng || p.Lng <= b.MaxLng
+}
+
+func (b BBox) Center() LatLng {
+	lng := (b.MinLng + b.MaxLng) / 2
+	if b.MinLng > b.MaxLng {
+		lng = normalizeLng(lng + 180)
+	}
+	return LatLng{Lat: (b.MinLat + b.MaxLat) / 2, Lng: lng}
+}
@@ -1,16 +0,0 @@
-package ggeo
-
-import "math"
-
-// Copied from github.com/showwin/speedtest-go
-func GeoDistance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
-	radius := 6378.137
-
-	a1 := lat1 * math.Pi / 180.0
-	b1 := lon1 * math.Pi / 180.0
-	a2 := lat2 * math.Pi / 180.0
-	b2 := lon2 * math.Pi / 180.0
-
-	x := math.Sin(a1)*math.Sin(a2) + math.Cos(a1)*math.Cos(a2)*math.Cos(b2-b1)
-	return radius * math.Acos(x)
-}
@@ -0,0 +1,114 @@
+package ggeo
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	// Flinders Peak to Buninyong, the example of Vincenty's paper
+	a := LatLng{Lat: -(37 + 57.0/60 + 3.72030/3600), Lng: 144 + 25.0/60 + 29.52440/3600}
+	b := LatLng{Lat: -(37 + 39.0/60 + 10.15610/3600), Lng: 143 + 55.0/60 + 35.38390/3600}
+	d, err := Vincenty(a, b)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, math.Abs(d-54972.271) < 0.001, "vincenty %v", d)
+	gtest.AssertTrue(t, math.Abs(Haversine(a, b)-d)/d < 0.005, "haversine %v", Haversine(a, b))
+	gtest.AssertTrue(t, math.Abs(GeoDistance(a.Lat, a.Lng, b.Lat, b.Lng)*1000-d)/d < 0.005, "geo distance")
+	d, err = Vincenty(a, a)
+	gtest.AssertTrue(t, err == nil && d == 0, "same point")
+
+	gtest.AssertTrue(t, math.Abs(Bearing(LatLng{0, 0}, LatLng{0, 1})-90) < 1e-9, "bearing east")
+	gtest.AssertTrue(t, math.Abs(Bearing(LatLng{0, 0}, LatLng{-1, 0})-180) < 1e-9, "bearing south")
+	from := LatLng{Lat: 51.5, Lng: -0.12}
+	to := Destination(from, 60, 100000)
+	gtest.AssertTrue(t, math.Abs(Haversine(from, to)-100000) < 1e-6 && math.Abs(Bearing(from, to)-60) < 1e-9, "destination %s", to)
+	to = Destination(LatLng{Lat: 0, Lng: 179.5}, 90, 100000)
+	gtest.AssertTrue(t, to.Lng < -179 && to.Lng > -180, "destination across antimeridian %s", to)
+}
+
+func TestBoundingBox(t *testing.T) {
+	center := LatLng{Lat: 40, Lng: 116}
+	box := BoundingBox(center, 10000)
+	for bearing := 0.0; bearing < 360; bearing += 15 {
+		gtest.AssertTrue(t, box.Contains(Destination(center, bearing, 9999)), "bearing %v outside %+v", bearing, box)
+	}
+	gtest.AssertTrue(t, !box.Contains(Destination(center, 0, 10100)) && !box.Contains(Destination(center, 90, 10100)), "too large")
+	wrap := BoundingBox(LatLng{Lat: 0, Lng: 179.99}, 10000)
+	gtest.AssertTrue(t, wrap.MinLng > wrap.MaxLng && wrap.Contains(LatLng{Lat: 0, Lng: -179.99}) && !wrap.Contains(LatLng{}), "antimeridian %+v", wrap)
+	pole := BoundingBox(LatLng{Lat: 89.99, Lng: 0}, 10000)
+	gtest.AssertTrue(t, pole.MaxLat == 90 && pole.Contains(LatLng{Lat: 89.995, Lng: 180}), "pole %+v", pole)
+}
+
+func TestGeohash(t *testing.T) {
+	gtest.AssertTrue(t, GeohashEncode(LatLng{Lat: 57.64911, Lng: 10.40744}, 11) == "u4pruydqqvj", "encode %s", GeohashEncode(LatLng{Lat: 57.64911, Lng: 10.40744}, 11))
+	c, box, err := GeohashDecode("EZS42")
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, math.Abs(c.Lat-42.605) < 0.01 && math.Abs(c.Lng+5.603) < 0.01 && box.Contains(c), "decode %s", c)
+	_, _, err = GeohashDecode("ezs4a")
+	gtest.AssertTrue(t, err != nil, "invalid char")
+
+	ns, err := GeohashNeighbors("ezs42")
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, ns[North] == "ezs48" && ns[East] == "ezs43" && ns[South] == "ezs40" && ns[West] == "ezefr", "neighbors %v", ns)
+	// east of the antimeridian and north of the pole
+	edge := GeohashEncode(LatLng{Lat: 89.99, Lng: 179.99}, 4)
+	ns, _ = GeohashNeighbors(edge)
+	gtest.AssertTrue(t, ns[North] == "" && ns[East] == GeohashEncode(LatLng{Lat: 89.99, Lng: -179.99}, 4), "edge neighbors %v", ns)
+}
+
+func TestProjection(t *testing.T) {
+	x, y := ToWebMercator(LatLng{Lat: 0, Lng: 180})
+	gtest.AssertTrue(t, math.Abs(x-20037508.342789244) < 1e-6 && math.Abs(y) < 1e-6, "mercator %v %v", x, y)
+	p := LatLng{Lat: 52.52, Lng: 13.405}
+	back := FromWebMercator(ToWebMercator(p))
+	gtest.AssertTrue(t, math.Abs(back.Lat-p.Lat) < 1e-9 && math.Abs(back.Lng-p.Lng) < 1e-9, "round trip %s", back)
+	tx, ty := Tile(p, 10)
+	gtest.AssertTrue(t, tx == 550 && ty == 335, "tile %d %d", tx, ty)
+	tx, ty = Tile(LatLng{Lat: 90, Lng: 180}, 2)
+	gtest.AssertTrue(t, tx == 3 && ty == 0, "edge tile %d %d", tx, ty)
+}
+
+func TestPolygon(t *testing.T) {
+	pg := Polygon{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 10}, {Lat: 5, Lng: 10}, {Lat: 5, Lng: 5}, {Lat: 10, Lng: 5}, {Lat: 10, Lng: 0}}
+	gtest.AssertTrue(t, pg.Contains(LatLng{Lat: 2, Lng: 8}) && pg.Contains(LatLng{Lat: 8, Lng: 2}) && !pg.Contains(LatLng{Lat: 8, Lng: 8}), "contains")
+	gtest.AssertTrue(t, pg.BBox() == BBox{MinLat: 0, MinLng: 0, MaxLat: 10, MaxLng: 10}, "bbox %+v", pg.BBox())
+}
+
+// TestIndex compares Index with brute force.
+func TestIndex(t *testing.T) {
+	rnd := rand.New(rand.NewSource(50))
+	idx := NewIndex[int]()
+	var pts []LatLng
+	for i := 0; i < 3000; i++ {
+		p := LatLng{Lat: math.Asin(2*rnd.Float64()-1) * 180 / math.Pi, Lng: rnd.Float64()*360 - 180}
+		pts = append(pts, p)
+		idx.Insert(p, i)
+	}
+	gtest.AssertTrue(t, idx.Len() == 3000, "len")
+	for q := 0; q < 100; q++ {
+		p := LatLng{Lat: rnd.Float64()*180 - 90, Lng: rnd.Float64()*360 - 180}
+		dists := make([]float64, len(pts))
+		for i, pt := range pts {
+			dists[i] = Haversine(p, pt)
+		}
+		sorted := slices.Clone(dists)
+		slices.Sort(sorted)
+		got := idx.Nearest(p, 5)
+		gtest.AssertTrue(t, len(got) == 5, "nearest len %d", len(got))
+		for i, n := range got {
+			gtest.AssertTrue(t, math.Abs(n.Distance-sorted[i]) < 1e-3 && math.Abs(dists[n.Value]-n.Distance) < 1e-3, "nearest %d of %s: %v != %v", i, p, n.Distance, sorted[i])
+		}
+		within := idx.Within(p, 500000)
+		count := 0
+		for _, d := range dists {
+			if d <= 500000 {
+				count++
+			}
+		}
+		gtest.AssertTrue(t, len(within) == count, "within %d != %d", len(within), count)
+	}
+	gtest.AssertTrue(t, len(NewIndex[int]().Nearest(LatLng{}, 3)) == 0, "empty index")
+}
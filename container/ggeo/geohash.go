@@ -0,0 +1,110 @@
+package ggeo
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"strings"
+)
+
+// Geohash encodes a point as base32 string, bits interleave longitude and latitude,
+// points sharing a longer prefix are usually closer. Precision 5 is about 4.9km x 4.9km, 9 is about 4.8m x 4.8m.
+// https://en.wikipedia.org/wiki/Geohash
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Directions of GeohashNeighbors.
+const (
+	North = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+func GeohashEncode(p LatLng, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLng, maxLng := -180.0, 180.0
+	var sb strings.Builder
+	even := true
+	bit, ch := 0, 0
+	for sb.Len() < precision {
+		if even {
+			if mid := (minLng + maxLng) / 2; p.Lng >= mid {
+				ch = ch<<1 | 1
+				minLng = mid
+			} else {
+				ch <<= 1
+				maxLng = mid
+			}
+		} else {
+			if mid := (minLat + maxLat) / 2; p.Lat >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch <<= 1
+				maxLat = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			sb.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// GeohashDecode returns the cell of hash and its center.
+func GeohashDecode(hash string) (LatLng, BBox, error) {
+	b := BBox{MinLat: -90, MinLng: -180, MaxLat: 90, MaxLng: 180}
+	if hash == "" {
+		return LatLng{}, b, gerrors.New("empty geohash")
+	}
+	even := true
+	for i := 0; i < len(hash); i++ {
+		v := strings.IndexByte(geohashBase32, hash[i]|0x20) // case-insensitive
+		if v < 0 {
+			return LatLng{}, b, gerrors.New("invalid geohash char %q in %s", hash[i], hash)
+		}
+		for shift := 4; shift >= 0; shift-- {
+			on := v>>shift&1 == 1
+			if even {
+				if mid := (b.MinLng + b.MaxLng) / 2; on {
+					b.MinLng = mid
+				} else {
+					b.MaxLng = mid
+				}
+			} else {
+				if mid := (b.MinLat + b.MaxLat) / 2; on {
+					b.MinLat = mid
+				} else {
+					b.MaxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return b.Center(), b, nil
+}
+
+// GeohashNeighbors returns the 8 neighbour cells of hash indexed by North, NorthEast ... NorthWest,
+// longitude wraps around the antimeridian, cells beyond the poles are "".
+func GeohashNeighbors(hash string) ([8]string, error) {
+	var r [8]string
+	c, b, err := GeohashDecode(hash)
+	if err != nil {
+		return r, err
+	}
+	h, w := b.MaxLat-b.MinLat, b.MaxLng-b.MinLng
+	offsets := [8][2]float64{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	for i, o := range offsets {
+		lat := c.Lat + o[0]*h
+		if lat > 90 || lat < -90 {
+			continue
+		}
+		r[i] = GeohashEncode(LatLng{Lat: lat, Lng: normalizeLng(c.Lng + o[1]*w)}, len(hash))
+	}
+	return r, nil
+}
@@ -0,0 +1,154 @@
+package ggeo
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+)
+
+type (
+	// Index is an in-memory spatial index for nearest-neighbour and radius queries.
+	// Points are stored as 3D unit vectors in a k-d tree, so queries are right near the poles and the antimeridian.
+	// The tree is not rebalanced, it is O(log n) per operation for points inserted in random order.
+	// It is safe for concurrent use.
+	Index[V any] struct {
+		mu   sync.RWMutex
+		root *kdNode[V]
+		size int
+	}
+
+	Neighbor[V any] struct {
+		Point    LatLng
+		Value    V
+		Distance float64 // great-circle distance in meters
+	}
+
+	kdNode[V any] struct {
+		vec         [3]float64
+		point       LatLng
+		value       V
+		left, right *kdNode[V]
+	}
+)
+
+func NewIndex[V any]() *Index[V] {
+	return &Index[V]{}
+}
+
+func unitVector(p LatLng) [3]float64 {
+	sinLat, cosLat := math.Sincos(toRadians(p.Lat))
+	sinLng, cosLng := math.Sincos(toRadians(p.Lng))
+	return [3]float64{cosLat * cosLng, cosLat * sinLng, sinLat}
+}
+
+// chord2 returns the squared straight-line distance between unit vectors, it has the same order as great-circle distance.
+func chord2(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+func chordToMeters(c2 float64) float64 {
+	return 2 * EarthRadius * math.Asin(min(math.Sqrt(c2)/2, 1))
+}
+
+func (idx *Index[V]) Insert(p LatLng, v V) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	n := &kdNode[V]{vec: unitVector(p), point: p, value: v}
+	link := &idx.root
+	for depth := 0; *link != nil; depth++ {
+		axis := depth % 3
+		if n.vec[axis] < (*link).vec[axis] {
+			link = &(*link).left
+		} else {
+			link = &(*link).right
+		}
+	}
+	*link = n
+	idx.size++
+}
+
+func (idx *Index[V]) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.size
+}
+
+// search visits nodes which may be within squared chord limit() of q, nearer subtrees first.
+func (idx *Index[V]) search(q [3]float64, limit func() float64, visit func(n *kdNode[V], c2 float64)) {
+	var walk func(n *kdNode[V], depth int)
+	walk = func(n *kdNode[V], depth int) {
+		if n == nil {
+			return
+		}
+		visit(n, chord2(q, n.vec))
+		axis := depth % 3
+		diff := q[axis] - n.vec[axis]
+		near, far := n.left, n.right
+		if diff >= 0 {
+			near, far = far, near
+		}
+		walk(near, depth+1)
+		if diff*diff <= limit() {
+			walk(far, depth+1)
+		}
+	}
+	walk(idx.root, 0)
+}
+
+// Nearest returns the k nearest points to p ordered by distance.
+func (idx *Index[V]) Nearest(p LatLng, k int) []Neighbor[V] {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	type candidate struct {
+		n  *kdNode[V]
+		c2 float64
+	}
+	if k <= 0 {
+		return nil
+	}
+	var best []candidate // ascending by c2, at most k
+	limit := func() float64 {
+		if len(best) < k {
+			return math.Inf(1)
+		}
+		return best[len(best)-1].c2
+	}
+	idx.search(unitVector(p), limit, func(n *kdNode[V], c2 float64) {
+		if len(best) == k && c2 >= best[k-1].c2 {
+			return
+		}
+		i, _ := slices.BinarySearchFunc(best, c2, func(c candidate, t float64) int {
+			if c.c2 <= t {
+				return -1
+			}
+			return 1
+		})
+		best = slices.Insert(best, i, candidate{n: n, c2: c2})
+		if len(best) > k {
+			best = best[:k]
+		}
+	})
+	r := make([]Neighbor[V], len(best))
+	for i, c := range best {
+		r[i] = Neighbor[V]{Point: c.n.point, Value: c.n.value, Distance: chordToMeters(c.c2)}
+	}
+	return r
+}
+
+// Within returns points within radius meters of p ordered by distance.
+func (idx *Index[V]) Within(p LatLng, radius float64) []Neighbor[V] {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	chord := 2 * math.Sin(math.Min(radius/EarthRadius, math.Pi)/2)
+	limit := chord * chord
+	var r []Neighbor[V]
+	idx.search(unitVector(p), func() float64 { return limit }, func(n *kdNode[V], c2 float64) {
+		if c2 <= limit {
+			r = append(r, Neighbor[V]{Point: n.point, Value: n.value, Distance: chordToMeters(c2)})
+		}
+	})
+	slices.SortFunc(r, func(a, b Neighbor[V]) int { return cmp.Compare(a.Distance, b.Distance) })
+	return r
+}
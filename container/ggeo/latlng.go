@@ -0,0 +1,127 @@
+package ggeo
+
+import (
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+)
+
+const (
+	EarthRadius = 6371008.8 // mean earth radius in meters
+
+	// WGS84 ellipsoid
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+	wgs84B = wgs84A * (1 - wgs84F)
+)
+
+// LatLng is a geographic coordinate in degrees.
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+func (p LatLng) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+func (p LatLng) String() string {
+	return fmt.Sprintf("%.6f,%.6f", p.Lat, p.Lng)
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// normalizeLng wraps longitude into [-180, 180).
+func normalizeLng(lng float64) float64 {
+	return math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+}
+
+// GeoDistance returns distance in kilometers by spherical law of cosines.
+// Copied from github.com/showwin/speedtest-go, Haversine is more accurate for short distances.
+func GeoDistance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	radius := 6378.137
+
+	a1 := lat1 * math.Pi / 180.0
+	b1 := lon1 * math.Pi / 180.0
+	a2 := lat2 * math.Pi / 180.0
+	b2 := lon2 * math.Pi / 180.0
+
+	x := math.Sin(a1)*math.Sin(a2) + math.Cos(a1)*math.Cos(a2)*math.Cos(b2-b1)
+	return radius * math.Acos(x)
+}
+
+// Haversine returns great-circle distance in meters on a sphere of EarthRadius, error is up to about 0.5%.
+func Haversine(a, b LatLng) float64 {
+	lat1, lat2 := toRadians(a.Lat), toRadians(b.Lat)
+	dLat, dLng := lat2-lat1, toRadians(b.Lng-a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(min(h, 1)))
+}
+
+// Vincenty returns distance in meters on WGS84 ellipsoid by Vincenty's inverse formula, it is accurate to 0.5mm,
+// but it may not converge for nearly antipodal points.
+func Vincenty(a, b LatLng) (float64, error) {
+	L := toRadians(b.Lng - a.Lng)
+	U1 := math.Atan((1 - wgs84F) * math.Tan(toRadians(a.Lat)))
+	U2 := math.Atan((1 - wgs84F) * math.Tan(toRadians(b.Lat)))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+
+	lambda := L
+	var sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	for i := 0; ; i++ {
+		if i == 200 {
+			return 0, gerrors.New("vincenty doesn't converge between %s and %s", a.String(), b.String())
+		}
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, nil // coincident points
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha // 0 on equatorial line
+		}
+		C := wgs84F / 16 * cos2Alpha * (4 + wgs84F*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = L + (1-C)*wgs84F*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) < 1e-12 {
+			break
+		}
+	}
+	uSq := cos2Alpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	return wgs84B * A * (sigma - deltaSigma), nil
+}
+
+// Bearing returns the initial bearing in degrees [0, 360) from a to b along the great circle, 0 is north, 90 is east.
+func Bearing(a, b LatLng) float64 {
+	lat1, lat2 := toRadians(a.Lat), toRadians(b.Lat)
+	dLng := toRadians(b.Lng - a.Lng)
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+	return math.Mod(toDegrees(math.Atan2(y, x))+360, 360)
+}
+
+// Destination returns the point reached from p by going distance meters along the great circle of initial bearing.
+func Destination(p LatLng, bearing, distance float64) LatLng {
+	lat1, lng1 := toRadians(p.Lat), toRadians(p.Lng)
+	theta := toRadians(bearing)
+	delta := distance / EarthRadius
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	lng2 := lng1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat1), math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+	return LatLng{Lat: toDegrees(lat2), Lng: normalizeLng(toDegrees(lng2))}
+}
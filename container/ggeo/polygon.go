@@ -0,0 +1,30 @@
+package ggeo
+
+// Polygon is a lat/lon polygon whose edges are straight lines in lat/lon plane,
+// the last vertex connects to the first one, it must not cross the antimeridian.
+type Polygon []LatLng
+
+// Contains reports whether p is inside the polygon by ray casting, points on the boundary may be either inside or outside.
+func (pg Polygon) Contains(p LatLng) bool {
+	inside := false
+	for i, a := range pg {
+		b := pg[(i+1)%len(pg)]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) && p.Lng < a.Lng+(p.Lat-a.Lat)*(b.Lng-a.Lng)/(b.Lat-a.Lat) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// BBox returns the bounding box of the polygon.
+func (pg Polygon) BBox() BBox {
+	if len(pg) == 0 {
+		return BBox{}
+	}
+	b := BBox{MinLat: pg[0].Lat, MinLng: pg[0].Lng, MaxLat: pg[0].Lat, MaxLng: pg[0].Lng}
+	for _, p := range pg[1:] {
+		b.MinLat, b.MaxLat = min(b.MinLat, p.Lat), max(b.MaxLat, p.Lat)
+		b.MinLng, b.MaxLng = min(b.MinLng, p.Lng), max(b.MaxLng, p.Lng)
+	}
+	return b
+}
@@ -0,0 +1,36 @@
+package ggeo
+
+import (
+	"math"
+)
+
+// MaxMercatorLat is the latitude limit of Web Mercator, the map is square at this latitude.
+const MaxMercatorLat = 85.05112877980659
+
+// ToWebMercator projects p to EPSG:3857 Web Mercator in meters, latitude is clamped to ±MaxMercatorLat.
+func ToWebMercator(p LatLng) (x, y float64) {
+	lat := math.Max(-MaxMercatorLat, math.Min(MaxMercatorLat, p.Lat))
+	x = wgs84A * toRadians(p.Lng)
+	y = wgs84A * math.Log(math.Tan(math.Pi/4+toRadians(lat)/2))
+	return x, y
+}
+
+// FromWebMercator is the inverse of ToWebMercator.
+func FromWebMercator(x, y float64) LatLng {
+	return LatLng{
+		Lat: toDegrees(2*math.Atan(math.Exp(y/wgs84A)) - math.Pi/2),
+		Lng: toDegrees(x / wgs84A),
+	}
+}
+
+// Tile returns x and y of the slippy map tile of zoom which contains p, like OpenStreetMap tiles.
+func Tile(p LatLng, zoom int) (x, y int) {
+	n := float64(int64(1) << zoom)
+	mx, my := ToWebMercator(p)
+	fx := (mx/(math.Pi*wgs84A) + 1) / 2 * n
+	fy := (1 - my/(math.Pi*wgs84A)) / 2 * n
+	clamp := func(v float64) int {
+		return int(math.Max(0, math.Min(n-1, math.Floor(v))))
+	}
+	return clamp(fx), clamp(fy)
+}
@@ -0,0 +1,64 @@
+package ggeometry
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	s := Segment{A: Pt(0, 0), B: Pt(4, 4)}
+	p, ok := s.Intersection(Segment{A: Pt(0, 4), B: Pt(4, 0)})
+	gtest.AssertTrue(t, ok && p.Eq(Pt(2, 2)), "intersection %v", p)
+	_, ok = s.Intersection(Segment{A: Pt(5, 0), B: Pt(6, -1)})
+	gtest.AssertTrue(t, !ok, "lines intersect but segments don't")
+	gtest.AssertTrue(t, s.Intersects(Segment{A: Pt(4, 4), B: Pt(5, 0)}), "touching at end")
+	gtest.AssertTrue(t, s.Intersects(Segment{A: Pt(3, 3), B: Pt(6, 6)}), "collinear overlap")
+	gtest.AssertTrue(t, !s.Intersects(Segment{A: Pt(5, 5), B: Pt(6, 6)}), "collinear disjoint")
+	gtest.AssertTrue(t, s.Contains(Pt(1, 1)) && !s.Contains(Pt(1, 2)) && !s.Contains(Pt(5, 5)), "contains")
+	gtest.AssertTrue(t, math.Abs(s.Dist(Pt(0, 4))-math.Sqrt(8)) < Epsilon && s.Dist(Pt(-3, -4)) == 5, "dist")
+
+	_, ok = LineIntersection(Pt(0, 0), Pt(1, 1), Pt(0, 1), Pt(1, 2))
+	gtest.AssertTrue(t, !ok, "parallel")
+	p, ok = LineIntersection(Pt(0, 0), Pt(1, 0), Pt(5, 1), Pt(5, 2))
+	gtest.AssertTrue(t, ok && p.Eq(Pt(5, 0)), "lines %v", p)
+	gtest.AssertTrue(t, Pt(0, 1).Angle() == 90 && Orientation(Pt(0, 0), Pt(1, 0), Pt(1, 1)) == 1, "angle")
+}
+
+func TestPolygon(t *testing.T) {
+	// L shape, counter-clockwise
+	pg := Polygon{Pt(0, 0), Pt(4, 0), Pt(4, 1), Pt(1, 1), Pt(1, 3), Pt(0, 3)}
+	gtest.AssertTrue(t, pg.SignedArea() == 6 && pg.Area() == 6 && pg.Perimeter() == 14, "area %v perimeter %v", pg.Area(), pg.Perimeter())
+	c := pg.Centroid()
+	gtest.AssertTrue(t, c.Eq(Pt(1.5, 1)), "centroid %v", c)
+	gtest.AssertTrue(t, pg.Contains(Pt(0.5, 2)) && pg.Contains(Pt(3, 0.5)) && !pg.Contains(Pt(2, 2)), "contains")
+	gtest.AssertTrue(t, pg.Contains(Pt(4, 0.5)) && pg.Contains(Pt(0, 0)), "boundary")
+	gtest.AssertTrue(t, !pg.IsConvex() && Polygon{Pt(0, 0), Pt(2, 0), Pt(2, 2), Pt(0, 2)}.IsConvex(), "convex")
+
+	cw := Polygon{Pt(0, 0), Pt(0, 2), Pt(2, 2), Pt(2, 0)}
+	gtest.AssertTrue(t, cw.SignedArea() == -4 && cw.Centroid().Eq(Pt(1, 1)), "clockwise")
+}
+
+func TestConvexHull(t *testing.T) {
+	pts := []Point{Pt(0, 0), Pt(2, 0), Pt(1, 1), Pt(2, 2), Pt(0, 2), Pt(1, 0), Pt(2, 2), Pt(0.5, 1.5)}
+	hull := ConvexHull(pts)
+	want := Polygon{Pt(0, 0), Pt(2, 0), Pt(2, 2), Pt(0, 2)}
+	gtest.AssertTrue(t, len(hull) == len(want), "hull %v", hull)
+	for i := range want {
+		gtest.AssertTrue(t, hull[i].Eq(want[i]), "hull %v", hull)
+	}
+	gtest.AssertTrue(t, len(ConvexHull([]Point{Pt(0, 0), Pt(1, 1), Pt(2, 2)})) == 2, "collinear")
+
+	// every random point is in the hull, and the hull is convex and counter-clockwise
+	rnd := rand.New(rand.NewSource(49))
+	pts = make([]Point, 500)
+	for i := range pts {
+		pts[i] = Pt(rnd.NormFloat64(), rnd.NormFloat64())
+	}
+	hull = ConvexHull(pts)
+	gtest.AssertTrue(t, hull.IsConvex() && hull.SignedArea() > 0, "hull not convex")
+	for _, p := range pts {
+		gtest.AssertTrue(t, hull.Contains(p), "%v not in hull", p)
+	}
+}
@@ -0,0 +1,139 @@
+package ggeometry
+
+import (
+	"fmt"
+	"math"
+)
+
+// Epsilon is the tolerance of float comparisons in this package.
+const Epsilon = 1e-9
+
+type (
+	// Point is a 2D point or vector.
+	Point struct {
+		X, Y float64
+	}
+
+	// Segment is the line segment from A to B.
+	Segment struct {
+		A, B Point
+	}
+)
+
+func Pt(x, y float64) Point {
+	return Point{X: x, Y: y}
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+func (p Point) Sub(q Point) Point {
+	return Point{X: p.X - q.X, Y: p.Y - q.Y}
+}
+
+func (p Point) Scale(k float64) Point {
+	return Point{X: p.X * k, Y: p.Y * k}
+}
+
+func (p Point) Dot(q Point) float64 {
+	return p.X*q.X + p.Y*q.Y
+}
+
+// Cross returns z of the cross product, > 0 if q is counter-clockwise from p.
+func (p Point) Cross(q Point) float64 {
+	return p.X*q.Y - p.Y*q.X
+}
+
+// Len returns the length of vector p.
+func (p Point) Len() float64 {
+	return math.Hypot(p.X, p.Y)
+}
+
+func (p Point) Dist(q Point) float64 {
+	return p.Sub(q).Len()
+}
+
+// Eq reports whether p and q are equal within Epsilon.
+func (p Point) Eq(q Point) bool {
+	return math.Abs(p.X-q.X) <= Epsilon && math.Abs(p.Y-q.Y) <= Epsilon
+}
+
+// Angle returns the angle degree of vector p from x axis in (-180, 180].
+func (p Point) Angle() float64 {
+	return RadianToAngle(math.Atan2(p.Y, p.X))
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%v, %v)", p.X, p.Y)
+}
+
+// Orientation returns 1 if a, b, c turn counter-clockwise, -1 if clockwise, 0 if collinear.
+func Orientation(a, b, c Point) int {
+	cross := b.Sub(a).Cross(c.Sub(a))
+	switch {
+	case cross > Epsilon:
+		return 1
+	case cross < -Epsilon:
+		return -1
+	}
+	return 0
+}
+
+func (s Segment) Len() float64 {
+	return s.A.Dist(s.B)
+}
+
+// Contains reports whether p is on the segment.
+func (s Segment) Contains(p Point) bool {
+	return Orientation(s.A, s.B, p) == 0 &&
+		p.X >= math.Min(s.A.X, s.B.X)-Epsilon && p.X <= math.Max(s.A.X, s.B.X)+Epsilon &&
+		p.Y >= math.Min(s.A.Y, s.B.Y)-Epsilon && p.Y <= math.Max(s.A.Y, s.B.Y)+Epsilon
+}
+
+// ClosestPoint returns the point on the segment closest to p.
+func (s Segment) ClosestPoint(p Point) Point {
+	d := s.B.Sub(s.A)
+	l2 := d.Dot(d)
+	if l2 == 0 {
+		return s.A
+	}
+	t := math.Max(0, math.Min(1, p.Sub(s.A).Dot(d)/l2))
+	return s.A.Add(d.Scale(t))
+}
+
+// Dist returns the distance from p to the segment.
+func (s Segment) Dist(p Point) float64 {
+	return p.Dist(s.ClosestPoint(p))
+}
+
+// Intersects reports whether the segments have any common point.
+func (s Segment) Intersects(o Segment) bool {
+	o1, o2 := Orientation(s.A, s.B, o.A), Orientation(s.A, s.B, o.B)
+	o3, o4 := Orientation(o.A, o.B, s.A), Orientation(o.A, o.B, s.B)
+	if o1 != o2 && o3 != o4 {
+		return true
+	}
+	return s.Contains(o.A) || s.Contains(o.B) || o.Contains(s.A) || o.Contains(s.B)
+}
+
+// Intersection returns the common point of the segments, ok is false if they don't intersect
+// or they overlap collinearly, in which case there is no single common point.
+func (s Segment) Intersection(o Segment) (p Point, ok bool) {
+	p, ok = LineIntersection(s.A, s.B, o.A, o.B)
+	if !ok || !s.Contains(p) || !o.Contains(p) {
+		return Point{}, false
+	}
+	return p, true
+}
+
+// LineIntersection returns the intersection of line a1-a2 and line b1-b2, ok is false if they are parallel.
+func LineIntersection(a1, a2, b1, b2 Point) (Point, bool) {
+	da, db := a2.Sub(a1), b2.Sub(b1)
+	denom := da.Cross(db)
+	if math.Abs(denom) <= Epsilon*da.Len()*db.Len() {
+		return Point{}, false
+	}
+	t := b1.Sub(a1).Cross(db) / denom
+	return a1.Add(da.Scale(t)), true
+}
@@ -0,0 +1,134 @@
+package ggeometry
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Polygon is a simple polygon of vertices in order, the last vertex connects to the first one.
+type Polygon []Point
+
+// SignedArea returns area by shoelace formula, it is > 0 if vertices are counter-clockwise.
+func (pg Polygon) SignedArea() float64 {
+	a := 0.0
+	for i, p := range pg {
+		a += p.Cross(pg[(i+1)%len(pg)])
+	}
+	return a / 2
+}
+
+func (pg Polygon) Area() float64 {
+	return math.Abs(pg.SignedArea())
+}
+
+func (pg Polygon) Perimeter() float64 {
+	l := 0.0
+	for i, p := range pg {
+		l += p.Dist(pg[(i+1)%len(pg)])
+	}
+	return l
+}
+
+// Centroid returns the center of mass of the polygon area,
+// it is the mean of vertices if the area is 0.
+func (pg Polygon) Centroid() Point {
+	a := pg.SignedArea()
+	if math.Abs(a) <= Epsilon {
+		var c Point
+		for _, p := range pg {
+			c = c.Add(p)
+		}
+		return c.Scale(1 / float64(max(len(pg), 1)))
+	}
+	var cx, cy float64
+	for i, p := range pg {
+		q := pg[(i+1)%len(pg)]
+		cross := p.Cross(q)
+		cx += (p.X + q.X) * cross
+		cy += (p.Y + q.Y) * cross
+	}
+	return Point{X: cx / (6 * a), Y: cy / (6 * a)}
+}
+
+// Edges returns edges of the polygon.
+func (pg Polygon) Edges() []Segment {
+	r := make([]Segment, len(pg))
+	for i, p := range pg {
+		r[i] = Segment{A: p, B: pg[(i+1)%len(pg)]}
+	}
+	return r
+}
+
+// Contains reports whether p is inside the polygon or on its boundary, by ray casting.
+func (pg Polygon) Contains(p Point) bool {
+	inside := false
+	for i, a := range pg {
+		b := pg[(i+1)%len(pg)]
+		if (Segment{A: a, B: b}).Contains(p) {
+			return true
+		}
+		// edge crosses the horizontal ray from p to +x
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < a.X+(p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// IsConvex reports whether the polygon is convex, collinear vertices are allowed.
+func (pg Polygon) IsConvex() bool {
+	sign := 0
+	for i := range pg {
+		o := Orientation(pg[i], pg[(i+1)%len(pg)], pg[(i+2)%len(pg)])
+		if o == 0 {
+			continue
+		}
+		if sign != 0 && o != sign {
+			return false
+		}
+		sign = o
+	}
+	return true
+}
+
+// ConvexHull returns the convex hull of points in counter-clockwise order without collinear vertices,
+// starting from the lowest-leftmost point, by Andrew's monotone chain in O(n log n).
+func ConvexHull(points []Point) Polygon {
+	ps := slices.Clone(points)
+	slices.SortFunc(ps, func(a, b Point) int {
+		if c := cmp.Compare(a.X, b.X); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Y, b.Y)
+	})
+	ps = slices.CompactFunc(ps, Point.Eq)
+	if len(ps) < 3 {
+		return ps
+	}
+	hull := make([]Point, 0, 2*len(ps))
+	// lower hull, then upper hull
+	for _, p := range ps {
+		for len(hull) >= 2 && Orientation(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(ps) - 2; i >= 0; i-- {
+		p := ps[i]
+		for len(hull) >= lower && Orientation(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	hull = hull[:len(hull)-1]
+	// start from the lowest-leftmost point
+	start := 0
+	for i, p := range hull {
+		if p.Y < hull[start].Y || (p.Y == hull[start].Y && p.X < hull[start].X) {
+			start = i
+		}
+	}
+	return append(hull[start:], hull[:start]...)
+}
@@ -0,0 +1,353 @@
+package glist
+
+import (
+	"cmp"
+	"iter"
+	"math/rand/v2"
+	"sync"
+)
+
+/*
+skipList is a skip list like Redis zset, every link records its span (count of nodes it skips),
+so rank queries are O(log n) expected besides search, insertion and deletion.
+Items equal by cmp are treated as the same item.
+*/
+
+const slMaxLevel = 32
+
+type (
+	skipList[T any] struct {
+		head  *slNode[T]
+		tail  *slNode[T]
+		level int
+		size  int
+		cmp   func(a, b T) int
+	}
+
+	slNode[T any] struct {
+		item   T
+		prev   *slNode[T]
+		levels []slLevel[T]
+	}
+
+	slLevel[T any] struct {
+		next *slNode[T]
+		span int
+	}
+
+	// SkipList is a sorted list with rank queries based on skip list,
+	// Insert, Delete, IndexOf and At are O(log n) expected.
+	// It is safe for concurrent use.
+	SkipList[T any] struct {
+		mu sync.RWMutex
+		sl *skipList[T]
+	}
+)
+
+func newSkipList[T any](compare func(a, b T) int) *skipList[T] {
+	return &skipList[T]{head: &slNode[T]{levels: make([]slLevel[T], slMaxLevel)}, level: 1, cmp: compare}
+}
+
+// randomLevel returns level in [1, slMaxLevel], level k has probability 1/4 of level k-1.
+func randomLevel() int {
+	lvl := 1
+	for lvl < slMaxLevel && rand.Uint32()&3 == 0 {
+		lvl++
+	}
+	return lvl
+}
+
+// insert adds item and returns true, it returns false if an equal item exists.
+func (l *skipList[T]) insert(item T) bool {
+	var update [slMaxLevel]*slNode[T]
+	var rank [slMaxLevel]int
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].next != nil && l.cmp(x.levels[i].next.item, item) < 0 {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].next
+		}
+		update[i] = x
+	}
+	if n := x.levels[0].next; n != nil && l.cmp(n.item, item) == 0 {
+		return false
+	}
+	lvl := randomLevel()
+	for i := l.level; i < lvl; i++ {
+		update[i] = l.head
+		update[i].levels[i].span = l.size
+	}
+	l.level = max(l.level, lvl)
+
+	x = &slNode[T]{item: item, levels: make([]slLevel[T], lvl)}
+	for i := 0; i < lvl; i++ {
+		x.levels[i].next = update[i].levels[i].next
+		update[i].levels[i].next = x
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+	for i := lvl; i < l.level; i++ {
+		update[i].levels[i].span++
+	}
+	if update[0] != l.head {
+		x.prev = update[0]
+	}
+	if next := x.levels[0].next; next != nil {
+		next.prev = x
+	} else {
+		l.tail = x
+	}
+	l.size++
+	return true
+}
+
+// delete removes the item equal to item.
+func (l *skipList[T]) delete(item T) (T, bool) {
+	var update [slMaxLevel]*slNode[T]
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].next != nil && l.cmp(x.levels[i].next.item, item) < 0 {
+			x = x.levels[i].next
+		}
+		update[i] = x
+	}
+	x = x.levels[0].next
+	if x == nil || l.cmp(x.item, item) != 0 {
+		var zero T
+		return zero, false
+	}
+	l.unlink(x, update[:l.level])
+	return x.item, true
+}
+
+// unlink removes x, update[i] is the last node before x on level i.
+func (l *skipList[T]) unlink(x *slNode[T], update []*slNode[T]) {
+	for i, u := range update {
+		if u.levels[i].next == x {
+			u.levels[i].span += x.levels[i].span - 1
+			u.levels[i].next = x.levels[i].next
+		} else {
+			u.levels[i].span--
+		}
+	}
+	if next := x.levels[0].next; next != nil {
+		next.prev = x.prev
+	} else {
+		l.tail = x.prev
+	}
+	for l.level > 1 && l.head.levels[l.level-1].next == nil {
+		l.level--
+	}
+	l.size--
+}
+
+// search returns the index and node of the first item for which pred is true,
+// pred must be false for a prefix of items and true for the rest, like sort.Search.
+func (l *skipList[T]) search(pred func(item T) bool) (int, *slNode[T]) {
+	rank := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].next != nil && !pred(x.levels[i].next.item) {
+			rank += x.levels[i].span
+			x = x.levels[i].next
+		}
+	}
+	return rank, x.levels[0].next
+}
+
+// find returns the node equal to item and its index.
+func (l *skipList[T]) find(item T) (int, *slNode[T]) {
+	i, n := l.search(func(v T) bool { return l.cmp(v, item) >= 0 })
+	if n == nil || l.cmp(n.item, item) != 0 {
+		return -1, nil
+	}
+	return i, n
+}
+
+// at returns the node of index i.
+func (l *skipList[T]) at(i int) *slNode[T] {
+	if i < 0 || i >= l.size {
+		return nil
+	}
+	target := i + 1
+	traversed := 0
+	x := l.head
+	for lvl := l.level - 1; lvl >= 0; lvl-- {
+		for x.levels[lvl].next != nil && traversed+x.levels[lvl].span <= target {
+			traversed += x.levels[lvl].span
+			x = x.levels[lvl].next
+		}
+		if traversed == target {
+			return x
+		}
+	}
+	return nil
+}
+
+// collect returns at most n items from node x, n < 0 means no limit.
+func collect[T any](x *slNode[T], n int, stop func(item T) bool) []T {
+	var r []T
+	for ; x != nil && n != 0 && (stop == nil || !stop(x.item)); x = x.levels[0].next {
+		r = append(r, x.item)
+		n--
+	}
+	return r
+}
+
+func NewSkipList[T cmp.Ordered]() *SkipList[T] {
+	return NewSkipListFunc[T](cmp.Compare[T])
+}
+
+// NewSkipListFunc creates a SkipList with custom comparator which returns -1, 0 or +1 like cmp.Compare.
+func NewSkipListFunc[T any](compare func(a, b T) int) *SkipList[T] {
+	return &SkipList[T]{sl: newSkipList(compare)}
+}
+
+// Insert adds item and returns true, it returns false if an equal item exists.
+func (s *SkipList[T]) Insert(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sl.insert(item)
+}
+
+// Delete removes the item equal to item.
+func (s *SkipList[T]) Delete(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.sl.delete(item)
+	return ok
+}
+
+// Get returns the stored item equal to item, it is useful if cmp compares part of T.
+func (s *SkipList[T]) Get(item T) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, n := s.sl.find(item); n != nil {
+		return n.item, true
+	}
+	var zero T
+	return zero, false
+}
+
+func (s *SkipList[T]) Contains(item T) bool {
+	_, ok := s.Get(item)
+	return ok
+}
+
+// IndexOf returns the 0-based rank of item, or -1 if it doesn't exist.
+func (s *SkipList[T]) IndexOf(item T) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, _ := s.sl.find(item)
+	return i
+}
+
+// At returns the item of 0-based rank i.
+func (s *SkipList[T]) At(i int) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if n := s.sl.at(i); n != nil {
+		return n.item, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Search returns the index of the first item for which pred is true, or Len() if there is none,
+// pred must be false for a prefix of items and true for the rest, like sort.Search.
+func (s *SkipList[T]) Search(pred func(item T) bool) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, _ := s.sl.search(pred)
+	return i
+}
+
+func (s *SkipList[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sl.size
+}
+
+func (s *SkipList[T]) Min() (T, bool) {
+	return s.At(0)
+}
+
+func (s *SkipList[T]) Max() (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.sl.tail == nil {
+		var zero T
+		return zero, false
+	}
+	return s.sl.tail.item, true
+}
+
+func (s *SkipList[T]) pop(last bool) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.sl.head.levels[0].next
+	if last {
+		n = s.sl.tail
+	}
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	return s.sl.delete(n.item)
+}
+
+func (s *SkipList[T]) PopMin() (T, bool) {
+	return s.pop(false)
+}
+
+func (s *SkipList[T]) PopMax() (T, bool) {
+	return s.pop(true)
+}
+
+// Range returns items with from <= item < to in ascending order.
+func (s *SkipList[T]) Range(from, to T) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, n := s.sl.search(func(v T) bool { return s.sl.cmp(v, from) >= 0 })
+	return collect(n, -1, func(v T) bool { return s.sl.cmp(v, to) >= 0 })
+}
+
+// RangeByRank returns items with start <= index < stop in ascending order.
+func (s *SkipList[T]) RangeByRank(start, stop int) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	start, stop = max(start, 0), min(stop, s.sl.size)
+	if start >= stop {
+		return nil
+	}
+	return collect(s.sl.at(start), stop-start, nil)
+}
+
+// All iterates items in ascending order under read lock, so the list must not be modified in the loop.
+func (s *SkipList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		for x := s.sl.head.levels[0].next; x != nil; x = x.levels[0].next {
+			if !yield(x.item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterates items in descending order under read lock, so the list must not be modified in the loop.
+func (s *SkipList[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		for x := s.sl.tail; x != nil; x = x.prev {
+			if !yield(x.item) {
+				return
+			}
+		}
+	}
+}
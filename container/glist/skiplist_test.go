@@ -0,0 +1,111 @@
+package glist
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// TestSkipList_Random compares SkipList with a sorted slice.
+func TestSkipList_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(46))
+	sl := NewSkipList[int]()
+	var want []int
+	for i := 0; i < 20000; i++ {
+		v := rnd.Intn(5000)
+		j, found := slices.BinarySearch(want, v)
+		if rnd.Intn(3) == 0 {
+			gtest.AssertTrue(t, sl.Delete(v) == found, "delete %d", v)
+			if found {
+				want = slices.Delete(want, j, j+1)
+			}
+		} else {
+			gtest.AssertTrue(t, sl.Insert(v) == !found, "insert %d", v)
+			if !found {
+				want = slices.Insert(want, j, v)
+			}
+		}
+	}
+	gtest.AssertTrue(t, sl.Len() == len(want) && slices.Equal(slices.Collect(sl.All()), want), "len %d != %d", sl.Len(), len(want))
+	for q := 0; q < 1000; q++ {
+		v := rnd.Intn(5100)
+		j, found := slices.BinarySearch(want, v)
+		if !found {
+			j = -1
+		}
+		gtest.AssertTrue(t, sl.IndexOf(v) == j, "index of %d: %d != %d", v, sl.IndexOf(v), j)
+		i := rnd.Intn(len(want))
+		got, ok := sl.At(i)
+		gtest.AssertTrue(t, ok && got == want[i], "at %d: %d != %d", i, got, want[i])
+		gtest.AssertTrue(t, sl.Search(func(x int) bool { return x >= v }) == sort.SearchInts(want, v), "search %d", v)
+	}
+	from, to := 1000, 1200
+	lo, hi := sort.SearchInts(want, from), sort.SearchInts(want, to)
+	gtest.AssertTrue(t, slices.Equal(sl.Range(from, to), want[lo:hi]), "range")
+	gtest.AssertTrue(t, slices.Equal(sl.RangeByRank(10, 20), want[10:20]), "range by rank")
+	back := slices.Collect(sl.Backward())
+	slices.Reverse(back)
+	gtest.AssertTrue(t, slices.Equal(back, want), "backward")
+
+	minV, _ := sl.PopMin()
+	maxV, _ := sl.PopMax()
+	gtest.AssertTrue(t, minV == want[0] && maxV == want[len(want)-1] && sl.Len() == len(want)-2, "pop")
+	_, ok := sl.At(len(want) - 2)
+	gtest.AssertTrue(t, !ok, "at out of range")
+}
+
+func TestSkipList_Concurrent(t *testing.T) {
+	sl := NewSkipList[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				sl.Insert(g*1000 + i)
+				_ = sl.IndexOf(i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	v, _ := sl.At(4321)
+	gtest.AssertTrue(t, sl.Len() == 8000 && v == 4321, "len %d", sl.Len())
+}
+
+func TestZSet(t *testing.T) {
+	z := NewZSet[string]()
+	gtest.AssertTrue(t, z.ZAdd(100, "alice") && z.ZAdd(80, "bob") && z.ZAdd(80, "amy") && z.ZAdd(120, "carol"), "add")
+	gtest.AssertTrue(t, !z.ZAdd(90, "bob") && z.ZIncrBy(50, "bob") == 140 && z.ZIncrBy(1, "dave") == 1, "update")
+	members := func(es []ZEntry[string]) []string {
+		var r []string
+		for _, e := range es {
+			r = append(r, e.Member)
+		}
+		return r
+	}
+	gtest.AssertTrue(t, slices.Equal(members(z.ZRange(0, -1)), []string{"dave", "amy", "alice", "carol", "bob"}), "range %v", z.ZRange(0, -1))
+	gtest.AssertTrue(t, slices.Equal(members(z.ZRevRange(0, 2)), []string{"bob", "carol", "alice"}), "top 3 %v", z.ZRevRange(0, 2))
+	gtest.AssertTrue(t, slices.Equal(members(z.ZRange(-2, 100)), []string{"carol", "bob"}), "negative range")
+	gtest.AssertTrue(t, len(z.ZRange(3, 1)) == 0 && len(z.ZRevRange(9, 10)) == 0, "empty range")
+
+	rank, ok := z.ZRank("alice")
+	revRank, _ := z.ZRevRank("alice")
+	gtest.AssertTrue(t, ok && rank == 2 && revRank == 2, "rank %d rev rank %d", rank, revRank)
+	_, ok = z.ZRank("nobody")
+	gtest.AssertTrue(t, !ok, "rank of missing member")
+
+	gtest.AssertTrue(t, slices.Equal(members(z.ZRangeByScore(80, 120, 0, -1)), []string{"amy", "alice", "carol"}), "by score")
+	gtest.AssertTrue(t, slices.Equal(members(z.ZRangeByScore(0, 1000, 1, 2)), []string{"amy", "alice"}), "by score with limit")
+	gtest.AssertTrue(t, slices.Equal(members(z.ZRevRangeByScore(120, 80, 1, -1)), []string{"alice", "amy"}), "rev by score %v", z.ZRevRangeByScore(120, 80, 1, -1))
+	gtest.AssertTrue(t, z.ZCount(80, 120) == 3 && z.ZCount(200, 300) == 0 && len(z.ZRangeByScore(200, 300, 0, -1)) == 0, "count")
+
+	gtest.AssertTrue(t, z.ZRemRangeByRank(0, -4) == 2 && z.ZCard() == 3, "rem by rank")
+	gtest.AssertTrue(t, z.ZRemRangeByScore(130, 200) == 1 && z.ZRem("alice", "nobody") == 1, "rem")
+	e, ok := z.ZPopMax()
+	gtest.AssertTrue(t, ok && e.Member == "carol" && e.Score == 120 && z.ZCard() == 0, "pop max")
+	_, ok = z.ZScore("carol")
+	gtest.AssertTrue(t, !ok, "score of popped member")
+}
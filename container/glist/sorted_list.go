@@ -1,78 +1,67 @@
 package glist
 
 import (
-	"container/list"
+	"cmp"
 	"fmt"
 )
 
+// SortedList is a list sorted by weight, values with the same weight are kept in push order.
+// It is based on skip list, so Push is O(log n).
 type SortedList struct {
-	list *list.List
+	list *skipList[sortedElement]
+	seq  uint64
 }
 
 type sortedElement struct {
 	weight int64
+	seq    uint64
 	value  interface{}
 }
 
+func compareSortedElement(a, b sortedElement) int {
+	if c := cmp.Compare(a.weight, b.weight); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
 func NewSortedList() *SortedList {
 	return &SortedList{
-		list: list.New(),
+		list: newSkipList(compareSortedElement),
 	}
 }
 
 func (sl *SortedList) Init() {
-	sl.list.Init()
+	sl.list = newSkipList(compareSortedElement)
 }
 
 func (sl *SortedList) Len() int {
-	return sl.list.Len()
+	return sl.list.size
 }
 
 func (sl *SortedList) Push(weight int64, value interface{}) {
-	newValue := sortedElement{weight: weight, value: value}
-
-	if sl.list.Len() == 0 {
-		sl.list.PushFront(newValue)
-	} else if weight <= sl.list.Front().Value.(sortedElement).weight { /* smallest new head */
-		sl.list.PushFront(newValue)
-	} else if weight >= sl.list.Back().Value.(sortedElement).weight { /* largest new tail */
-		sl.list.PushBack(newValue)
-	} else {
-		for e := sl.list.Front(); e != nil; e = e.Next() {
-			if weight <= e.Value.(sortedElement).weight {
-				sl.list.InsertBefore(newValue, e)
-				break
-			}
-		}
-	}
+	sl.seq++
+	sl.list.insert(sortedElement{weight: weight, seq: sl.seq, value: value})
 }
 
-func (sl *SortedList) PopMinWeight() (weight int64, value interface{}, exist bool) {
-	if sl.list.Len() == 0 {
+func (sl *SortedList) pop(node *slNode[sortedElement]) (weight int64, value interface{}, exist bool) {
+	if node == nil {
 		return 0, nil, false
 	}
+	sl.list.delete(node.item)
+	return node.item.weight, node.item.value, true
+}
 
-	node := sl.list.Front()
-	weight = node.Value.(sortedElement).weight
-	value = node.Value.(sortedElement).value
-	sl.list.Remove(node)
-	return weight, value, true
+func (sl *SortedList) PopMinWeight() (weight int64, value interface{}, exist bool) {
+	return sl.pop(sl.list.head.levels[0].next)
 }
 
 func (sl *SortedList) PopMaxWeight() (weight int64, value interface{}, exist bool) {
-	if sl.list.Len() == 0 {
-		return 0, nil, false
-	}
-
-	node := sl.list.Back()
-	weight = node.Value.(sortedElement).weight
-	value = node.Value.(sortedElement).value
-	sl.list.Remove(node)
-	return weight, value, true
+	return sl.pop(sl.list.tail)
 }
 
 func (sl *SortedList) Println() {
-	for e := sl.list.Front(); e != nil; e = e.Next() {
-		fmt.Printf("<-[weight:%d][val:%v]->", e.Value.(sortedElement).weight, e.Value.(sortedElement).value)
+	for e := sl.list.head.levels[0].next; e != nil; e = e.levels[0].next {
+		fmt.Printf("<-[weight:%d][val:%v]->", e.item.weight, e.item.value)
 	}
 }
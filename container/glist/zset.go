@@ -0,0 +1,254 @@
+package glist
+
+import (
+	"cmp"
+	"sync"
+)
+
+type (
+	ZEntry[M cmp.Ordered] struct {
+		Member M
+		Score  float64
+	}
+
+	// ZSet is a sorted set like Redis zset, members are unique and ordered by score then member,
+	// it fits leaderboards and order books.
+	// Ranks are 0-based, negative indexes of ZRange count from the end like Redis.
+	// It is safe for concurrent use.
+	ZSet[M cmp.Ordered] struct {
+		mu     sync.RWMutex
+		sl     *skipList[ZEntry[M]]
+		scores map[M]float64
+	}
+)
+
+func compareZEntry[M cmp.Ordered](a, b ZEntry[M]) int {
+	if c := cmp.Compare(a.Score, b.Score); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Member, b.Member)
+}
+
+func NewZSet[M cmp.Ordered]() *ZSet[M] {
+	return &ZSet[M]{sl: newSkipList(compareZEntry[M]), scores: make(map[M]float64)}
+}
+
+func (z *ZSet[M]) add(member M, score float64) bool {
+	old, exists := z.scores[member]
+	if exists {
+		if old == score {
+			return false
+		}
+		z.sl.delete(ZEntry[M]{Member: member, Score: old})
+	}
+	z.scores[member] = score
+	z.sl.insert(ZEntry[M]{Member: member, Score: score})
+	return !exists
+}
+
+// ZAdd sets score of member, it returns true if member is new.
+func (z *ZSet[M]) ZAdd(score float64, member M) bool {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.add(member, score)
+}
+
+// ZIncrBy increases score of member by incr and returns the new score, missing member starts from 0.
+func (z *ZSet[M]) ZIncrBy(incr float64, member M) float64 {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	score := z.scores[member] + incr
+	z.add(member, score)
+	return score
+}
+
+// ZRem removes members and returns count of removed ones.
+func (z *ZSet[M]) ZRem(members ...M) int {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	n := 0
+	for _, m := range members {
+		if score, ok := z.scores[m]; ok {
+			delete(z.scores, m)
+			z.sl.delete(ZEntry[M]{Member: m, Score: score})
+			n++
+		}
+	}
+	return n
+}
+
+func (z *ZSet[M]) ZScore(member M) (float64, bool) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	score, ok := z.scores[member]
+	return score, ok
+}
+
+func (z *ZSet[M]) ZCard() int {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return z.sl.size
+}
+
+// ZRank returns the rank of member in ascending order of score.
+func (z *ZSet[M]) ZRank(member M) (int, bool) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	score, ok := z.scores[member]
+	if !ok {
+		return 0, false
+	}
+	i, _ := z.sl.find(ZEntry[M]{Member: member, Score: score})
+	return i, true
+}
+
+// ZRevRank returns the rank of member in descending order of score.
+func (z *ZSet[M]) ZRevRank(member M) (int, bool) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	score, ok := z.scores[member]
+	if !ok {
+		return 0, false
+	}
+	i, _ := z.sl.find(ZEntry[M]{Member: member, Score: score})
+	return z.sl.size - 1 - i, true
+}
+
+// rankRange converts Redis style inclusive range [start, stop] which may be negative to [from, to).
+func rankRange(start, stop, n int) (from, to int) {
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	start, stop = max(start, 0), min(stop, n-1)
+	if start > stop {
+		return 0, 0
+	}
+	return start, stop + 1
+}
+
+// ZRange returns entries of ranks [start, stop] in ascending order of score, ZRange(0, -1) returns all.
+func (z *ZSet[M]) ZRange(start, stop int) []ZEntry[M] {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	from, to := rankRange(start, stop, z.sl.size)
+	if from == to {
+		return nil
+	}
+	return collect(z.sl.at(from), to-from, nil)
+}
+
+// ZRevRange returns entries of ranks [start, stop] in descending order of score, ZRevRange(0, 9) returns top 10.
+func (z *ZSet[M]) ZRevRange(start, stop int) []ZEntry[M] {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	from, to := rankRange(start, stop, z.sl.size)
+	var r []ZEntry[M]
+	if from == to {
+		return r
+	}
+	for x := z.sl.at(z.sl.size - 1 - from); x != nil && len(r) < to-from; x = x.prev {
+		r = append(r, x.item)
+	}
+	return r
+}
+
+// scoreRange returns index and node of the first entry with score >= minScore, and index after the last entry with score <= maxScore.
+func (z *ZSet[M]) scoreRange(minScore, maxScore float64) (int, *slNode[ZEntry[M]], int) {
+	from, n := z.sl.search(func(e ZEntry[M]) bool { return e.Score >= minScore })
+	to, _ := z.sl.search(func(e ZEntry[M]) bool { return e.Score > maxScore })
+	return from, n, to
+}
+
+// ZRangeByScore returns entries with minScore <= score <= maxScore in ascending order of score,
+// at most count entries are returned after skipping offset ones, count < 0 means no limit.
+func (z *ZSet[M]) ZRangeByScore(minScore, maxScore float64, offset, count int) []ZEntry[M] {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	from, n, to := z.scoreRange(minScore, maxScore)
+	if from+offset >= to {
+		return nil
+	}
+	if offset > 0 {
+		n = z.sl.at(from + offset)
+	}
+	if remain := to - from - max(offset, 0); count < 0 || count > remain {
+		count = remain
+	}
+	return collect(n, count, nil)
+}
+
+// ZRevRangeByScore returns entries with minScore <= score <= maxScore in descending order of score,
+// at most count entries are returned after skipping offset ones, count < 0 means no limit.
+func (z *ZSet[M]) ZRevRangeByScore(maxScore, minScore float64, offset, count int) []ZEntry[M] {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	from, _, to := z.scoreRange(minScore, maxScore)
+	var r []ZEntry[M]
+	for x := z.sl.at(to - 1 - offset); x != nil && to-1-offset-len(r) >= from && (count < 0 || len(r) < count); x = x.prev {
+		r = append(r, x.item)
+	}
+	return r
+}
+
+// ZCount returns count of entries with minScore <= score <= maxScore in O(log n).
+func (z *ZSet[M]) ZCount(minScore, maxScore float64) int {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	from, _, to := z.scoreRange(minScore, maxScore)
+	return to - from
+}
+
+func (z *ZSet[M]) removeFrom(x *slNode[ZEntry[M]], n int) int {
+	removed := 0
+	for ; x != nil && removed < n; removed++ {
+		next := x.levels[0].next
+		delete(z.scores, x.item.Member)
+		z.sl.delete(x.item)
+		x = next
+	}
+	return removed
+}
+
+// ZRemRangeByRank removes entries of ranks [start, stop] and returns count of removed ones,
+// ZRemRangeByRank(0, -101) keeps the top 100.
+func (z *ZSet[M]) ZRemRangeByRank(start, stop int) int {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	from, to := rankRange(start, stop, z.sl.size)
+	return z.removeFrom(z.sl.at(from), to-from)
+}
+
+// ZRemRangeByScore removes entries with minScore <= score <= maxScore and returns count of removed ones.
+func (z *ZSet[M]) ZRemRangeByScore(minScore, maxScore float64) int {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	from, n, to := z.scoreRange(minScore, maxScore)
+	return z.removeFrom(n, to-from)
+}
+
+func (z *ZSet[M]) pop(last bool) (ZEntry[M], bool) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	n := z.sl.head.levels[0].next
+	if last {
+		n = z.sl.tail
+	}
+	if n == nil {
+		return ZEntry[M]{}, false
+	}
+	delete(z.scores, n.item.Member)
+	return z.sl.delete(n.item)
+}
+
+// ZPopMin removes and returns the entry with the lowest score.
+func (z *ZSet[M]) ZPopMin() (ZEntry[M], bool) {
+	return z.pop(false)
+}
+
+// ZPopMax removes and returns the entry with the highest score.
+func (z *ZSet[M]) ZPopMax() (ZEntry[M], bool) {
+	return z.pop(true)
+}
@@ -0,0 +1,156 @@
+package gmap
+
+type (
+	// ARC is an adaptive replacement cache, it balances between recency (t1) and frequency (t2)
+	// by tracking keys recently evicted from both (ghost lists b1 and b2).
+	//
+	// Reference
+	// Megiddo, Modha: ARC: A Self-Tuning, Low Overhead Replacement Cache, 2003.
+	ARC[K comparable, V any] struct {
+		t1, t2  *OrderedMap[K, V]        // seen once recently / seen at least twice recently, oldest is LRU
+		b1, b2  *OrderedMap[K, struct{}] // ghost entries evicted from t1 / t2
+		p       int                      // target size of t1
+		cap     int
+		onEvict EvictCallback[K, V]
+	}
+)
+
+func NewARC[K comparable, V any](capacity int, onEvict EvictCallback[K, V]) (*ARC[K, V], error) {
+	if capacity <= 0 {
+		return nil, errInvalidCapacity
+	}
+	return &ARC[K, V]{
+		t1:      NewOrderedMap[K, V](),
+		t2:      NewOrderedMap[K, V](),
+		b1:      NewOrderedMap[K, struct{}](),
+		b2:      NewOrderedMap[K, struct{}](),
+		cap:     capacity,
+		onEvict: onEvict,
+	}, nil
+}
+
+func (c *ARC[K, V]) Get(key K) (V, bool) {
+	if v, ok := c.t1.Get(key); ok {
+		c.t1.Delete(key)
+		c.t2.Set(key, v)
+		return v, true
+	}
+	if v, ok := c.t2.Get(key); ok {
+		c.t2.MoveToBack(key)
+		return v, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (c *ARC[K, V]) Peek(key K) (V, bool) {
+	if v, ok := c.t1.Get(key); ok {
+		return v, true
+	}
+	return c.t2.Get(key)
+}
+
+// replace evicts the LRU entry of t1 or t2 into its ghost list.
+func (c *ARC[K, V]) replace(inB2 bool) {
+	if c.t1.Len() > 0 && (c.t1.Len() > c.p || (inB2 && c.t1.Len() == c.p)) {
+		k, v, _ := c.t1.Oldest()
+		c.t1.Delete(k)
+		c.b1.Set(k, struct{}{})
+		c.evicted(k, v)
+	} else if c.t2.Len() > 0 {
+		k, v, _ := c.t2.Oldest()
+		c.t2.Delete(k)
+		c.b2.Set(k, struct{}{})
+		c.evicted(k, v)
+	}
+}
+
+func (c *ARC[K, V]) evicted(k K, v V) {
+	if c.onEvict != nil {
+		c.onEvict(k, v)
+	}
+}
+
+func (c *ARC[K, V]) Set(key K, value V) bool {
+	if c.t1.Has(key) {
+		c.t1.Delete(key)
+		c.t2.Set(key, value)
+		return false
+	}
+	if c.t2.Has(key) {
+		c.t2.Set(key, value)
+		c.t2.MoveToBack(key)
+		return false
+	}
+
+	before := c.t1.Len() + c.t2.Len()
+	switch {
+	case c.b1.Has(key):
+		// Hit in ghost of recency list, favor recency.
+		c.p = min(c.cap, c.p+max(c.b2.Len()/c.b1.Len(), 1))
+		c.b1.Delete(key)
+		if before >= c.cap {
+			c.replace(false)
+		}
+		c.t2.Set(key, value)
+	case c.b2.Has(key):
+		// Hit in ghost of frequency list, favor frequency.
+		c.p = max(0, c.p-max(c.b1.Len()/c.b2.Len(), 1))
+		c.b2.Delete(key)
+		if before >= c.cap {
+			c.replace(true)
+		}
+		c.t2.Set(key, value)
+	default:
+		if c.t1.Len()+c.b1.Len() >= c.cap {
+			if c.t1.Len() < c.cap {
+				k, _, _ := c.b1.Oldest()
+				c.b1.Delete(k)
+				// cache may be not full after Delete
+				if before >= c.cap {
+					c.replace(false)
+				}
+			} else {
+				k, v, _ := c.t1.Oldest()
+				c.t1.Delete(k)
+				c.evicted(k, v)
+			}
+		} else if total := before + c.b1.Len() + c.b2.Len(); total >= c.cap {
+			if total >= 2*c.cap {
+				k, _, _ := c.b2.Oldest()
+				c.b2.Delete(k)
+			}
+			if before >= c.cap {
+				c.replace(false)
+			}
+		}
+		c.t1.Set(key, value)
+	}
+	return c.t1.Len()+c.t2.Len() <= before
+}
+
+func (c *ARC[K, V]) Delete(key K) bool {
+	c.b1.Delete(key)
+	c.b2.Delete(key)
+	return c.t1.Delete(key) || c.t2.Delete(key)
+}
+
+func (c *ARC[K, V]) Has(key K) bool {
+	return c.t1.Has(key) || c.t2.Has(key)
+}
+
+func (c *ARC[K, V]) Len() int {
+	return c.t1.Len() + c.t2.Len()
+}
+
+func (c *ARC[K, V]) Cap() int {
+	return c.cap
+}
+
+func (c *ARC[K, V]) Clear() {
+	c.t1.Clear()
+	c.t2.Clear()
+	c.b1.Clear()
+	c.b2.Clear()
+	c.p = 0
+}
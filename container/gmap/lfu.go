@@ -0,0 +1,169 @@
+package gmap
+
+type (
+	// LFU evicts the least frequently used entry, ties are broken by evicting the least recently used one.
+	// All operations are O(1).
+	//
+	// Reference
+	// http://dhruvbird.com/lfu.pdf
+	LFU[K comparable, V any] struct {
+		items   map[K]*lfuItem[K, V]
+		head    *lfuBucket[K] // bucket with the lowest frequency
+		cap     int
+		onEvict EvictCallback[K, V]
+	}
+
+	lfuItem[K comparable, V any] struct {
+		value  V
+		bucket *lfuBucket[K]
+	}
+
+	// lfuBucket holds keys with the same access frequency, oldest key is the least recently used.
+	lfuBucket[K comparable] struct {
+		freq       uint64
+		keys       *OrderedMap[K, struct{}]
+		prev, next *lfuBucket[K]
+	}
+)
+
+func NewLFU[K comparable, V any](capacity int, onEvict EvictCallback[K, V]) (*LFU[K, V], error) {
+	if capacity <= 0 {
+		return nil, errInvalidCapacity
+	}
+	return &LFU[K, V]{items: make(map[K]*lfuItem[K, V]), cap: capacity, onEvict: onEvict}, nil
+}
+
+func (c *LFU[K, V]) removeBucket(b *lfuBucket[K]) {
+	if b.prev != nil {
+		b.prev.next = b.next
+	} else {
+		c.head = b.next
+	}
+	if b.next != nil {
+		b.next.prev = b.prev
+	}
+}
+
+// bucketAfter returns bucket with frequency freq which follows prev, prev == nil means head.
+func (c *LFU[K, V]) bucketAfter(prev *lfuBucket[K], freq uint64) *lfuBucket[K] {
+	next := c.head
+	if prev != nil {
+		next = prev.next
+	}
+	if next != nil && next.freq == freq {
+		return next
+	}
+	b := &lfuBucket[K]{freq: freq, keys: NewOrderedMap[K, struct{}](), prev: prev, next: next}
+	if prev != nil {
+		prev.next = b
+	} else {
+		c.head = b
+	}
+	if next != nil {
+		next.prev = b
+	}
+	return b
+}
+
+func (c *LFU[K, V]) touch(key K, it *lfuItem[K, V]) {
+	old := it.bucket
+	nb := c.bucketAfter(old, old.freq+1)
+	nb.keys.Set(key, struct{}{})
+	old.keys.Delete(key)
+	if old.keys.Len() == 0 {
+		c.removeBucket(old)
+	}
+	it.bucket = nb
+}
+
+func (c *LFU[K, V]) Get(key K) (V, bool) {
+	it, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.touch(key, it)
+	return it.value, true
+}
+
+func (c *LFU[K, V]) Peek(key K) (V, bool) {
+	it, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return it.value, true
+}
+
+// Set inserts or updates key, updating counts as an access.
+func (c *LFU[K, V]) Set(key K, value V) bool {
+	if it, ok := c.items[key]; ok {
+		it.value = value
+		c.touch(key, it)
+		return false
+	}
+	evicted := false
+	if len(c.items) >= c.cap {
+		c.RemoveLeast()
+		evicted = true
+	}
+	b := c.bucketAfter(nil, 1)
+	b.keys.Set(key, struct{}{})
+	c.items[key] = &lfuItem[K, V]{value: value, bucket: b}
+	return evicted
+}
+
+// RemoveLeast evicts the least frequently used entry.
+func (c *LFU[K, V]) RemoveLeast() (K, V, bool) {
+	if c.head == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	k, _, _ := c.head.keys.Oldest()
+	it := c.items[k]
+	c.Delete(k)
+	if c.onEvict != nil {
+		c.onEvict(k, it.value)
+	}
+	return k, it.value, true
+}
+
+// Frequency returns access count of key, insertion counts as the first access.
+func (c *LFU[K, V]) Frequency(key K) uint64 {
+	if it, ok := c.items[key]; ok {
+		return it.bucket.freq
+	}
+	return 0
+}
+
+func (c *LFU[K, V]) Delete(key K) bool {
+	it, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	it.bucket.keys.Delete(key)
+	if it.bucket.keys.Len() == 0 {
+		c.removeBucket(it.bucket)
+	}
+	delete(c.items, key)
+	return true
+}
+
+func (c *LFU[K, V]) Has(key K) bool {
+	_, ok := c.items[key]
+	return ok
+}
+
+func (c *LFU[K, V]) Len() int {
+	return len(c.items)
+}
+
+func (c *LFU[K, V]) Cap() int {
+	return c.cap
+}
+
+func (c *LFU[K, V]) Clear() {
+	clear(c.items)
+	c.head = nil
+}
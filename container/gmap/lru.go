@@ -0,0 +1,116 @@
+package gmap
+
+import "github.com/davidforest123/goutil/basic/gerrors"
+
+type (
+	// EvictCallback is called with every entry evicted by a bounded map because it is full,
+	// entries removed by Delete are not reported.
+	EvictCallback[K comparable, V any] func(key K, value V)
+
+	// BoundedMap is the common interface of LRU, LFU and ARC, none of them is safe for concurrent use.
+	BoundedMap[K comparable, V any] interface {
+		// Get returns value of key and records the access.
+		Get(key K) (V, bool)
+		// Peek returns value of key without recording the access.
+		Peek(key K) (V, bool)
+		// Set inserts or updates key, it returns true if another entry is evicted.
+		Set(key K, value V) (evicted bool)
+		Delete(key K) bool
+		Has(key K) bool
+		Len() int
+		Cap() int
+		Clear()
+	}
+
+	// LRU evicts the least recently used entry.
+	LRU[K comparable, V any] struct {
+		om      *OrderedMap[K, V] // oldest is the least recently used
+		cap     int
+		onEvict EvictCallback[K, V]
+	}
+)
+
+var errInvalidCapacity = gerrors.New("capacity must be positive")
+
+func NewLRU[K comparable, V any](capacity int, onEvict EvictCallback[K, V]) (*LRU[K, V], error) {
+	if capacity <= 0 {
+		return nil, errInvalidCapacity
+	}
+	return &LRU[K, V]{om: NewOrderedMap[K, V](), cap: capacity, onEvict: onEvict}, nil
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	v, ok := c.om.Get(key)
+	if ok {
+		c.om.MoveToBack(key)
+	}
+	return v, ok
+}
+
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	return c.om.Get(key)
+}
+
+func (c *LRU[K, V]) Set(key K, value V) bool {
+	if !c.om.Set(key, value) {
+		c.om.MoveToBack(key)
+		return false
+	}
+	evicted := false
+	for c.om.Len() > c.cap {
+		c.RemoveOldest()
+		evicted = true
+	}
+	return evicted
+}
+
+// RemoveOldest evicts the least recently used entry.
+func (c *LRU[K, V]) RemoveOldest() (K, V, bool) {
+	k, v, ok := c.om.Oldest()
+	if ok {
+		c.om.Delete(k)
+		if c.onEvict != nil {
+			c.onEvict(k, v)
+		}
+	}
+	return k, v, ok
+}
+
+func (c *LRU[K, V]) Delete(key K) bool {
+	return c.om.Delete(key)
+}
+
+func (c *LRU[K, V]) Has(key K) bool {
+	return c.om.Has(key)
+}
+
+func (c *LRU[K, V]) Len() int {
+	return c.om.Len()
+}
+
+func (c *LRU[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes capacity, it returns count of evicted entries.
+func (c *LRU[K, V]) Resize(capacity int) (int, error) {
+	if capacity <= 0 {
+		return 0, errInvalidCapacity
+	}
+	c.cap = capacity
+	n := 0
+	for c.om.Len() > c.cap {
+		c.RemoveOldest()
+		n++
+	}
+	return n, nil
+}
+
+// Keys returns keys from least to most recently used.
+func (c *LRU[K, V]) Keys() []K {
+	return c.om.Keys()
+}
+
+func (c *LRU[K, V]) Clear() {
+	c.om.Clear()
+}
@@ -0,0 +1,168 @@
+package gmap
+
+import "iter"
+
+type (
+	// OrderedMap is a map which keeps insertion order, Set, Get and Delete are all O(1).
+	// It is not safe for concurrent use.
+	OrderedMap[K comparable, V any] struct {
+		data map[K]*omEntry[K, V]
+		root omEntry[K, V] // sentinel of circular doubly linked list, root.next is the oldest
+	}
+
+	omEntry[K comparable, V any] struct {
+		prev, next *omEntry[K, V]
+		key        K
+		value      V
+	}
+)
+
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	om := &OrderedMap[K, V]{data: make(map[K]*omEntry[K, V])}
+	om.root.prev = &om.root
+	om.root.next = &om.root
+	return om
+}
+
+func (om *OrderedMap[K, V]) insertBefore(e, at *omEntry[K, V]) {
+	e.prev = at.prev
+	e.next = at
+	at.prev.next = e
+	at.prev = e
+}
+
+func (om *OrderedMap[K, V]) unlink(e *omEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+}
+
+// Set inserts or updates key, an updated key keeps its original position.
+// It returns true if key is newly inserted.
+func (om *OrderedMap[K, V]) Set(key K, value V) bool {
+	if e, ok := om.data[key]; ok {
+		e.value = value
+		return false
+	}
+	e := &omEntry[K, V]{key: key, value: value}
+	om.data[key] = e
+	om.insertBefore(e, &om.root)
+	return true
+}
+
+func (om *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := om.data[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (om *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := om.data[key]
+	return ok
+}
+
+// Delete removes key, it returns false if key doesn't exist.
+func (om *OrderedMap[K, V]) Delete(key K) bool {
+	e, ok := om.data[key]
+	if !ok {
+		return false
+	}
+	om.unlink(e)
+	delete(om.data, key)
+	return true
+}
+
+func (om *OrderedMap[K, V]) Len() int {
+	return len(om.data)
+}
+
+// Oldest returns the first inserted entry.
+func (om *OrderedMap[K, V]) Oldest() (K, V, bool) {
+	return om.entryOrZero(om.root.next)
+}
+
+// Newest returns the last inserted entry.
+func (om *OrderedMap[K, V]) Newest() (K, V, bool) {
+	return om.entryOrZero(om.root.prev)
+}
+
+func (om *OrderedMap[K, V]) entryOrZero(e *omEntry[K, V]) (K, V, bool) {
+	if e == &om.root {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return e.key, e.value, true
+}
+
+// MoveToBack makes key the newest entry.
+func (om *OrderedMap[K, V]) MoveToBack(key K) bool {
+	e, ok := om.data[key]
+	if !ok {
+		return false
+	}
+	om.unlink(e)
+	om.insertBefore(e, &om.root)
+	return true
+}
+
+// MoveToFront makes key the oldest entry.
+func (om *OrderedMap[K, V]) MoveToFront(key K) bool {
+	e, ok := om.data[key]
+	if !ok {
+		return false
+	}
+	om.unlink(e)
+	om.insertBefore(e, om.root.next)
+	return true
+}
+
+// All iterates entries from oldest to newest, deleting the current key during iteration is allowed.
+func (om *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := om.root.next; e != &om.root; {
+			next := e.next
+			if !yield(e.key, e.value) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Backward iterates entries from newest to oldest.
+func (om *OrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := om.root.prev; e != &om.root; {
+			prev := e.prev
+			if !yield(e.key, e.value) {
+				return
+			}
+			e = prev
+		}
+	}
+}
+
+func (om *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(om.data))
+	for e := om.root.next; e != &om.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func (om *OrderedMap[K, V]) Values() []V {
+	values := make([]V, 0, len(om.data))
+	for e := om.root.next; e != &om.root; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+func (om *OrderedMap[K, V]) Clear() {
+	clear(om.data)
+	om.root.prev = &om.root
+	om.root.next = &om.root
+}
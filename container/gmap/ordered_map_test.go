@@ -0,0 +1,181 @@
+package gmap
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestOrderedMap(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	for i, k := range []string{"c", "a", "b", "d"} {
+		gtest.AssertTrue(t, om.Set(k, i), "%s should be newly inserted", k)
+	}
+	gtest.AssertTrue(t, !om.Set("a", 10), "a should be updated")
+	gtest.AssertTrue(t, om.Delete("b"), "b should be deleted")
+	om.MoveToBack("c")
+	gtest.AssertTrue(t, slices.Equal(om.Keys(), []string{"a", "d", "c"}), "keys %v", om.Keys())
+	v, _ := om.Get("a")
+	gtest.AssertTrue(t, v == 10, "a should be 10 but got %d", v)
+
+	var got []string
+	for k := range om.All() {
+		got = append(got, k)
+		om.Delete(k)
+	}
+	gtest.AssertTrue(t, slices.Equal(got, []string{"a", "d", "c"}) && om.Len() == 0, "iterate with delete %v", got)
+}
+
+func TestTreeMap(t *testing.T) {
+	tm := NewTreeMap[int, string]()
+	ref := map[int]string{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			gtest.AssertTrue(t, tm.Delete(k) == (ref[k] != ""), "delete %d mismatch", k)
+			delete(ref, k)
+		} else {
+			tm.Set(k, strconv.Itoa(k))
+			ref[k] = strconv.Itoa(k)
+		}
+	}
+	var keys []int
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	gtest.AssertTrue(t, tm.Len() == len(keys), "len should be %d but got %d", len(keys), tm.Len())
+	gtest.AssertTrue(t, slices.Equal(tm.Keys(), keys), "keys are not sorted")
+	gtest.AssertTrue(t, height(tm.root) <= 15, "tree is not balanced, height %d", height(tm.root))
+
+	var back []int
+	for k := range tm.Backward() {
+		back = append(back, k)
+	}
+	slices.Reverse(back)
+	gtest.AssertTrue(t, slices.Equal(back, keys), "backward iteration mismatch")
+
+	var inRange []int
+	for k := range tm.Range(100, 200) {
+		inRange = append(inRange, k)
+	}
+	var expect []int
+	for _, k := range keys {
+		if k >= 100 && k < 200 {
+			expect = append(expect, k)
+		}
+	}
+	gtest.AssertTrue(t, slices.Equal(inRange, expect), "range mismatch %v", inRange)
+
+	tm2 := NewTreeMap[int, int]()
+	for _, k := range []int{10, 20, 30} {
+		tm2.Set(k, k)
+	}
+	k, _, _ := tm2.Floor(25)
+	gtest.AssertTrue(t, k == 20, "floor of 25 should be 20 but got %d", k)
+	k, _, _ = tm2.Ceiling(25)
+	gtest.AssertTrue(t, k == 30, "ceiling of 25 should be 30 but got %d", k)
+	_, _, ok := tm2.Ceiling(31)
+	gtest.AssertTrue(t, !ok, "ceiling of 31 should not exist")
+}
+
+func TestShardedMap(t *testing.T) {
+	sm := NewShardedMap[string, int](16, nil)
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				sm.Update("counter", func(old int, _ bool) (int, bool) { return old + 1, true })
+				sm.Set(strconv.Itoa(g*1000+i), i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	v, _ := sm.Get("counter")
+	gtest.AssertTrue(t, v == 8000, "counter should be 8000 but got %d", v)
+	gtest.AssertTrue(t, sm.Len() == 8001, "len should be 8001 but got %d", sm.Len())
+}
+
+func TestShardedMap_DefaultHash(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	fm := NewShardedMap[float64, int](64, nil)
+	fm.Set(negZero, 1)
+	v, ok := fm.Get(0)
+	gtest.AssertTrue(t, ok && v == 1, "+0 and -0 should be the same key")
+
+	type point struct {
+		X, Y float64
+		name string
+	}
+	pm := NewShardedMap[point, int](64, nil)
+	pm.Set(point{X: negZero, Y: 1, name: "a"}, 2)
+	v, ok = pm.Get(point{X: 0, Y: 1, name: "a"})
+	gtest.AssertTrue(t, ok && v == 2, "struct keys with +0 and -0 should be the same key")
+	allocs := testing.AllocsPerRun(100, func() { pm.Get(point{X: 1, Y: 2, name: "b"}) })
+	gtest.AssertTrue(t, allocs == 0, "struct key lookup allocates %v times", allocs)
+}
+
+func TestBoundedMaps(t *testing.T) {
+	var evicted []string
+	onEvict := func(k string, v int) { evicted = append(evicted, k) }
+
+	lru, err := NewLRU[string, int](2, onEvict)
+	gtest.Assert(t, err)
+	lru.Set("a", 1)
+	lru.Set("b", 2)
+	lru.Get("a")
+	gtest.AssertTrue(t, lru.Set("c", 3), "set c should evict")
+	gtest.AssertTrue(t, slices.Equal(evicted, []string{"b"}), "lru should evict b but evicted %v", evicted)
+
+	evicted = nil
+	lfu, err := NewLFU[string, int](2, onEvict)
+	gtest.Assert(t, err)
+	lfu.Set("a", 1)
+	lfu.Set("b", 2)
+	lfu.Get("a")
+	lfu.Get("a")
+	lfu.Get("b")
+	lfu.Set("c", 3)
+	gtest.AssertTrue(t, slices.Equal(evicted, []string{"b"}), "lfu should evict b but evicted %v", evicted)
+	gtest.AssertTrue(t, lfu.Frequency("a") == 3, "frequency of a should be 3 but got %d", lfu.Frequency("a"))
+	lfu.Set("d", 4)
+	gtest.AssertTrue(t, slices.Equal(evicted, []string{"b", "c"}), "lfu should evict c but evicted %v", evicted)
+
+	// arc which is not full after Delete doesn't evict
+	evicted = nil
+	small, err := NewARC[string, int](2, onEvict)
+	gtest.Assert(t, err)
+	small.Set("a", 1)
+	small.Set("b", 2)
+	small.Get("a")
+	small.Set("c", 3)
+	small.Delete("a")
+	gtest.AssertTrue(t, !small.Set("d", 4), "set d should not evict, evicted %v", evicted)
+	gtest.AssertTrue(t, small.Len() == 2 && small.Has("c") && small.Has("d"), "arc len should be 2 but got %d", small.Len())
+
+	var maps []BoundedMap[string, int]
+	arc, err := NewARC[string, int](100, nil)
+	gtest.Assert(t, err)
+	maps = append(maps, lru, lfu, arc)
+	for _, m := range maps {
+		m.Clear()
+		r := rand.New(rand.NewSource(2))
+		for i := 0; i < 10000; i++ {
+			k := strconv.Itoa(r.Intn(300))
+			if _, ok := m.Get(k); !ok {
+				m.Set(k, i)
+			}
+			gtest.AssertTrue(t, m.Len() <= m.Cap(), "%T len %d over cap %d", m, m.Len(), m.Cap())
+		}
+	}
+	gtest.AssertTrue(t, arc.Len() == 100, "arc should be full but len is %d", arc.Len())
+	gtest.AssertTrue(t, arc.b1.Len()+arc.b2.Len() <= 100, "arc ghost lists over capacity")
+}
@@ -0,0 +1,228 @@
+package gmap
+
+import (
+	"hash/maphash"
+	"iter"
+	"math"
+	"reflect"
+	"sync"
+)
+
+type (
+	// ShardedMap is a concurrent map which splits keys into shards with their own locks,
+	// so goroutines working on different keys rarely contend.
+	ShardedMap[K comparable, V any] struct {
+		shards []*mapShard[K, V]
+		mask   uint64
+		hash   func(key K) uint64
+	}
+
+	mapShard[K comparable, V any] struct {
+		mu   sync.RWMutex
+		data map[K]V
+	}
+)
+
+var shardSeed = maphash.MakeSeed()
+
+// NewShardedMap creates a sharded map, shards is rounded up to power of 2.
+// If hash is nil, a default hash is used which is fast for strings, integers and floats,
+// other key types are hashed field by field with reflection, equal keys like +0 and -0 always share a shard.
+func NewShardedMap[K comparable, V any](shards int, hash func(key K) uint64) *ShardedMap[K, V] {
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	if hash == nil {
+		hash = defaultHash[K]
+	}
+	sm := &ShardedMap[K, V]{shards: make([]*mapShard[K, V], n), mask: uint64(n - 1), hash: hash}
+	for i := range sm.shards {
+		sm.shards[i] = &mapShard[K, V]{data: make(map[K]V)}
+	}
+	return sm
+}
+
+func defaultHash[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(shardSeed, k)
+	case int:
+		return mix64(uint64(k))
+	case int64:
+		return mix64(uint64(k))
+	case int32:
+		return mix64(uint64(k))
+	case uint:
+		return mix64(uint64(k))
+	case uint64:
+		return mix64(k)
+	case uint32:
+		return mix64(uint64(k))
+	case float64:
+		return mix64(floatBits(k))
+	case float32:
+		return mix64(floatBits(float64(k)))
+	default:
+		return hashValue(0, reflect.ValueOf(key))
+	}
+}
+
+// floatBits returns bits of f with -0 normalized to +0, because they are the same map key.
+// NaN is never equal to itself, so any bits will do.
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+// hashValue combines h with hash of v, values equal by == have the same hash.
+func hashValue(h uint64, v reflect.Value) uint64 {
+	add := func(x uint64) {
+		h = mix64(h*0x9e3779b97f4a7c15 ^ x)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		add(maphash.String(shardSeed, v.String()))
+	case reflect.Bool:
+		if v.Bool() {
+			add(1)
+		} else {
+			add(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		add(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		add(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		add(floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		add(floatBits(real(v.Complex())))
+		add(floatBits(imag(v.Complex())))
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		add(uint64(uintptr(v.UnsafePointer())))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			h = hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			h = hashValue(h, v.Field(i))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			h = hashValue(h, v.Elem())
+		}
+	}
+	return h
+}
+
+// mix64 is the finalizer of splitmix64, it spreads integer keys over all shards.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (sm *ShardedMap[K, V]) shard(key K) *mapShard[K, V] {
+	return sm.shards[sm.hash(key)&sm.mask]
+}
+
+func (sm *ShardedMap[K, V]) Set(key K, value V) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	s.data[key] = value
+	s.mu.Unlock()
+}
+
+func (sm *ShardedMap[K, V]) Get(key K) (V, bool) {
+	s := sm.shard(key)
+	s.mu.RLock()
+	v, ok := s.data[key]
+	s.mu.RUnlock()
+	return v, ok
+}
+
+func (sm *ShardedMap[K, V]) Has(key K) bool {
+	_, ok := sm.Get(key)
+	return ok
+}
+
+func (sm *ShardedMap[K, V]) Delete(key K) bool {
+	s := sm.shard(key)
+	s.mu.Lock()
+	_, ok := s.data[key]
+	delete(s.data, key)
+	s.mu.Unlock()
+	return ok
+}
+
+// GetOrSet returns existing value of key, or sets and returns value if key doesn't exist.
+func (sm *ShardedMap[K, V]) GetOrSet(key K, value V) (actual V, loaded bool) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.data[key]; ok {
+		return v, true
+	}
+	s.data[key] = value
+	return value, false
+}
+
+// Update calls fn with current value of key under shard lock and stores the result,
+// if fn returns keep == false the key is deleted.
+func (sm *ShardedMap[K, V]) Update(key K, fn func(old V, exists bool) (new V, keep bool)) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.data[key]
+	if v, keep := fn(old, ok); keep {
+		s.data[key] = v
+	} else {
+		delete(s.data, key)
+	}
+}
+
+// Len returns count of entries, it is O(shards) rather than O(n) like SyncMapLen.
+func (sm *ShardedMap[K, V]) Len() int {
+	n := 0
+	for _, s := range sm.shards {
+		s.mu.RLock()
+		n += len(s.data)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// All iterates entries shard by shard, every shard is read locked while iterated,
+// so yield must not modify the map.
+func (sm *ShardedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, s := range sm.shards {
+			stop := false
+			s.mu.RLock()
+			for k, v := range s.data {
+				if !yield(k, v) {
+					stop = true
+					break
+				}
+			}
+			s.mu.RUnlock()
+			if stop {
+				return
+			}
+		}
+	}
+}
+
+func (sm *ShardedMap[K, V]) Clear() {
+	for _, s := range sm.shards {
+		s.mu.Lock()
+		clear(s.data)
+		s.mu.Unlock()
+	}
+}
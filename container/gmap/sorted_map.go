@@ -26,7 +26,7 @@ func (sm *SortedMap) Delete(key string) {
 	if !exist {
 		return
 	}
-	gstring.RemoveByValue(sm.queueKeys, key)
+	sm.queueKeys = gstring.RemoveByValue(sm.queueKeys, key)
 	delete(sm.data, key)
 }
 
@@ -0,0 +1,314 @@
+package gmap
+
+import (
+	"cmp"
+	"iter"
+)
+
+type (
+	// TreeMap is a map sorted by key, implemented as AVL tree, Set, Get and Delete are O(log n).
+	// It is not safe for concurrent use.
+	TreeMap[K any, V any] struct {
+		root *tmNode[K, V]
+		size int
+		cmp  func(a, b K) int
+	}
+
+	tmNode[K any, V any] struct {
+		left, right *tmNode[K, V]
+		height      int
+		key         K
+		value       V
+	}
+)
+
+func NewTreeMap[K cmp.Ordered, V any]() *TreeMap[K, V] {
+	return NewTreeMapFunc[K, V](cmp.Compare[K])
+}
+
+// NewTreeMapFunc creates a TreeMap with custom key comparator which returns -1, 0 or +1 like cmp.Compare.
+func NewTreeMapFunc[K any, V any](compare func(a, b K) int) *TreeMap[K, V] {
+	return &TreeMap[K, V]{cmp: compare}
+}
+
+func height[K any, V any](n *tmNode[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *tmNode[K, V]) fix() {
+	n.height = max(height(n.left), height(n.right)) + 1
+}
+
+func rotateRight[K any, V any](n *tmNode[K, V]) *tmNode[K, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.fix()
+	l.fix()
+	return l
+}
+
+func rotateLeft[K any, V any](n *tmNode[K, V]) *tmNode[K, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.fix()
+	r.fix()
+	return r
+}
+
+func balance[K any, V any](n *tmNode[K, V]) *tmNode[K, V] {
+	n.fix()
+	switch bf := height(n.left) - height(n.right); {
+	case bf > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case bf < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// Set inserts or updates key, it returns true if key is newly inserted.
+func (tm *TreeMap[K, V]) Set(key K, value V) bool {
+	inserted := false
+	var insert func(n *tmNode[K, V]) *tmNode[K, V]
+	insert = func(n *tmNode[K, V]) *tmNode[K, V] {
+		if n == nil {
+			inserted = true
+			return &tmNode[K, V]{key: key, value: value, height: 1}
+		}
+		switch c := tm.cmp(key, n.key); {
+		case c < 0:
+			n.left = insert(n.left)
+		case c > 0:
+			n.right = insert(n.right)
+		default:
+			n.value = value
+			return n
+		}
+		return balance(n)
+	}
+	tm.root = insert(tm.root)
+	if inserted {
+		tm.size++
+	}
+	return inserted
+}
+
+func (tm *TreeMap[K, V]) find(key K) *tmNode[K, V] {
+	n := tm.root
+	for n != nil {
+		switch c := tm.cmp(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+func (tm *TreeMap[K, V]) Get(key K) (V, bool) {
+	if n := tm.find(key); n != nil {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (tm *TreeMap[K, V]) Has(key K) bool {
+	return tm.find(key) != nil
+}
+
+// Delete removes key, it returns false if key doesn't exist.
+func (tm *TreeMap[K, V]) Delete(key K) bool {
+	deleted := false
+	var remove func(n *tmNode[K, V], key K) *tmNode[K, V]
+	remove = func(n *tmNode[K, V], key K) *tmNode[K, V] {
+		if n == nil {
+			return nil
+		}
+		switch c := tm.cmp(key, n.key); {
+		case c < 0:
+			n.left = remove(n.left, key)
+		case c > 0:
+			n.right = remove(n.right, key)
+		default:
+			deleted = true
+			if n.left == nil {
+				return n.right
+			}
+			if n.right == nil {
+				return n.left
+			}
+			// Replace with the minimum of right subtree.
+			succ := n.right
+			for succ.left != nil {
+				succ = succ.left
+			}
+			n.key, n.value = succ.key, succ.value
+			n.right = remove(n.right, succ.key)
+		}
+		return balance(n)
+	}
+	tm.root = remove(tm.root, key)
+	if deleted {
+		tm.size--
+	}
+	return deleted
+}
+
+func (tm *TreeMap[K, V]) Len() int {
+	return tm.size
+}
+
+func (tm *TreeMap[K, V]) Clear() {
+	tm.root = nil
+	tm.size = 0
+}
+
+func nodeOrZero[K any, V any](n *tmNode[K, V]) (K, V, bool) {
+	if n == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return n.key, n.value, true
+}
+
+// Min returns the entry with the smallest key.
+func (tm *TreeMap[K, V]) Min() (K, V, bool) {
+	n := tm.root
+	for n != nil && n.left != nil {
+		n = n.left
+	}
+	return nodeOrZero(n)
+}
+
+// Max returns the entry with the largest key.
+func (tm *TreeMap[K, V]) Max() (K, V, bool) {
+	n := tm.root
+	for n != nil && n.right != nil {
+		n = n.right
+	}
+	return nodeOrZero(n)
+}
+
+// Floor returns the entry with the largest key <= key.
+func (tm *TreeMap[K, V]) Floor(key K) (K, V, bool) {
+	var best *tmNode[K, V]
+	for n := tm.root; n != nil; {
+		c := tm.cmp(key, n.key)
+		if c == 0 {
+			return n.key, n.value, true
+		}
+		if c > 0 {
+			best = n
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return nodeOrZero(best)
+}
+
+// Ceiling returns the entry with the smallest key >= key.
+func (tm *TreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	var best *tmNode[K, V]
+	for n := tm.root; n != nil; {
+		c := tm.cmp(key, n.key)
+		if c == 0 {
+			return n.key, n.value, true
+		}
+		if c < 0 {
+			best = n
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return nodeOrZero(best)
+}
+
+// ascend iterates keys >= from (or all keys if from is nil) in ascending order until yield returns false.
+// The tree must not be modified during iteration.
+func (tm *TreeMap[K, V]) ascend(from *K, yield func(n *tmNode[K, V]) bool) {
+	var stack []*tmNode[K, V]
+	for n := tm.root; n != nil; {
+		if from != nil && tm.cmp(n.key, *from) < 0 {
+			n = n.right
+			continue
+		}
+		stack = append(stack, n)
+		n = n.left
+	}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !yield(n) {
+			return
+		}
+		for c := n.right; c != nil; c = c.left {
+			stack = append(stack, c)
+		}
+	}
+}
+
+// All iterates entries in ascending key order, the map must not be modified during iteration.
+func (tm *TreeMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		tm.ascend(nil, func(n *tmNode[K, V]) bool { return yield(n.key, n.value) })
+	}
+}
+
+// Backward iterates entries in descending key order.
+func (tm *TreeMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		var stack []*tmNode[K, V]
+		for n := tm.root; n != nil; n = n.right {
+			stack = append(stack, n)
+		}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(n.key, n.value) {
+				return
+			}
+			for c := n.left; c != nil; c = c.right {
+				stack = append(stack, c)
+			}
+		}
+	}
+}
+
+// Range iterates entries with from <= key < to in ascending order.
+func (tm *TreeMap[K, V]) Range(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		tm.ascend(&from, func(n *tmNode[K, V]) bool {
+			if tm.cmp(n.key, to) >= 0 {
+				return false
+			}
+			return yield(n.key, n.value)
+		})
+	}
+}
+
+func (tm *TreeMap[K, V]) Keys() []K {
+	keys := make([]K, 0, tm.size)
+	tm.ascend(nil, func(n *tmNode[K, V]) bool {
+		keys = append(keys, n.key)
+		return true
+	})
+	return keys
+}
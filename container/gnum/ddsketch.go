@@ -0,0 +1,196 @@
+package gnum
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+	"sort"
+)
+
+// DDSketch is a quantile sketch with relative accuracy guarantee, every quantile it returns is within
+// relativeAccuracy of the exact value, e.g. 0.01 means 1%. It keeps counts of logarithmic buckets
+// instead of samples. It is not safe for concurrent use, give every goroutine its own sketch and Merge them.
+type DDSketch struct {
+	relativeAccuracy float64
+	gamma            float64
+	logGamma         float64
+	maxBins          int
+	positive         map[int]uint64 // bucket index -> count
+	negative         map[int]uint64 // buckets of -v
+	zero             uint64
+	count            uint64
+	min              float64
+	max              float64
+	sum              float64
+}
+
+// NewDDSketch creates a sketch, relativeAccuracy is in (0, 1). If maxBins > 0, the lowest buckets are
+// collapsed when positive or negative buckets exceed it, so accuracy of the lowest quantiles is given up
+// to bound memory, 2048 bins cover 1ns to 1 day with 1% accuracy.
+func NewDDSketch(relativeAccuracy float64, maxBins int) (*DDSketch, error) {
+	if relativeAccuracy <= 0 || relativeAccuracy >= 1 {
+		return nil, gerrors.New("invalid relative accuracy %f", relativeAccuracy)
+	}
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &DDSketch{
+		relativeAccuracy: relativeAccuracy,
+		gamma:            gamma,
+		logGamma:         math.Log(gamma),
+		maxBins:          maxBins,
+		positive:         make(map[int]uint64),
+		negative:         make(map[int]uint64),
+	}, nil
+}
+
+func (s *DDSketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / s.logGamma))
+}
+
+// value returns the representative value of bucket i, which is within relativeAccuracy of every value in it.
+func (s *DDSketch) value(i int) float64 {
+	return 2 * math.Pow(s.gamma, float64(i)) / (s.gamma + 1)
+}
+
+// Add adds one sample, NaN is ignored.
+func (s *DDSketch) Add(v float64) {
+	s.AddN(v, 1)
+}
+
+// AddN adds n samples of value v.
+func (s *DDSketch) AddN(v float64, n uint64) {
+	if math.IsNaN(v) || n == 0 {
+		return
+	}
+	const minIndexable = 1e-300
+	switch {
+	case v > minIndexable:
+		s.positive[s.index(v)] += n
+		s.collapse(s.positive)
+	case v < -minIndexable:
+		s.negative[s.index(-v)] += n
+		s.collapse(s.negative)
+	default:
+		s.zero += n
+	}
+	if s.count == 0 {
+		s.min, s.max = v, v
+	} else {
+		s.min = math.Min(s.min, v)
+		s.max = math.Max(s.max, v)
+	}
+	s.count += n
+	s.sum += v * float64(n)
+}
+
+// collapse merges the lowest buckets of store until it has maxBins buckets.
+func (s *DDSketch) collapse(store map[int]uint64) {
+	if s.maxBins <= 0 || len(store) <= s.maxBins {
+		return
+	}
+	keys := sortedKeys(store)
+	extra := len(keys) - s.maxBins
+	target := keys[extra]
+	for _, k := range keys[:extra] {
+		store[target] += store[k]
+		delete(store, k)
+	}
+}
+
+func sortedKeys(store map[int]uint64) []int {
+	keys := make([]int, 0, len(store))
+	for k := range store {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// Merge adds all samples of other into s, both must have the same relative accuracy.
+func (s *DDSketch) Merge(other *DDSketch) error {
+	if s.gamma != other.gamma {
+		return gerrors.New("can't merge DDSketch of relative accuracy %f into %f", other.relativeAccuracy, s.relativeAccuracy)
+	}
+	if other.count == 0 {
+		return nil
+	}
+	for k, c := range other.positive {
+		s.positive[k] += c
+	}
+	for k, c := range other.negative {
+		s.negative[k] += c
+	}
+	s.collapse(s.positive)
+	s.collapse(s.negative)
+	if s.count == 0 {
+		s.min, s.max = other.min, other.max
+	} else {
+		s.min = math.Min(s.min, other.min)
+		s.max = math.Max(s.max, other.max)
+	}
+	s.zero += other.zero
+	s.count += other.count
+	s.sum += other.sum
+	return nil
+}
+
+// Quantile returns estimated q quantile, q is in [0, 1].
+func (s *DDSketch) Quantile(q float64) float64 {
+	if s.count == 0 || q < 0 || q > 1 {
+		return math.NaN()
+	}
+	if q == 0 {
+		return s.min
+	}
+	if q == 1 {
+		return s.max
+	}
+	rank := uint64(q * float64(s.count-1))
+	var seen uint64
+	// negative buckets from the most negative value
+	neg := sortedKeys(s.negative)
+	for i := len(neg) - 1; i >= 0; i-- {
+		seen += s.negative[neg[i]]
+		if seen > rank {
+			return s.clamp(-s.value(neg[i]))
+		}
+	}
+	seen += s.zero
+	if seen > rank {
+		return 0
+	}
+	for _, k := range sortedKeys(s.positive) {
+		seen += s.positive[k]
+		if seen > rank {
+			return s.clamp(s.value(k))
+		}
+	}
+	return s.max
+}
+
+// clamp keeps estimation in [min, max], which makes extreme quantiles exact.
+func (s *DDSketch) clamp(v float64) float64 {
+	return math.Max(s.min, math.Min(s.max, v))
+}
+
+func (s *DDSketch) Count() uint64 { return s.count }
+func (s *DDSketch) Sum() float64  { return s.sum }
+
+func (s *DDSketch) Mean() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.sum / float64(s.count)
+}
+
+func (s *DDSketch) Min() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.min
+}
+
+func (s *DDSketch) Max() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.max
+}
@@ -1,6 +1,7 @@
 package gnum
 
 import (
+	"database/sql/driver"
 	"github.com/davidforest123/goutil/basic/gerrors"
 	"github.com/davidforest123/goutil/container/gany"
 	"github.com/davidforest123/goutil/container/gstring"
@@ -13,6 +14,7 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"unsafe"
 )
 
 /*
@@ -90,11 +92,11 @@ func NewDecimalFromDecimal(d2 decimal.Decimal) Decimal {
 }
 
 func NewDecimalFromFloat32(val float32) Decimal {
-	return Decimal(decimal.NewFromFloat32(val))
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromFloat64(val float64) Decimal {
-	return Decimal(decimal.NewFromFloat(val))
+	return NewDecimalFromNumber(val)
 }
 
 // TODO: test required
@@ -110,49 +112,58 @@ func NewDecimalFromBigFloat(val big.Float) (Decimal, error) {
 	return Decimal(origin), nil
 }
 
+// NewDecimalFromNumber creates Decimal from any built-in number type.
+func NewDecimalFromNumber[T Number](val T) Decimal {
+	switch {
+	case isFloat[T]() && unsafe.Sizeof(val) == 4:
+		return Decimal(decimal.NewFromFloat32(float32(val)))
+	case isFloat[T]():
+		return Decimal(decimal.NewFromFloat(float64(val)))
+	case isSigned[T]():
+		return Decimal(decimal.New(int64(val), 0))
+	default:
+		return Decimal(decimal.NewFromBigInt(new(big.Int).SetUint64(uint64(val)), 0))
+	}
+}
+
 func NewDecimalFromInt(val int) Decimal {
-	return Decimal(decimal.New(int64(val), 0))
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromInt8(val int8) Decimal {
-	return Decimal(decimal.New(int64(val), 0))
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromInt16(val int16) Decimal {
-	return Decimal(decimal.New(int64(val), 0))
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromInt32(val int32) Decimal {
-	return Decimal(decimal.New(int64(val), 0))
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromInt64(val int64) Decimal {
-	return Decimal(decimal.New(val, 0))
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromUint(val uint) Decimal {
-	r, _ := decimal.NewFromString(strconv.FormatUint(uint64(val), 10))
-	return Decimal(r)
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromUint8(val uint8) Decimal {
-	r, _ := decimal.NewFromString(strconv.FormatUint(uint64(val), 10))
-	return Decimal(r)
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromUint16(val uint16) Decimal {
-	r, _ := decimal.NewFromString(strconv.FormatUint(uint64(val), 10))
-	return Decimal(r)
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromUint32(val uint32) Decimal {
-	r, _ := decimal.NewFromString(strconv.FormatUint(uint64(val), 10))
-	return Decimal(r)
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromUint64(val uint64) Decimal {
-	r, _ := decimal.NewFromString(strconv.FormatUint(val, 10))
-	return Decimal(r)
+	return NewDecimalFromNumber(val)
 }
 
 func NewDecimalFromString(val string) (Decimal, error) {
@@ -179,7 +190,7 @@ func (d *Decimal) Conv(sample any, allowFractionalLoss bool) (any, error) {
 	case "float32", "float64", gany.Type(big.Int{}), gany.Type(big.Float{}), gany.Type(Decimal{}):
 		break // these types don't have Min number
 	default:
-		minAny, err := Min(sample)
+		minAny, err := LimitMin(sample)
 		if err != nil {
 			return nil, err
 		}
@@ -197,7 +208,7 @@ func (d *Decimal) Conv(sample any, allowFractionalLoss bool) (any, error) {
 	case gany.Type(big.Int{}), gany.Type(big.Float{}), gany.Type(Decimal{}):
 		break // these types don't have Max number
 	default:
-		maxAny, err := Max(sample)
+		maxAny, err := LimitMax(sample)
 		if err != nil {
 			return nil, err
 		}
@@ -291,11 +302,11 @@ func (d Decimal) Add(d2 Decimal) Decimal {
 }
 
 func (d Decimal) AddInt(n int) Decimal {
-	return d.Add(NewDecimalFromInt(n))
+	return AddN(d, n)
 }
 
 func (d Decimal) AddFloat64(n float64) Decimal {
-	return d.Add(NewDecimalFromFloat64(n))
+	return AddN(d, n)
 }
 
 func (d Decimal) Sub(d2 Decimal) Decimal {
@@ -303,11 +314,11 @@ func (d Decimal) Sub(d2 Decimal) Decimal {
 }
 
 func (d Decimal) SubInt(n int) Decimal {
-	return d.Sub(NewDecimalFromInt(n))
+	return SubN(d, n)
 }
 
 func (d Decimal) SubFloat64(n float64) Decimal {
-	return d.Sub(NewDecimalFromFloat64(n))
+	return SubN(d, n)
 }
 
 func (d Decimal) Mul(d2 Decimal) Decimal {
@@ -315,11 +326,11 @@ func (d Decimal) Mul(d2 Decimal) Decimal {
 }
 
 func (d Decimal) MulInt(n int) Decimal {
-	return d.Mul(NewDecimalFromInt(n))
+	return MulN(d, n)
 }
 
 func (d Decimal) MulFloat64(n float64) Decimal {
-	return d.Mul(NewDecimalFromFloat64(n))
+	return MulN(d, n)
 }
 
 func (d Decimal) Div(d2 Decimal) Decimal {
@@ -327,11 +338,11 @@ func (d Decimal) Div(d2 Decimal) Decimal {
 }
 
 func (d Decimal) DivInt(n int) Decimal {
-	return d.Div(NewDecimalFromInt(n))
+	return DivN(d, n)
 }
 
 func (d Decimal) DivFloat64(n float64) Decimal {
-	return d.Div(NewDecimalFromFloat64(n))
+	return DivN(d, n)
 }
 
 // FIXME： 这个是RoundUp还是RoundDown？
@@ -373,11 +384,11 @@ func (d Decimal) GreaterThan(cmp Decimal) bool {
 }
 
 func (d Decimal) GreaterThanInt(cmp int) bool {
-	return d.GreaterThan(NewDecimalFromInt(cmp))
+	return CmpN(d, cmp) > 0
 }
 
 func (d Decimal) GreaterThanFloat64(cmp float64) bool {
-	return d.GreaterThan(NewDecimalFromFloat64(cmp))
+	return CmpN(d, cmp) > 0
 }
 
 func (d Decimal) GreaterThanOrEqual(cmp Decimal) bool {
@@ -385,11 +396,11 @@ func (d Decimal) GreaterThanOrEqual(cmp Decimal) bool {
 }
 
 func (d Decimal) GreaterThanOrEqualInt(cmp int) bool {
-	return d.GreaterThanOrEqual(NewDecimalFromInt(cmp))
+	return CmpN(d, cmp) >= 0
 }
 
 func (d Decimal) GreaterThanOrEqualFloat64(cmp float64) bool {
-	return d.GreaterThanOrEqual(NewDecimalFromFloat64(cmp))
+	return CmpN(d, cmp) >= 0
 }
 
 func (d Decimal) LessThan(cmp Decimal) bool {
@@ -397,11 +408,11 @@ func (d Decimal) LessThan(cmp Decimal) bool {
 }
 
 func (d Decimal) LessThanInt(cmp int) bool {
-	return d.LessThan(NewDecimalFromInt(cmp))
+	return CmpN(d, cmp) < 0
 }
 
 func (d Decimal) LessThanFloat64(cmp float64) bool {
-	return d.LessThan(NewDecimalFromFloat64(cmp))
+	return CmpN(d, cmp) < 0
 }
 
 func (d Decimal) LessThanOrEqual(cmp Decimal) bool {
@@ -409,11 +420,11 @@ func (d Decimal) LessThanOrEqual(cmp Decimal) bool {
 }
 
 func (d Decimal) LessThanOrEqualInt(cmp int) bool {
-	return d.LessThanOrEqual(NewDecimalFromInt(cmp))
+	return CmpN(d, cmp) <= 0
 }
 
 func (d Decimal) LessThanOrEqualFloat64(cmp float64) bool {
-	return d.LessThanOrEqual(NewDecimalFromFloat64(cmp))
+	return CmpN(d, cmp) <= 0
 }
 
 func (d Decimal) Equal(cmp Decimal) bool {
@@ -421,11 +432,11 @@ func (d Decimal) Equal(cmp Decimal) bool {
 }
 
 func (d Decimal) EqualInt(cmp int) bool {
-	return d.Equal(NewDecimalFromInt(cmp))
+	return CmpN(d, cmp) == 0
 }
 
 func (d Decimal) EqualFloat64(cmp float64) bool {
-	return d.Equal(NewDecimalFromFloat64(cmp))
+	return CmpN(d, cmp) == 0
 }
 
 func (d Decimal) IntPart() int {
@@ -449,6 +460,37 @@ func (d Decimal) String() string {
 	return d.raw().String()
 }
 
+// Cmp returns -1 if d < d2, 0 if d == d2, 1 if d > d2.
+func (d Decimal) Cmp(d2 Decimal) int {
+	return d.raw().Cmp(d2.raw())
+}
+
+/*
+Generic operations with built-in numbers, they are functions because Go methods can't have type parameters,
+e.g. gnum.AddN(price, 3) equals to price.AddInt(3).
+*/
+
+func AddN[T Number](d Decimal, n T) Decimal {
+	return d.Add(NewDecimalFromNumber(n))
+}
+
+func SubN[T Number](d Decimal, n T) Decimal {
+	return d.Sub(NewDecimalFromNumber(n))
+}
+
+func MulN[T Number](d Decimal, n T) Decimal {
+	return d.Mul(NewDecimalFromNumber(n))
+}
+
+func DivN[T Number](d Decimal, n T) Decimal {
+	return d.Div(NewDecimalFromNumber(n))
+}
+
+// CmpN compares d with n like Decimal.Cmp.
+func CmpN[T Number](d Decimal, n T) int {
+	return d.Cmp(NewDecimalFromNumber(n))
+}
+
 func (d *Decimal) SetInt(val int) {
 	*d = NewDecimalFromInt(val)
 }
@@ -588,6 +630,16 @@ func (d *Decimal) UnmarshalText(text []byte) error {
 	return (*decimal.Decimal)(d).UnmarshalText(text)
 }
 
+// Scan implements sql.Scanner, it accepts string, []byte, int64 and float64 columns.
+func (d *Decimal) Scan(value any) error {
+	return (*decimal.Decimal)(d).Scan(value)
+}
+
+// Value implements driver.Valuer, Decimal is stored as string to keep precision.
+func (d Decimal) Value() (driver.Value, error) {
+	return decimal.Decimal(d).Value()
+}
+
 // in BSON, Decimal128 could has 34 numbers after decimal point, it will loss precision if more than 34 numbers after decimal point
 // so we serialize Decimal into string, just like in JSON.
 // in BSON, omitempty of Decimal works
@@ -15,9 +15,8 @@ import (
 // 0.00883300000000003 -> 0.01          -> 0.0088
 // 0.000012800003      -> 0.00          -> 0.000013
 type ElegantFloat struct {
-	val          float64
-	prec         int
-	fmtNaNasNull bool // false default. true: format NaN to "NaN"; false: format NaN to null, null is valid in javascript number calc like echarts
+	val  float64
+	prec int
 }
 
 func NewElegantFloat(val float64, prec int) ElegantFloat {
@@ -101,13 +100,16 @@ func (t *ElegantFloat) Raw() float64 {
 	return t.val
 }
 
-// TODO: NaN +-Inf会被ParseFloat64认为是错误吗，需要测试一下
-// UnmarshalJSON will unmarshal using 2006-01-02T15:04:05+07:00 layout
+// UnmarshalJSON accepts numbers, null as NaN, and "NaN", "+Inf", "-Inf" bare or quoted.
 func (t *ElegantFloat) UnmarshalJSON(b []byte) error {
-	val, err := strconv.ParseFloat(string(b), 64)
+	str := string(b)
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		str = str[1 : len(str)-1]
+	}
+	val, err := strconv.ParseFloat(str, 64)
 	if err != nil {
-		switch string(b) {
-		case "NaN":
+		switch str {
+		case "NaN", "null":
 			t.val = math.NaN()
 			return nil
 		case "+Inf":
@@ -126,14 +128,17 @@ func (t *ElegantFloat) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// MarshalJSON will marshal using 2006-01-02T15:04:05+07:00 layout
-//
-// FIXME
-// if t.val = math.NaN and t.JSON(false), json.Marshal(t) will output error:
-// json: error calling MarshalJSON for type *gnum.ElegantFloat: invalid character 'N' looking for beginning of value
-// why and how to fix?
+// MarshalJSON always outputs valid JSON, JSON has no NaN or Inf number,
+// so NaN is output as null and +-Inf as string "+Inf" / "-Inf".
 func (t ElegantFloat) MarshalJSON() ([]byte, error) {
-	return t.JSON(t.fmtNaNasNull)
+	if math.IsInf(t.val, 0) {
+		b, err := t.JSON(true)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(`"` + string(b) + `"`), nil
+	}
+	return t.JSON(true)
 }
 
 // What will happen if value is math.NaN? it will output bytes buffer `"NaN"`
@@ -75,6 +75,18 @@ func TestElegantFloat_JSON(t *testing.T) {
 		return
 	}
 
+	b, err = json.Marshal([]ElegantFloat{f4, f5})
+	if err != nil || string(b) != `["+Inf","-Inf"]` {
+		t.Errorf("Inf json got %s %v", b, err)
+		return
+	}
+	var infs []ElegantFloat
+	if err := json.Unmarshal([]byte(`["+Inf","-Inf",null]`), &infs); err != nil || len(infs) != 3 ||
+		!math.IsInf(infs[0].Raw(), 1) || !math.IsInf(infs[1].Raw(), -1) || !math.IsNaN(infs[2].Raw()) {
+		t.Errorf("Inf json unmarshal got %v %v", infs, err)
+		return
+	}
+
 	f6 := NewElegantFloat(1.2345, -1)
 	b, err = f6.JSON(true)
 	if err != nil {
@@ -0,0 +1,218 @@
+package gnum
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"strconv"
+	"unsafe"
+)
+
+/*
+Generic numeric API, per-type functions like MinInt64 and FormatUint32 are thin wrappers of it.
+
+Breaking change: the generic Min, Max and Format take the names of the old Min(any), Max(any) and
+Format(any, int), which are renamed to the deprecated LimitMin, LimitMax and FormatAny.
+*/
+
+type (
+	Signed interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64
+	}
+
+	Unsigned interface {
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+	}
+
+	Integer interface {
+		Signed | Unsigned
+	}
+
+	Float interface {
+		~float32 | ~float64
+	}
+
+	Number interface {
+		Integer | Float
+	}
+)
+
+var ErrOverflow = gerrors.New("integer overflow")
+
+// Min returns the minimum of first and args.
+func Min[T Number](first T, args ...T) T {
+	for _, v := range args {
+		if v < first {
+			first = v
+		}
+	}
+	return first
+}
+
+// Max returns the maximum of first and args.
+func Max[T Number](first T, args ...T) T {
+	for _, v := range args {
+		if v > first {
+			first = v
+		}
+	}
+	return first
+}
+
+// Clamp bounds v into [lo, hi].
+func Clamp[T Number](v, lo, hi T) T {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// Sum (和)
+func Sum[T Number](values []T) T {
+	var ret T
+	for _, v := range values {
+		ret += v
+	}
+	return ret
+}
+
+// Abs returns absolute value, Abs of the minimum signed integer overflows to itself.
+func Abs[T Signed | Float](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// isFloat reports whether T is a float type.
+func isFloat[T Number]() bool {
+	var one T = 1
+	return one/2 != 0
+}
+
+// isSigned reports whether T is a signed integer or float type.
+func isSigned[T Number]() bool {
+	var zero, one T = 0, 1
+	return zero-one < zero
+}
+
+// Format formats integers in base, floats are formatted in the shortest decimal representation and base is ignored.
+func Format[T Number](num T, base int) string {
+	switch {
+	case isFloat[T]():
+		return strconv.FormatFloat(float64(num), 'f', -1, int(unsafe.Sizeof(num))*8)
+	case isSigned[T]():
+		return strconv.FormatInt(int64(num), base)
+	default:
+		return strconv.FormatUint(uint64(num), base)
+	}
+}
+
+// integerLimits returns minimum and maximum value of T.
+func integerLimits[T Integer]() (T, T) {
+	var zero T
+	if isSigned[T]() {
+		var one T = 1
+		minT := one << (unsafe.Sizeof(zero)*8 - 1)
+		return minT, ^minT
+	}
+	return 0, ^zero
+}
+
+// MinValue returns the minimum value of T.
+func MinValue[T Integer]() T {
+	minT, _ := integerLimits[T]()
+	return minT
+}
+
+// MaxValue returns the maximum value of T.
+func MaxValue[T Integer]() T {
+	_, maxT := integerLimits[T]()
+	return maxT
+}
+
+// AddChecked returns a+b, or ErrOverflow if the result overflows T.
+func AddChecked[T Integer](a, b T) (T, error) {
+	c := a + b
+	if isSigned[T]() {
+		if (b > 0 && c < a) || (b < 0 && c > a) {
+			return c, ErrOverflow
+		}
+	} else if c < a {
+		return c, ErrOverflow
+	}
+	return c, nil
+}
+
+// SubChecked returns a-b, or ErrOverflow if the result overflows T.
+func SubChecked[T Integer](a, b T) (T, error) {
+	c := a - b
+	if isSigned[T]() {
+		if (b > 0 && c > a) || (b < 0 && c < a) {
+			return c, ErrOverflow
+		}
+	} else if b > a {
+		return c, ErrOverflow
+	}
+	return c, nil
+}
+
+// MulChecked returns a*b, or ErrOverflow if the result overflows T.
+func MulChecked[T Integer](a, b T) (T, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	c := a * b
+	if isSigned[T]() {
+		minT, _ := integerLimits[T]()
+		var one T = 1
+		// minT*-1 == minT and minT/-1 == minT, division can't detect it
+		if (a == -one && b == minT) || (b == -one && a == minT) {
+			return c, ErrOverflow
+		}
+	}
+	if c/b != a {
+		return c, ErrOverflow
+	}
+	return c, nil
+}
+
+// AddSat returns a+b saturated to the range of T.
+func AddSat[T Integer](a, b T) T {
+	c, err := AddChecked(a, b)
+	if err == nil {
+		return c
+	}
+	minT, maxT := integerLimits[T]()
+	if isSigned[T]() && b < 0 {
+		return minT
+	}
+	return maxT
+}
+
+// SubSat returns a-b saturated to the range of T.
+func SubSat[T Integer](a, b T) T {
+	c, err := SubChecked(a, b)
+	if err == nil {
+		return c
+	}
+	minT, maxT := integerLimits[T]()
+	if isSigned[T]() && b < 0 {
+		return maxT
+	}
+	return minT
+}
+
+// MulSat returns a*b saturated to the range of T.
+func MulSat[T Integer](a, b T) T {
+	c, err := MulChecked(a, b)
+	if err == nil {
+		return c
+	}
+	minT, maxT := integerLimits[T]()
+	if isSigned[T]() && (a < 0) != (b < 0) {
+		return minT
+	}
+	return maxT
+}
@@ -0,0 +1,83 @@
+package gnum
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"testing"
+)
+
+type myInt int16
+
+func TestGeneric(t *testing.T) {
+	gtest.AssertTrue(t, Min(3, 1, 2) == 1 && Max(3, 1, 2) == 3, "Min/Max failed")
+	gtest.AssertTrue(t, Min(myInt(-3), 5) == -3, "Min of named type failed")
+	gtest.AssertTrue(t, Clamp(15, 0, 10) == 10 && Clamp(-1.5, 0, 10) == 0 && Clamp(uint8(5), 0, 10) == 5, "Clamp failed")
+	gtest.AssertTrue(t, Sum([]uint16{1, 2, 3}) == 6 && Sum([]float64{0.5, 0.25}) == 0.75, "Sum failed")
+	gtest.AssertTrue(t, Abs(-3) == 3 && Abs(-2.5) == 2.5, "Abs failed")
+
+	cases := map[string]string{
+		Format(-255, 16):                   "-ff",
+		Format(uint64(math.MaxUint64), 10): "18446744073709551615",
+		Format(float32(0.1), 10):           "0.1",
+		Format(1.25, 10):                   "1.25",
+		FormatUint8(200):                   "200",
+		FormatInt64(-7):                    "-7",
+	}
+	for got, expect := range cases {
+		gtest.AssertTrue(t, got == expect, "Format got %s but expect %s", got, expect)
+	}
+	s, err := FormatAny(NewUint24(300), 10)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, s == "300", "FormatAny(Uint24) got %s", s)
+}
+
+func TestChecked(t *testing.T) {
+	gtest.AssertTrue(t, MinValue[int8]() == math.MinInt8 && MaxValue[int8]() == math.MaxInt8, "int8 limits")
+	gtest.AssertTrue(t, MinValue[uint32]() == 0 && MaxValue[uint32]() == math.MaxUint32, "uint32 limits")
+	gtest.AssertTrue(t, MinValue[int64]() == math.MinInt64 && MaxValue[int]() == math.MaxInt, "int64 and int limits")
+	i8, err := AddChecked(int8(100), int8(27))
+	gtest.AssertTrue(t, err == nil && i8 == 127, "100+27 shouldn't overflow int8")
+	_, err = AddChecked(int8(100), int8(28))
+	gtest.AssertTrue(t, err == ErrOverflow, "100+28 should overflow int8")
+	_, err = AddChecked(int8(-100), int8(-29))
+	gtest.AssertTrue(t, err == ErrOverflow, "-100-29 should overflow int8")
+	_, err = AddChecked(uint64(math.MaxUint64), 1)
+	gtest.AssertTrue(t, err == ErrOverflow, "MaxUint64+1 should overflow")
+	_, err = SubChecked(uint32(1), 2)
+	gtest.AssertTrue(t, err == ErrOverflow, "1-2 should overflow uint32")
+	_, err = SubChecked(int64(math.MinInt64), 1)
+	gtest.AssertTrue(t, err == ErrOverflow, "MinInt64-1 should overflow")
+	_, err = MulChecked(int64(math.MinInt64), -1)
+	gtest.AssertTrue(t, err == ErrOverflow, "MinInt64*-1 should overflow")
+	_, err = MulChecked(int32(-1), math.MinInt32)
+	gtest.AssertTrue(t, err == ErrOverflow, "-1*MinInt32 should overflow")
+	v, err := MulChecked(int16(-128), 256)
+	gtest.AssertTrue(t, err == nil && v == math.MinInt16, "-128*256 got %d %v", v, err)
+	_, err = MulChecked(uint16(256), 256)
+	gtest.AssertTrue(t, err == ErrOverflow, "256*256 should overflow uint16")
+
+	gtest.AssertTrue(t, AddSat(int8(100), 100) == math.MaxInt8 && AddSat(int8(-100), -100) == math.MinInt8, "AddSat int8 failed")
+	gtest.AssertTrue(t, SubSat(uint8(1), 2) == 0 && SubSat(int8(100), -100) == math.MaxInt8, "SubSat failed")
+	gtest.AssertTrue(t, MulSat(int32(-65536), 65536) == math.MinInt32 && MulSat(uint8(16), 16) == math.MaxUint8, "MulSat failed")
+	gtest.AssertTrue(t, AddSat(3, 4) == 7, "AddSat without overflow failed")
+
+	max24 := NewUint24(MaxUint24)
+	_, err = max24.AddChecked(NewUint24(1))
+	gtest.AssertTrue(t, err == ErrOverflow, "MaxUint24+1 should overflow")
+	u, err := NewUint24(4096).MulChecked(NewUint24(4095))
+	gtest.AssertTrue(t, err == nil && u.Uint32() == 4096*4095, "Uint24 MulChecked got %d %v", u.Uint32(), err)
+	_, err = NewUint24(4096).MulChecked(NewUint24(4096))
+	gtest.AssertTrue(t, err == ErrOverflow, "4096*4096 should overflow Uint24")
+	_, err = NewUint24(1).SubChecked(NewUint24(2))
+	gtest.AssertTrue(t, err == ErrOverflow, "1-2 should overflow Uint24")
+	gtest.AssertTrue(t, max24.AddSat(max24) == max24 && NewUint24(1).SubSat(max24).Uint32() == 0 && max24.MulSat(max24) == max24, "Uint24 saturating ops failed")
+}
+
+func TestDecimalGeneric(t *testing.T) {
+	d := NewDecimalFromInt(10)
+	gtest.AssertTrue(t, AddN(d, uint64(math.MaxUint64)).String() == "18446744073709551625", "AddN(uint64) got %s", AddN(d, uint64(math.MaxUint64)))
+	gtest.AssertTrue(t, SubN(d, float32(0.5)).String() == "9.5", "SubN(float32) got %s", SubN(d, float32(0.5)))
+	gtest.AssertTrue(t, MulN(d, int8(-3)).String() == "-30", "MulN got %s", MulN(d, int8(-3)))
+	gtest.AssertTrue(t, DivN(d, 4).String() == "2.5", "DivN got %s", DivN(d, 4))
+	gtest.AssertTrue(t, CmpN(d, 9.99) == 1 && CmpN(d, myInt(10)) == 0 && d.GreaterThanInt(9) && d.LessThanFloat64(10.01), "CmpN failed")
+}
@@ -6,7 +6,10 @@ import (
 	"math"
 )
 
-func Min(numTypeSample any) (any, error) {
+// LimitMin returns the minimum value of the type of numTypeSample, it was named Min before generic Min.
+//
+// Deprecated: use MinValue, or NewUint24(0) for Uint24.
+func LimitMin(numTypeSample any) (any, error) {
 	ntsType := gany.Type(numTypeSample)
 	switch ntsType {
 	case "uint8":
@@ -36,7 +39,10 @@ func Min(numTypeSample any) (any, error) {
 	}
 }
 
-func Max(numTypeSample any) (any, error) {
+// LimitMax returns the maximum value of the type of numTypeSample, it was named Max before generic Max.
+//
+// Deprecated: use MaxValue, MaxUint24 for Uint24, or math.MaxFloat32 and math.MaxFloat64 for floats.
+func LimitMax(numTypeSample any) (any, error) {
 	ntsType := gany.Type(numTypeSample)
 	switch ntsType {
 	case "uint8":
@@ -71,7 +77,7 @@ func Max(numTypeSample any) (any, error) {
 }
 
 func MustMin(numTypeSample any) any {
-	result, err := Min(numTypeSample)
+	result, err := LimitMin(numTypeSample)
 	if err != nil {
 		panic(gerrors.Wrap(err, "MustMin()"))
 	}
@@ -79,7 +85,7 @@ func MustMin(numTypeSample any) any {
 }
 
 func MustMax(numTypeSample any) any {
-	result, err := Max(numTypeSample)
+	result, err := LimitMax(numTypeSample)
 	if err != nil {
 		panic(gerrors.Wrap(err, "MustMax()"))
 	}
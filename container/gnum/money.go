@@ -0,0 +1,262 @@
+package gnum
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/i18n/gfiat"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+/*
+Money is an amount of Decimal in a currency, arithmetic between different currencies fails with
+ErrCurrencyMismatch. Amount is not rounded automatically except by operations which must round (Mul, Div,
+Allocate), call Round to round to minor units of the currency explicitly.
+
+serialize:
+JSON: {"amount":"12.30","currency":"USD"}
+Text/SQL: "12.30 USD"
+*/
+
+type Money struct {
+	amount   Decimal
+	currency gfiat.Currency
+}
+
+var ErrCurrencyMismatch = gerrors.New("currency mismatch")
+
+// NewMoney creates money of ISO 4217 currency code.
+func NewMoney(amount Decimal, code string) (Money, error) {
+	c, err := gfiat.ParseCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, currency: c}, nil
+}
+
+// NewMoneyFromString parses amount like "12.30".
+func NewMoneyFromString(amount, code string) (Money, error) {
+	d, err := NewDecimalFromString(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(d, code)
+}
+
+// NewMoneyFromMinor creates money from amount in minor units, e.g. cents for USD.
+func NewMoneyFromMinor(minor int64, code string) (Money, error) {
+	c, err := gfiat.ParseCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: Decimal(decimal.New(minor, -int32(c.MinorUnits))), currency: c}, nil
+}
+
+func MustNewMoney(amount Decimal, code string) Money {
+	m, err := NewMoney(amount, code)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) Amount() Decimal           { return m.amount }
+func (m Money) Currency() gfiat.Currency  { return m.currency }
+func (m Money) IsZero() bool              { return m.amount.IsZero() }
+func (m Money) IsPositive() bool          { return m.amount.IsPositive() }
+func (m Money) IsNegative() bool          { return m.amount.IsNegative() }
+func (m Money) Negate() Money             { return m.with(m.amount.TurnPositiveNegative()) }
+func (m Money) Abs() Money                { return m.with(m.amount.Abs()) }
+func (m Money) with(amount Decimal) Money { return Money{amount: amount, currency: m.currency} }
+func (m Money) SameCurrency(o Money) bool { return m.currency.Code == o.currency.Code }
+
+func (m Money) check(o Money) error {
+	if !m.SameCurrency(o) {
+		return gerrors.Wrap(ErrCurrencyMismatch, m.currency.Code+" and "+o.currency.Code)
+	}
+	return nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.check(o); err != nil {
+		return Money{}, err
+	}
+	return m.with(m.amount.Add(o.amount)), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.check(o); err != nil {
+		return Money{}, err
+	}
+	return m.with(m.amount.Sub(o.amount)), nil
+}
+
+// Cmp returns -1, 0 or 1 like Decimal.Cmp.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.check(o); err != nil {
+		return 0, err
+	}
+	return m.amount.Cmp(o.amount), nil
+}
+
+// Equal reports whether m and o have the same currency and amount.
+func (m Money) Equal(o Money) bool {
+	return m.SameCurrency(o) && m.amount.Equal(o.amount)
+}
+
+// Mul returns m*factor rounded to minor units by mode.
+func (m Money) Mul(factor Decimal, mode RoundingMode) Money {
+	return m.with(m.amount.Mul(factor).RoundMode(m.currency.MinorUnits, mode))
+}
+
+// Div returns m/divisor rounded to minor units by mode.
+func (m Money) Div(divisor Decimal, mode RoundingMode) Money {
+	return m.with(m.amount.DivRoundMode(divisor, m.currency.MinorUnits, mode))
+}
+
+// Round rounds amount to minor units of the currency by mode.
+func (m Money) Round(mode RoundingMode) Money {
+	return m.with(m.amount.RoundMode(m.currency.MinorUnits, mode))
+}
+
+// Minor returns amount in minor units, amount must be already rounded to minor units.
+func (m Money) Minor() (int64, error) {
+	shifted := m.amount.raw().Shift(int32(m.currency.MinorUnits))
+	if !shifted.Equal(shifted.Truncate(0)) {
+		return 0, gerrors.New("amount %s has more digits than minor units of %s", m.amount, m.currency.Code)
+	}
+	if !shifted.BigInt().IsInt64() {
+		return 0, ErrOverflow
+	}
+	return shifted.IntPart(), nil
+}
+
+// Allocate splits m by ratios without losing any minor unit, the amount is rounded to minor units
+// by banker's rounding first, remainders of minor units go to the leading parts one by one,
+// e.g. 0.05 USD allocated by 3:7 is [0.02, 0.03], 100 split by 1:1:1 is [33.34, 33.33, 33.33].
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, gerrors.New("no ratio to allocate")
+	}
+	total := int64(0)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, gerrors.New("negative ratio %d", r)
+		}
+		total += int64(r)
+	}
+	if total == 0 {
+		return nil, gerrors.New("sum of ratios is zero")
+	}
+
+	places := int32(m.currency.MinorUnits)
+	minor := m.amount.RoundMode(int(places), RoundHalfEven).raw().Shift(places) // integer
+	totalD := decimal.New(total, 0)
+	out := make([]Money, len(ratios))
+	remain := minor
+	for i, r := range ratios {
+		share := minor.Mul(decimal.New(int64(r), 0)).Div(totalD).Truncate(0)
+		out[i] = m.with(Decimal(share))
+		remain = remain.Sub(share)
+	}
+	one := decimal.New(int64(minor.Sign()), 0)
+	for i := 0; !remain.IsZero(); i = (i + 1) % len(out) {
+		if ratios[i] == 0 {
+			continue
+		}
+		out[i].amount = Decimal(out[i].amount.raw().Add(one))
+		remain = remain.Sub(one)
+	}
+	for i := range out {
+		out[i].amount = Decimal(out[i].amount.raw().Shift(-places))
+	}
+	return out, nil
+}
+
+// Split splits m into n equal parts without losing any minor unit.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, gerrors.New("invalid split count %d", n)
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// amountString returns amount with at least minor unit digits, e.g. "12.30" for USD.
+func (m Money) amountString() string {
+	places := m.currency.MinorUnits
+	if exp := -int(m.amount.raw().Exponent()); exp > places {
+		places = exp // never hide digits
+	}
+	return m.amount.StringFixed(places)
+}
+
+// String returns amount and currency code, e.g. "12.30 USD".
+func (m Money) String() string {
+	return m.amountString() + " " + m.currency.Code
+}
+
+// ParseMoney parses the format of Money.String, like "12.30 USD".
+func ParseMoney(s string) (Money, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Money{}, gerrors.New("invalid money %s", s)
+	}
+	return NewMoneyFromString(fields[0], fields[1])
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.amountString(), Currency: m.currency.Code})
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var mj moneyJSON
+	if err := json.Unmarshal(b, &mj); err != nil {
+		return err
+	}
+	parsed, err := NewMoneyFromString(mj.Amount, mj.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner, column must be a string like "12.30 USD".
+func (m *Money) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		return m.UnmarshalText([]byte(v))
+	case []byte:
+		return m.UnmarshalText(v)
+	default:
+		return gerrors.New("can't scan %T into Money", value)
+	}
+}
+
+// Value implements driver.Valuer.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
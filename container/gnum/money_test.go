@@ -0,0 +1,138 @@
+package gnum
+
+import (
+	"encoding/json"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"github.com/davidforest123/goutil/i18n/gfiat"
+	"testing"
+)
+
+func mustDecimal(t *testing.T, s string) Decimal {
+	d, err := NewDecimalFromString(s)
+	gtest.Assert(t, err)
+	return d
+}
+
+func TestDecimal_RoundMode(t *testing.T) {
+	cases := []struct {
+		in     string
+		mode   RoundingMode
+		expect string
+	}{
+		{"2.5", RoundHalfUp, "3"}, {"-2.5", RoundHalfUp, "-3"},
+		{"2.5", RoundHalfEven, "2"}, {"3.5", RoundBanker, "4"}, {"-2.5", RoundHalfEven, "-2"},
+		{"2.1", RoundCeiling, "3"}, {"-2.9", RoundCeiling, "-2"},
+		{"2.9", RoundFloor, "2"}, {"-2.1", RoundFloor, "-3"},
+		{"2.1", RoundUp, "3"}, {"-2.1", RoundUp, "-3"},
+		{"2.9", RoundDown, "2"}, {"-2.9", RoundDown, "-2"},
+	}
+	for _, c := range cases {
+		got := mustDecimal(t, c.in).RoundMode(0, c.mode)
+		gtest.AssertTrue(t, got.String() == c.expect, "RoundMode(%s, %s) got %s but expect %s", c.in, c.mode, got, c.expect)
+	}
+
+	// 1/8 = 0.125 exactly, 2/3 = 0.666...
+	divCases := []struct {
+		a, b   int
+		mode   RoundingMode
+		expect string
+	}{
+		{1, 8, RoundHalfUp, "0.13"}, {1, 8, RoundHalfEven, "0.12"}, {-1, 8, RoundHalfEven, "-0.12"},
+		{3, 8, RoundHalfEven, "0.38"}, {2, 3, RoundDown, "0.66"}, {2, 3, RoundHalfEven, "0.67"},
+		{-2, 3, RoundCeiling, "-0.66"}, {-2, 3, RoundFloor, "-0.67"}, {1, 300, RoundUp, "0.01"},
+	}
+	for _, c := range divCases {
+		got := NewDecimalFromInt(c.a).DivRoundMode(NewDecimalFromInt(c.b), 2, c.mode)
+		gtest.AssertTrue(t, got.String() == c.expect, "%d/%d %s got %s but expect %s", c.a, c.b, c.mode, got, c.expect)
+	}
+}
+
+func TestDecimal_SQL(t *testing.T) {
+	var d Decimal
+	gtest.Assert(t, d.Scan([]byte("12.345")))
+	v, err := d.Value()
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, v.(string) == "12.345", "Value got %v", v)
+	gtest.Assert(t, d.Scan(int64(7)))
+	gtest.AssertTrue(t, d.EqualInt(7), "Scan(int64) got %s", d)
+}
+
+func TestMoney(t *testing.T) {
+	usd, err := gfiat.ParseCurrency("usd")
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, usd.Code == "USD" && usd.MinorUnits == 2, "USD got %+v", usd)
+	jpy := gfiat.MustParseCurrency("JPY")
+	kwd := gfiat.MustParseCurrency("KWD")
+	gtest.AssertTrue(t, jpy.MinorUnits == 0 && kwd.MinorUnits == 3, "minor units of JPY %d KWD %d", jpy.MinorUnits, kwd.MinorUnits)
+	_, err = gfiat.ParseCurrency("XYZ")
+	gtest.AssertTrue(t, err != nil, "unknown currency should fail")
+
+	a, err := NewMoneyFromString("10.10", "USD")
+	gtest.Assert(t, err)
+	b, err := NewMoneyFromMinor(205, "USD")
+	gtest.Assert(t, err)
+	sum, err := a.Add(b)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, sum.String() == "12.15 USD", "Add got %s", sum)
+	_, err = a.Add(MustNewMoney(Decimal1, "EUR"))
+	gtest.AssertTrue(t, err != nil, "adding different currencies should fail")
+
+	tax := a.Mul(mustDecimal(t, "0.075"), RoundHalfEven) // 0.7575
+	gtest.AssertTrue(t, tax.String() == "0.76 USD", "Mul got %s", tax)
+	yen := MustNewMoney(NewDecimalFromInt(1000), "JPY").Div(NewDecimalFromInt(3), RoundHalfUp)
+	gtest.AssertTrue(t, yen.String() == "333 JPY", "Div got %s", yen)
+	minor, err := sum.Minor()
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, minor == 1215, "Minor got %d", minor)
+}
+
+func TestMoney_Allocate(t *testing.T) {
+	check := func(m Money, parts []Money, expect ...string) {
+		total := MustNewMoney(Decimal0, m.Currency().Code)
+		for i, p := range parts {
+			gtest.AssertTrue(t, p.String() == expect[i], "part %d got %s but expect %s", i, p, expect[i])
+			total, _ = total.Add(p)
+		}
+		gtest.AssertTrue(t, total.Equal(m), "parts sum %s != %s", total, m)
+	}
+	m, _ := NewMoneyFromString("100", "USD")
+	parts, err := m.Split(3)
+	gtest.Assert(t, err)
+	check(m, parts, "33.34 USD", "33.33 USD", "33.33 USD")
+
+	m, _ = NewMoneyFromString("0.05", "USD")
+	parts, err = m.Allocate(3, 7)
+	gtest.Assert(t, err)
+	check(m, parts, "0.02 USD", "0.03 USD")
+
+	m, _ = NewMoneyFromString("-10", "JPY")
+	parts, err = m.Allocate(1, 0, 2)
+	gtest.Assert(t, err)
+	check(m, parts, "-4 JPY", "0 JPY", "-6 JPY")
+
+	_, err = m.Allocate(0, 0)
+	gtest.AssertTrue(t, err != nil, "zero ratios should fail")
+}
+
+func TestMoney_Codec(t *testing.T) {
+	m, _ := NewMoneyFromString("12.3", "USD")
+	b, err := json.Marshal(m)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, string(b) == `{"amount":"12.30","currency":"USD"}`, "JSON got %s", b)
+	var m2 Money
+	gtest.Assert(t, json.Unmarshal(b, &m2))
+	gtest.AssertTrue(t, m2.Equal(m), "JSON round trip got %s", m2)
+
+	v, err := m.Value()
+	gtest.Assert(t, err)
+	var m3 Money
+	gtest.Assert(t, m3.Scan([]byte(v.(string))))
+	gtest.AssertTrue(t, m3.Equal(m), "SQL round trip got %s", m3)
+	gtest.AssertTrue(t, m3.Scan(12) != nil, "Scan of int should fail")
+
+	text, err := m.MarshalText()
+	gtest.Assert(t, err)
+	var m4 Money
+	gtest.Assert(t, m4.UnmarshalText(text))
+	gtest.AssertTrue(t, m4.Equal(m) && string(text) == "12.30 USD", "text round trip got %s", m4)
+}
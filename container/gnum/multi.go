@@ -107,150 +107,76 @@ func FloatsEqual(a, b []float64) bool {
 }
 
 func MinInt(first int, args ...int) int {
-	for _, v := range args {
-		if first > v {
-			first = v
-		}
-	}
-	return first
+	return Min(first, args...)
 }
 
 func MinInt64(first int64, args ...int64) int64 {
-	for _, v := range args {
-		if first > v {
-			first = v
-		}
-	}
-	return first
+	return Min(first, args...)
 }
 
 func MinUint32(first uint32, args ...uint32) uint32 {
-	for _, v := range args {
-		if first > v {
-			first = v
-		}
-	}
-	return first
+	return Min(first, args...)
 }
 
 func MinUintArray(args []uint) uint {
 	if len(args) == 0 {
 		panic(gerrors.Errorf("MinUintArray nil input args"))
-		return 0
-	}
-
-	min := args[0]
-	for _, v := range args {
-		if v < min {
-			min = v
-		}
 	}
-	return min
+	return Min(args[0], args[1:]...)
 }
 
 func MinUint64(first uint64, args ...uint64) uint64 {
-	for _, v := range args {
-		if first > v {
-			first = v
-		}
-	}
-	return first
+	return Min(first, args...)
 }
 
 func MinFloat64(first float64, args ...float64) float64 {
-	for _, v := range args {
-		if first > v {
-			first = v
-		}
-	}
-	return first
+	return Min(first, args...)
 }
 
 func MinFloat(args ...float64) float64 {
 	if len(args) == 0 {
 		panic("nil args input")
 	}
-	first := args[0]
-	for _, v := range args {
-		if first > v {
-			first = v
-		}
-	}
-	return first
+	return Min(args[0], args[1:]...)
 }
 
 func MaxInt(first int, args ...int) int {
-	for _, v := range args {
-		if first < v {
-			first = v
-		}
-	}
-	return first
+	return Max(first, args...)
 }
 
 func MaxInt64(first int64, args ...int64) int64 {
-	for _, v := range args {
-		if first < v {
-			first = v
-		}
-	}
-	return first
+	return Max(first, args...)
 }
 
 func MaxUint32(first uint32, args ...uint32) uint32 {
-	for _, v := range args {
-		if first < v {
-			first = v
-		}
-	}
-	return first
+	return Max(first, args...)
 }
 
 func MaxUint64(first uint64, args ...uint64) uint64 {
-	for _, v := range args {
-		if first < v {
-			first = v
-		}
-	}
-	return first
+	return Max(first, args...)
 }
 
 func MaxFloat64(first float64, args ...float64) float64 {
-	for _, v := range args {
-		if first < v {
-			first = v
-		}
-	}
-	return first
+	return Max(first, args...)
 }
 
 func MaxFloat(args ...float64) float64 {
 	if len(args) == 0 {
 		panic("nil args input")
 	}
-	first := args[0]
-	for _, v := range args {
-		if first < v {
-			first = v
-		}
-	}
-	return first
+	return Max(args[0], args[1:]...)
 }
 
 func SumFloat(args ...float64) float64 {
 	if len(args) == 0 {
 		panic("nil args input")
 	}
-	sum := float64(0)
-	for _, v := range args {
-		sum += v
-	}
-	return sum
+	return Sum(args)
 }
 
 // 给toBound划界，不超过[min, max]的范围
 func BoundUint32(min, toBound, max uint32) uint32 {
-	return MinUint32(MaxUint32(min, toBound), max)
+	return Clamp(toBound, min, max)
 }
 
 func RemoveDuplicate(elements []int) []int {
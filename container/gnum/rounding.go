@@ -0,0 +1,100 @@
+package gnum
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// RoundingMode decides how Decimal.RoundMode drops digits.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds half away from zero, 2.5 -> 3, -2.5 -> -3.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds half to the nearest even digit, 2.5 -> 2, 3.5 -> 4.
+	RoundHalfEven
+	// RoundCeiling rounds toward positive infinity, 2.1 -> 3, -2.9 -> -2.
+	RoundCeiling
+	// RoundFloor rounds toward negative infinity, 2.9 -> 2, -2.1 -> -3.
+	RoundFloor
+	// RoundUp rounds away from zero, 2.1 -> 3, -2.1 -> -3.
+	RoundUp
+	// RoundDown rounds toward zero (truncates), 2.9 -> 2, -2.9 -> -2.
+	RoundDown
+
+	// RoundBanker is banker's rounding, which is RoundHalfEven.
+	RoundBanker = RoundHalfEven
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfUp:
+		return "HalfUp"
+	case RoundHalfEven:
+		return "HalfEven"
+	case RoundCeiling:
+		return "Ceiling"
+	case RoundFloor:
+		return "Floor"
+	case RoundUp:
+		return "Up"
+	case RoundDown:
+		return "Down"
+	default:
+		return "Unknown"
+	}
+}
+
+// RoundMode rounds d to places digits after decimal point by mode, places can be negative,
+// e.g. RoundMode(-2, RoundHalfUp) of 1250 is 1300.
+func (d Decimal) RoundMode(places int, mode RoundingMode) Decimal {
+	p := int32(places)
+	switch mode {
+	case RoundHalfEven:
+		return Decimal(d.raw().RoundBank(p))
+	case RoundCeiling:
+		return Decimal(d.raw().RoundCeil(p))
+	case RoundFloor:
+		return Decimal(d.raw().RoundFloor(p))
+	case RoundUp:
+		return Decimal(d.raw().RoundUp(p))
+	case RoundDown:
+		return Decimal(d.raw().RoundDown(p))
+	default:
+		return Decimal(d.raw().Round(p))
+	}
+}
+
+// DivRoundMode returns d/d2 rounded to places digits after decimal point by mode, it is exact
+// no matter how many digits the quotient has.
+func (d Decimal) DivRoundMode(d2 Decimal, places int, mode RoundingMode) Decimal {
+	// q is truncated toward zero and d = d2*q + r
+	q, r := d.raw().QuoRem(d2.raw(), int32(places))
+	if r.IsZero() {
+		return Decimal(q)
+	}
+	ulp := decimal.New(1, -int32(places))
+	sign := d.raw().Sign() * d2.raw().Sign()
+	away := false
+	switch mode {
+	case RoundDown:
+	case RoundUp:
+		away = true
+	case RoundCeiling:
+		away = sign > 0
+	case RoundFloor:
+		away = sign < 0
+	default:
+		// compare remainder with half ulp of divisor
+		half := r.Abs().Mul(decimal.New(2, 0)).Cmp(d2.raw().Abs().Mul(ulp))
+		away = half > 0 || (half == 0 && (mode == RoundHalfUp || !q.Shift(int32(places)).Mod(decimal.New(2, 0)).IsZero()))
+	}
+	if away {
+		return Decimal(q.Add(ulp.Mul(decimal.New(int64(sign), 0))))
+	}
+	return Decimal(q)
+}
+
+// StringFixed returns string with exactly places digits after decimal point, it rounds half away from zero.
+func (d Decimal) StringFixed(places int) string {
+	return d.raw().StringFixed(int32(places))
+}
@@ -4,16 +4,11 @@ package gnum
 statistic functions
 */
 
-import "math"
-
-// Sum (和)
-func Sum(values []float64) float64 {
-	ret := 0.
-	for _, v := range values {
-		ret += v
-	}
-	return ret
-}
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+	"sort"
+)
 
 // Mean (均值)
 func Mean(values []float64) float64 {
@@ -40,4 +35,148 @@ func Std(values []float64, ddof int) float64 {
 }
 
 // Variance (方差)
-// func Var(sample []float64, wholePop bool) float64
+// ddof works like Std.
+func Var(values []float64, ddof int) float64 {
+	s := Std(values, ddof)
+	return s * s
+}
+
+// Interpolation decides how Percentile picks value between two data points, same as numpy.
+type Interpolation int
+
+const (
+	InterpolationLinear Interpolation = iota
+	InterpolationLower
+	InterpolationHigher
+	InterpolationNearest
+	InterpolationMidpoint
+)
+
+// Median (中位数)
+func Median(values []float64) float64 {
+	return Percentile(values, 50, InterpolationLinear)
+}
+
+// Percentile (百分位数), p is in [0, 100].
+func Percentile(values []float64, p float64, interpolation Interpolation) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentileSorted(sorted, p, interpolation)
+}
+
+// Percentiles returns percentiles of every p in ps, values are sorted only once.
+func Percentiles(values []float64, ps []float64, interpolation Interpolation) []float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	out := make([]float64, len(ps))
+	for i, p := range ps {
+		out[i] = percentileSorted(sorted, p, interpolation)
+	}
+	return out
+}
+
+func percentileSorted(sorted []float64, p float64, interpolation Interpolation) float64 {
+	n := len(sorted)
+	if n == 0 || p < 0 || p > 100 || math.IsNaN(p) {
+		return math.NaN()
+	}
+	pos := p / 100 * float64(n-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	frac := pos - float64(lo)
+	switch interpolation {
+	case InterpolationLower:
+		return sorted[lo]
+	case InterpolationHigher:
+		return sorted[hi]
+	case InterpolationNearest:
+		// round half to even like numpy
+		return sorted[int(math.RoundToEven(pos))]
+	case InterpolationMidpoint:
+		return (sorted[lo] + sorted[hi]) / 2
+	default:
+		return sorted[lo] + (sorted[hi]-sorted[lo])*frac
+	}
+}
+
+// centralMoment returns Σ(v-mean)^k / n.
+func centralMoment(values []float64, k int) float64 {
+	m := Mean(values)
+	sum := 0.
+	for _, v := range values {
+		sum += math.Pow(v-m, float64(k))
+	}
+	return sum / float64(len(values))
+}
+
+// Skewness (偏度), it is the biased population skewness like scipy.stats.skew(bias=True).
+func Skewness(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	m2 := centralMoment(values, 2)
+	return centralMoment(values, 3) / math.Pow(m2, 1.5)
+}
+
+// Kurtosis (峰度), it is the biased excess kurtosis like scipy.stats.kurtosis(fisher=True, bias=True),
+// normal distribution has 0.
+func Kurtosis(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	m2 := centralMoment(values, 2)
+	return centralMoment(values, 4)/(m2*m2) - 3
+}
+
+// Covariance (协方差), ddof works like Std.
+func Covariance(x, y []float64, ddof int) (float64, error) {
+	if len(x) != len(y) {
+		return 0, gerrors.New("x length %d != y length %d", len(x), len(y))
+	}
+	if len(x)-ddof <= 0 {
+		return math.NaN(), nil
+	}
+	mx, my := Mean(x), Mean(y)
+	sum := 0.
+	for i := range x {
+		sum += (x[i] - mx) * (y[i] - my)
+	}
+	return sum / float64(len(x)-ddof), nil
+}
+
+// Correlation (皮尔逊相关系数) returns Pearson correlation coefficient.
+func Correlation(x, y []float64) (float64, error) {
+	cov, err := Covariance(x, y, 0)
+	if err != nil {
+		return 0, err
+	}
+	return cov / (Std(x, 0) * Std(y, 0)), nil
+}
+
+// LinearRegression (线性回归) fits y = slope*x + intercept by least squares, r2 is the coefficient of determination.
+func LinearRegression(x, y []float64) (slope, intercept, r2 float64, err error) {
+	if len(x) != len(y) {
+		return 0, 0, 0, gerrors.New("x length %d != y length %d", len(x), len(y))
+	}
+	if len(x) < 2 {
+		return 0, 0, 0, gerrors.New("linear regression needs at least 2 points")
+	}
+	mx, my := Mean(x), Mean(y)
+	sxx, sxy, syy := 0., 0., 0.
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, 0, 0, gerrors.New("all x are equal")
+	}
+	slope = sxy / sxx
+	intercept = my - slope*mx
+	r2 = 1.
+	if syy != 0 {
+		r2 = sxy * sxy / (sxx * syy)
+	}
+	return slope, intercept, r2, nil
+}
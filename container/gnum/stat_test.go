@@ -0,0 +1,165 @@
+package gnum
+
+import (
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	cases := map[Interpolation]float64{
+		InterpolationLinear:   2.2,
+		InterpolationLower:    2,
+		InterpolationHigher:   3,
+		InterpolationNearest:  2,
+		InterpolationMidpoint: 2.5,
+	}
+	for interpolation, expect := range cases {
+		got := Percentile(values, 40, interpolation)
+		gtest.AssertTrue(t, almostEqual(got, expect, 1e-12), "Percentile(40, %d) got %f but expect %f", interpolation, got, expect)
+	}
+	gtest.AssertTrue(t, Median(values) == 2.5 && Median([]float64{3, 1, 2}) == 2, "Median failed")
+	gtest.AssertTrue(t, math.IsNaN(Percentile(nil, 50, InterpolationLinear)), "Percentile of empty should be NaN")
+	ps := Percentiles(values, []float64{0, 100}, InterpolationLinear)
+	gtest.AssertTrue(t, ps[0] == 1 && ps[1] == 4, "Percentiles got %v", ps)
+}
+
+func TestMoments(t *testing.T) {
+	values := []float64{1, 2, 3, 10}
+	// reference values are the same as scipy.stats.skew and scipy.stats.kurtosis
+	gtest.AssertTrue(t, almostEqual(Skewness(values), 1.0182337649086284, 1e-12), "Skewness got %f", Skewness(values))
+	gtest.AssertTrue(t, almostEqual(Kurtosis(values), -0.7696, 1e-12), "Kurtosis got %f", Kurtosis(values))
+
+	x := []float64{1, 2, 3, 4, 5}
+	y := []float64{2, 4, 5, 4, 5}
+	cov, err := Covariance(x, y, 1)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, almostEqual(cov, 1.5, 1e-12), "Covariance got %f", cov)
+	corr, err := Correlation(x, y)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, almostEqual(corr, 0.7745966692414834, 1e-12), "Correlation got %f", corr)
+	slope, intercept, r2, err := LinearRegression(x, y)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, almostEqual(slope, 0.6, 1e-12) && almostEqual(intercept, 2.2, 1e-12) && almostEqual(r2, 0.6, 1e-12),
+		"LinearRegression got %f %f %f", slope, intercept, r2)
+	_, err = Covariance(x, y[:2], 0)
+	gtest.AssertTrue(t, err != nil, "Covariance of different lengths should fail")
+}
+
+func TestWelford(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = rnd.ExpFloat64() * 10
+	}
+	var all Welford
+	parts := []*Welford{NewWelford(), NewWelford(), NewWelford()}
+	for i, v := range values {
+		all.Add(v)
+		parts[i%3].Add(v)
+	}
+	merged := NewWelford()
+	for _, p := range parts {
+		merged.Merge(p)
+	}
+	for _, w := range []*Welford{&all, merged} {
+		gtest.AssertTrue(t, w.Count() == 1000, "Count got %d", w.Count())
+		gtest.AssertTrue(t, almostEqual(w.Mean(), Mean(values), 1e-9), "Mean got %f", w.Mean())
+		gtest.AssertTrue(t, almostEqual(w.Std(1), Std(values, 1), 1e-9), "Std got %f", w.Std(1))
+		gtest.AssertTrue(t, almostEqual(w.Skewness(), Skewness(values), 1e-9), "Skewness got %f", w.Skewness())
+		gtest.AssertTrue(t, almostEqual(w.Kurtosis(), Kurtosis(values), 1e-9), "Kurtosis got %f", w.Kurtosis())
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	gtest.AssertTrue(t, merged.Min() == sorted[0] && merged.Max() == sorted[999], "Min/Max failed")
+}
+
+func TestDDSketch(t *testing.T) {
+	const accuracy = 0.01
+	rnd := rand.New(rand.NewSource(2))
+	values := make([]float64, 100000)
+	for i := range values {
+		values[i] = math.Exp(rnd.NormFloat64()*2) - 0.5 // mostly positive latencies with some negatives
+	}
+
+	// per goroutine sketches merged into one
+	sketches := make([]*DDSketch, 4)
+	wg := sync.WaitGroup{}
+	for g := range sketches {
+		s, err := NewDDSketch(accuracy, 0)
+		gtest.Assert(t, err)
+		sketches[g] = s
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g; i < len(values); i += len(sketches) {
+				s.Add(values[i])
+			}
+		}()
+	}
+	wg.Wait()
+	for _, s := range sketches[1:] {
+		gtest.Assert(t, sketches[0].Merge(s))
+	}
+	s := sketches[0]
+
+	sort.Float64s(values)
+	gtest.AssertTrue(t, s.Count() == uint64(len(values)), "Count got %d", s.Count())
+	for _, q := range []float64{0, 0.01, 0.25, 0.5, 0.9, 0.99, 0.999, 1} {
+		expect := values[int(q*float64(len(values)-1))]
+		got := s.Quantile(q)
+		gtest.AssertTrue(t, math.Abs(got-expect) <= accuracy*math.Abs(expect)+1e-12, "Quantile(%f) got %f but expect %f", q, got, expect)
+	}
+
+	other, err := NewDDSketch(0.02, 0)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, s.Merge(other) != nil, "Merge of different accuracy should fail")
+
+	bounded, err := NewDDSketch(accuracy, 400)
+	gtest.Assert(t, err)
+	for _, v := range values {
+		bounded.Add(v)
+	}
+	gtest.AssertTrue(t, len(bounded.positive) <= 400, "bins should be bounded but got %d", len(bounded.positive))
+	expect := values[int(0.99*float64(len(values)-1))]
+	gtest.AssertTrue(t, math.Abs(bounded.Quantile(0.99)-expect) <= accuracy*expect, "bounded p99 got %f", bounded.Quantile(0.99))
+}
+
+func TestHyperLogLog(t *testing.T) {
+	a, err := NewHyperLogLog(14)
+	gtest.Assert(t, err)
+	b, err := NewHyperLogLog(14)
+	gtest.Assert(t, err)
+	for i := 0; i < 60000; i++ {
+		a.AddString(fmt.Sprintf("user-%d", i))
+	}
+	for i := 40000; i < 100000; i++ {
+		b.AddString(fmt.Sprintf("user-%d", i))
+	}
+	gtest.AssertTrue(t, math.Abs(float64(a.Count())-60000)/60000 < 0.03, "Count got %d", a.Count())
+	gtest.Assert(t, a.Merge(b))
+	gtest.AssertTrue(t, math.Abs(float64(a.Count())-100000)/100000 < 0.03, "merged Count got %d", a.Count())
+
+	data, err := a.MarshalBinary()
+	gtest.Assert(t, err)
+	var c HyperLogLog
+	gtest.Assert(t, c.UnmarshalBinary(data))
+	gtest.AssertTrue(t, c.Count() == a.Count(), "unmarshaled Count got %d", c.Count())
+
+	small, err := NewHyperLogLog(10)
+	gtest.Assert(t, err)
+	for i := 0; i < 100; i++ {
+		small.AddString(fmt.Sprint(i % 50))
+	}
+	gtest.AssertTrue(t, math.Abs(float64(small.Count())-50) <= 2, "small range Count got %d", small.Count())
+	gtest.AssertTrue(t, a.Merge(small) != nil, "Merge of different precision should fail")
+}
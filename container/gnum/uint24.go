@@ -53,4 +53,46 @@ func (u Uint24) ToBytes() []byte {
 		return nil
 	}
 	return buf.Bytes()
-}
\ No newline at end of file
+}
+
+// AddChecked returns u+v, or ErrOverflow if the result exceeds MaxUint24.
+func (u Uint24) AddChecked(v Uint24) (Uint24, error) {
+	r := u.Uint32() + v.Uint32()
+	if r > MaxUint24 {
+		return NewUint24(r & MaxUint24), ErrOverflow
+	}
+	return NewUint24(r), nil
+}
+
+// SubChecked returns u-v, or ErrOverflow if v > u.
+func (u Uint24) SubChecked(v Uint24) (Uint24, error) {
+	r := u.Uint32() - v.Uint32()
+	if v.Uint32() > u.Uint32() {
+		return NewUint24(r & MaxUint24), ErrOverflow
+	}
+	return NewUint24(r), nil
+}
+
+// MulChecked returns u*v, or ErrOverflow if the result exceeds MaxUint24.
+func (u Uint24) MulChecked(v Uint24) (Uint24, error) {
+	r := uint64(u.Uint32()) * uint64(v.Uint32())
+	if r > MaxUint24 {
+		return NewUint24(uint32(r & MaxUint24)), ErrOverflow
+	}
+	return NewUint24(uint32(r)), nil
+}
+
+// AddSat returns u+v saturated to MaxUint24.
+func (u Uint24) AddSat(v Uint24) Uint24 {
+	return NewUint24(Min(u.Uint32()+v.Uint32(), MaxUint24))
+}
+
+// SubSat returns u-v saturated to 0.
+func (u Uint24) SubSat(v Uint24) Uint24 {
+	return NewUint24(SubSat(u.Uint32(), v.Uint32()))
+}
+
+// MulSat returns u*v saturated to MaxUint24.
+func (u Uint24) MulSat(v Uint24) Uint24 {
+	return NewUint24(uint32(Min(uint64(u.Uint32())*uint64(v.Uint32()), MaxUint24)))
+}
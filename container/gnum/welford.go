@@ -0,0 +1,123 @@
+package gnum
+
+import (
+	"math"
+)
+
+// Welford is a streaming accumulator of count, mean, variance, skewness, kurtosis, min and max,
+// it keeps no sample and zero value is ready to use. It is not safe for concurrent use,
+// give every goroutine its own accumulator and Merge them.
+type Welford struct {
+	n    float64
+	mean float64
+	m2   float64 // sums of powers of differences from mean
+	m3   float64
+	m4   float64
+	min  float64
+	max  float64
+}
+
+func NewWelford() *Welford {
+	return &Welford{}
+}
+
+// Add adds one sample.
+func (w *Welford) Add(v float64) {
+	n1 := w.n
+	w.n++
+	delta := v - w.mean
+	deltaN := delta / w.n
+	deltaN2 := deltaN * deltaN
+	term1 := delta * deltaN * n1
+	w.mean += deltaN
+	w.m4 += term1*deltaN2*(w.n*w.n-3*w.n+3) + 6*deltaN2*w.m2 - 4*deltaN*w.m3
+	w.m3 += term1*deltaN*(w.n-2) - 3*deltaN*w.m2
+	w.m2 += term1
+	if n1 == 0 {
+		w.min, w.max = v, v
+	} else {
+		w.min = math.Min(w.min, v)
+		w.max = math.Max(w.max, v)
+	}
+}
+
+// Merge adds all samples of other into w, other is not changed.
+func (w *Welford) Merge(other *Welford) {
+	if other.n == 0 {
+		return
+	}
+	if w.n == 0 {
+		*w = *other
+		return
+	}
+	na, nb := w.n, other.n
+	n := na + nb
+	delta := other.mean - w.mean
+	delta2 := delta * delta
+	delta3 := delta * delta2
+	delta4 := delta2 * delta2
+
+	m2 := w.m2 + other.m2 + delta2*na*nb/n
+	m3 := w.m3 + other.m3 + delta3*na*nb*(na-nb)/(n*n) +
+		3*delta*(na*other.m2-nb*w.m2)/n
+	m4 := w.m4 + other.m4 + delta4*na*nb*(na*na-na*nb+nb*nb)/(n*n*n) +
+		6*delta2*(na*na*other.m2+nb*nb*w.m2)/(n*n) +
+		4*delta*(na*other.m3-nb*w.m3)/n
+
+	w.mean += delta * nb / n
+	w.n, w.m2, w.m3, w.m4 = n, m2, m3, m4
+	w.min = math.Min(w.min, other.min)
+	w.max = math.Max(w.max, other.max)
+}
+
+func (w *Welford) Count() uint64 { return uint64(w.n) }
+
+func (w *Welford) Mean() float64 {
+	if w.n == 0 {
+		return math.NaN()
+	}
+	return w.mean
+}
+
+// Var returns variance, ddof works like Std.
+func (w *Welford) Var(ddof int) float64 {
+	if w.n-float64(ddof) <= 0 {
+		return math.NaN()
+	}
+	return w.m2 / (w.n - float64(ddof))
+}
+
+// Std returns standard deviation, ddof works like Std.
+func (w *Welford) Std(ddof int) float64 {
+	return math.Sqrt(w.Var(ddof))
+}
+
+// Skewness returns the same value as Skewness of all samples.
+func (w *Welford) Skewness() float64 {
+	if w.n == 0 {
+		return math.NaN()
+	}
+	return math.Sqrt(w.n) * w.m3 / math.Pow(w.m2, 1.5)
+}
+
+// Kurtosis returns the same value as Kurtosis of all samples.
+func (w *Welford) Kurtosis() float64 {
+	if w.n == 0 {
+		return math.NaN()
+	}
+	return w.n*w.m4/(w.m2*w.m2) - 3
+}
+
+func (w *Welford) Min() float64 {
+	if w.n == 0 {
+		return math.NaN()
+	}
+	return w.min
+}
+
+func (w *Welford) Max() float64 {
+	if w.n == 0 {
+		return math.NaN()
+	}
+	return w.max
+}
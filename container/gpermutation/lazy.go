@@ -0,0 +1,231 @@
+package gpermutation
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"iter"
+	"math/big"
+	"slices"
+)
+
+// Lazy generic iterators, every yielded slice is newly allocated so it can be kept by caller.
+
+// Permute iterates all len(set)! permutations of set by Heap's algorithm,
+// every permutation differs from the previous one by a single swap.
+// The order is not lexicographic, see PermuteFrom for ranked access.
+func Permute[T any](set []T) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		a := slices.Clone(set)
+		if !yield(slices.Clone(a)) {
+			return
+		}
+		// c[i] is the loop counter of level i of the recursive version
+		c := make([]int, len(a))
+		for i := 1; i < len(a); {
+			if c[i] < i {
+				if i%2 == 0 {
+					a[0], a[i] = a[i], a[0]
+				} else {
+					a[c[i]], a[i] = a[i], a[c[i]]
+				}
+				if !yield(slices.Clone(a)) {
+					return
+				}
+				c[i]++
+				i = 1
+			} else {
+				c[i] = 0
+				i++
+			}
+		}
+	}
+}
+
+// PermuteFrom iterates permutations of set in lexicographic order of indexes like NthPermutation,
+// from the permutation of rank start, nil start means 0, so ranges of ranks can be iterated in parallel.
+// Nothing is yielded if start is out of range.
+func PermuteFrom[T any](set []T, start *big.Int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		n := len(set)
+		idx := make([]int, n)
+		for i := range idx {
+			idx[i] = i
+		}
+		if start != nil {
+			var err error
+			if idx, err = unrankPermutation(n, start); err != nil {
+				return
+			}
+		}
+		for {
+			perm := make([]T, n)
+			for i, v := range idx {
+				perm[i] = set[v]
+			}
+			if !yield(perm) {
+				return
+			}
+			// next permutation: reverse the suffix after swapping its predecessor with the next larger index
+			i := n - 2
+			for i >= 0 && idx[i] > idx[i+1] {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+			j := n - 1
+			for idx[j] < idx[i] {
+				j--
+			}
+			idx[i], idx[j] = idx[j], idx[i]
+			slices.Reverse(idx[i+1:])
+		}
+	}
+}
+
+// Product iterates the cartesian product of sets, one item from every set, the last set varies fastest.
+func Product[T any](sets ...[]T) iter.Seq[[]T] {
+	return ProductFrom(nil, sets...)
+}
+
+// ProductFrom iterates the cartesian product of sets from the tuple of rank start, nil start means 0.
+// Nothing is yielded if start is out of range.
+func ProductFrom[T any](start *big.Int, sets ...[]T) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		idx := make([]int, len(sets))
+		if start != nil {
+			var err error
+			if idx, err = unrankProduct(start, sets); err != nil {
+				return
+			}
+		}
+		for _, s := range sets {
+			if len(s) == 0 {
+				return
+			}
+		}
+		for {
+			tuple := make([]T, len(sets))
+			for i, s := range sets {
+				tuple[i] = s[idx[i]]
+			}
+			if !yield(tuple) {
+				return
+			}
+			// odometer increment
+			i := len(sets) - 1
+			for ; i >= 0; i-- {
+				if idx[i]++; idx[i] < len(sets[i]) {
+					break
+				}
+				idx[i] = 0
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
+
+// CountPermutations returns n!.
+func CountPermutations(n int) *big.Int {
+	if n < 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).MulRange(1, int64(n))
+}
+
+// CountProduct returns count of tuples of the cartesian product of sets.
+func CountProduct[T any](sets ...[]T) *big.Int {
+	r := big.NewInt(1)
+	for _, s := range sets {
+		r.Mul(r, big.NewInt(int64(len(s))))
+	}
+	return r
+}
+
+func unrankProduct[T any](rank *big.Int, sets [][]T) ([]int, error) {
+	total := CountProduct(sets...)
+	if rank.Sign() < 0 || rank.Cmp(total) >= 0 {
+		return nil, gerrors.New("product rank %s out of range [0, %s)", rank.String(), total.String())
+	}
+	idx := make([]int, len(sets))
+	r := new(big.Int).Set(rank)
+	m := new(big.Int)
+	for i := len(sets) - 1; i >= 0; i-- {
+		r.DivMod(r, big.NewInt(int64(len(sets[i]))), m)
+		idx[i] = int(m.Int64())
+	}
+	return idx, nil
+}
+
+// NthProduct returns the tuple of rank in the order of Product, rank starts from 0.
+func NthProduct[T any](rank *big.Int, sets ...[]T) ([]T, error) {
+	idx, err := unrankProduct(rank, sets)
+	if err != nil {
+		return nil, err
+	}
+	tuple := make([]T, len(sets))
+	for i, s := range sets {
+		tuple[i] = s[idx[i]]
+	}
+	return tuple, nil
+}
+
+// unrankPermutation returns indexes of the permutation of rank by factorial number system.
+func unrankPermutation(n int, rank *big.Int) ([]int, error) {
+	total := CountPermutations(n)
+	if rank.Sign() < 0 || rank.Cmp(total) >= 0 {
+		return nil, gerrors.New("permutation rank %s out of range [0, %s)", rank.String(), total.String())
+	}
+	rest := make([]int, n)
+	for i := range rest {
+		rest[i] = i
+	}
+	r := new(big.Int).Set(rank)
+	digit := new(big.Int)
+	idx := make([]int, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		// digit i of factorial number system picks one of the rest indexes
+		digit.DivMod(r, CountPermutations(i), r)
+		d := int(digit.Int64())
+		idx = append(idx, rest[d])
+		rest = slices.Delete(rest, d, d+1)
+	}
+	return idx, nil
+}
+
+// NthPermutation returns the permutation of rank in lexicographic order of indexes by factorial number system,
+// rank starts from 0.
+func NthPermutation[T any](set []T, rank *big.Int) ([]T, error) {
+	idx, err := unrankPermutation(len(set), rank)
+	if err != nil {
+		return nil, err
+	}
+	perm := make([]T, len(set))
+	for i, v := range idx {
+		perm[i] = set[v]
+	}
+	return perm, nil
+}
+
+// RankPermutation returns the rank of permutation of indexes 0..n-1 in lexicographic order.
+func RankPermutation(indexes []int) (*big.Int, error) {
+	n := len(indexes)
+	used := make([]bool, n)
+	rank := new(big.Int)
+	for i, idx := range indexes {
+		if idx < 0 || idx >= n || used[idx] {
+			return nil, gerrors.New("invalid permutation indexes %v", indexes)
+		}
+		// unused indexes less than idx
+		less := 0
+		for v := 0; v < idx; v++ {
+			if !used[v] {
+				less++
+			}
+		}
+		used[idx] = true
+		rank.Add(rank, new(big.Int).Mul(big.NewInt(int64(less)), CountPermutations(n-1-i)))
+	}
+	return rank, nil
+}
@@ -0,0 +1,93 @@
+package gpermutation
+
+import (
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+func TestPermute(t *testing.T) {
+	set := []int{0, 1, 2, 3, 4}
+	seen := map[string]bool{}
+	var prev []int
+	for p := range Permute(set) {
+		key := fmt.Sprint(p)
+		gtest.AssertTrue(t, !seen[key], "duplicate %s", key)
+		seen[key] = true
+		if prev != nil {
+			diff := 0
+			for i := range p {
+				if p[i] != prev[i] {
+					diff++
+				}
+			}
+			gtest.AssertTrue(t, diff == 2, "not a single swap %v -> %v", prev, p)
+		}
+		prev = p
+	}
+	gtest.AssertTrue(t, int64(len(seen)) == CountPermutations(len(set)).Int64(), "count %d", len(seen))
+	gtest.AssertTrue(t, len(slices.Collect(Permute([]int{}))) == 1, "empty set")
+	gtest.AssertTrue(t, CountPermutations(25).String() == "15511210043330985984000000", "big count")
+
+	// stop early on 25 items
+	n := 0
+	for range Permute(make([]int, 25)) {
+		if n++; n == 100 {
+			break
+		}
+	}
+	gtest.AssertTrue(t, n == 100, "lazy")
+}
+
+func TestNthPermutation(t *testing.T) {
+	set := []int{0, 1, 2, 3}
+	var all [][]int
+	for p := range Permute(set) {
+		all = append(all, p)
+	}
+	slices.SortFunc(all, slices.Compare)
+	for rank, want := range all {
+		got, err := NthPermutation(set, big.NewInt(int64(rank)))
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, slices.Equal(got, want), "nth %d: %v != %v", rank, got, want)
+		r, err := RankPermutation(want)
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, r.Int64() == int64(rank), "rank of %v: %s", want, r)
+	}
+	_, err := NthPermutation(set, big.NewInt(24))
+	gtest.AssertTrue(t, err != nil, "rank out of range")
+
+	// PermuteFrom follows the order of NthPermutation, ranges of ranks split the work
+	got := slices.Collect(PermuteFrom(set, nil))
+	gtest.AssertTrue(t, slices.EqualFunc(got, all, slices.Equal), "permute from nil %v", got)
+	var joined [][]int
+	for start := int64(0); start < 24; start += 5 {
+		for p := range PermuteFrom(set, big.NewInt(start)) {
+			if int64(len(joined)) == min(start+5, 24) {
+				break
+			}
+			joined = append(joined, p)
+		}
+	}
+	gtest.AssertTrue(t, slices.EqualFunc(joined, all, slices.Equal), "joined ranges %v", joined)
+	gtest.AssertTrue(t, len(slices.Collect(PermuteFrom(set, big.NewInt(24)))) == 0, "start out of range")
+	gtest.AssertTrue(t, len(slices.Collect(PermuteFrom([]int{}, nil))) == 1, "empty set")
+	_, err = RankPermutation([]int{0, 0, 1})
+	gtest.AssertTrue(t, err != nil, "invalid indexes")
+}
+
+func TestProduct(t *testing.T) {
+	sets := [][]string{{"a"}, {"b", "c", "d"}, {"e", "f"}}
+	all := slices.Collect(Product(sets...))
+	gtest.AssertTrue(t, int64(len(all)) == CountProduct(sets...).Int64() && len(all) == 6, "count %d", len(all))
+	for rank, want := range all {
+		got, err := NthProduct(big.NewInt(int64(rank)), sets...)
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, slices.Equal(got, want), "nth %d: %v != %v", rank, got, want)
+		rest := slices.Collect(ProductFrom(big.NewInt(int64(rank)), sets...))
+		gtest.AssertTrue(t, len(rest) == len(all)-rank && slices.Equal(rest[0], want), "from %d", rank)
+	}
+	gtest.AssertTrue(t, len(slices.Collect(Product([]int{1}, []int{}))) == 0, "empty set")
+}
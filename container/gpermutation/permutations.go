@@ -7,17 +7,16 @@ package gpermutation
 // AddKind("b", "c")
 // AddKind("d", "e")
 // ListAll() => [["a","b","d"], ["a","b","e"], ["a","c","d"], ["a","c","e"]]
+// It is the cartesian product of kinds, see Product for the lazy generic version.
 
 // reference: https://www.zhangshengrong.com/p/7B1LBqRNwp/
 
 import (
-	"github.com/davidforest123/goutil/sys/gcounter"
-	"sync/atomic"
+	"iter"
 )
 
 type Permutations struct {
-	list     [][]interface{}
-	currLoop atomic.Value
+	list [][]interface{}
 }
 
 type Result struct {
@@ -47,54 +46,15 @@ func (c *Permutations) GetCurrentLoop() int {
 	return -1
 }
 
-func (c *Permutations) ListAll() []Result {
-	// 计算结果条目总数
-	rstCount := 1
-	for _, v := range c.list {
-		rstCount *= len(v)
-	}
-
-	// 计算每条结果的元素长度
-	itemCount := len(c.list)
-
-	// 缓存，每行对应一个Kind
-	// get error: non-constant array bound itemCount
-	//cache := [rstCount][itemCount]interface{}{}
-	cache := make([][]interface{}, rstCount)
-	for i := range cache {
-		cache[i] = make([]interface{}, itemCount)
-	}
-
-	// 生成全部cache
-	for i := 0; i < itemCount; i++ {
-
-		// 计算步进
-		step := 1
-		for k := i + 1; k < len(c.list); k++ {
-			step *= len(c.list[k])
-		}
-
-		// 根据步进创建步进累加器
-		sa, err := gcounter.NewStepRecycleAccumulator(0, int64(len(c.list[i])-1), int64(step))
-		if err != nil {
-			panic(err)
-			return nil
-		}
-
-		// 填充cache
-		for j := 0; j < rstCount; j++ {
-			cache[j][i] = c.list[i][sa.Get()]
-			sa.Incr()
-		}
-
-	}
+// All iterates all combinations lazily.
+func (c *Permutations) All() iter.Seq[[]interface{}] {
+	return Product(c.list...)
+}
 
-	// 转换格式
+func (c *Permutations) ListAll() []Result {
 	var rstList []Result
-	for i := 0; i < rstCount; i++ {
-		rstItem := Result{}
-		rstItem.Items = cache[i]
-		rstList = append(rstList, rstItem)
+	for items := range c.All() {
+		rstList = append(rstList, Result{Items: items})
 	}
 	return rstList
 }
@@ -0,0 +1,100 @@
+package gpoly
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// FitResult is the least squares polynomial fitting result with residual statistics.
+type FitResult struct {
+	Coefficients []float64 // ascending order, y = c[0] + c[1]x + c[2]x² + ...
+	Residuals    []float64 // y - fitted y of every sample
+	RSquared     float64   // 决定系数, 1 - SSres/SStot, 1 means perfect fit
+	AdjRSquared  float64   // R² adjusted by degrees of freedom, NaN if there are not enough samples
+	StdError     float64   // residual standard error sqrt(SSres / (n - degree - 1)), NaN if there are not enough samples
+	CoefStdErrs  []float64 // standard errors of Coefficients, NaN if there are not enough samples
+}
+
+// Fit fits a polynomial of degree to samples by least squares with QR decomposition,
+// which is more stable than normal equations of PolyFit.
+func Fit(xs, ys []float64, degree int) (*FitResult, error) {
+	n, m := len(xs), degree+1
+	if len(ys) != n {
+		return nil, gerrors.New("length of xs %d != length of ys %d", n, len(ys))
+	}
+	if degree < 0 || n < m {
+		return nil, gerrors.New("%d samples are not enough to fit degree %d", n, degree)
+	}
+	x := mat.NewDense(n, m, nil)
+	for i, xi := range xs {
+		v := 1.0
+		for j := 0; j < m; j++ {
+			x.Set(i, j, v)
+			v *= xi
+		}
+	}
+	var qr mat.QR
+	qr.Factorize(x)
+	var beta mat.Dense
+	if err := qr.SolveTo(&beta, false, mat.NewDense(n, 1, ys)); err != nil {
+		return nil, gerrors.Wrap(err, "solve least squares")
+	}
+
+	r := &FitResult{Coefficients: make([]float64, m), Residuals: make([]float64, n), CoefStdErrs: make([]float64, m)}
+	for j := range r.Coefficients {
+		r.Coefficients[j] = beta.At(j, 0)
+	}
+	mean := 0.0
+	for _, y := range ys {
+		mean += y
+	}
+	mean /= float64(n)
+	ssRes, ssTot := 0.0, 0.0
+	for i, y := range ys {
+		r.Residuals[i] = y - Eval(r.Coefficients, xs[i])
+		ssRes += r.Residuals[i] * r.Residuals[i]
+		ssTot += (y - mean) * (y - mean)
+	}
+	r.RSquared = 1.0
+	if ssTot > 0 {
+		r.RSquared = 1 - ssRes/ssTot
+	}
+
+	dof := n - m
+	r.AdjRSquared, r.StdError = math.NaN(), math.NaN()
+	for j := range r.CoefStdErrs {
+		r.CoefStdErrs[j] = math.NaN()
+	}
+	if dof <= 0 {
+		return r, nil
+	}
+	if n > 1 {
+		r.AdjRSquared = 1 - (1-r.RSquared)*float64(n-1)/float64(dof)
+	}
+	variance := ssRes / float64(dof)
+	r.StdError = math.Sqrt(variance)
+	// covariance of coefficients is variance * (XᵀX)⁻¹
+	var xtx, inv mat.Dense
+	xtx.Mul(x.T(), x)
+	if err := inv.Inverse(&xtx); err == nil {
+		for j := range r.CoefStdErrs {
+			r.CoefStdErrs[j] = math.Sqrt(variance * inv.At(j, j))
+		}
+	}
+	return r, nil
+}
+
+// Predict evaluates the fitted polynomial at x.
+func (r *FitResult) Predict(x float64) float64 {
+	return Eval(r.Coefficients, x)
+}
+
+// Eval evaluates polynomial of ascending coefficients at x by Horner's method.
+func Eval(coefficients []float64, x float64) float64 {
+	y := 0.0
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = y*x + coefficients[i]
+	}
+	return y
+}
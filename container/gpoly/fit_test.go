@@ -0,0 +1,98 @@
+package gpoly
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"testing"
+)
+
+func near(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol
+}
+
+func TestFit(t *testing.T) {
+	// y = 1 + 2x + 3x² exactly
+	xs := []float64{-2, -1, 0, 1, 2, 3}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = 1 + 2*x + 3*x*x
+	}
+	r, err := Fit(xs, ys, 2)
+	gtest.Assert(t, err)
+	for i, want := range []float64{1, 2, 3} {
+		gtest.AssertTrue(t, near(r.Coefficients[i], want, 1e-9), "coefficient %d = %v", i, r.Coefficients[i])
+	}
+	gtest.AssertTrue(t, near(r.RSquared, 1, 1e-12) && r.StdError < 1e-9 && near(r.Predict(4), 57, 1e-9), "%+v", r)
+
+	// linear regression with known statistics
+	xs = []float64{1, 2, 3, 4, 5}
+	ys = []float64{2, 4, 5, 4, 5}
+	r, err = Fit(xs, ys, 1)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, near(r.Coefficients[0], 2.2, 1e-9) && near(r.Coefficients[1], 0.6, 1e-9), "coefficients %v", r.Coefficients)
+	gtest.AssertTrue(t, near(r.RSquared, 0.6, 1e-9) && near(r.AdjRSquared, 0.4666666666666667, 1e-9), "r² %v adj %v", r.RSquared, r.AdjRSquared)
+	gtest.AssertTrue(t, near(r.StdError, math.Sqrt(2.4/3), 1e-9) && near(r.CoefStdErrs[1], math.Sqrt(0.8/10), 1e-9), "std errors %v %v", r.StdError, r.CoefStdErrs)
+
+	_, err = Fit([]float64{1, 2}, []float64{1, 2}, 2)
+	gtest.AssertTrue(t, err != nil, "not enough samples")
+	r, err = Fit([]float64{1, 2}, []float64{1, 3}, 1)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, math.IsNaN(r.StdError), "no degrees of freedom")
+}
+
+func TestInterpolators(t *testing.T) {
+	xs := []float64{0, 1, 2, 3, 4, 5}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = math.Sin(x)
+	}
+	lin, err := NewLinear(xs, ys)
+	gtest.Assert(t, err)
+	spline, err := NewCubicSpline(xs, ys)
+	gtest.Assert(t, err)
+	akima, err := NewAkima(xs, ys)
+	gtest.Assert(t, err)
+	for _, it := range []Interpolator{lin, spline, akima} {
+		for i, x := range xs {
+			gtest.AssertTrue(t, near(it.At(x), ys[i], 1e-12), "%T at knot %v", it, x)
+		}
+	}
+	gtest.AssertTrue(t, near(lin.At(0.5), (ys[0]+ys[1])/2, 1e-12), "linear")
+	gtest.AssertTrue(t, near(spline.At(2.5), math.Sin(2.5), 0.02) && near(akima.At(2.5), math.Sin(2.5), 0.05), "smooth %v %v", spline.At(2.5), akima.At(2.5))
+
+	// splines reproduce straight lines, Akima doesn't overshoot on a step
+	line, _ := NewCubicSpline([]float64{0, 1, 3, 4}, []float64{1, 3, 7, 9})
+	gtest.AssertTrue(t, near(line.At(2), 5, 1e-12), "spline line %v", line.At(2))
+	step, _ := NewAkima([]float64{0, 1, 2, 3, 4, 5}, []float64{0, 0, 0, 1, 1, 1})
+	for x := 0.0; x <= 5; x += 0.1 {
+		gtest.AssertTrue(t, step.At(x) >= -1e-12 && step.At(x) <= 1+1e-12, "akima overshoot at %v: %v", x, step.At(x))
+	}
+	_, err = NewLinear([]float64{0, 0}, []float64{1, 2})
+	gtest.AssertTrue(t, err != nil, "xs not increasing")
+}
+
+func TestRoots(t *testing.T) {
+	f := func(x float64) float64 { return x*x*x - 2*x - 5 }
+	df := func(x float64) float64 { return 3*x*x - 2 }
+	const root = 2.0945514815423265
+	x, err := Newton(f, df, 2, 1e-12, 50)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, near(x, root, 1e-10), "newton %v", x)
+	x, err = Brent(f, 0, 3, 1e-12)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, near(x, root, 1e-10), "brent %v", x)
+	x, err = Brent(math.Cos, 0, 3, 1e-12)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, near(x, math.Pi/2, 1e-10), "brent cos %v", x)
+	// tol below ULP of root is limited by machine epsilon
+	x, err = Brent(func(x float64) float64 { return x*x - 2 }, 0, 2, 0)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, near(x, math.Sqrt2, 1e-15), "brent sqrt2 %v", x)
+	x, err = Brent(func(x float64) float64 { return math.Log(x) - math.Log(1e6+0.3) }, 1, 2e6, 1e-12)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, near(x, 1e6+0.3, 1e-9), "brent 1e6 %v", x)
+	_, err = Brent(f, 3, 4, 1e-12)
+	gtest.AssertTrue(t, err != nil, "same sign")
+	_, err = Newton(func(x float64) float64 { return x*x + 1 }, func(x float64) float64 { return 2 * x }, 0, 1e-12, 50)
+	gtest.AssertTrue(t, err != nil, "zero derivative")
+}
@@ -0,0 +1,148 @@
+package gpoly
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+	"slices"
+	"sort"
+)
+
+/*
+插值 Interpolation
+Linear 分段线性插值
+CubicSpline 自然三次样条插值, second derivatives are 0 at both ends
+Akima Akima 插值, it has less overshoot than cubic spline near outliers
+Values out of [xs[0], xs[n-1]] are extrapolated with the first or last piece.
+*/
+
+type (
+	Interpolator interface {
+		At(x float64) float64
+	}
+
+	Linear struct {
+		xs, ys []float64
+	}
+
+	// CubicSpline is natural cubic spline.
+	CubicSpline struct {
+		xs, ys []float64
+		m      []float64 // second derivatives at xs
+	}
+
+	// Akima is piecewise cubic Hermite interpolation with Akima's derivatives.
+	Akima struct {
+		xs, ys []float64
+		t      []float64 // first derivatives at xs
+	}
+)
+
+func checkSamples(xs, ys []float64, minLen int) error {
+	if len(xs) != len(ys) {
+		return gerrors.New("length of xs %d != length of ys %d", len(xs), len(ys))
+	}
+	if len(xs) < minLen {
+		return gerrors.New("at least %d samples are required, got %d", minLen, len(xs))
+	}
+	for i := 1; i < len(xs); i++ {
+		if !(xs[i] > xs[i-1]) {
+			return gerrors.New("xs must be strictly increasing, xs[%d] = %v, xs[%d] = %v", i-1, xs[i-1], i, xs[i])
+		}
+	}
+	return nil
+}
+
+// segment returns i that x is in [xs[i], xs[i+1]), it is clamped to the first or last segment.
+func segment(xs []float64, x float64) int {
+	i := sort.SearchFloat64s(xs, x) - 1
+	return min(max(i, 0), len(xs)-2)
+}
+
+func NewLinear(xs, ys []float64) (*Linear, error) {
+	if err := checkSamples(xs, ys, 2); err != nil {
+		return nil, err
+	}
+	return &Linear{xs: slices.Clone(xs), ys: slices.Clone(ys)}, nil
+}
+
+func (l *Linear) At(x float64) float64 {
+	i := segment(l.xs, x)
+	t := (x - l.xs[i]) / (l.xs[i+1] - l.xs[i])
+	return l.ys[i] + t*(l.ys[i+1]-l.ys[i])
+}
+
+func NewCubicSpline(xs, ys []float64) (*CubicSpline, error) {
+	if err := checkSamples(xs, ys, 2); err != nil {
+		return nil, err
+	}
+	n := len(xs)
+	s := &CubicSpline{xs: slices.Clone(xs), ys: slices.Clone(ys), m: make([]float64, n)}
+	if n == 2 {
+		return s, nil
+	}
+	// tridiagonal system of m[1..n-2] solved by Thomas algorithm, m[0] = m[n-1] = 0
+	c := make([]float64, n)
+	d := make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		h0, h1 := xs[i]-xs[i-1], xs[i+1]-xs[i]
+		a, b := h0, 2*(h0+h1)
+		r := 6 * ((ys[i+1]-ys[i])/h1 - (ys[i]-ys[i-1])/h0)
+		if i > 1 {
+			b -= a * c[i-1]
+			r -= a * d[i-1]
+		}
+		c[i] = h1 / b
+		d[i] = r / b
+	}
+	for i := n - 2; i >= 1; i-- {
+		s.m[i] = d[i] - c[i]*s.m[i+1]
+	}
+	return s, nil
+}
+
+func (s *CubicSpline) At(x float64) float64 {
+	i := segment(s.xs, x)
+	h := s.xs[i+1] - s.xs[i]
+	a, b := (s.xs[i+1]-x)/h, (x-s.xs[i])/h
+	return a*s.ys[i] + b*s.ys[i+1] + ((a*a*a-a)*s.m[i]+(b*b*b-b)*s.m[i+1])*h*h/6
+}
+
+func NewAkima(xs, ys []float64) (*Akima, error) {
+	if err := checkSamples(xs, ys, 2); err != nil {
+		return nil, err
+	}
+	n := len(xs)
+	// slopes of segments with 2 extra ones at both ends, m[k+2] is the slope of segment k
+	m := make([]float64, n+3)
+	for k := 0; k < n-1; k++ {
+		m[k+2] = (ys[k+1] - ys[k]) / (xs[k+1] - xs[k])
+	}
+	if n == 2 {
+		m[1], m[0], m[4], m[3] = m[2], m[2], m[2], m[2]
+	} else {
+		m[1] = 2*m[2] - m[3]
+		m[0] = 2*m[1] - m[2]
+		m[n+1] = 2*m[n] - m[n-1]
+		m[n+2] = 2*m[n+1] - m[n]
+	}
+	a := &Akima{xs: slices.Clone(xs), ys: slices.Clone(ys), t: make([]float64, n)}
+	for i := range a.t {
+		// slopes m[i], m[i+1] are before point i, m[i+2], m[i+3] are after it
+		w1, w2 := math.Abs(m[i+3]-m[i+2]), math.Abs(m[i+1]-m[i])
+		if w1+w2 == 0 {
+			a.t[i] = (m[i+1] + m[i+2]) / 2
+		} else {
+			a.t[i] = (w1*m[i+1] + w2*m[i+2]) / (w1 + w2)
+		}
+	}
+	return a, nil
+}
+
+func (a *Akima) At(x float64) float64 {
+	i := segment(a.xs, x)
+	h := a.xs[i+1] - a.xs[i]
+	t := (x - a.xs[i]) / h
+	// cubic Hermite basis
+	t2, t3 := t*t, t*t*t
+	return (2*t3-3*t2+1)*a.ys[i] + (t3-2*t2+t)*h*a.t[i] + (-2*t3+3*t2)*a.ys[i+1] + (t3-t2)*h*a.t[i+1]
+}
@@ -3,7 +3,6 @@ package gpoly
 import (
 	"github.com/davidforest123/goutil/container/ggeometry"
 	"github.com/davidforest123/goutil/container/gpoly/polyfit"
-	"math"
 )
 
 /*
@@ -14,7 +13,7 @@ A=polyfit(x,y,2);
 z=polyval(A,x);
 */
 
-// 多项式曲线拟合函数，也就是离散点拟合直线
+// 多项式曲线拟合函数，也就是离散点拟合直线, see Fit for residual statistics
 // xs: 横坐标值
 // ys：纵坐标值
 // degree：拟合多项式的次数
@@ -27,23 +26,11 @@ func PolyVal(xs []float64, coefficients []float64) []float64 {
 	ys := make([]float64, len(xs))
 
 	for i := 0; i < len(ys); i++ {
-		ys[i] = evalPoly(xs[i], coefficients)
+		ys[i] = Eval(coefficients, xs[i])
 	}
 	return ys
 }
 
-// x: 横坐标值
-// coeffs: PolyFit计算出的系数
-// Evaluate a polynomial at a point
-func evalPoly(x float64, coeffs []float64) float64 {
-	ret := 0.0
-
-	for i, coefficient := range coeffs {
-		ret += math.Pow(x, float64(i)) * coefficient
-	}
-	return ret
-}
-
 // 等间距离散点拟合直线的夹角
 func PolyAngle(ys []float64) float64 {
 	xs := make([]float64, len(ys))
@@ -0,0 +1,104 @@
+package gpoly
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+)
+
+// 求根 Root finding
+
+// Newton finds a root of f from x0 by Newton's method with derivative df,
+// it converges fast near a simple root but may diverge from a bad x0.
+func Newton(f, df func(x float64) float64, x0, tol float64, maxIter int) (float64, error) {
+	x := x0
+	for i := 0; i < maxIter; i++ {
+		fx := f(x)
+		if fx == 0 {
+			return x, nil
+		}
+		d := df(x)
+		if d == 0 || math.IsNaN(d) {
+			return x, gerrors.New("zero derivative at %v", x)
+		}
+		next := x - fx/d
+		if math.IsNaN(next) || math.IsInf(next, 0) {
+			return x, gerrors.New("newton diverges at %v", x)
+		}
+		if math.Abs(next-x) <= tol {
+			return next, nil
+		}
+		x = next
+	}
+	return x, gerrors.New("newton doesn't converge in %d iterations", maxIter)
+}
+
+// Brent finds a root of f in [a, b] by Brent's method, f(a) and f(b) must have different signs.
+// It combines bisection, secant and inverse quadratic interpolation, so it always converges.
+func Brent(f func(x float64) float64, a, b, tol float64) (float64, error) {
+	fa, fb := f(a), f(b)
+	if fa == 0 {
+		return a, nil
+	}
+	if fb == 0 {
+		return b, nil
+	}
+	if fa*fb > 0 {
+		return 0, gerrors.New("f(%v) = %v and f(%v) = %v have the same sign", a, fa, b, fb)
+	}
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for i := 0; i < 200; i++ {
+		if fb*fc > 0 {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+		tol1 := 2*0x1p-52*math.Abs(b) + tol/2 // 0x1p-52 is machine epsilon
+		xm := (c - b) / 2
+		if math.Abs(xm) <= tol1 || fb == 0 {
+			return b, nil
+		}
+		if math.Abs(e) >= tol1 && math.Abs(fa) > math.Abs(fb) {
+			// try interpolation
+			s := fb / fa
+			var p, q float64
+			if a == c {
+				// secant
+				p = 2 * xm * s
+				q = 1 - s
+			} else {
+				// inverse quadratic interpolation
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*xm*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			}
+			p = math.Abs(p)
+			if 2*p < min(3*xm*q-math.Abs(tol1*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d, e = xm, xm
+			}
+		} else {
+			// bisection
+			d, e = xm, xm
+		}
+		a, fa = b, fb
+		if math.Abs(d) > tol1 {
+			b += d
+		} else {
+			b += math.Copysign(tol1, xm)
+		}
+		fb = f(b)
+	}
+	return b, gerrors.New("brent doesn't converge")
+}
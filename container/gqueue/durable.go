@@ -0,0 +1,254 @@
+package gqueue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/sys/gfs"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+type (
+	// Codec converts queue items to bytes for durable queue.
+	Codec[T any] interface {
+		Encode(v T) ([]byte, error)
+		Decode(b []byte) (T, error)
+	}
+
+	// JsonCodec encodes items with encoding/json.
+	JsonCodec[T any] struct{}
+
+	// wal is an append-only write-ahead log of push and pop operations.
+	//
+	// Record layout, all integers are big endian:
+	//	op u8 | payload length u32 | payload | crc32(IEEE) of op, length and payload u32
+	wal[T any] struct {
+		path  string
+		f     *os.File
+		codec Codec[T]
+		sync  bool
+		pops  int // pop records since last compaction
+	}
+)
+
+const (
+	walOpPush byte = 1
+	walOpPop  byte = 2
+
+	walCompactMinPops = 1024
+)
+
+func (JsonCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JsonCodec[T]) Decode(b []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(b, &v)
+	return v, err
+}
+
+// NewDurableQueue opens or creates a FIFO queue backed by a write-ahead log at path,
+// items pushed and not popped before process exit are restored on next open.
+// If syncEveryWrite is true every operation is fsynced, which survives OS crash but is much slower,
+// otherwise the log survives process crash and is fsynced on Sync and Close.
+// If codec is nil, JsonCodec is used.
+func NewDurableQueue[T any](path string, codec Codec[T], limit uint64, policy OverflowPolicy, syncEveryWrite bool) (*Queue[T], error) {
+	if codec == nil {
+		codec = JsonCodec[T]{}
+	}
+	r := newRing[T]()
+	w := &wal[T]{path: path, codec: codec, sync: syncEveryWrite}
+	if err := w.replay(r); err != nil {
+		return nil, err
+	}
+	// Rewrite the log with live items only, this also drops a torn record left by a crash.
+	if err := w.compact(r); err != nil {
+		return nil, err
+	}
+	q := NewQueue[T](limit, policy)
+	q.e.st = r
+	q.e.wal = w
+	return q, nil
+}
+
+// Sync commits the log of a durable queue to stable storage, it does nothing for memory queue.
+func (q *Queue[T]) Sync() error {
+	q.e.mu.Lock()
+	defer q.e.mu.Unlock()
+	if q.e.wal == nil || q.e.wal.f == nil {
+		return nil
+	}
+	return q.e.wal.f.Sync()
+}
+
+func (w *wal[T]) replay(st store[T]) error {
+	f, err := os.Open(w.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(f)
+	for off := int64(0); ; {
+		op, payload, n, err := readWalRecord(br, fi.Size()-off)
+		if err == io.EOF || err == io.ErrUnexpectedEOF || (err == errWalChecksum && off+n == fi.Size()) {
+			// Torn tail written during a crash, everything before it is valid.
+			return nil
+		}
+		if err == errWalChecksum {
+			// compaction would drop the records after the broken one
+			return gerrors.New("queue log %s is corrupted at offset %d", w.path, off)
+		}
+		if err != nil {
+			return err
+		}
+		off += n
+		switch op {
+		case walOpPush:
+			v, err := w.codec.Decode(payload)
+			if err != nil {
+				return gerrors.Wrap(err, "decode queue log item")
+			}
+			st.push(v)
+		case walOpPop:
+			if st.len() > 0 {
+				st.pop()
+			}
+		default:
+			return gerrors.New("invalid queue log op %d", op)
+		}
+	}
+}
+
+var errWalChecksum = gerrors.New("queue log checksum mismatch")
+
+// readWalRecord reads a record of n bytes, a record longer than remaining bytes of the file is torn.
+func readWalRecord(r io.Reader, remaining int64) (op byte, payload []byte, n int64, err error) {
+	hdr := [5]byte{}
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, 0, err
+	}
+	n = int64(len(hdr)) + int64(binary.BigEndian.Uint32(hdr[1:])) + 4
+	if n > remaining {
+		return 0, nil, 0, io.ErrUnexpectedEOF
+	}
+	payload = make([]byte, n-9)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, 0, err
+	}
+	sum := [4]byte{}
+	if _, err := io.ReadFull(r, sum[:]); err != nil {
+		return 0, nil, 0, err
+	}
+	crc := crc32.ChecksumIEEE(hdr[:])
+	crc = crc32.Update(crc, crc32.IEEETable, payload)
+	if crc != binary.BigEndian.Uint32(sum[:]) {
+		return 0, nil, n, errWalChecksum
+	}
+	return hdr[0], payload, n, nil
+}
+
+func encodeWalRecord(op byte, payload []byte) []byte {
+	b := make([]byte, 5+len(payload)+4)
+	b[0] = op
+	binary.BigEndian.PutUint32(b[1:5], uint32(len(payload)))
+	copy(b[5:], payload)
+	binary.BigEndian.PutUint32(b[5+len(payload):], crc32.ChecksumIEEE(b[:5+len(payload)]))
+	return b
+}
+
+func (w *wal[T]) write(rec []byte) error {
+	if w.f == nil {
+		return ErrClosed
+	}
+	if _, err := w.f.Write(rec); err != nil {
+		return err
+	}
+	if w.sync {
+		return w.f.Sync()
+	}
+	return nil
+}
+
+func (w *wal[T]) appendPush(v T) error {
+	payload, err := w.codec.Encode(v)
+	if err != nil {
+		return err
+	}
+	return w.write(encodeWalRecord(walOpPush, payload))
+}
+
+func (w *wal[T]) appendPop() error {
+	if err := w.write(encodeWalRecord(walOpPop, nil)); err != nil {
+		return err
+	}
+	w.pops++
+	return nil
+}
+
+// compactIfNeeded rewrites the log when most records are obsolete.
+func (w *wal[T]) compactIfNeeded(st store[T]) error {
+	if w.pops < walCompactMinPops || w.pops < st.len() {
+		return nil
+	}
+	return w.compact(st)
+}
+
+// compact writes live items into a new log and atomically replaces the old one.
+func (w *wal[T]) compact(st store[T]) error {
+	var buf bytes.Buffer
+	var err error
+	st.each(func(v T) {
+		if err != nil {
+			return
+		}
+		payload, e := w.codec.Encode(v)
+		if e != nil {
+			err = e
+			return
+		}
+		buf.Write(encodeWalRecord(walOpPush, payload))
+	})
+	if err != nil {
+		return err
+	}
+	if err := gfs.BytesToFileAtomic(buf.Bytes(), w.path); err != nil {
+		return err
+	}
+
+	// keep appending to the new log
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	if w.f != nil {
+		w.f.Close()
+	}
+	w.f = f
+	w.pops = 0
+	return nil
+}
+
+func (w *wal[T]) close() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Sync()
+	if e := w.f.Close(); err == nil {
+		err = e
+	}
+	w.f = nil
+	return err
+}
@@ -0,0 +1,206 @@
+package gqueue
+
+import (
+	"context"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"sync"
+	"time"
+)
+
+type (
+	// store is the underlying container of a queue, it is always accessed with engine.mu held.
+	store[T any] interface {
+		push(v T)
+		pop() T
+		peek() T
+		// evict removes the item which should be dropped when DropOldest overflow happens.
+		evict() T
+		len() int
+		each(fn func(v T))
+	}
+
+	// engine implements locking, blocking, overflow policy and persistence shared by all queues.
+	engine[T any] struct {
+		mu      sync.Mutex
+		st      store[T]
+		limit   uint64
+		policy  OverflowPolicy
+		onDrop  func(v T)
+		closed  bool
+		signal  chan struct{} // closed and replaced on every state change to wake up waiters
+		wal     *wal[T]       // nil unless durable
+		dropped uint64
+	}
+)
+
+func newEngine[T any](st store[T], limit uint64, policy OverflowPolicy) *engine[T] {
+	return &engine[T]{st: st, limit: limit, policy: policy, signal: make(chan struct{})}
+}
+
+func (e *engine[T]) broadcastLocked() {
+	close(e.signal)
+	e.signal = make(chan struct{})
+}
+
+func (e *engine[T]) fullLocked() bool {
+	return e.limit > 0 && uint64(e.st.len()) >= e.limit
+}
+
+// pushLocked tries to push v, if wait is true the caller should wait for a state change and try again.
+func (e *engine[T]) pushLocked(v T) (wait bool, dropped []T, err error) {
+	if e.closed {
+		return false, nil, ErrClosed
+	}
+	if e.fullLocked() {
+		switch e.policy {
+		case Block:
+			return true, nil, nil
+		case DropNewest:
+			e.dropped++
+			return false, []T{v}, ErrDropped
+		default:
+			for e.fullLocked() {
+				if e.wal != nil {
+					if err := e.wal.appendPop(); err != nil {
+						return false, dropped, err
+					}
+				}
+				dropped = append(dropped, e.st.evict())
+				e.dropped++
+			}
+		}
+	}
+	if e.wal != nil {
+		if err := e.wal.appendPush(v); err != nil {
+			return false, dropped, err
+		}
+	}
+	e.st.push(v)
+	e.broadcastLocked()
+	return false, dropped, nil
+}
+
+// popLocked tries to pop an item, if wait is true the caller should wait for a state change and try again.
+func (e *engine[T]) popLocked() (v T, wait bool, err error) {
+	if e.st.len() == 0 {
+		if e.closed {
+			return v, false, ErrClosed
+		}
+		return v, true, ErrEmpty
+	}
+	if e.wal != nil {
+		if err := e.wal.appendPop(); err != nil {
+			return v, false, err
+		}
+	}
+	v = e.st.pop()
+	if e.wal != nil {
+		// The log is still valid if compaction fails, it will be retried on next pop.
+		_ = e.wal.compactIfNeeded(e.st)
+	}
+	e.broadcastLocked()
+	return v, false, nil
+}
+
+func (e *engine[T]) notifyDropped(dropped []T) {
+	if e.onDrop == nil {
+		return
+	}
+	for _, v := range dropped {
+		e.onDrop(v)
+	}
+}
+
+func (e *engine[T]) tryPush(v T) error {
+	e.mu.Lock()
+	wait, dropped, err := e.pushLocked(v)
+	e.mu.Unlock()
+	e.notifyDropped(dropped)
+	if wait {
+		return ErrFull
+	}
+	return err
+}
+
+func (e *engine[T]) push(ctx context.Context, v T) error {
+	for {
+		e.mu.Lock()
+		wait, dropped, err := e.pushLocked(v)
+		ch := e.signal
+		e.mu.Unlock()
+		e.notifyDropped(dropped)
+		if !wait {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+func (e *engine[T]) tryPop() (T, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v, _, err := e.popLocked()
+	return v, err
+}
+
+func (e *engine[T]) pop(ctx context.Context) (T, error) {
+	for {
+		e.mu.Lock()
+		v, wait, err := e.popLocked()
+		ch := e.signal
+		e.mu.Unlock()
+		if !wait {
+			return v, err
+		}
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+func (e *engine[T]) popTimeout(timeout time.Duration) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	v, err := e.pop(ctx)
+	if err == context.DeadlineExceeded {
+		return v, gerrors.ErrTimeout
+	}
+	return v, err
+}
+
+func (e *engine[T]) peek() (T, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.st.len() == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return e.st.peek(), nil
+}
+
+func (e *engine[T]) len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.st.len()
+}
+
+func (e *engine[T]) close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	e.broadcastLocked()
+	if e.wal != nil {
+		return e.wal.close()
+	}
+	return nil
+}
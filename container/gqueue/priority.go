@@ -0,0 +1,142 @@
+package gqueue
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// PriorityQueue is a concurrency-safe queue which pops the item with highest priority first,
+// items with equal priority are popped in FIFO order.
+// With DropOldest overflow policy, the item with lowest priority is dropped.
+type PriorityQueue[T any] struct {
+	e *engine[T]
+}
+
+// NewPriorityQueue creates a priority queue, less(a, b) reports whether a has higher priority than b.
+// limit 0 means unlimited.
+func NewPriorityQueue[T any](less func(a, b T) bool, limit uint64, policy OverflowPolicy) *PriorityQueue[T] {
+	return &PriorityQueue[T]{e: newEngine[T](&prioStore[T]{h: prioHeap[T]{less: less}}, limit, policy)}
+}
+
+func (q *PriorityQueue[T]) SetOnDrop(fn func(v T)) {
+	q.e.mu.Lock()
+	defer q.e.mu.Unlock()
+	q.e.onDrop = fn
+}
+
+func (q *PriorityQueue[T]) TryPush(v T) error {
+	return q.e.tryPush(v)
+}
+
+func (q *PriorityQueue[T]) Push(ctx context.Context, v T) error {
+	return q.e.push(ctx, v)
+}
+
+func (q *PriorityQueue[T]) TryPop() (T, error) {
+	return q.e.tryPop()
+}
+
+func (q *PriorityQueue[T]) Pop(ctx context.Context) (T, error) {
+	return q.e.pop(ctx)
+}
+
+func (q *PriorityQueue[T]) PopTimeout(timeout time.Duration) (T, error) {
+	return q.e.popTimeout(timeout)
+}
+
+// Peek returns the item with highest priority without removing it.
+func (q *PriorityQueue[T]) Peek() (T, error) {
+	return q.e.peek()
+}
+
+func (q *PriorityQueue[T]) Len() int {
+	return q.e.len()
+}
+
+func (q *PriorityQueue[T]) Dropped() uint64 {
+	q.e.mu.Lock()
+	defer q.e.mu.Unlock()
+	return q.e.dropped
+}
+
+func (q *PriorityQueue[T]) Close() error {
+	return q.e.close()
+}
+
+type (
+	prioItem[T any] struct {
+		v   T
+		seq uint64
+	}
+
+	prioHeap[T any] struct {
+		items []prioItem[T]
+		less  func(a, b T) bool
+	}
+
+	prioStore[T any] struct {
+		h   prioHeap[T]
+		seq uint64
+	}
+)
+
+func (h *prioHeap[T]) Len() int { return len(h.items) }
+
+func (h *prioHeap[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.v, b.v) {
+		return true
+	}
+	if h.less(b.v, a.v) {
+		return false
+	}
+	return a.seq < b.seq
+}
+
+func (h *prioHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *prioHeap[T]) Push(x any) { h.items = append(h.items, x.(prioItem[T])) }
+
+func (h *prioHeap[T]) Pop() any {
+	n := len(h.items)
+	it := h.items[n-1]
+	h.items[n-1] = prioItem[T]{}
+	h.items = h.items[:n-1]
+	return it
+}
+
+func (s *prioStore[T]) push(v T) {
+	s.seq++
+	heap.Push(&s.h, prioItem[T]{v: v, seq: s.seq})
+}
+
+func (s *prioStore[T]) pop() T {
+	return heap.Pop(&s.h).(prioItem[T]).v
+}
+
+func (s *prioStore[T]) peek() T {
+	return s.h.items[0].v
+}
+
+// evict removes the item with lowest priority, the lowest item is always a leaf, so only leaves are scanned.
+func (s *prioStore[T]) evict() T {
+	n := len(s.h.items)
+	worst := n / 2
+	for i := worst + 1; i < n; i++ {
+		if s.h.Less(worst, i) {
+			worst = i
+		}
+	}
+	return heap.Remove(&s.h, worst).(prioItem[T]).v
+}
+
+func (s *prioStore[T]) len() int {
+	return len(s.h.items)
+}
+
+func (s *prioStore[T]) each(fn func(v T)) {
+	for _, it := range s.h.items {
+		fn(it.v)
+	}
+}
@@ -1,57 +1,182 @@
 package gqueue
 
 import (
-	"container/list"
-	"github.com/davidforest123/goutil/safe/gwg"
+	"context"
+	"github.com/davidforest123/goutil/basic/gerrors"
 	"time"
 )
 
 type (
-	Queue struct {
-		list  *list.List
-		wg    *gwg.WaitSwitch
-		limit uint64
+	// OverflowPolicy decides what happens when an item is pushed into a full queue.
+	OverflowPolicy int
+
+	// Queue is a concurrency-safe FIFO queue.
+	Queue[T any] struct {
+		e *engine[T]
 	}
 )
 
-func NewQueue() *Queue {
-	q := &Queue{list: list.New(), wg: gwg.NewWaitSwitch()}
-	q.wg.SetBlock()
-	return q
+const (
+	// DropOldest removes the oldest item to make room for the new one, this is the default policy.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the item being pushed, Push returns ErrDropped.
+	DropNewest
+	// Block makes Push wait until there is room, TryPush returns ErrFull.
+	Block
+)
+
+var (
+	ErrEmpty   = gerrors.New("queue is empty")
+	ErrFull    = gerrors.New("queue is full")
+	ErrDropped = gerrors.New("item dropped because queue is full")
+	ErrClosed  = gerrors.New("queue is closed")
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case DropOldest:
+		return "DropOldest"
+	case DropNewest:
+		return "DropNewest"
+	case Block:
+		return "Block"
+	default:
+		return "Unknown"
+	}
 }
 
-func (q *Queue) SetLimit(n uint64) {
-	q.limit = n
+// NewQueue creates a FIFO queue, limit 0 means unlimited.
+func NewQueue[T any](limit uint64, policy OverflowPolicy) *Queue[T] {
+	return &Queue[T]{e: newEngine[T](newRing[T](), limit, policy)}
 }
 
-func (q *Queue) Push(v any) (oldestDeleted bool) {
-	defer q.wg.SetUnBlock()
+// SetLimit updates max length of queue, 0 means unlimited.
+// Items over the new limit are kept until popped, only later pushes are affected.
+func (q *Queue[T]) SetLimit(n uint64) {
+	q.e.mu.Lock()
+	defer q.e.mu.Unlock()
+	q.e.limit = n
+	q.e.broadcastLocked()
+}
 
-	oldestDeleted = false
-	if q.limit > 0 && uint64(q.list.Len()) >= q.limit {
-		q.Pop(nil)
-		oldestDeleted = true
-	}
-	q.list.PushBack(v)
-	return oldestDeleted
+func (q *Queue[T]) SetOverflowPolicy(p OverflowPolicy) {
+	q.e.mu.Lock()
+	defer q.e.mu.Unlock()
+	q.e.policy = p
+	q.e.broadcastLocked()
+}
+
+// SetOnDrop sets callback which is called with every item dropped by overflow policy.
+// It is called without queue lock held, so it is safe to access the queue inside.
+func (q *Queue[T]) SetOnDrop(fn func(v T)) {
+	q.e.mu.Lock()
+	defer q.e.mu.Unlock()
+	q.e.onDrop = fn
+}
+
+// TryPush pushes v without blocking.
+func (q *Queue[T]) TryPush(v T) error {
+	return q.e.tryPush(v)
+}
+
+// Push pushes v, with Block policy it waits until there is room or ctx is done.
+func (q *Queue[T]) Push(ctx context.Context, v T) error {
+	return q.e.push(ctx, v)
+}
+
+// TryPop pops the oldest item without blocking, it returns ErrEmpty if queue is empty.
+func (q *Queue[T]) TryPop() (T, error) {
+	return q.e.tryPop()
+}
+
+// Pop waits until an item is available or ctx is done.
+// After Close, Pop drains remaining items and then returns ErrClosed.
+func (q *Queue[T]) Pop(ctx context.Context) (T, error) {
+	return q.e.pop(ctx)
+}
+
+// PopTimeout is like Pop, it returns gerrors.ErrTimeout if no item is available before timeout.
+func (q *Queue[T]) PopTimeout(timeout time.Duration) (T, error) {
+	return q.e.popTimeout(timeout)
+}
+
+// Peek returns the oldest item without removing it.
+func (q *Queue[T]) Peek() (T, error) {
+	return q.e.peek()
 }
 
-func (q *Queue) Pop(waitTimeout *time.Duration) any {
-	if waitTimeout != nil {
-		q.wg.Wait(waitTimeout)
+func (q *Queue[T]) Len() int {
+	return q.e.len()
+}
+
+// Dropped returns the count of items dropped by overflow policy.
+func (q *Queue[T]) Dropped() uint64 {
+	q.e.mu.Lock()
+	defer q.e.mu.Unlock()
+	return q.e.dropped
+}
+
+// Close wakes up all waiters, later pushes return ErrClosed.
+// For durable queue it also syncs and closes the log file.
+func (q *Queue[T]) Close() error {
+	return q.e.close()
+}
+
+// ring is a growable circular buffer, it implements store with FIFO order.
+type ring[T any] struct {
+	buf  []T
+	head int
+	size int
+}
+
+func newRing[T any]() *ring[T] {
+	return &ring[T]{}
+}
+
+func (r *ring[T]) grow() {
+	n := len(r.buf) * 2
+	if n == 0 {
+		n = 16
+	}
+	buf := make([]T, n)
+	for i := 0; i < r.size; i++ {
+		buf[i] = r.buf[(r.head+i)%len(r.buf)]
 	}
+	r.buf = buf
+	r.head = 0
+}
 
-	var result any = nil
-	if q.list.Len() > 0 {
-		result = q.list.Front().Value
-		q.list.Remove(q.list.Front())
-		if q.list.Len() == 0 {
-			q.wg.SetBlock()
-		}
+func (r *ring[T]) push(v T) {
+	if r.size == len(r.buf) {
+		r.grow()
 	}
-	return result
+	r.buf[(r.head+r.size)%len(r.buf)] = v
+	r.size++
+}
+
+func (r *ring[T]) pop() T {
+	var zero T
+	v := r.buf[r.head]
+	r.buf[r.head] = zero // release reference for GC
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	return v
+}
+
+func (r *ring[T]) peek() T {
+	return r.buf[r.head]
 }
 
-func (q *Queue) Len() int {
-	return q.list.Len()
+func (r *ring[T]) evict() T {
+	return r.pop()
+}
+
+func (r *ring[T]) len() int {
+	return r.size
+}
+
+func (r *ring[T]) each(fn func(v T)) {
+	for i := 0; i < r.size; i++ {
+		fn(r.buf[(r.head+i)%len(r.buf)])
+	}
 }
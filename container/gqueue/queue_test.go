@@ -0,0 +1,179 @@
+package gqueue
+
+import (
+	"bytes"
+	"context"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue_Overflow(t *testing.T) {
+	q := NewQueue[int](3, DropOldest)
+	var dropped []int
+	q.SetOnDrop(func(v int) { dropped = append(dropped, v) })
+	for i := 0; i < 5; i++ {
+		gtest.Assert(t, q.TryPush(i))
+	}
+	gtest.AssertTrue(t, q.Len() == 3, "len should be 3 but got %d", q.Len())
+	gtest.AssertTrue(t, len(dropped) == 2 && dropped[0] == 0 && dropped[1] == 1, "dropped %v", dropped)
+	v, err := q.TryPop()
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, v == 2, "should pop 2 but got %d", v)
+
+	q = NewQueue[int](1, DropNewest)
+	gtest.Assert(t, q.TryPush(1))
+	gtest.AssertTrue(t, q.TryPush(2) == ErrDropped, "push into full DropNewest queue should be dropped")
+	v, _ = q.TryPop()
+	gtest.AssertTrue(t, v == 1, "should pop 1 but got %d", v)
+	_, err = q.TryPop()
+	gtest.AssertTrue(t, err == ErrEmpty, "should be empty error but got %v", err)
+
+	q = NewQueue[int](1, Block)
+	gtest.Assert(t, q.TryPush(1))
+	gtest.AssertTrue(t, q.TryPush(2) == ErrFull, "TryPush into full Block queue should fail")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	gtest.AssertTrue(t, q.Push(ctx, 2) == context.DeadlineExceeded, "Push should wait until ctx done")
+}
+
+func TestQueue_Blocking(t *testing.T) {
+	q := NewQueue[int](4, Block)
+	_, err := q.PopTimeout(10 * time.Millisecond)
+	gtest.AssertTrue(t, err == gerrors.ErrTimeout, "should be timeout but got %v", err)
+
+	const producers, each = 4, 1000
+	wg := sync.WaitGroup{}
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < each; i++ {
+				if err := q.Push(context.Background(), p*each+i); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(p)
+	}
+
+	seen := make(map[int]bool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			v, err := q.Pop(context.Background())
+			if err == ErrClosed {
+				return
+			}
+			seen[v] = true
+		}
+	}()
+	wg.Wait()
+	gtest.Assert(t, q.Close())
+	<-done
+	gtest.AssertTrue(t, len(seen) == producers*each, "should pop %d items but got %d", producers*each, len(seen))
+	gtest.AssertTrue(t, q.TryPush(1) == ErrClosed, "push after close should fail")
+}
+
+func TestPriorityQueue(t *testing.T) {
+	type job struct {
+		prio int
+		name string
+	}
+	q := NewPriorityQueue[job](func(a, b job) bool { return a.prio > b.prio }, 4, DropOldest)
+	for _, j := range []job{{1, "a"}, {5, "b"}, {3, "c"}, {5, "d"}, {0, "e"}, {4, "f"}} {
+		gtest.Assert(t, q.TryPush(j))
+	}
+	gtest.AssertTrue(t, q.Dropped() == 2, "should drop 2 items but dropped %d", q.Dropped())
+	var got string
+	for q.Len() > 0 {
+		j, err := q.TryPop()
+		gtest.Assert(t, err)
+		got += j.name
+	}
+	gtest.AssertTrue(t, got == "bdfc", "pop order should be bdfc but got %s", got)
+}
+
+func TestDurableQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+	q, err := NewDurableQueue[string](path, nil, 0, DropOldest, false)
+	gtest.Assert(t, err)
+	for _, s := range []string{"a", "b", "c", "d"} {
+		gtest.Assert(t, q.TryPush(s))
+	}
+	v, err := q.TryPop()
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, v == "a", "should pop a but got %s", v)
+	gtest.Assert(t, q.Close())
+
+	// Simulate a crash in the middle of writing a record.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	gtest.Assert(t, err)
+	_, err = f.Write(encodeWalRecord(walOpPush, []byte(`"torn"`))[:7])
+	gtest.Assert(t, err)
+	gtest.Assert(t, f.Close())
+
+	q, err = NewDurableQueue[string](path, nil, 0, DropOldest, false)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, q.Len() == 3, "should restore 3 items but got %d", q.Len())
+	got := ""
+	for q.Len() > 0 {
+		v, err := q.TryPop()
+		gtest.Assert(t, err)
+		got += v
+	}
+	gtest.AssertTrue(t, got == "bcd", "should restore bcd but got %s", got)
+	gtest.Assert(t, q.TryPush("e"))
+	gtest.Assert(t, q.Close())
+
+	q, err = NewDurableQueue[string](path, nil, 0, DropOldest, false)
+	gtest.Assert(t, err)
+	v, err = q.TryPop()
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, v == "e" && q.Len() == 0, "should restore only e but got %s and %d more", v, q.Len())
+	gtest.Assert(t, q.Close())
+}
+
+func TestDurableQueue_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	var data []byte
+	for _, s := range []string{`"a"`, `"b"`, `"c"`, `"d"`, `"e"`} {
+		data = append(data, encodeWalRecord(walOpPush, []byte(s))...)
+	}
+	recLen := len(encodeWalRecord(walOpPush, []byte(`"a"`)))
+
+	// broken checksum of the last record is a torn tail
+	path := filepath.Join(dir, "tail.wal")
+	tail := bytes.Clone(data)
+	tail[len(tail)-1] ^= 0xff
+	gtest.Assert(t, os.WriteFile(path, tail, 0644))
+	q, err := NewDurableQueue[string](path, nil, 0, DropOldest, false)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, q.Len() == 4, "should restore 4 items but got %d", q.Len())
+	gtest.Assert(t, q.Close())
+
+	// broken record in the middle keeps the log untouched
+	path = filepath.Join(dir, "middle.wal")
+	middle := bytes.Clone(data)
+	middle[recLen+6] ^= 0xff
+	gtest.Assert(t, os.WriteFile(path, middle, 0644))
+	_, err = NewDurableQueue[string](path, nil, 0, DropOldest, false)
+	gtest.AssertTrue(t, err != nil, "corrupted middle record should fail")
+	b, err := os.ReadFile(path)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, bytes.Equal(b, middle), "log should not be compacted")
+
+	// huge length is not allocated
+	path = filepath.Join(dir, "length.wal")
+	huge := append(bytes.Clone(data[:recLen]), walOpPush, 0xff, 0xff, 0xff, 0xf0)
+	gtest.Assert(t, os.WriteFile(path, huge, 0644))
+	q, err = NewDurableQueue[string](path, nil, 0, DropOldest, false)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, q.Len() == 1, "should restore 1 item but got %d", q.Len())
+	gtest.Assert(t, q.Close())
+}
@@ -0,0 +1,223 @@
+package grange
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+type (
+	// Interval is half-open range [Lo, Hi), it is empty if Lo >= Hi.
+	// Closed integer range [a, b] is Interval{a, b+1}.
+	Interval[T cmp.Ordered] struct {
+		Lo T
+		Hi T
+	}
+
+	// IntervalSet is a set of values of T described by sorted disjoint intervals,
+	// adjacent intervals like [1,3) and [3,5) are merged into [1,5).
+	// It is not safe for concurrent use.
+	IntervalSet[T cmp.Ordered] struct {
+		ivs []Interval[T]
+	}
+)
+
+func NewInterval[T cmp.Ordered](lo, hi T) Interval[T] {
+	return Interval[T]{Lo: lo, Hi: hi}
+}
+
+func (iv Interval[T]) Empty() bool {
+	return iv.Lo >= iv.Hi
+}
+
+func (iv Interval[T]) Contains(v T) bool {
+	return iv.Lo <= v && v < iv.Hi
+}
+
+func (iv Interval[T]) Overlaps(o Interval[T]) bool {
+	return iv.Lo < o.Hi && o.Lo < iv.Hi && !iv.Empty() && !o.Empty()
+}
+
+// Intersect returns the common part of iv and o, ok is false if they don't overlap.
+func (iv Interval[T]) Intersect(o Interval[T]) (Interval[T], bool) {
+	r := Interval[T]{Lo: max(iv.Lo, o.Lo), Hi: min(iv.Hi, o.Hi)}
+	return r, !r.Empty()
+}
+
+func (iv Interval[T]) String() string {
+	return fmt.Sprintf("[%v,%v)", iv.Lo, iv.Hi)
+}
+
+func NewIntervalSet[T cmp.Ordered](ivs ...Interval[T]) *IntervalSet[T] {
+	s := &IntervalSet[T]{}
+	for _, iv := range ivs {
+		s.Add(iv)
+	}
+	return s
+}
+
+func (s *IntervalSet[T]) Len() int {
+	return len(s.ivs)
+}
+
+// Intervals returns a copy of sorted disjoint intervals.
+func (s *IntervalSet[T]) Intervals() []Interval[T] {
+	return slices.Clone(s.ivs)
+}
+
+func (s *IntervalSet[T]) All() iter.Seq[Interval[T]] {
+	return slices.Values(s.ivs)
+}
+
+func (s *IntervalSet[T]) Clone() *IntervalSet[T] {
+	return &IntervalSet[T]{ivs: slices.Clone(s.ivs)}
+}
+
+// search returns the index of the first interval whose Hi >= v.
+func (s *IntervalSet[T]) search(v T) int {
+	i, _ := slices.BinarySearchFunc(s.ivs, v, func(iv Interval[T], v T) int { return cmp.Compare(iv.Hi, v) })
+	return i
+}
+
+// Add adds iv in O(log n + k), k is count of intervals merged with iv.
+func (s *IntervalSet[T]) Add(iv Interval[T]) {
+	if iv.Empty() {
+		return
+	}
+	// intervals in [i, j) overlap or touch iv
+	i := s.search(iv.Lo)
+	j := i
+	for j < len(s.ivs) && s.ivs[j].Lo <= iv.Hi {
+		j++
+	}
+	if i < j {
+		iv.Lo = min(iv.Lo, s.ivs[i].Lo)
+		iv.Hi = max(iv.Hi, s.ivs[j-1].Hi)
+	}
+	s.ivs = slices.Replace(s.ivs, i, j, iv)
+}
+
+// Remove removes all values in iv.
+func (s *IntervalSet[T]) Remove(iv Interval[T]) {
+	if iv.Empty() {
+		return
+	}
+	// intervals in [i, j) overlap iv
+	i := s.search(iv.Lo)
+	if i < len(s.ivs) && s.ivs[i].Hi == iv.Lo {
+		i++
+	}
+	j := i
+	for j < len(s.ivs) && s.ivs[j].Lo < iv.Hi {
+		j++
+	}
+	if i == j {
+		return
+	}
+	var rest []Interval[T]
+	if left := (Interval[T]{Lo: s.ivs[i].Lo, Hi: iv.Lo}); !left.Empty() {
+		rest = append(rest, left)
+	}
+	if right := (Interval[T]{Lo: iv.Hi, Hi: s.ivs[j-1].Hi}); !right.Empty() {
+		rest = append(rest, right)
+	}
+	s.ivs = slices.Replace(s.ivs, i, j, rest...)
+}
+
+// Contains reports whether v is in s in O(log n).
+func (s *IntervalSet[T]) Contains(v T) bool {
+	i, found := slices.BinarySearchFunc(s.ivs, v, func(iv Interval[T], v T) int { return cmp.Compare(iv.Hi, v) })
+	if found {
+		i++ // v == Hi is not contained
+	}
+	return i < len(s.ivs) && s.ivs[i].Contains(v)
+}
+
+// ContainsInterval reports whether all values of iv are in s.
+func (s *IntervalSet[T]) ContainsInterval(iv Interval[T]) bool {
+	if iv.Empty() {
+		return true
+	}
+	i := s.search(iv.Lo)
+	if i < len(s.ivs) && s.ivs[i].Hi == iv.Lo {
+		i++
+	}
+	return i < len(s.ivs) && s.ivs[i].Lo <= iv.Lo && iv.Hi <= s.ivs[i].Hi
+}
+
+// Union returns a new set of values in s or o.
+func (s *IntervalSet[T]) Union(o *IntervalSet[T]) *IntervalSet[T] {
+	r := &IntervalSet[T]{ivs: make([]Interval[T], 0, len(s.ivs)+len(o.ivs))}
+	i, j := 0, 0
+	for i < len(s.ivs) || j < len(o.ivs) {
+		var iv Interval[T]
+		if j >= len(o.ivs) || (i < len(s.ivs) && s.ivs[i].Lo <= o.ivs[j].Lo) {
+			iv, i = s.ivs[i], i+1
+		} else {
+			iv, j = o.ivs[j], j+1
+		}
+		if n := len(r.ivs); n > 0 && iv.Lo <= r.ivs[n-1].Hi {
+			r.ivs[n-1].Hi = max(r.ivs[n-1].Hi, iv.Hi)
+		} else {
+			r.ivs = append(r.ivs, iv)
+		}
+	}
+	return r
+}
+
+// Intersect returns a new set of values in both s and o.
+func (s *IntervalSet[T]) Intersect(o *IntervalSet[T]) *IntervalSet[T] {
+	r := &IntervalSet[T]{}
+	i, j := 0, 0
+	for i < len(s.ivs) && j < len(o.ivs) {
+		if iv, ok := s.ivs[i].Intersect(o.ivs[j]); ok {
+			r.ivs = append(r.ivs, iv)
+		}
+		if s.ivs[i].Hi < o.ivs[j].Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return r
+}
+
+// Difference returns a new set of values in s but not in o.
+func (s *IntervalSet[T]) Difference(o *IntervalSet[T]) *IntervalSet[T] {
+	r := &IntervalSet[T]{}
+	j := 0
+	for _, iv := range s.ivs {
+		for j < len(o.ivs) && o.ivs[j].Hi <= iv.Lo {
+			j++
+		}
+		for k := j; k < len(o.ivs) && o.ivs[k].Lo < iv.Hi; k++ {
+			if left := (Interval[T]{Lo: iv.Lo, Hi: o.ivs[k].Lo}); !left.Empty() {
+				r.ivs = append(r.ivs, left)
+			}
+			iv.Lo = max(iv.Lo, o.ivs[k].Hi)
+		}
+		if !iv.Empty() {
+			r.ivs = append(r.ivs, iv)
+		}
+	}
+	return r
+}
+
+// Complement returns a new set of values in bounds but not in s.
+func (s *IntervalSet[T]) Complement(bounds Interval[T]) *IntervalSet[T] {
+	return NewIntervalSet(bounds).Difference(s)
+}
+
+func (s *IntervalSet[T]) Equal(o *IntervalSet[T]) bool {
+	return slices.Equal(s.ivs, o.ivs)
+}
+
+func (s *IntervalSet[T]) String() string {
+	ss := make([]string, len(s.ivs))
+	for i, iv := range s.ivs {
+		ss[i] = iv.String()
+	}
+	return strings.Join(ss, " ")
+}
@@ -0,0 +1,173 @@
+package grange
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math/rand"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIntervalSet(t *testing.T) {
+	s := NewIntervalSet(NewInterval(10, 20), NewInterval(30, 40), NewInterval(20, 25))
+	gtest.AssertTrue(t, s.String() == "[10,25) [30,40)", "got %s", s)
+	s.Add(NewInterval(5, 10))
+	s.Add(NewInterval(24, 31))
+	gtest.AssertTrue(t, s.String() == "[5,40)", "got %s", s)
+	s.Remove(NewInterval(15, 18))
+	s.Remove(NewInterval(39, 50))
+	gtest.AssertTrue(t, s.String() == "[5,15) [18,39)", "got %s", s)
+	gtest.AssertTrue(t, s.Contains(5) && !s.Contains(15) && s.Contains(18) && !s.Contains(39), "contains")
+	gtest.AssertTrue(t, s.ContainsInterval(NewInterval(20, 39)) && !s.ContainsInterval(NewInterval(14, 19)), "contains interval")
+
+	o := NewIntervalSet(NewInterval(0, 6), NewInterval(14, 20))
+	gtest.AssertTrue(t, s.Union(o).String() == "[0,39)", "union %s", s.Union(o))
+	gtest.AssertTrue(t, s.Intersect(o).String() == "[5,6) [14,15) [18,20)", "intersect %s", s.Intersect(o))
+	gtest.AssertTrue(t, s.Difference(o).String() == "[6,14) [20,39)", "difference %s", s.Difference(o))
+	gtest.AssertTrue(t, s.Complement(NewInterval(0, 50)).String() == "[0,5) [15,18) [39,50)", "complement %s", s.Complement(NewInterval(0, 50)))
+
+	fs := NewIntervalSet(NewInterval(0.5, 1.5))
+	gtest.AssertTrue(t, fs.Contains(1.4999) && !fs.Contains(1.5), "float")
+}
+
+// TestIntervalSet_Random compares set algebra with a bitmap.
+func TestIntervalSet_Random(t *testing.T) {
+	const n = 200
+	rnd := rand.New(rand.NewSource(43))
+	randomSet := func() (*IntervalSet[int], [n]bool) {
+		var bm [n]bool
+		s := NewIntervalSet[int]()
+		for i := 0; i < 20; i++ {
+			lo := rnd.Intn(n)
+			hi := min(n, lo+rnd.Intn(20))
+			if rnd.Intn(3) == 0 {
+				s.Remove(NewInterval(lo, hi))
+				for v := lo; v < hi; v++ {
+					bm[v] = false
+				}
+			} else {
+				s.Add(NewInterval(lo, hi))
+				for v := lo; v < hi; v++ {
+					bm[v] = true
+				}
+			}
+		}
+		return s, bm
+	}
+	check := func(s *IntervalSet[int], want func(v int) bool, name string) {
+		ivs := s.Intervals()
+		for i := 1; i < len(ivs); i++ {
+			gtest.AssertTrue(t, ivs[i-1].Hi < ivs[i].Lo, "%s not normalized: %s", name, s)
+		}
+		for v := 0; v < n; v++ {
+			gtest.AssertTrue(t, s.Contains(v) == want(v), "%s contains %d: %s", name, v, s)
+		}
+	}
+	for round := 0; round < 200; round++ {
+		a, am := randomSet()
+		b, bm := randomSet()
+		check(a, func(v int) bool { return am[v] }, "a")
+		check(a.Union(b), func(v int) bool { return am[v] || bm[v] }, "union")
+		check(a.Intersect(b), func(v int) bool { return am[v] && bm[v] }, "intersect")
+		check(a.Difference(b), func(v int) bool { return am[v] && !bm[v] }, "difference")
+		check(a.Complement(NewInterval(10, 150)), func(v int) bool { return v >= 10 && v < 150 && !am[v] }, "complement")
+	}
+}
+
+func TestIntervalTree(t *testing.T) {
+	rnd := rand.New(rand.NewSource(43))
+	tr := NewIntervalTree[int, int]()
+	var all []IntervalEntry[int, int]
+	for i := 0; i < 2000; i++ {
+		lo := rnd.Intn(10000)
+		iv := NewInterval(lo, lo+1+rnd.Intn(200))
+		tr.Insert(iv, i)
+		all = append(all, IntervalEntry[int, int]{Interval: iv, Value: i})
+	}
+	// delete some entries, including ones with equal intervals
+	for i := 0; i < 500; i++ {
+		k := rnd.Intn(len(all))
+		e := all[k]
+		gtest.AssertTrue(t, tr.Delete(e.Interval, func(v int) bool { return v == e.Value }), "delete %v", e)
+		all = slices.Delete(all, k, k+1)
+	}
+	gtest.AssertTrue(t, tr.Len() == len(all), "len %d", tr.Len())
+	gtest.AssertTrue(t, !tr.Delete(NewInterval(-2, -1), nil), "delete missing")
+
+	sortEntries := func(es []IntervalEntry[int, int]) {
+		slices.SortFunc(es, func(a, b IntervalEntry[int, int]) int {
+			if c := compareInterval(a.Interval, b.Interval); c != 0 {
+				return c
+			}
+			return a.Value - b.Value
+		})
+	}
+	for q := 0; q < 300; q++ {
+		p := rnd.Intn(10300)
+		var want []IntervalEntry[int, int]
+		for _, e := range all {
+			if e.Interval.Contains(p) {
+				want = append(want, e)
+			}
+		}
+		got := tr.Stab(p)
+		sortEntries(got)
+		sortEntries(want)
+		gtest.AssertTrue(t, slices.Equal(got, want), "stab %d: %d != %d", p, len(got), len(want))
+
+		iv := NewInterval(p, p+rnd.Intn(50))
+		want = want[:0]
+		for _, e := range all {
+			if e.Interval.Overlaps(iv) {
+				want = append(want, e)
+			}
+		}
+		got = tr.Overlap(iv)
+		sortEntries(got)
+		sortEntries(want)
+		gtest.AssertTrue(t, slices.Equal(got, want), "overlap %s: %d != %d", iv, len(got), len(want))
+	}
+
+	prev := NewInterval(-1, -1)
+	for iv := range tr.All() {
+		gtest.AssertTrue(t, compareInterval(prev, iv) <= 0, "order %s after %s", iv, prev)
+		prev = iv
+	}
+}
+
+func TestTimeSet(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	ts := NewTimeSet(TimeRange{From: at(0), To: at(8)}, TimeRange{From: at(10), To: at(12)})
+	ts.Add(at(8), at(9))
+	ts.Remove(at(2), at(3))
+	gtest.AssertTrue(t, ts.Duration() == 10*time.Hour, "duration %s", ts.Duration())
+	gtest.AssertTrue(t, ts.Contains(at(1)) && !ts.Contains(at(2)) && !ts.Contains(at(9)), "contains")
+	gaps := ts.Complement(at(0), at(24)).Ranges()
+	gtest.AssertTrue(t, len(gaps) == 3 && gaps[1].From.Equal(at(9)) && gaps[1].Duration() == time.Hour, "gaps %v", gaps)
+}
+
+func TestIPSet(t *testing.T) {
+	s := NewIPSet()
+	gtest.Assert(t, s.AddPrefix(netip.MustParsePrefix("10.0.0.0/8")))
+	gtest.Assert(t, s.AddRange(netip.MustParseAddr("11.0.0.0"), netip.MustParseAddr("11.0.0.255")))
+	gtest.Assert(t, s.RemovePrefix(netip.MustParsePrefix("10.1.0.0/16")))
+	gtest.Assert(t, s.AddPrefix(netip.MustParsePrefix("2001:db8::/32")))
+	gtest.Assert(t, s.AddPrefix(netip.MustParsePrefix("255.255.255.0/24")))
+	gtest.AssertTrue(t, s.Contains(netip.MustParseAddr("10.2.3.4")) && !s.Contains(netip.MustParseAddr("10.1.3.4")), "contains v4")
+	gtest.AssertTrue(t, s.Contains(netip.MustParseAddr("2001:db8::1")) && !s.Contains(netip.MustParseAddr("2001:db9::1")), "contains v6")
+	gtest.AssertTrue(t, s.Contains(netip.MustParseAddr("255.255.255.255")), "contains max v4")
+
+	var got []string
+	for _, r := range s.Ranges() {
+		got = append(got, r.From.String()+"-"+r.To.String())
+	}
+	want := []string{"10.0.0.0-10.0.255.255", "10.2.0.0-11.0.0.255", "255.255.255.0-255.255.255.255", "2001:db8::-2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"}
+	gtest.AssertTrue(t, slices.Equal(got, want), "ranges %v", got)
+
+	o := NewIPSet()
+	gtest.Assert(t, o.AddPrefix(netip.MustParsePrefix("10.0.0.0/24")))
+	gtest.AssertTrue(t, len(s.Intersect(o).Ranges()) == 1 && s.Difference(o).Contains(netip.MustParseAddr("10.0.1.1")), "algebra")
+	gtest.AssertTrue(t, s.AddRange(netip.Addr{}, netip.MustParseAddr("1.1.1.1")) != nil, "invalid range")
+}
@@ -0,0 +1,226 @@
+package grange
+
+import (
+	"cmp"
+	"iter"
+)
+
+type (
+	// IntervalTree stores possibly overlapping intervals with values, it is an AVL tree ordered by Lo then Hi,
+	// each node is augmented with the max Hi of its subtree, so subtrees without results are skipped,
+	// stabbing and overlap queries are O(log n) if there are few results, O((k+1) log n) at most, k is count of results.
+	// It is not safe for concurrent use.
+	IntervalTree[T cmp.Ordered, V any] struct {
+		root *itNode[T, V]
+		size int
+	}
+
+	IntervalEntry[T cmp.Ordered, V any] struct {
+		Interval Interval[T]
+		Value    V
+	}
+
+	itNode[T cmp.Ordered, V any] struct {
+		left, right *itNode[T, V]
+		height      int
+		maxHi       T
+		entry       IntervalEntry[T, V]
+	}
+)
+
+func NewIntervalTree[T cmp.Ordered, V any]() *IntervalTree[T, V] {
+	return &IntervalTree[T, V]{}
+}
+
+func compareInterval[T cmp.Ordered](a, b Interval[T]) int {
+	if c := cmp.Compare(a.Lo, b.Lo); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Hi, b.Hi)
+}
+
+func itHeight[T cmp.Ordered, V any](n *itNode[T, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *itNode[T, V]) fix() {
+	n.height = max(itHeight(n.left), itHeight(n.right)) + 1
+	n.maxHi = n.entry.Interval.Hi
+	if n.left != nil {
+		n.maxHi = max(n.maxHi, n.left.maxHi)
+	}
+	if n.right != nil {
+		n.maxHi = max(n.maxHi, n.right.maxHi)
+	}
+}
+
+func itRotateRight[T cmp.Ordered, V any](n *itNode[T, V]) *itNode[T, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.fix()
+	l.fix()
+	return l
+}
+
+func itRotateLeft[T cmp.Ordered, V any](n *itNode[T, V]) *itNode[T, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.fix()
+	r.fix()
+	return r
+}
+
+func itBalance[T cmp.Ordered, V any](n *itNode[T, V]) *itNode[T, V] {
+	n.fix()
+	switch bf := itHeight(n.left) - itHeight(n.right); {
+	case bf > 1:
+		if itHeight(n.left.left) < itHeight(n.left.right) {
+			n.left = itRotateLeft(n.left)
+		}
+		return itRotateRight(n)
+	case bf < -1:
+		if itHeight(n.right.right) < itHeight(n.right.left) {
+			n.right = itRotateRight(n.right)
+		}
+		return itRotateLeft(n)
+	}
+	return n
+}
+
+func (t *IntervalTree[T, V]) Len() int {
+	return t.size
+}
+
+// Insert adds iv with value v, intervals equal to existing ones are added too.
+func (t *IntervalTree[T, V]) Insert(iv Interval[T], v V) {
+	t.root = t.insert(t.root, IntervalEntry[T, V]{Interval: iv, Value: v})
+	t.size++
+}
+
+func (t *IntervalTree[T, V]) insert(n *itNode[T, V], e IntervalEntry[T, V]) *itNode[T, V] {
+	if n == nil {
+		n = &itNode[T, V]{entry: e}
+		n.fix()
+		return n
+	}
+	if compareInterval(e.Interval, n.entry.Interval) < 0 {
+		n.left = t.insert(n.left, e)
+	} else {
+		n.right = t.insert(n.right, e)
+	}
+	return itBalance(n)
+}
+
+// Delete removes one entry whose interval equals iv and match(value) is true, match can be nil.
+func (t *IntervalTree[T, V]) Delete(iv Interval[T], match func(v V) bool) bool {
+	var deleted bool
+	t.root, deleted = t.delete(t.root, iv, match)
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+func (t *IntervalTree[T, V]) delete(n *itNode[T, V], iv Interval[T], match func(v V) bool) (*itNode[T, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	var deleted bool
+	c := compareInterval(iv, n.entry.Interval)
+	// equal intervals may be in both subtrees after rotations
+	if c <= 0 {
+		if n.left, deleted = t.delete(n.left, iv, match); deleted {
+			return itBalance(n), true
+		}
+	}
+	if c == 0 && (match == nil || match(n.entry.Value)) {
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+		var successor *itNode[T, V]
+		n.right, successor = itDeleteMin(n.right)
+		successor.left, successor.right = n.left, n.right
+		return itBalance(successor), true
+	}
+	if c >= 0 {
+		if n.right, deleted = t.delete(n.right, iv, match); deleted {
+			return itBalance(n), true
+		}
+	}
+	return n, false
+}
+
+// itDeleteMin removes the min node of subtree n, and returns the new subtree and the removed node.
+func itDeleteMin[T cmp.Ordered, V any](n *itNode[T, V]) (*itNode[T, V], *itNode[T, V]) {
+	if n.left == nil {
+		return n.right, n
+	}
+	var m *itNode[T, V]
+	n.left, m = itDeleteMin(n.left)
+	return itBalance(n), m
+}
+
+// Stab returns entries whose interval contains point p, ordered by interval.
+func (t *IntervalTree[T, V]) Stab(p T) []IntervalEntry[T, V] {
+	var r []IntervalEntry[T, V]
+	var visit func(n *itNode[T, V])
+	visit = func(n *itNode[T, V]) {
+		if n == nil || n.maxHi <= p {
+			return
+		}
+		visit(n.left)
+		if n.entry.Interval.Lo > p {
+			// intervals in right subtree start after p too
+			return
+		}
+		if n.entry.Interval.Contains(p) {
+			r = append(r, n.entry)
+		}
+		visit(n.right)
+	}
+	visit(t.root)
+	return r
+}
+
+// Overlap returns entries whose interval overlaps iv, ordered by interval.
+func (t *IntervalTree[T, V]) Overlap(iv Interval[T]) []IntervalEntry[T, V] {
+	var r []IntervalEntry[T, V]
+	if iv.Empty() {
+		return r
+	}
+	var visit func(n *itNode[T, V])
+	visit = func(n *itNode[T, V]) {
+		if n == nil || n.maxHi <= iv.Lo {
+			return
+		}
+		visit(n.left)
+		if n.entry.Interval.Lo >= iv.Hi {
+			return
+		}
+		if n.entry.Interval.Overlaps(iv) {
+			r = append(r, n.entry)
+		}
+		visit(n.right)
+	}
+	visit(t.root)
+	return r
+}
+
+// All iterates all entries ordered by interval.
+func (t *IntervalTree[T, V]) All() iter.Seq2[Interval[T], V] {
+	return func(yield func(Interval[T], V) bool) {
+		var walk func(n *itNode[T, V]) bool
+		walk = func(n *itNode[T, V]) bool {
+			return n == nil || (walk(n.left) && yield(n.entry.Interval, n.entry.Value) && walk(n.right))
+		}
+		walk(t.root)
+	}
+}
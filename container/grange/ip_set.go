@@ -0,0 +1,133 @@
+package grange
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"net/netip"
+)
+
+type (
+	// IPRange is closed IP range [From, To].
+	IPRange struct {
+		From netip.Addr
+		To   netip.Addr
+	}
+
+	// IPSet is an IntervalSet of IPv4 and IPv6 addresses, e.g. a firewall allow list.
+	// Addresses are keyed by their 16 bytes form, IPv4 addresses are stored as IPv4-mapped IPv6 addresses
+	// and returned as IPv4 again.
+	IPSet struct {
+		s IntervalSet[string]
+	}
+)
+
+// ipKey returns the 16 bytes key of addr.
+func ipKey(addr netip.Addr) string {
+	b := addr.As16()
+	return string(b[:])
+}
+
+// ipKeyNext returns the key right after addr, the key after the max address is 17 bytes
+// which is still greater than all 16 bytes keys.
+func ipKeyNext(addr netip.Addr) string {
+	next := addr.Next()
+	if !next.IsValid() {
+		return ipKey(addr) + "\x00"
+	}
+	return ipKey(next)
+}
+
+func ipOfKey(key string) netip.Addr {
+	return netip.AddrFrom16([16]byte([]byte(key[:16]))).Unmap()
+}
+
+// ipOfKeyPrev returns the address right before key, it is the reverse of ipKeyNext.
+func ipOfKeyPrev(key string) netip.Addr {
+	if len(key) > 16 {
+		return ipOfKey(key)
+	}
+	return netip.AddrFrom16([16]byte([]byte(key))).Prev().Unmap()
+}
+
+func ipInterval(from, to netip.Addr) (Interval[string], error) {
+	if !from.IsValid() || !to.IsValid() {
+		return Interval[string]{}, gerrors.New("invalid ip range %s-%s", from.String(), to.String())
+	}
+	return Interval[string]{Lo: ipKey(from), Hi: ipKeyNext(to)}, nil
+}
+
+func prefixRange(p netip.Prefix) (netip.Addr, netip.Addr, error) {
+	if !p.IsValid() {
+		return netip.Addr{}, netip.Addr{}, gerrors.New("invalid prefix %s", p.String())
+	}
+	p = p.Masked()
+	from := p.Addr()
+	b := from.AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	to, _ := netip.AddrFromSlice(b)
+	return from, to, nil
+}
+
+func NewIPSet() *IPSet {
+	return &IPSet{}
+}
+
+// AddRange adds addresses from from to to, both are included.
+func (s *IPSet) AddRange(from, to netip.Addr) error {
+	iv, err := ipInterval(from, to)
+	if err == nil {
+		s.s.Add(iv)
+	}
+	return err
+}
+
+func (s *IPSet) AddPrefix(p netip.Prefix) error {
+	from, to, err := prefixRange(p)
+	if err != nil {
+		return err
+	}
+	return s.AddRange(from, to)
+}
+
+// RemoveRange removes addresses from from to to, both are included.
+func (s *IPSet) RemoveRange(from, to netip.Addr) error {
+	iv, err := ipInterval(from, to)
+	if err == nil {
+		s.s.Remove(iv)
+	}
+	return err
+}
+
+func (s *IPSet) RemovePrefix(p netip.Prefix) error {
+	from, to, err := prefixRange(p)
+	if err != nil {
+		return err
+	}
+	return s.RemoveRange(from, to)
+}
+
+func (s *IPSet) Contains(addr netip.Addr) bool {
+	return addr.IsValid() && s.s.Contains(ipKey(addr))
+}
+
+// Ranges returns sorted disjoint closed IP ranges.
+func (s *IPSet) Ranges() []IPRange {
+	r := make([]IPRange, 0, s.s.Len())
+	for iv := range s.s.All() {
+		r = append(r, IPRange{From: ipOfKey(iv.Lo), To: ipOfKeyPrev(iv.Hi)})
+	}
+	return r
+}
+
+func (s *IPSet) Union(o *IPSet) *IPSet {
+	return &IPSet{s: *s.s.Union(&o.s)}
+}
+
+func (s *IPSet) Intersect(o *IPSet) *IPSet {
+	return &IPSet{s: *s.s.Intersect(&o.s)}
+}
+
+func (s *IPSet) Difference(o *IPSet) *IPSet {
+	return &IPSet{s: *s.s.Difference(&o.s)}
+}
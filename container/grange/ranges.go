@@ -10,6 +10,7 @@ import (
 // this is a data structure to describe a large amount
 // and ALMOST consequent integer numbers, instead of huge integer array.
 // replace huge memory cost with CPU.
+// IntervalSet is the generic version with set algebra, RangeFilter is kept for closed int64 ranges.
 
 type Range struct {
 	Begin int64
@@ -0,0 +1,81 @@
+package grange
+
+import (
+	"time"
+)
+
+type (
+	// TimeRange is half-open time range [From, To).
+	TimeRange struct {
+		From time.Time
+		To   time.Time
+	}
+
+	// TimeSet is an IntervalSet of time in nanoseconds precision, e.g. online periods of a device.
+	TimeSet struct {
+		s IntervalSet[int64]
+	}
+)
+
+func (tr TimeRange) interval() Interval[int64] {
+	return Interval[int64]{Lo: tr.From.UnixNano(), Hi: tr.To.UnixNano()}
+}
+
+func (tr TimeRange) Duration() time.Duration {
+	return tr.To.Sub(tr.From)
+}
+
+func NewTimeSet(trs ...TimeRange) *TimeSet {
+	ts := &TimeSet{}
+	for _, tr := range trs {
+		ts.Add(tr.From, tr.To)
+	}
+	return ts
+}
+
+func (ts *TimeSet) Add(from, to time.Time) {
+	ts.s.Add(TimeRange{From: from, To: to}.interval())
+}
+
+func (ts *TimeSet) Remove(from, to time.Time) {
+	ts.s.Remove(TimeRange{From: from, To: to}.interval())
+}
+
+func (ts *TimeSet) Contains(t time.Time) bool {
+	return ts.s.Contains(t.UnixNano())
+}
+
+// Ranges returns sorted disjoint time ranges in local time.
+func (ts *TimeSet) Ranges() []TimeRange {
+	r := make([]TimeRange, 0, ts.s.Len())
+	for iv := range ts.s.All() {
+		r = append(r, TimeRange{From: time.Unix(0, iv.Lo), To: time.Unix(0, iv.Hi)})
+	}
+	return r
+}
+
+// Duration returns total duration of all time ranges.
+func (ts *TimeSet) Duration() time.Duration {
+	var d time.Duration
+	for iv := range ts.s.All() {
+		d += time.Duration(iv.Hi - iv.Lo)
+	}
+	return d
+}
+
+func (ts *TimeSet) Union(o *TimeSet) *TimeSet {
+	return &TimeSet{s: *ts.s.Union(&o.s)}
+}
+
+func (ts *TimeSet) Intersect(o *TimeSet) *TimeSet {
+	return &TimeSet{s: *ts.s.Intersect(&o.s)}
+}
+
+func (ts *TimeSet) Difference(o *TimeSet) *TimeSet {
+	return &TimeSet{s: *ts.s.Difference(&o.s)}
+}
+
+// Complement returns gaps of ts in [from, to).
+func (ts *TimeSet) Complement(from, to time.Time) *TimeSet {
+	return &TimeSet{s: *ts.s.Complement(TimeRange{From: from, To: to}.interval())}
+}
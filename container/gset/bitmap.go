@@ -0,0 +1,388 @@
+package gset
+
+import (
+	"fmt"
+	"github.com/davidforest123/goutil/container/gbit"
+	"iter"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+/*
+Bitmap is a compressed bitmap of uint32 like Roaring Bitmap, it fits sets of dense integer IDs.
+Values are grouped by the high 16 bits into containers, a container stores the low 16 bits
+as a sorted array if it has at most 4096 values, otherwise as a 65536 bits bitmap,
+so every container takes at most 8KB.
+It is not safe for concurrent use, see SyncBitmap.
+*/
+
+type (
+	Bitmap struct {
+		keys []uint16 // sorted high 16 bits
+		cs   []*container
+	}
+
+	// container is array container if words is nil, otherwise bitmap container.
+	container struct {
+		array []uint16
+		words []uint64
+		card  int
+	}
+)
+
+const (
+	arrayMaxSize = 4096
+	bitmapWords  = 1 << 16 / 64
+)
+
+func NewBitmap(values ...uint32) *Bitmap {
+	b := &Bitmap{}
+	b.Add(values...)
+	return b
+}
+
+func popcount(words []uint64) int {
+	n := 0
+	for _, w := range words {
+		n += int(gbit.Count1BitsHamming64(w))
+	}
+	return n
+}
+
+func (c *container) contains(lo uint16) bool {
+	if c.words != nil {
+		return c.words[lo/64]&(1<<(lo%64)) != 0
+	}
+	_, found := slices.BinarySearch(c.array, lo)
+	return found
+}
+
+func (c *container) add(lo uint16) {
+	if c.words != nil {
+		if w := &c.words[lo/64]; *w&(1<<(lo%64)) == 0 {
+			*w |= 1 << (lo % 64)
+			c.card++
+		}
+		return
+	}
+	i, found := slices.BinarySearch(c.array, lo)
+	if found {
+		return
+	}
+	c.array = slices.Insert(c.array, i, lo)
+	c.card++
+	if c.card > arrayMaxSize {
+		c.words = c.bitmap()
+		c.array = nil
+	}
+}
+
+func (c *container) remove(lo uint16) {
+	if c.words != nil {
+		if w := &c.words[lo/64]; *w&(1<<(lo%64)) != 0 {
+			*w &^= 1 << (lo % 64)
+			c.card--
+			c.normalize()
+		}
+		return
+	}
+	if i, found := slices.BinarySearch(c.array, lo); found {
+		c.array = slices.Delete(c.array, i, i+1)
+		c.card--
+	}
+}
+
+// bitmap returns words of c, it is a copy for array container.
+func (c *container) bitmap() []uint64 {
+	if c.words != nil {
+		return c.words
+	}
+	words := make([]uint64, bitmapWords)
+	for _, lo := range c.array {
+		words[lo/64] |= 1 << (lo % 64)
+	}
+	return words
+}
+
+// normalize converts bitmap container which has few values to array container.
+func (c *container) normalize() {
+	if c.words == nil || c.card > arrayMaxSize {
+		return
+	}
+	c.array = make([]uint16, 0, c.card)
+	for lo := range c.all() {
+		c.array = append(c.array, lo)
+	}
+	c.words = nil
+}
+
+func (c *container) clone() *container {
+	return &container{array: slices.Clone(c.array), words: slices.Clone(c.words), card: c.card}
+}
+
+func (c *container) all() iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		if c.words == nil {
+			for _, lo := range c.array {
+				if !yield(lo) {
+					return
+				}
+			}
+			return
+		}
+		for i, w := range c.words {
+			for w != 0 {
+				if !yield(uint16(i*64 + bits.TrailingZeros64(w))) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// combine returns a new container of values selected by op, op is a bitwise operation on words,
+// like func(a, b uint64) uint64 { return a & b }.
+func combine(a, b *container, op func(a, b uint64) uint64) *container {
+	if a.words == nil && b.words == nil {
+		// merge sorted arrays, at most 8192 values
+		r := &container{}
+		i, j := 0, 0
+		for i < len(a.array) || j < len(b.array) {
+			var lo uint16
+			var bitA, bitB uint64
+			switch {
+			case j >= len(b.array) || (i < len(a.array) && a.array[i] < b.array[j]):
+				lo, bitA = a.array[i], 1
+				i++
+			case i >= len(a.array) || b.array[j] < a.array[i]:
+				lo, bitB = b.array[j], 1
+				j++
+			default:
+				lo, bitA, bitB = a.array[i], 1, 1
+				i++
+				j++
+			}
+			if op(bitA, bitB)&1 != 0 {
+				r.array = append(r.array, lo)
+			}
+		}
+		r.card = len(r.array)
+		if r.card > arrayMaxSize {
+			r.words = r.bitmap()
+			r.array = nil
+		}
+		return r
+	}
+	wa, wb := a.bitmap(), b.bitmap()
+	r := &container{words: make([]uint64, bitmapWords)}
+	for i := range r.words {
+		r.words[i] = op(wa[i], wb[i])
+	}
+	r.card = popcount(r.words)
+	r.normalize()
+	return r
+}
+
+// find returns index of container of key hi.
+func (b *Bitmap) find(hi uint16) (int, bool) {
+	return slices.BinarySearch(b.keys, hi)
+}
+
+func (b *Bitmap) Add(values ...uint32) {
+	for _, v := range values {
+		hi := uint16(v >> 16)
+		i, found := b.find(hi)
+		if !found {
+			b.keys = slices.Insert(b.keys, i, hi)
+			b.cs = slices.Insert(b.cs, i, &container{})
+		}
+		b.cs[i].add(uint16(v))
+	}
+}
+
+// AddRange adds values in [lo, hi).
+func (b *Bitmap) AddRange(lo, hi uint32) {
+	for v := lo; v < hi; v++ {
+		b.Add(v)
+	}
+}
+
+func (b *Bitmap) Remove(values ...uint32) {
+	for _, v := range values {
+		i, found := b.find(uint16(v >> 16))
+		if !found {
+			continue
+		}
+		b.cs[i].remove(uint16(v))
+		if b.cs[i].card == 0 {
+			b.keys = slices.Delete(b.keys, i, i+1)
+			b.cs = slices.Delete(b.cs, i, i+1)
+		}
+	}
+}
+
+// Contains reports whether all values are in the bitmap.
+func (b *Bitmap) Contains(values ...uint32) bool {
+	for _, v := range values {
+		i, found := b.find(uint16(v >> 16))
+		if !found || !b.cs[i].contains(uint16(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the cardinality in O(n), n is count of containers.
+func (b *Bitmap) Len() int {
+	n := 0
+	for _, c := range b.cs {
+		n += c.card
+	}
+	return n
+}
+
+// Clear clears all values in the bitmap.
+func (b *Bitmap) Clear() {
+	b.keys, b.cs = nil, nil
+}
+
+func (b *Bitmap) Clone() *Bitmap {
+	r := &Bitmap{keys: slices.Clone(b.keys), cs: make([]*container, len(b.cs))}
+	for i, c := range b.cs {
+		r.cs[i] = c.clone()
+	}
+	return r
+}
+
+func (b *Bitmap) Min() (uint32, bool) {
+	if len(b.cs) == 0 {
+		return 0, false
+	}
+	for lo := range b.cs[0].all() {
+		return uint32(b.keys[0])<<16 | uint32(lo), true
+	}
+	return 0, false
+}
+
+func (b *Bitmap) Max() (uint32, bool) {
+	if len(b.cs) == 0 {
+		return 0, false
+	}
+	n := len(b.cs) - 1
+	c := b.cs[n]
+	hi := uint32(b.keys[n]) << 16
+	if c.words == nil {
+		return hi | uint32(c.array[len(c.array)-1]), true
+	}
+	for i := len(c.words) - 1; i >= 0; i-- {
+		if c.words[i] != 0 {
+			return hi | uint32(i*64+63-bits.LeadingZeros64(c.words[i])), true
+		}
+	}
+	return 0, false
+}
+
+// All iterates values in ascending order, the bitmap must not be modified during iteration.
+func (b *Bitmap) All() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range b.cs {
+			hi := uint32(b.keys[i]) << 16
+			for lo := range c.all() {
+				if !yield(hi | uint32(lo)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Slice returns values in ascending order.
+func (b *Bitmap) Slice() []uint32 {
+	return slices.AppendSeq(make([]uint32, 0, b.Len()), b.All())
+}
+
+// merge combines containers of b and o with op, containers only in b or o are kept if keepB or keepO.
+func (b *Bitmap) merge(o *Bitmap, op func(a, b uint64) uint64, keepB, keepO bool) *Bitmap {
+	r := &Bitmap{}
+	appendC := func(key uint16, c *container) {
+		if c.card > 0 {
+			r.keys = append(r.keys, key)
+			r.cs = append(r.cs, c)
+		}
+	}
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(o.keys) {
+		switch {
+		case j >= len(o.keys) || (i < len(b.keys) && b.keys[i] < o.keys[j]):
+			if keepB {
+				appendC(b.keys[i], b.cs[i].clone())
+			}
+			i++
+		case i >= len(b.keys) || o.keys[j] < b.keys[i]:
+			if keepO {
+				appendC(o.keys[j], o.cs[j].clone())
+			}
+			j++
+		default:
+			appendC(b.keys[i], combine(b.cs[i], o.cs[j], op))
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// Union returns a new bitmap of values in b or o.
+func (b *Bitmap) Union(o *Bitmap) *Bitmap {
+	return b.merge(o, func(x, y uint64) uint64 { return x | y }, true, true)
+}
+
+// Intersect returns a new bitmap of values in both b and o.
+func (b *Bitmap) Intersect(o *Bitmap) *Bitmap {
+	return b.merge(o, func(x, y uint64) uint64 { return x & y }, false, false)
+}
+
+// Difference returns a new bitmap of values in b but not in o.
+func (b *Bitmap) Difference(o *Bitmap) *Bitmap {
+	return b.merge(o, func(x, y uint64) uint64 { return x &^ y }, true, false)
+}
+
+// SymmetricDifference returns a new bitmap of values in either b or o but not both.
+func (b *Bitmap) SymmetricDifference(o *Bitmap) *Bitmap {
+	return b.merge(o, func(x, y uint64) uint64 { return x ^ y }, true, true)
+}
+
+// IsSubset reports whether all values of b are in o.
+func (b *Bitmap) IsSubset(o *Bitmap) bool {
+	for i, key := range b.keys {
+		j, found := o.find(key)
+		if !found || b.cs[i].card > o.cs[j].card {
+			return false
+		}
+		for lo := range b.cs[i].all() {
+			if !o.cs[j].contains(lo) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether all values of o are in b.
+func (b *Bitmap) IsSuperset(o *Bitmap) bool {
+	return o.IsSubset(b)
+}
+
+func (b *Bitmap) Equal(o *Bitmap) bool {
+	return b.Len() == o.Len() && b.IsSubset(o)
+}
+
+func (b *Bitmap) String() string {
+	ss := make([]string, 0, b.Len())
+	for v := range b.All() {
+		ss = append(ss, fmt.Sprint(v))
+	}
+	return "{" + strings.Join(ss, " ") + "}"
+}
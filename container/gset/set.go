@@ -0,0 +1,159 @@
+package gset
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Set is an unordered set based on map, it is not safe for concurrent use, see SyncSet.
+type Set[T comparable] struct {
+	m map[T]struct{}
+}
+
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{m: make(map[T]struct{}, len(items))}
+	s.Add(items...)
+	return s
+}
+
+// Collect creates a Set from values of seq.
+func Collect[T comparable](seq iter.Seq[T]) *Set[T] {
+	s := NewSet[T]()
+	for v := range seq {
+		s.m[v] = struct{}{}
+	}
+	return s
+}
+
+func (s *Set[T]) Add(items ...T) {
+	for _, v := range items {
+		s.m[v] = struct{}{}
+	}
+}
+
+func (s *Set[T]) Remove(items ...T) {
+	for _, v := range items {
+		delete(s.m, v)
+	}
+}
+
+// Contains reports whether all items are in the set.
+func (s *Set[T]) Contains(items ...T) bool {
+	for _, v := range items {
+		if _, ok := s.m[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAny reports whether any of items is in the set.
+func (s *Set[T]) ContainsAny(items ...T) bool {
+	for _, v := range items {
+		if _, ok := s.m[v]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Set[T]) Len() int {
+	return len(s.m)
+}
+
+// Clear clears all values in the set.
+func (s *Set[T]) Clear() {
+	clear(s.m)
+}
+
+func (s *Set[T]) Clone() *Set[T] {
+	return &Set[T]{m: maps.Clone(s.m)}
+}
+
+// All iterates values in random order, values can be removed during iteration.
+func (s *Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s.m)
+}
+
+// Slice returns values in random order.
+func (s *Set[T]) Slice() []T {
+	return slices.AppendSeq(make([]T, 0, len(s.m)), maps.Keys(s.m))
+}
+
+// Union returns a new set of values in s or o.
+func (s *Set[T]) Union(o *Set[T]) *Set[T] {
+	r := s.Clone()
+	maps.Copy(r.m, o.m)
+	return r
+}
+
+// Intersect returns a new set of values in both s and o.
+func (s *Set[T]) Intersect(o *Set[T]) *Set[T] {
+	small, big := s, o
+	if small.Len() > big.Len() {
+		small, big = big, small
+	}
+	r := NewSet[T]()
+	for v := range small.m {
+		if _, ok := big.m[v]; ok {
+			r.m[v] = struct{}{}
+		}
+	}
+	return r
+}
+
+// Difference returns a new set of values in s but not in o.
+func (s *Set[T]) Difference(o *Set[T]) *Set[T] {
+	r := NewSet[T]()
+	for v := range s.m {
+		if _, ok := o.m[v]; !ok {
+			r.m[v] = struct{}{}
+		}
+	}
+	return r
+}
+
+// SymmetricDifference returns a new set of values in either s or o but not both.
+func (s *Set[T]) SymmetricDifference(o *Set[T]) *Set[T] {
+	r := s.Difference(o)
+	for v := range o.m {
+		if _, ok := s.m[v]; !ok {
+			r.m[v] = struct{}{}
+		}
+	}
+	return r
+}
+
+// IsSubset reports whether all values of s are in o.
+func (s *Set[T]) IsSubset(o *Set[T]) bool {
+	if s.Len() > o.Len() {
+		return false
+	}
+	for v := range s.m {
+		if _, ok := o.m[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether all values of o are in s.
+func (s *Set[T]) IsSuperset(o *Set[T]) bool {
+	return o.IsSubset(s)
+}
+
+func (s *Set[T]) Equal(o *Set[T]) bool {
+	return s.Len() == o.Len() && s.IsSubset(o)
+}
+
+func (s *Set[T]) String() string {
+	ss := make([]string, 0, len(s.m))
+	for v := range s.m {
+		ss = append(ss, fmt.Sprint(v))
+	}
+	slices.Sort(ss)
+	return "{" + strings.Join(ss, " ") + "}"
+}
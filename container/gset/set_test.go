@@ -0,0 +1,137 @@
+package gset
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+	gtest.AssertTrue(t, a.Union(b).String() == "{1 2 3 4 5}", "union %s", a.Union(b))
+	gtest.AssertTrue(t, a.Intersect(b).String() == "{3 4}", "intersect %s", a.Intersect(b))
+	gtest.AssertTrue(t, a.Difference(b).String() == "{1 2}", "difference %s", a.Difference(b))
+	gtest.AssertTrue(t, a.SymmetricDifference(b).String() == "{1 2 5}", "symmetric difference %s", a.SymmetricDifference(b))
+	gtest.AssertTrue(t, NewSet(3, 4).IsSubset(a) && a.IsSuperset(NewSet(3, 4)) && !b.IsSubset(a), "subset")
+	gtest.AssertTrue(t, a.Contains(1, 2) && !a.Contains(1, 5) && a.ContainsAny(0, 4), "contains")
+	gtest.AssertTrue(t, Collect(slices.Values([]int{4, 3, 2, 1, 1})).Equal(a), "collect")
+	for v := range a.All() {
+		a.Remove(v)
+	}
+	gtest.AssertTrue(t, a.Len() == 0, "remove during iteration")
+}
+
+func TestTreeSet(t *testing.T) {
+	a := NewTreeSet(5, 1, 3, 9, 7)
+	b := NewTreeSet(3, 4, 5, 6)
+	gtest.AssertTrue(t, a.String() == "{1 3 5 7 9}", "order %s", a)
+	gtest.AssertTrue(t, a.Union(b).String() == "{1 3 4 5 6 7 9}", "union %s", a.Union(b))
+	gtest.AssertTrue(t, a.Intersect(b).String() == "{3 5}", "intersect %s", a.Intersect(b))
+	gtest.AssertTrue(t, a.Difference(b).String() == "{1 7 9}", "difference %s", a.Difference(b))
+	gtest.AssertTrue(t, a.SymmetricDifference(b).String() == "{1 4 6 7 9}", "symmetric difference %s", a.SymmetricDifference(b))
+	gtest.AssertTrue(t, NewTreeSet(3, 5).IsSubset(a) && !b.IsSubset(a) && a.Clone().Equal(a), "subset")
+
+	floor, _ := a.Floor(6)
+	ceiling, _ := a.Ceiling(6)
+	lo, _ := a.Min()
+	hi, _ := a.Max()
+	gtest.AssertTrue(t, floor == 5 && ceiling == 7 && lo == 1 && hi == 9, "floor %d ceiling %d min %d max %d", floor, ceiling, lo, hi)
+	gtest.AssertTrue(t, slices.Equal(slices.Collect(a.Range(3, 9)), []int{3, 5, 7}), "range")
+	gtest.AssertTrue(t, slices.Equal(slices.Collect(a.Backward()), []int{9, 7, 5, 3, 1}), "backward")
+
+	desc := NewTreeSetFunc(func(a, b string) int { return -strings.Compare(a, b) })
+	desc.Add("a", "c", "b")
+	gtest.AssertTrue(t, desc.String() == "{c b a}", "custom order %s", desc)
+}
+
+// TestBitmap compares Bitmap with Set, values are dense enough to create both kinds of containers.
+func TestBitmap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(44))
+	random := func() (*Bitmap, *Set[uint32]) {
+		b, s := NewBitmap(), NewSet[uint32]()
+		for i := 0; i < 20000; i++ {
+			// container 0 is dense, container 1 is sparse, others are random
+			v := uint32(rnd.Intn(10000))
+			switch rnd.Intn(3) {
+			case 1:
+				v = 1<<16 + uint32(rnd.Intn(60000))
+			case 2:
+				v = rnd.Uint32()
+			}
+			b.Add(v)
+			s.Add(v)
+		}
+		for i := 0; i < 6000; i++ {
+			v := uint32(rnd.Intn(10000))
+			b.Remove(v)
+			s.Remove(v)
+		}
+		return b, s
+	}
+	check := func(b *Bitmap, s *Set[uint32], name string) {
+		want := s.Slice()
+		slices.Sort(want)
+		got := b.Slice()
+		gtest.AssertTrue(t, b.Len() == len(want) && slices.Equal(got, want), "%s: %d != %d", name, b.Len(), len(want))
+		for _, v := range want[:100] {
+			gtest.AssertTrue(t, b.Contains(v), "%s contains %d", name, v)
+		}
+		if len(want) > 0 {
+			lo, _ := b.Min()
+			hi, _ := b.Max()
+			gtest.AssertTrue(t, lo == want[0] && hi == want[len(want)-1], "%s min %d max %d", name, lo, hi)
+		}
+	}
+	for round := 0; round < 5; round++ {
+		a, as := random()
+		b, bs := random()
+		check(a, as, "a")
+		check(a.Union(b), as.Union(bs), "union")
+		check(a.Intersect(b), as.Intersect(bs), "intersect")
+		check(a.Difference(b), as.Difference(bs), "difference")
+		check(a.SymmetricDifference(b), as.SymmetricDifference(bs), "symmetric difference")
+		gtest.AssertTrue(t, a.Intersect(b).IsSubset(a) && !a.IsSubset(b) && a.Clone().Equal(a), "subset")
+	}
+
+	b := NewBitmap()
+	b.AddRange(0, 5000)
+	b.Remove(0, 1, 2)
+	b.Remove(3)
+	gtest.AssertTrue(t, b.Len() == 4996 && b.cs[0].words != nil, "bitmap container %d", b.Len())
+	for v := uint32(4); v < 1004; v++ {
+		b.Remove(v)
+	}
+	gtest.AssertTrue(t, b.Len() == 3996 && b.cs[0].words == nil, "array container %d", b.Len())
+	b.Remove(b.Slice()...)
+	gtest.AssertTrue(t, b.Len() == 0 && len(b.cs) == 0, "empty")
+}
+
+func TestSyncSet(t *testing.T) {
+	s := NewSyncSet[int]()
+	bm := NewSyncBitmap()
+	added := make([]bool, 100)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if s.AddIfAbsent(i) {
+					mu.Lock()
+					gtest.AssertTrue(t, !added[i], "added twice %d", i)
+					added[i] = true
+					mu.Unlock()
+				}
+				bm.Add(uint32(i))
+				_ = s.Union(s).Len()
+			}
+		}()
+	}
+	wg.Wait()
+	gtest.AssertTrue(t, s.Len() == 100 && bm.Len() == 100, "len %d %d", s.Len(), bm.Len())
+}
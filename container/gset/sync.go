@@ -0,0 +1,161 @@
+package gset
+
+import (
+	"iter"
+	"slices"
+	"sync"
+)
+
+type (
+	// SyncSet is a Set protected by RWMutex, set algebra takes a snapshot of the other set first,
+	// so it never holds two locks at the same time.
+	SyncSet[T comparable] struct {
+		mu  sync.RWMutex
+		set *Set[T]
+	}
+
+	// SyncBitmap is a Bitmap protected by RWMutex.
+	SyncBitmap struct {
+		mu sync.RWMutex
+		bm *Bitmap
+	}
+)
+
+func NewSyncSet[T comparable](items ...T) *SyncSet[T] {
+	return &SyncSet[T]{set: NewSet(items...)}
+}
+
+func (s *SyncSet[T]) Add(items ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Add(items...)
+}
+
+// AddIfAbsent adds item and returns true if it was not in the set, it is atomic.
+func (s *SyncSet[T]) AddIfAbsent(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.set.Contains(item) {
+		return false
+	}
+	s.set.Add(item)
+	return true
+}
+
+func (s *SyncSet[T]) Remove(items ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Remove(items...)
+}
+
+func (s *SyncSet[T]) Contains(items ...T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Contains(items...)
+}
+
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Len()
+}
+
+func (s *SyncSet[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Clear()
+}
+
+// Snapshot returns a copy as Set.
+func (s *SyncSet[T]) Snapshot() *Set[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Clone()
+}
+
+// All iterates a snapshot, so the set can be modified during iteration.
+func (s *SyncSet[T]) All() iter.Seq[T] {
+	return slices.Values(s.Snapshot().Slice())
+}
+
+func (s *SyncSet[T]) Union(o *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().Union(o.Snapshot())}
+}
+
+func (s *SyncSet[T]) Intersect(o *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().Intersect(o.Snapshot())}
+}
+
+func (s *SyncSet[T]) Difference(o *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().Difference(o.Snapshot())}
+}
+
+func (s *SyncSet[T]) SymmetricDifference(o *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().SymmetricDifference(o.Snapshot())}
+}
+
+func (s *SyncSet[T]) IsSubset(o *SyncSet[T]) bool {
+	return s.Snapshot().IsSubset(o.Snapshot())
+}
+
+func (s *SyncSet[T]) Equal(o *SyncSet[T]) bool {
+	return s.Snapshot().Equal(o.Snapshot())
+}
+
+func NewSyncBitmap(values ...uint32) *SyncBitmap {
+	return &SyncBitmap{bm: NewBitmap(values...)}
+}
+
+func (b *SyncBitmap) Add(values ...uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bm.Add(values...)
+}
+
+func (b *SyncBitmap) Remove(values ...uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bm.Remove(values...)
+}
+
+func (b *SyncBitmap) Contains(values ...uint32) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.bm.Contains(values...)
+}
+
+func (b *SyncBitmap) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.bm.Len()
+}
+
+func (b *SyncBitmap) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bm.Clear()
+}
+
+// Snapshot returns a copy as Bitmap.
+func (b *SyncBitmap) Snapshot() *Bitmap {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.bm.Clone()
+}
+
+// All iterates a snapshot, so the bitmap can be modified during iteration.
+func (b *SyncBitmap) All() iter.Seq[uint32] {
+	return b.Snapshot().All()
+}
+
+func (b *SyncBitmap) Union(o *SyncBitmap) *SyncBitmap {
+	return &SyncBitmap{bm: b.Snapshot().Union(o.Snapshot())}
+}
+
+func (b *SyncBitmap) Intersect(o *SyncBitmap) *SyncBitmap {
+	return &SyncBitmap{bm: b.Snapshot().Intersect(o.Snapshot())}
+}
+
+func (b *SyncBitmap) Difference(o *SyncBitmap) *SyncBitmap {
+	return &SyncBitmap{bm: b.Snapshot().Difference(o.Snapshot())}
+}
@@ -0,0 +1,211 @@
+package gset
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/davidforest123/goutil/container/gmap"
+	"iter"
+	"strings"
+)
+
+// TreeSet is a sorted set based on gmap.TreeMap, Add, Remove and Contains are O(log n),
+// set algebra merges sorted values in O(n + m).
+// It is not safe for concurrent use.
+type TreeSet[T any] struct {
+	tm  *gmap.TreeMap[T, struct{}]
+	cmp func(a, b T) int
+}
+
+func NewTreeSet[T cmp.Ordered](items ...T) *TreeSet[T] {
+	s := NewTreeSetFunc[T](cmp.Compare[T])
+	s.Add(items...)
+	return s
+}
+
+// NewTreeSetFunc creates a TreeSet with custom comparator which returns -1, 0 or +1 like cmp.Compare.
+func NewTreeSetFunc[T any](compare func(a, b T) int) *TreeSet[T] {
+	return &TreeSet[T]{tm: gmap.NewTreeMapFunc[T, struct{}](compare), cmp: compare}
+}
+
+func (s *TreeSet[T]) Add(items ...T) {
+	for _, v := range items {
+		s.tm.Set(v, struct{}{})
+	}
+}
+
+func (s *TreeSet[T]) Remove(items ...T) {
+	for _, v := range items {
+		s.tm.Delete(v)
+	}
+}
+
+// Contains reports whether all items are in the set.
+func (s *TreeSet[T]) Contains(items ...T) bool {
+	for _, v := range items {
+		if !s.tm.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *TreeSet[T]) Len() int {
+	return s.tm.Len()
+}
+
+// Clear clears all values in the set.
+func (s *TreeSet[T]) Clear() {
+	s.tm.Clear()
+}
+
+func (s *TreeSet[T]) Clone() *TreeSet[T] {
+	r := NewTreeSetFunc(s.cmp)
+	for v := range s.All() {
+		r.tm.Set(v, struct{}{})
+	}
+	return r
+}
+
+func (s *TreeSet[T]) Min() (T, bool) {
+	v, _, ok := s.tm.Min()
+	return v, ok
+}
+
+func (s *TreeSet[T]) Max() (T, bool) {
+	v, _, ok := s.tm.Max()
+	return v, ok
+}
+
+// Floor returns the greatest value <= v.
+func (s *TreeSet[T]) Floor(v T) (T, bool) {
+	r, _, ok := s.tm.Floor(v)
+	return r, ok
+}
+
+// Ceiling returns the least value >= v.
+func (s *TreeSet[T]) Ceiling(v T) (T, bool) {
+	r, _, ok := s.tm.Ceiling(v)
+	return r, ok
+}
+
+func keys[T any](seq iter.Seq2[T, struct{}]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// All iterates values in ascending order, the set must not be modified during iteration.
+func (s *TreeSet[T]) All() iter.Seq[T] {
+	return keys(s.tm.All())
+}
+
+// Backward iterates values in descending order.
+func (s *TreeSet[T]) Backward() iter.Seq[T] {
+	return keys(s.tm.Backward())
+}
+
+// Range iterates values with from <= v < to in ascending order.
+func (s *TreeSet[T]) Range(from, to T) iter.Seq[T] {
+	return keys(s.tm.Range(from, to))
+}
+
+// Slice returns values in ascending order.
+func (s *TreeSet[T]) Slice() []T {
+	return s.tm.Keys()
+}
+
+// merge walks sorted values of s and o together, emit decides whether a value is kept
+// by whether it is in s and whether it is in o.
+func (s *TreeSet[T]) merge(o *TreeSet[T], emit func(inS, inO bool) bool) *TreeSet[T] {
+	r := NewTreeSetFunc(s.cmp)
+	a, stopA := iter.Pull(s.All())
+	defer stopA()
+	b, stopB := iter.Pull(o.All())
+	defer stopB()
+	va, okA := a()
+	vb, okB := b()
+	for okA || okB {
+		c := 0
+		switch {
+		case !okB:
+			c = -1
+		case !okA:
+			c = 1
+		default:
+			c = s.cmp(va, vb)
+		}
+		switch {
+		case c < 0:
+			if emit(true, false) {
+				r.tm.Set(va, struct{}{})
+			}
+			va, okA = a()
+		case c > 0:
+			if emit(false, true) {
+				r.tm.Set(vb, struct{}{})
+			}
+			vb, okB = b()
+		default:
+			if emit(true, true) {
+				r.tm.Set(va, struct{}{})
+			}
+			va, okA = a()
+			vb, okB = b()
+		}
+	}
+	return r
+}
+
+// Union returns a new set of values in s or o, it uses the comparator of s.
+func (s *TreeSet[T]) Union(o *TreeSet[T]) *TreeSet[T] {
+	return s.merge(o, func(inS, inO bool) bool { return true })
+}
+
+// Intersect returns a new set of values in both s and o.
+func (s *TreeSet[T]) Intersect(o *TreeSet[T]) *TreeSet[T] {
+	return s.merge(o, func(inS, inO bool) bool { return inS && inO })
+}
+
+// Difference returns a new set of values in s but not in o.
+func (s *TreeSet[T]) Difference(o *TreeSet[T]) *TreeSet[T] {
+	return s.merge(o, func(inS, inO bool) bool { return inS && !inO })
+}
+
+// SymmetricDifference returns a new set of values in either s or o but not both.
+func (s *TreeSet[T]) SymmetricDifference(o *TreeSet[T]) *TreeSet[T] {
+	return s.merge(o, func(inS, inO bool) bool { return inS != inO })
+}
+
+// IsSubset reports whether all values of s are in o.
+func (s *TreeSet[T]) IsSubset(o *TreeSet[T]) bool {
+	if s.Len() > o.Len() {
+		return false
+	}
+	for v := range s.All() {
+		if !o.tm.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether all values of o are in s.
+func (s *TreeSet[T]) IsSuperset(o *TreeSet[T]) bool {
+	return o.IsSubset(s)
+}
+
+func (s *TreeSet[T]) Equal(o *TreeSet[T]) bool {
+	return s.Len() == o.Len() && s.IsSubset(o)
+}
+
+func (s *TreeSet[T]) String() string {
+	ss := make([]string, 0, s.Len())
+	for v := range s.All() {
+		ss = append(ss, fmt.Sprint(v))
+	}
+	return "{" + strings.Join(ss, " ") + "}"
+}
@@ -0,0 +1,302 @@
+package gspeed
+
+import (
+	"github.com/davidforest123/goutil/container/gnum"
+	"math"
+	"sync"
+	"time"
+)
+
+/*
+Meter measures throughput of a byte stream, all methods are safe for concurrent use.
+
+Rate: bytes of the last Window, counted in fixed buckets, so memory doesn't grow with traffic.
+Rate1/Rate5/Rate15: exponentially weighted moving average of 1/5/15 minutes like unix load average,
+ticked every 5 seconds.
+MeanRate: bytes since NewMeter or Reset divided by elapsed time.
+Peak/Percentile: distribution of per-second speeds, idle seconds count as zero speed.
+*/
+
+const (
+	ewmaTickInterval = 5 * time.Second
+	meterPeakSlot    = time.Second
+)
+
+type (
+	// Clock is the time source of Meter, gtime.Clock implements it. gtime can't be imported here
+	// because gtime depends on gspeed indirectly.
+	Clock interface {
+		Now() time.Time
+	}
+
+	sysClock struct{}
+
+	MeterOptions struct {
+		Clock   Clock         // default system clock
+		Window  time.Duration // sliding window of Rate, default 10s
+		Buckets int           // buckets of sliding window, default 10
+	}
+
+	Meter struct {
+		mu    sync.Mutex
+		clock Clock
+		begin time.Time
+		total uint64
+
+		// sliding window
+		bucketDur time.Duration
+		buckets   []uint64
+		epochs    []int64 // epoch of every bucket, bucket is stale if its epoch is out of window
+
+		// ewma
+		lastTick  time.Time
+		uncounted uint64
+		m1        ewma
+		m5        ewma
+		m15       ewma
+
+		// per-second speeds
+		slot      int64 // current second since begin
+		slotBytes uint64
+		sketch    *gnum.DDSketch
+		peak      float64
+	}
+
+	// MeterSnapshot is a point-in-time copy of Meter statistics.
+	MeterSnapshot struct {
+		Total    uint64
+		Rate     Speed
+		Rate1    Speed
+		Rate5    Speed
+		Rate15   Speed
+		MeanRate Speed
+		Peak     Speed
+		P50      Speed
+		P90      Speed
+		P99      Speed
+	}
+
+	ewma struct {
+		alpha float64
+		rate  float64 // bytes per second
+		init  bool
+	}
+)
+
+func (sysClock) Now() time.Time { return time.Now() }
+
+func newEWMA(minutes float64) ewma {
+	return ewma{alpha: 1 - math.Exp(-ewmaTickInterval.Seconds()/60/minutes)}
+}
+
+func (e *ewma) tick(bytes uint64) {
+	instant := float64(bytes) / ewmaTickInterval.Seconds()
+	if e.init {
+		e.rate += e.alpha * (instant - e.rate)
+	} else {
+		e.rate = instant
+		e.init = true
+	}
+}
+
+// decay applies n ticks without any bytes.
+func (e *ewma) decay(n int64) {
+	if e.init && n > 0 {
+		e.rate *= math.Pow(1-e.alpha, float64(n))
+	}
+}
+
+func NewMeter(opts MeterOptions) *Meter {
+	if opts.Clock == nil {
+		opts.Clock = sysClock{}
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10 * time.Second
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = 10
+	}
+	bucketDur := opts.Window / time.Duration(opts.Buckets)
+	if bucketDur <= 0 {
+		bucketDur = 1
+	}
+	m := &Meter{
+		clock:     opts.Clock,
+		bucketDur: bucketDur,
+		buckets:   make([]uint64, opts.Buckets),
+		epochs:    make([]int64, opts.Buckets),
+	}
+	m.reset()
+	return m
+}
+
+func (m *Meter) reset() {
+	m.begin = m.clock.Now()
+	m.total = 0
+	clear(m.buckets)
+	for i := range m.epochs {
+		m.epochs[i] = math.MinInt64
+	}
+	m.lastTick = m.begin
+	m.uncounted = 0
+	m.m1, m.m5, m.m15 = newEWMA(1), newEWMA(5), newEWMA(15)
+	m.slot, m.slotBytes, m.peak = 0, 0, 0
+	m.sketch, _ = gnum.NewDDSketch(0.01, 2048)
+}
+
+// Reset clears all statistics and restarts timing.
+func (m *Meter) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reset()
+}
+
+// advance moves ewma ticks and per-second slots to now.
+func (m *Meter) advance(now time.Time) {
+	if ticks := int64(now.Sub(m.lastTick) / ewmaTickInterval); ticks > 0 {
+		for _, e := range []*ewma{&m.m1, &m.m5, &m.m15} {
+			e.tick(m.uncounted)
+			e.decay(ticks - 1)
+		}
+		m.uncounted = 0
+		m.lastTick = m.lastTick.Add(time.Duration(ticks) * ewmaTickInterval)
+	}
+
+	if slot := int64(now.Sub(m.begin) / meterPeakSlot); slot > m.slot {
+		bits := float64(m.slotBytes) * 8 / meterPeakSlot.Seconds()
+		m.sketch.Add(bits)
+		m.peak = math.Max(m.peak, bits)
+		m.sketch.AddN(0, uint64(slot-m.slot-1))
+		m.slot, m.slotBytes = slot, 0
+	}
+}
+
+// Mark records n bytes transferred now.
+func (m *Meter) Mark(n uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.advance(now)
+	m.total += n
+	m.uncounted += n
+	m.slotBytes += n
+
+	epoch := now.UnixNano() / int64(m.bucketDur)
+	i := int(epoch % int64(len(m.buckets)))
+	if m.epochs[i] != epoch {
+		m.epochs[i] = epoch
+		m.buckets[i] = 0
+	}
+	m.buckets[i] += n
+}
+
+// Total returns bytes marked since NewMeter or Reset.
+func (m *Meter) Total() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
+// rate returns speed of sliding window, the current bucket is partial, so the window ends at now
+// instead of the end of current bucket, and it never starts before begin.
+func (m *Meter) rate(now time.Time) Speed {
+	n := int64(len(m.buckets))
+	epoch := now.UnixNano() / int64(m.bucketDur)
+	bytes := uint64(0)
+	for i, e := range m.epochs {
+		if e > epoch-n && e <= epoch {
+			bytes += m.buckets[i]
+		}
+	}
+	windowBegin := time.Unix(0, (epoch-n+1)*int64(m.bucketDur))
+	if windowBegin.Before(m.begin) {
+		windowBegin = m.begin
+	}
+	elapsed := now.Sub(windowBegin)
+	if elapsed <= 0 {
+		return 0
+	}
+	return Speed(float64(bytes) * 8 / elapsed.Seconds())
+}
+
+// Rate returns speed of the sliding window.
+func (m *Meter) Rate() Speed {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rate(m.clock.Now())
+}
+
+func (m *Meter) ewmaRate(e *ewma) Speed {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance(m.clock.Now())
+	return Speed(e.rate * 8)
+}
+
+// Rate1 returns 1-minute exponentially weighted moving average speed.
+func (m *Meter) Rate1() Speed { return m.ewmaRate(&m.m1) }
+
+// Rate5 returns 5-minute exponentially weighted moving average speed.
+func (m *Meter) Rate5() Speed { return m.ewmaRate(&m.m5) }
+
+// Rate15 returns 15-minute exponentially weighted moving average speed.
+func (m *Meter) Rate15() Speed { return m.ewmaRate(&m.m15) }
+
+func (m *Meter) meanRate(now time.Time) Speed {
+	elapsed := now.Sub(m.begin)
+	if elapsed <= 0 {
+		return 0
+	}
+	return Speed(float64(m.total) * 8 / elapsed.Seconds())
+}
+
+// MeanRate returns average speed since NewMeter or Reset.
+func (m *Meter) MeanRate() Speed {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.meanRate(m.clock.Now())
+}
+
+// Peak returns the highest speed of finished seconds.
+func (m *Meter) Peak() Speed {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance(m.clock.Now())
+	return Speed(m.peak)
+}
+
+func (m *Meter) percentile(q float64) Speed {
+	if m.sketch.Count() == 0 {
+		return 0
+	}
+	return Speed(m.sketch.Quantile(q))
+}
+
+// Percentile returns q-quantile (0 <= q <= 1) of per-second speeds of finished seconds,
+// it is accurate within 1%.
+func (m *Meter) Percentile(q float64) Speed {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance(m.clock.Now())
+	return m.percentile(q)
+}
+
+func (m *Meter) Snapshot() MeterSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	m.advance(now)
+	return MeterSnapshot{
+		Total:    m.total,
+		Rate:     m.rate(now),
+		Rate1:    Speed(m.m1.rate * 8),
+		Rate5:    Speed(m.m5.rate * 8),
+		Rate15:   Speed(m.m15.rate * 8),
+		MeanRate: m.meanRate(now),
+		Peak:     Speed(m.peak),
+		P50:      m.percentile(0.5),
+		P90:      m.percentile(0.9),
+		P99:      m.percentile(0.99),
+	}
+}
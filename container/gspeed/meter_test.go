@@ -0,0 +1,108 @@
+package gspeed
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manual clock, gtime.MockClock can't be used because gtime depends on gspeed.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func near(a, b Speed, tolerance float64) bool {
+	return math.Abs(float64(a-b)) <= tolerance*math.Abs(float64(b))
+}
+
+func TestMeter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	m := NewMeter(MeterOptions{Clock: clock, Window: 10 * time.Second, Buckets: 10})
+
+	for i := 0; i < 10; i++ {
+		m.Mark(1000)
+		clock.Add(time.Second)
+	}
+	gtest.AssertTrue(t, m.Total() == 10000, "Total got %d", m.Total())
+	gtest.AssertTrue(t, m.Rate() == 8000, "Rate got %v", float64(m.Rate()))
+	gtest.AssertTrue(t, m.MeanRate() == 8000, "MeanRate got %v", float64(m.MeanRate()))
+
+	m.Mark(5000)
+	clock.Add(time.Second)
+	gtest.AssertTrue(t, m.Peak() == 40000, "Peak got %v", float64(m.Peak()))
+	gtest.AssertTrue(t, near(m.Percentile(0.5), 8000, 0.01), "P50 got %v", float64(m.Percentile(0.5)))
+	gtest.AssertTrue(t, near(m.Percentile(1), 40000, 0.01), "P100 got %v", float64(m.Percentile(1)))
+
+	// steady 1000 B/s for 10 minutes
+	for i := 0; i < 600; i++ {
+		m.Mark(1000)
+		clock.Add(time.Second)
+	}
+	gtest.AssertTrue(t, near(m.Rate1(), 8000, 0.01), "Rate1 got %v", float64(m.Rate1()))
+	gtest.AssertTrue(t, near(m.Rate(), 8000, 0.001), "Rate got %v", float64(m.Rate()))
+
+	// idle for 5 minutes, 1-minute rate decays faster
+	clock.Add(5 * time.Minute)
+	s := m.Snapshot()
+	gtest.AssertTrue(t, s.Rate == 0, "Rate after idle got %v", float64(s.Rate))
+	gtest.AssertTrue(t, s.Rate1 < 100 && s.Rate15 > s.Rate5 && s.Rate5 > s.Rate1, "decayed rates got %+v", s)
+	gtest.AssertTrue(t, near(s.P50, 8000, 0.01), "P50 got %v", float64(s.P50))
+	gtest.AssertTrue(t, m.Percentile(0.2) == 0, "idle seconds should count as zero speed, P20 got %v", float64(m.Percentile(0.2)))
+
+	m.Reset()
+	gtest.AssertTrue(t, m.Total() == 0 && m.Rate() == 0 && m.Peak() == 0, "Reset failed")
+}
+
+func TestSpeedCounter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(2_000_000, 0)}
+	c := NewSpeedCounterWithClock(time.Minute, clock)
+	_, err := c.Get()
+	gtest.AssertTrue(t, err != nil, "Get before BeginCount should fail")
+
+	c.BeginCount()
+	c.Add(1000)
+	clock.Add(2 * time.Second)
+	s, err := c.Get()
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, *s == 4000, "Get got %v", float64(*s))
+
+	clock.Add(2 * time.Minute)
+	s, err = c.Get()
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, *s == 0, "expired bytes still counted, got %v", float64(*s))
+}
+
+func TestMeteredConn(t *testing.T) {
+	c1, c2 := net.Pipe()
+	read, write := NewMeter(MeterOptions{}), NewMeter(MeterOptions{})
+	mc := NewMeteredConn(c1, read, write)
+	go func() {
+		_, _ = mc.Write(make([]byte, 100))
+		_, _ = c2.Write(make([]byte, 30))
+	}()
+	buf := make([]byte, 100)
+	_, err := io.ReadFull(c2, buf)
+	gtest.Assert(t, err)
+	_, err = io.ReadFull(NewMeteredReader(mc, nil), buf[:30])
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, write.Total() == 100 && read.Total() == 30, "metered write %d read %d", write.Total(), read.Total())
+	_ = mc.Close()
+	_ = c2.Close()
+}
@@ -0,0 +1,95 @@
+package gspeed
+
+import (
+	"io"
+	"net"
+)
+
+// Wrappers below mark every transferred byte to meters, nil meter is allowed and ignored.
+// They deliberately don't implement io.WriterTo / io.ReaderFrom, so io.Copy and gio.TwoWaysCopy
+// can't bypass metering.
+
+type (
+	meteredReader struct {
+		r io.Reader
+		m *Meter
+	}
+
+	meteredWriter struct {
+		w io.Writer
+		m *Meter
+	}
+
+	// MeteredConn is a net.Conn which marks read bytes to ReadMeter and written bytes to WriteMeter,
+	// it also works as io.ReadWriteCloser for gio.TwoWaysCopy.
+	MeteredConn struct {
+		net.Conn
+		ReadMeter  *Meter
+		WriteMeter *Meter
+	}
+
+	// MeteredReadWriteCloser is MeteredConn for io.ReadWriteCloser which is not a net.Conn.
+	MeteredReadWriteCloser struct {
+		io.ReadWriteCloser
+		ReadMeter  *Meter
+		WriteMeter *Meter
+	}
+)
+
+func mark(m *Meter, n int) {
+	if m != nil && n > 0 {
+		m.Mark(uint64(n))
+	}
+}
+
+func NewMeteredReader(r io.Reader, m *Meter) io.Reader {
+	return &meteredReader{r: r, m: m}
+}
+
+func (mr *meteredReader) Read(p []byte) (int, error) {
+	n, err := mr.r.Read(p)
+	mark(mr.m, n)
+	return n, err
+}
+
+func NewMeteredWriter(w io.Writer, m *Meter) io.Writer {
+	return &meteredWriter{w: w, m: m}
+}
+
+func (mw *meteredWriter) Write(p []byte) (int, error) {
+	n, err := mw.w.Write(p)
+	mark(mw.m, n)
+	return n, err
+}
+
+func NewMeteredConn(c net.Conn, readMeter, writeMeter *Meter) *MeteredConn {
+	return &MeteredConn{Conn: c, ReadMeter: readMeter, WriteMeter: writeMeter}
+}
+
+func (c *MeteredConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	mark(c.ReadMeter, n)
+	return n, err
+}
+
+func (c *MeteredConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	mark(c.WriteMeter, n)
+	return n, err
+}
+
+func NewMeteredReadWriteCloser(rwc io.ReadWriteCloser, readMeter, writeMeter *Meter) *MeteredReadWriteCloser {
+	return &MeteredReadWriteCloser{ReadWriteCloser: rwc, ReadMeter: readMeter, WriteMeter: writeMeter}
+}
+
+func (c *MeteredReadWriteCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	mark(c.ReadMeter, n)
+	return n, err
+}
+
+func (c *MeteredReadWriteCloser) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	mark(c.WriteMeter, n)
+	return n, err
+}
@@ -0,0 +1,606 @@
+package gstruct
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Binary codec driven by `bin` struct tags, codecs are compiled by reflection once per type and cached.
+
+tag options, separated by comma:
+-                  skip the field
+u8 ... u64, i8 ... i64, uvarint, varint, f32, f64, bool
+                   wire kind of numbers, default: fixed size of Go type, int/uint are varint/uvarint
+be / le            byte order, default little endian
+len=u8|u16|u32|uvarint
+                   length prefix of strings and slices, default uvarint
+since=N            field is added in schema version N
+
+Fixed arrays have no length prefix, pointers are optional fields with a presence byte, nested structs are
+encoded in place. Options of slices, arrays and pointers apply to their elements. Unexported fields are
+ignored. Maps and interfaces are not supported.
+
+A blank field carries options of the whole struct:
+	_ struct{} `bin:"version=2,be"`
+version=N makes the struct versioned, it is encoded as uvarint version + uvarint body length + body.
+New fields must be appended at the end with since=N, readers skip fields newer than data version and
+ignore trailing body bytes of fields they don't know, so data is both forward and backward compatible.
+be / le sets default byte order of all fields.
+*/
+
+type (
+	// BinaryAppender and BinaryDecoder are implemented by code generated by GenerateBinary,
+	// the codec uses them instead of reflection.
+	BinaryAppender interface {
+		AppendBin(p []byte) ([]byte, error)
+	}
+
+	BinaryDecoder interface {
+		// DecodeBin decodes from p and returns the rest bytes.
+		DecodeBin(p []byte) ([]byte, error)
+	}
+
+	binOpts struct {
+		kind    BinKind
+		lenKind BinKind
+		endian  uint8 // 0: unset, 1: little endian, 2: big endian
+		since   uint64
+		version uint64 // only for blank field
+		skip    bool
+	}
+
+	binCodec struct {
+		enc func(p []byte, v reflect.Value) ([]byte, error)
+		dec func(p []byte, v reflect.Value) ([]byte, error)
+	}
+
+	binKey struct {
+		t    reflect.Type
+		opts binOpts
+	}
+
+	binField struct {
+		index int
+		since uint64
+		codec *binCodec
+	}
+
+	binCompiler struct {
+		pending map[binKey]*binCodec
+	}
+)
+
+const (
+	endianLittle = 1
+	endianBig    = 2
+
+	// maxBinEmptyElems limits decoded slices of elements encoded to no bytes like []struct{}.
+	maxBinEmptyElems = 1 << 16
+)
+
+var (
+	binCodecs    sync.Map // binKey -> *binCodec
+	binCompileMu sync.Mutex
+
+	appenderType = reflect.TypeOf((*BinaryAppender)(nil)).Elem()
+	decoderType  = reflect.TypeOf((*BinaryDecoder)(nil)).Elem()
+)
+
+func parseBinTag(tag string) (binOpts, error) {
+	o := binOpts{}
+	if tag == "-" {
+		o.skip = true
+		return o, nil
+	}
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		name, val, hasVal := strings.Cut(item, "=")
+		switch {
+		case item == "":
+		case item == "be":
+			o.endian = endianBig
+		case item == "le":
+			o.endian = endianLittle
+		case name == "len" && hasVal:
+			k, ok := binKindNames[val]
+			if !ok || !k.integer() {
+				return o, gerrors.New("invalid length kind %s", val)
+			}
+			o.lenKind = k
+		case (name == "since" || name == "version") && hasVal:
+			n, err := strconv.ParseUint(val, 10, 64)
+			if err != nil || n == 0 {
+				return o, gerrors.New("invalid %s %s", name, val)
+			}
+			if name == "since" {
+				o.since = n
+			} else {
+				o.version = n
+			}
+		default:
+			k, ok := binKindNames[item]
+			if !ok {
+				return o, gerrors.New("unknown bin tag option %s", item)
+			}
+			o.kind = k
+		}
+	}
+	return o, nil
+}
+
+// tag returns canonical tag of options which apply to a value.
+func (o binOpts) tag() string {
+	var items []string
+	if o.kind != BinDefault {
+		items = append(items, o.kind.String())
+	}
+	if o.endian == endianBig {
+		items = append(items, "be")
+	}
+	if o.lenKind != BinDefault {
+		items = append(items, "len="+o.lenKind.String())
+	}
+	return strings.Join(items, ",")
+}
+
+func (o binOpts) be() bool {
+	return o.endian == endianBig
+}
+
+// valueOpts drops options which don't affect encoding of a value.
+func (o binOpts) valueOpts() binOpts {
+	return binOpts{kind: o.kind, lenKind: o.lenKind, endian: o.endian}
+}
+
+// resolveKind checks kind option against Go kind and returns wire kind.
+func resolveKind(rk reflect.Kind, k BinKind) (BinKind, error) {
+	var def BinKind
+	switch rk {
+	case reflect.Bool:
+		def = BinBool
+	case reflect.Int:
+		def = BinVarint
+	case reflect.Int8:
+		def = BinI8
+	case reflect.Int16:
+		def = BinI16
+	case reflect.Int32:
+		def = BinI32
+	case reflect.Int64:
+		def = BinI64
+	case reflect.Uint, reflect.Uintptr:
+		def = BinUvarint
+	case reflect.Uint8:
+		def = BinU8
+	case reflect.Uint16:
+		def = BinU16
+	case reflect.Uint32:
+		def = BinU32
+	case reflect.Uint64:
+		def = BinU64
+	case reflect.Float32:
+		def = BinF32
+	case reflect.Float64:
+		def = BinF64
+	default:
+		if k != BinDefault {
+			return k, gerrors.New("kind %s doesn't apply to %s", k, rk)
+		}
+		return BinDefault, nil
+	}
+	if k == BinDefault {
+		return def, nil
+	}
+	if (def == BinBool) != (k == BinBool) || def.integer() != k.integer() {
+		return k, gerrors.New("kind %s doesn't apply to %s", k, rk)
+	}
+	return k, nil
+}
+
+func binCodecOf(t reflect.Type, o binOpts) (*binCodec, error) {
+	key := binKey{t: t, opts: o.valueOpts()}
+	if c, ok := binCodecs.Load(key); ok {
+		return c.(*binCodec), nil
+	}
+	binCompileMu.Lock()
+	defer binCompileMu.Unlock()
+	bc := &binCompiler{pending: map[binKey]*binCodec{}}
+	c, err := bc.compile(t, o)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range bc.pending {
+		binCodecs.Store(k, v)
+	}
+	return c, nil
+}
+
+func (bc *binCompiler) compile(t reflect.Type, o binOpts) (*binCodec, error) {
+	o = o.valueOpts()
+	if t.Kind() == reflect.Struct {
+		o = binOpts{} // struct has its own options
+	}
+	key := binKey{t: t, opts: o}
+	if c, ok := binCodecs.Load(key); ok {
+		return c.(*binCodec), nil
+	}
+	if c, ok := bc.pending[key]; ok {
+		return c, nil
+	}
+	c := &binCodec{}
+	bc.pending[key] = c // placeholder for recursive types
+	if err := bc.build(c, t, o); err != nil {
+		return nil, gerrors.Wrap(err, t.String())
+	}
+	return c, nil
+}
+
+func (bc *binCompiler) build(c *binCodec, t reflect.Type, o binOpts) error {
+	be := o.be()
+	if reflect.PointerTo(t).Implements(appenderType) && reflect.PointerTo(t).Implements(decoderType) {
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			if !v.CanAddr() {
+				cp := reflect.New(t)
+				cp.Elem().Set(v)
+				v = cp.Elem()
+			}
+			return v.Addr().Interface().(BinaryAppender).AppendBin(p)
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			return v.Addr().Interface().(BinaryDecoder).DecodeBin(p)
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		k, err := resolveKind(t.Kind(), o.kind)
+		if err != nil {
+			return err
+		}
+		bc.buildNumber(c, t, k, be)
+
+	case reflect.String:
+		if o.kind != BinDefault {
+			return gerrors.New("kind %s doesn't apply to string", o.kind)
+		}
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			return AppendString(p, v.String(), o.lenKind, be)
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			b, rest, err := ReadBytes(p, o.lenKind, be)
+			if err != nil {
+				return nil, err
+			}
+			v.SetString(string(b))
+			return rest, nil
+		}
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && o.kind == BinDefault {
+			c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+				return AppendBytes(p, v.Bytes(), o.lenKind, be)
+			}
+			c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+				b, rest, err := ReadBytes(p, o.lenKind, be)
+				if err != nil {
+					return nil, err
+				}
+				v.SetBytes(append([]byte(nil), b...))
+				return rest, nil
+			}
+			return nil
+		}
+		ec, err := bc.compile(t.Elem(), o)
+		if err != nil {
+			return err
+		}
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			p, err := AppendLen(p, v.Len(), o.lenKind, be)
+			for i := 0; i < v.Len() && err == nil; i++ {
+				p, err = ec.enc(p, v.Index(i))
+			}
+			return p, err
+		}
+		// elements encoded to no bytes can't be bounded by input size, ec may be pending here for recursive types
+		var emptyOnce sync.Once
+		var emptyElem bool
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			n, p, err := ReadLen(p, o.lenKind, be)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				v.SetZero()
+				return p, nil
+			}
+			emptyOnce.Do(func() {
+				b, err := ec.enc(nil, reflect.Zero(t.Elem()))
+				emptyElem = err == nil && len(b) == 0
+			})
+			// n is untrusted, other elements take at least one byte each
+			if emptyElem && n > maxBinEmptyElems {
+				return nil, gerrors.New("length %d of %s exceeds %d", n, t, maxBinEmptyElems)
+			}
+			if !emptyElem && n > len(p) {
+				return nil, ErrShortBuffer
+			}
+			s := reflect.MakeSlice(t, 0, n)
+			for i := 0; i < n; i++ {
+				s = reflect.Append(s, reflect.Zero(t.Elem()))
+				if p, err = ec.dec(p, s.Index(i)); err != nil {
+					return nil, err
+				}
+			}
+			v.Set(s)
+			return p, nil
+		}
+
+	case reflect.Array:
+		ec, err := bc.compile(t.Elem(), o)
+		if err != nil {
+			return err
+		}
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			var err error
+			for i := 0; i < v.Len() && err == nil; i++ {
+				p, err = ec.enc(p, v.Index(i))
+			}
+			return p, err
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			var err error
+			for i := 0; i < v.Len() && err == nil; i++ {
+				p, err = ec.dec(p, v.Index(i))
+			}
+			return p, err
+		}
+
+	case reflect.Pointer:
+		ec, err := bc.compile(t.Elem(), o)
+		if err != nil {
+			return err
+		}
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			p = AppendBool(p, !v.IsNil())
+			if v.IsNil() {
+				return p, nil
+			}
+			return ec.enc(p, v.Elem())
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			ok, p, err := ReadBool(p)
+			if err != nil || !ok {
+				v.SetZero()
+				return p, err
+			}
+			nv := reflect.New(t.Elem())
+			if p, err = ec.dec(p, nv.Elem()); err != nil {
+				return nil, err
+			}
+			v.Set(nv)
+			return p, nil
+		}
+
+	case reflect.Struct:
+		return bc.buildStruct(c, t)
+
+	default:
+		return gerrors.New("unsupported type %s", t)
+	}
+	return nil
+}
+
+func (bc *binCompiler) buildNumber(c *binCodec, t reflect.Type, k BinKind, be bool) {
+	switch {
+	case k == BinBool:
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			return AppendBool(p, v.Bool()), nil
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			b, rest, err := ReadBool(p)
+			v.SetBool(b)
+			return rest, err
+		}
+	case k == BinF32 || k == BinF64:
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			return AppendFloat(p, v.Float(), k, be)
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			f, rest, err := ReadFloat(p, k, be)
+			v.SetFloat(f)
+			return rest, err
+		}
+	case isSignedKind(t.Kind()):
+		bits := t.Bits()
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			return AppendInt(p, v.Int(), k, be)
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			n, rest, err := ReadInt(p, k, be, bits)
+			v.SetInt(n)
+			return rest, err
+		}
+	default:
+		bits := t.Bits()
+		c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+			return AppendUint(p, v.Uint(), k, be)
+		}
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			n, rest, err := ReadUint(p, k, be, bits)
+			v.SetUint(n)
+			return rest, err
+		}
+	}
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func (bc *binCompiler) buildStruct(c *binCodec, t reflect.Type) error {
+	var fields []binField
+	var version uint64
+	endian := uint8(0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "_" {
+			o, err := parseBinTag(f.Tag.Get("bin"))
+			if err != nil {
+				return err
+			}
+			version, endian = o.version, o.endian
+		}
+	}
+
+	lastSince := uint64(0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "_" || !f.IsExported() {
+			continue
+		}
+		o, err := parseBinTag(f.Tag.Get("bin"))
+		if err != nil {
+			return gerrors.Wrap(err, f.Name)
+		}
+		if o.skip {
+			continue
+		}
+		if o.since > 0 && (version == 0 || o.since > version) {
+			return gerrors.New("field %s since version %d but struct version is %d", f.Name, o.since, version)
+		}
+		if o.since < lastSince {
+			return gerrors.New("field %s since version %d must be after fields of version %d", f.Name, o.since, lastSince)
+		}
+		lastSince = o.since
+		if o.endian == 0 {
+			o.endian = endian
+		}
+		fc, err := bc.compile(f.Type, o)
+		if err != nil {
+			return gerrors.Wrap(err, f.Name)
+		}
+		fields = append(fields, binField{index: i, since: o.since, codec: fc})
+	}
+
+	encBody := func(p []byte, v reflect.Value) ([]byte, error) {
+		var err error
+		for _, f := range fields {
+			if p, err = f.codec.enc(p, v.Field(f.index)); err != nil {
+				return nil, err
+			}
+		}
+		return p, nil
+	}
+	decBody := func(p []byte, v reflect.Value, version uint64) ([]byte, error) {
+		var err error
+		v.SetZero()
+		for _, f := range fields {
+			if f.since > version {
+				break
+			}
+			if p, err = f.codec.dec(p, v.Field(f.index)); err != nil {
+				return nil, err
+			}
+		}
+		return p, nil
+	}
+
+	if version == 0 {
+		c.enc = encBody
+		c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+			return decBody(p, v, 0)
+		}
+		return nil
+	}
+	c.enc = func(p []byte, v reflect.Value) ([]byte, error) {
+		p, start := BeginVersion(p, version)
+		p, err := encBody(p, v)
+		if err != nil {
+			return nil, err
+		}
+		return EndVersion(p, start), nil
+	}
+	c.dec = func(p []byte, v reflect.Value) ([]byte, error) {
+		ver, body, rest, err := ReadVersion(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = decBody(body, v, ver); err != nil {
+			return nil, err
+		}
+		return rest, nil
+	}
+	return nil
+}
+
+// Pack encodes v by bin tags, v is a struct or any other supported type, pointers of v are dereferenced.
+func Pack(v any) ([]byte, error) {
+	return AppendPack(nil, v)
+}
+
+// AppendPack appends encoding of v to p.
+func AppendPack(p []byte, v any) ([]byte, error) {
+	return AppendValue(p, v, "")
+}
+
+// Unpack decodes p into v, which must be a non-nil pointer, p must be consumed completely.
+func Unpack(p []byte, v any) error {
+	rest, err := UnpackPrefix(p, v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return gerrors.New("%d trailing bytes after unpack", len(rest))
+	}
+	return nil
+}
+
+// UnpackPrefix decodes the beginning of p into v and returns the rest bytes.
+func UnpackPrefix(p []byte, v any) ([]byte, error) {
+	return DecodeValue(p, v, "")
+}
+
+// AppendValue appends encoding of v with bin tag options, generated code uses it for types it can't
+// generate code for.
+func AppendValue(p []byte, v any, tag string) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || rv.Kind() == reflect.Pointer {
+		return nil, gerrors.New("can't pack nil")
+	}
+	o, err := parseBinTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	c, err := binCodecOf(rv.Type(), o)
+	if err != nil {
+		return nil, err
+	}
+	return c.enc(p, rv)
+}
+
+// DecodeValue decodes the beginning of p into pointer v with bin tag options and returns the rest bytes.
+func DecodeValue(p []byte, v any, tag string) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, gerrors.New("must receive a non-nil pointer, but received %T", v)
+	}
+	o, err := parseBinTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	c, err := binCodecOf(rv.Type().Elem(), o)
+	if err != nil {
+		return nil, err
+	}
+	return c.dec(p, rv.Elem())
+}
@@ -0,0 +1,427 @@
+package gstruct
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Code generator of zero-reflection AppendBin / DecodeBin methods, the generated code produces exactly the
+// same bytes as Pack / Unpack. Fields of builtin types, strings, slices, arrays, pointers and structs which
+// are generated together are encoded without reflection, other fields (e.g. named types like
+// time.Duration) fall back to AppendValue / DecodeValue.
+//
+// usage:
+//	//go:generate go run github.com/davidforest123/goutil/container/gstruct/bingen-cmd -type Header,Packet $GOFILE
+
+var builtinKinds = map[string]reflect.Kind{
+	"bool": reflect.Bool, "int": reflect.Int, "int8": reflect.Int8, "int16": reflect.Int16,
+	"int32": reflect.Int32, "rune": reflect.Int32, "int64": reflect.Int64,
+	"uint": reflect.Uint, "uint8": reflect.Uint8, "byte": reflect.Uint8, "uint16": reflect.Uint16,
+	"uint32": reflect.Uint32, "uint64": reflect.Uint64, "uintptr": reflect.Uintptr,
+	"float32": reflect.Float32, "float64": reflect.Float64, "string": reflect.String,
+}
+
+type binGen struct {
+	buf     bytes.Buffer
+	qual    string // "gstruct." or "" when generating into package gstruct
+	structs map[string]bool
+	imports map[string]bool
+	vars    int
+}
+
+// GenerateBinary generates AppendBin / DecodeBin methods for struct types of Go source file, all struct
+// types of the file are generated if types is empty.
+func GenerateBinary(filename string, src []byte, types []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	all := map[string]*ast.StructType{}
+	var order []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.TypeParams == nil {
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				all[ts.Name.Name] = st
+				order = append(order, ts.Name.Name)
+			}
+		}
+		return true
+	})
+	if len(types) == 0 {
+		types = order
+	}
+
+	g := &binGen{qual: "gstruct.", structs: map[string]bool{}, imports: map[string]bool{}}
+	if file.Name.Name == "gstruct" {
+		g.qual = ""
+	}
+	for _, name := range types {
+		if all[name] == nil {
+			return nil, gerrors.New("struct type %s not found in %s", name, filename)
+		}
+		g.structs[name] = true
+	}
+	for _, name := range types {
+		if err := g.genStruct(name, all[name]); err != nil {
+			return nil, gerrors.Wrap(err, name)
+		}
+	}
+
+	if g.qual != "" {
+		g.imports["github.com/davidforest123/goutil/container/gstruct"] = true
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gstruct bingen-cmd; DO NOT EDIT.\n\npackage %s\n\n", file.Name.Name)
+	if len(g.imports) > 0 {
+		var paths []string
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		out.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+		out.WriteString(")\n")
+	}
+	out.Write(g.buf.Bytes())
+	return format.Source(out.Bytes())
+}
+
+type genField struct {
+	name string
+	expr ast.Expr
+	opts binOpts
+}
+
+func (g *binGen) genStruct(name string, st *ast.StructType) error {
+	var fields []genField
+	var version uint64
+	endian := uint8(0)
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(unquoted).Get("bin")
+		}
+		o, err := parseBinTag(tag)
+		if err != nil {
+			return err
+		}
+		names := f.Names
+		if len(names) == 0 { // embedded
+			t := f.Type
+			if star, ok := t.(*ast.StarExpr); ok {
+				t = star.X
+			}
+			id, ok := t.(*ast.Ident)
+			if !ok {
+				return gerrors.New("unsupported embedded field %s", types.ExprString(f.Type))
+			}
+			names = []*ast.Ident{id}
+		}
+		for _, n := range names {
+			if n.Name == "_" {
+				version, endian = o.version, o.endian
+				continue
+			}
+			if !o.skip && ast.IsExported(n.Name) {
+				fields = append(fields, genField{name: n.Name, expr: f.Type, opts: o})
+			}
+		}
+	}
+
+	lastSince := uint64(0)
+	for i := range fields {
+		f := &fields[i]
+		if f.opts.since > 0 && (version == 0 || f.opts.since > version) {
+			return gerrors.New("field %s since version %d but struct version is %d", f.name, f.opts.since, version)
+		}
+		if f.opts.since < lastSince {
+			return gerrors.New("field %s since version %d must be after fields of version %d", f.name, f.opts.since, lastSince)
+		}
+		lastSince = f.opts.since
+		if f.opts.endian == 0 {
+			f.opts.endian = endian
+		}
+	}
+
+	w := &g.buf
+	fmt.Fprintf(w, "\n// AppendBin appends binary encoding of x to p.\nfunc (x *%s) AppendBin(p []byte) ([]byte, error) {\n\tvar err error\n", name)
+	if version > 0 {
+		fmt.Fprintf(w, "p, start := %sBeginVersion(p, %d)\n", g.qual, version)
+	}
+	for _, f := range fields {
+		if err := g.genEnc("x."+f.name, f.expr, f.opts.valueOpts()); err != nil {
+			return gerrors.Wrap(err, f.name)
+		}
+	}
+	if version > 0 {
+		fmt.Fprintf(w, "p = %sEndVersion(p, start)\n", g.qual)
+	}
+	w.WriteString("return p, err\n}\n")
+
+	fmt.Fprintf(w, "\n// DecodeBin decodes x from p and returns the rest bytes.\nfunc (x *%s) DecodeBin(p []byte) ([]byte, error) {\n\tvar err error\n\t*x = %s{}\n", name, name)
+	if version > 0 {
+		ver := "_"
+		if lastSince > 0 {
+			ver = "ver"
+		}
+		fmt.Fprintf(w, "%s, q, rest, err := %sReadVersion(p)\nif err != nil {\nreturn nil, err\n}\n", ver, g.qual)
+	} else {
+		w.WriteString("q := p\n")
+	}
+	openSince := uint64(0)
+	for _, f := range fields {
+		if f.opts.since != openSince {
+			if openSince > 0 {
+				w.WriteString("}\n")
+			}
+			fmt.Fprintf(w, "if ver >= %d {\n", f.opts.since)
+			openSince = f.opts.since
+		}
+		if err := g.genDec("x."+f.name, f.expr, f.opts.valueOpts()); err != nil {
+			return gerrors.Wrap(err, f.name)
+		}
+	}
+	if openSince > 0 {
+		w.WriteString("}\n")
+	}
+	if version > 0 {
+		w.WriteString("_ = q\nreturn rest, err\n}\n")
+	} else {
+		w.WriteString("return q, err\n}\n")
+	}
+	return nil
+}
+
+func (g *binGen) newVar(prefix string) string {
+	g.vars++
+	return prefix + strconv.Itoa(g.vars)
+}
+
+func (g *binGen) check(call string) {
+	fmt.Fprintf(&g.buf, "if %s; err != nil {\nreturn nil, err\n}\n", call)
+}
+
+func (g *binGen) fallback(e string, expr ast.Expr, o binOpts, enc bool) error {
+	switch expr.(type) {
+	case *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return gerrors.New("unsupported type %s", types.ExprString(expr))
+	}
+	if enc {
+		g.check(fmt.Sprintf("p, err = %sAppendValue(p, %s, %q)", g.qual, e, o.tag()))
+	} else {
+		g.check(fmt.Sprintf("q, err = %sDecodeValue(q, &%s, %q)", g.qual, e, o.tag()))
+	}
+	return nil
+}
+
+func kindConst(k BinKind) string {
+	for name, v := range map[string]BinKind{
+		"BinBool": BinBool, "BinU8": BinU8, "BinU16": BinU16, "BinU32": BinU32, "BinU64": BinU64,
+		"BinI8": BinI8, "BinI16": BinI16, "BinI32": BinI32, "BinI64": BinI64,
+		"BinUvarint": BinUvarint, "BinVarint": BinVarint, "BinF32": BinF32, "BinF64": BinF64,
+	} {
+		if v == k {
+			return name
+		}
+	}
+	return "BinDefault"
+}
+
+func (g *binGen) genEnc(e string, expr ast.Expr, o binOpts) error {
+	w := &g.buf
+	q, be := g.qual, o.be()
+	switch t := expr.(type) {
+	case *ast.Ident:
+		rk, builtin := builtinKinds[t.Name]
+		switch {
+		case g.structs[t.Name]:
+			g.check(fmt.Sprintf("p, err = %s.AppendBin(p)", e))
+		case !builtin:
+			return g.fallback(e, expr, o, true)
+		case rk == reflect.String:
+			if o.kind != BinDefault {
+				return gerrors.New("kind %s doesn't apply to string", o.kind)
+			}
+			g.check(fmt.Sprintf("p, err = %sAppendString(p, string(%s), %s%s, %t)", q, e, q, kindConst(o.lenKind), be))
+		default:
+			k, err := resolveKind(rk, o.kind)
+			if err != nil {
+				return err
+			}
+			switch {
+			case k == BinBool:
+				fmt.Fprintf(w, "p = %sAppendBool(p, bool(%s))\n", q, e)
+			case k == BinF32 || k == BinF64:
+				g.check(fmt.Sprintf("p, err = %sAppendFloat(p, float64(%s), %s%s, %t)", q, e, q, kindConst(k), be))
+			case isSignedKind(rk):
+				g.check(fmt.Sprintf("p, err = %sAppendInt(p, int64(%s), %s%s, %t)", q, e, q, kindConst(k), be))
+			default:
+				g.check(fmt.Sprintf("p, err = %sAppendUint(p, uint64(%s), %s%s, %t)", q, e, q, kindConst(k), be))
+			}
+		}
+	case *ast.ArrayType:
+		if isByteIdent(t.Elt) && o.kind == BinDefault {
+			if t.Len == nil {
+				g.check(fmt.Sprintf("p, err = %sAppendBytes(p, %s, %s%s, %t)", q, e, q, kindConst(o.lenKind), be))
+			} else {
+				fmt.Fprintf(w, "p = append(p, %s[:]...)\n", e)
+			}
+			return nil
+		}
+		if t.Len == nil {
+			g.check(fmt.Sprintf("p, err = %sAppendLen(p, len(%s), %s%s, %t)", q, e, q, kindConst(o.lenKind), be))
+		}
+		i := g.newVar("i")
+		fmt.Fprintf(w, "for %s := range %s {\n", i, e)
+		if err := g.genEnc(e+"["+i+"]", t.Elt, o); err != nil {
+			return err
+		}
+		w.WriteString("}\n")
+	case *ast.StarExpr:
+		fmt.Fprintf(w, "p = %sAppendBool(p, %s != nil)\nif %s != nil {\n", q, e, e)
+		if err := g.genEnc("(*"+e+")", t.X, o); err != nil {
+			return err
+		}
+		w.WriteString("}\n")
+	case *ast.ParenExpr:
+		return g.genEnc(e, t.X, o)
+	default:
+		return g.fallback(e, expr, o, true)
+	}
+	return nil
+}
+
+func (g *binGen) genDec(e string, expr ast.Expr, o binOpts) error {
+	w := &g.buf
+	q, be := g.qual, o.be()
+	switch t := expr.(type) {
+	case *ast.Ident:
+		rk, builtin := builtinKinds[t.Name]
+		switch {
+		case g.structs[t.Name]:
+			g.check(fmt.Sprintf("q, err = %s.DecodeBin(q)", e))
+		case !builtin:
+			return g.fallback(e, expr, o, false)
+		case rk == reflect.String:
+			if o.kind != BinDefault {
+				return gerrors.New("kind %s doesn't apply to string", o.kind)
+			}
+			b := g.newVar("b")
+			fmt.Fprintf(w, "var %s []byte\n", b)
+			g.check(fmt.Sprintf("%s, q, err = %sReadBytes(q, %s%s, %t)", b, q, q, kindConst(o.lenKind), be))
+			fmt.Fprintf(w, "%s = %s(%s)\n", e, t.Name, b)
+		default:
+			k, err := resolveKind(rk, o.kind)
+			if err != nil {
+				return err
+			}
+			v := g.newVar("v")
+			switch {
+			case k == BinBool:
+				fmt.Fprintf(w, "var %s bool\n", v)
+				g.check(fmt.Sprintf("%s, q, err = %sReadBool(q)", v, q))
+			case k == BinF32 || k == BinF64:
+				fmt.Fprintf(w, "var %s float64\n", v)
+				g.check(fmt.Sprintf("%s, q, err = %sReadFloat(q, %s%s, %t)", v, q, q, kindConst(k), be))
+			default:
+				bits := strconv.Itoa(kindBits(rk))
+				if bits == "0" {
+					bits = "strconv.IntSize"
+					g.imports["strconv"] = true
+				}
+				fn, typ := "ReadUint", "uint64"
+				if isSignedKind(rk) {
+					fn, typ = "ReadInt", "int64"
+				}
+				fmt.Fprintf(w, "var %s %s\n", v, typ)
+				g.check(fmt.Sprintf("%s, q, err = %s%s(q, %s%s, %t, %s)", v, q, fn, q, kindConst(k), be, bits))
+			}
+			fmt.Fprintf(w, "%s = %s(%s)\n", e, t.Name, v)
+		}
+	case *ast.ArrayType:
+		if isByteIdent(t.Elt) && o.kind == BinDefault {
+			if t.Len == nil {
+				b := g.newVar("b")
+				fmt.Fprintf(w, "var %s []byte\n", b)
+				g.check(fmt.Sprintf("%s, q, err = %sReadBytes(q, %s%s, %t)", b, q, q, kindConst(o.lenKind), be))
+				fmt.Fprintf(w, "%s = append(%s(nil), %s...)\n", e, types.ExprString(t), b)
+			} else {
+				fmt.Fprintf(w, "if len(q) < len(%s) {\nreturn nil, %sErrShortBuffer\n}\nq = q[copy(%s[:], q):]\n", e, q, e)
+			}
+			return nil
+		}
+		i := g.newVar("i")
+		if t.Len != nil {
+			fmt.Fprintf(w, "for %s := range %s {\n", i, e)
+			if err := g.genDec(e+"["+i+"]", t.Elt, o); err != nil {
+				return err
+			}
+			w.WriteString("}\n")
+			return nil
+		}
+		n := g.newVar("n")
+		fmt.Fprintf(w, "var %s int\n", n)
+		g.check(fmt.Sprintf("%s, q, err = %sReadLen(q, %s%s, %t)", n, q, q, kindConst(o.lenKind), be))
+		fmt.Fprintf(w, "if %s > 0 {\n%s = make(%s, 0, min(%s, len(q)))\n", n, e, types.ExprString(t), n)
+		fmt.Fprintf(w, "for %s := 0; %s < %s; %s++ {\n%s = append(%s, *new(%s))\n", i, i, n, i, e, e, types.ExprString(t.Elt))
+		l := g.newVar("l")
+		fmt.Fprintf(w, "%s := len(q)\n", l)
+		if err := g.genDec(e+"["+i+"]", t.Elt, o); err != nil {
+			return err
+		}
+		// n is untrusted, elements of other types run out of q
+		g.check(fmt.Sprintf("err = %sCheckSliceElem(%s, %s-len(q), %q)", q, n, l, types.ExprString(t)))
+		w.WriteString("}\n}\n")
+	case *ast.StarExpr:
+		ok := g.newVar("ok")
+		fmt.Fprintf(w, "var %s bool\n", ok)
+		g.check(fmt.Sprintf("%s, q, err = %sReadBool(q)", ok, q))
+		fmt.Fprintf(w, "if %s {\n%s = new(%s)\n", ok, e, types.ExprString(t.X))
+		if err := g.genDec("(*"+e+")", t.X, o); err != nil {
+			return err
+		}
+		w.WriteString("}\n")
+	case *ast.ParenExpr:
+		return g.genDec(e, t.X, o)
+	default:
+		return g.fallback(e, expr, o, false)
+	}
+	return nil
+}
+
+func isByteIdent(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && (id.Name == "byte" || id.Name == "uint8")
+}
+
+func kindBits(rk reflect.Kind) int {
+	switch rk {
+	case reflect.Int8, reflect.Uint8:
+		return 8
+	case reflect.Int16, reflect.Uint16:
+		return 16
+	case reflect.Int32, reflect.Uint32:
+		return 32
+	case reflect.Int64, reflect.Uint64:
+		return 64
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,303 @@
+// Code generated by gstruct bingen-cmd; DO NOT EDIT.
+
+package gstruct
+
+import (
+	"strconv"
+)
+
+// AppendBin appends binary encoding of x to p.
+func (x *SampleHeader) AppendBin(p []byte) ([]byte, error) {
+	var err error
+	p = append(p, x.Magic[:]...)
+	if p, err = AppendInt(p, int64(x.Length), BinU16, true); err != nil {
+		return nil, err
+	}
+	if p, err = AppendUint(p, uint64(x.Flags), BinU8, true); err != nil {
+		return nil, err
+	}
+	p = AppendBool(p, bool(x.Checked))
+	return p, err
+}
+
+// DecodeBin decodes x from p and returns the rest bytes.
+func (x *SampleHeader) DecodeBin(p []byte) ([]byte, error) {
+	var err error
+	*x = SampleHeader{}
+	q := p
+	if len(q) < len(x.Magic) {
+		return nil, ErrShortBuffer
+	}
+	q = q[copy(x.Magic[:], q):]
+	var v1 int64
+	if v1, q, err = ReadInt(q, BinU16, true, strconv.IntSize); err != nil {
+		return nil, err
+	}
+	x.Length = int(v1)
+	var v2 uint64
+	if v2, q, err = ReadUint(q, BinU8, true, 8); err != nil {
+		return nil, err
+	}
+	x.Flags = uint8(v2)
+	var v3 bool
+	if v3, q, err = ReadBool(q); err != nil {
+		return nil, err
+	}
+	x.Checked = bool(v3)
+	return q, err
+}
+
+// AppendBin appends binary encoding of x to p.
+func (x *SamplePacket) AppendBin(p []byte) ([]byte, error) {
+	var err error
+	p, start := BeginVersion(p, 2)
+	if p, err = x.Header.AppendBin(p); err != nil {
+		return nil, err
+	}
+	if p, err = AppendUint(p, uint64(x.ID), BinUvarint, false); err != nil {
+		return nil, err
+	}
+	if p, err = AppendInt(p, int64(x.Delta), BinI32, true); err != nil {
+		return nil, err
+	}
+	if p, err = AppendString(p, string(x.Name), BinU8, false); err != nil {
+		return nil, err
+	}
+	if p, err = AppendBytes(p, x.Payload, BinDefault, false); err != nil {
+		return nil, err
+	}
+	if p, err = AppendLen(p, len(x.Ports), BinU16, true); err != nil {
+		return nil, err
+	}
+	for i4 := range x.Ports {
+		if p, err = AppendUint(p, uint64(x.Ports[i4]), BinU16, true); err != nil {
+			return nil, err
+		}
+	}
+	if p, err = AppendFloat(p, float64(x.Ratio), BinF32, false); err != nil {
+		return nil, err
+	}
+	p = AppendBool(p, x.Next != nil)
+	if x.Next != nil {
+		if p, err = (*x.Next).AppendBin(p); err != nil {
+			return nil, err
+		}
+	}
+	if p, err = AppendLen(p, len(x.Tags), BinDefault, false); err != nil {
+		return nil, err
+	}
+	for i5 := range x.Tags {
+		if p, err = AppendString(p, string(x.Tags[i5]), BinDefault, false); err != nil {
+			return nil, err
+		}
+	}
+	if p, err = AppendValue(p, x.Timeout, ""); err != nil {
+		return nil, err
+	}
+	p = AppendBool(p, x.Note != nil)
+	if x.Note != nil {
+		if p, err = AppendString(p, string((*x.Note)), BinDefault, false); err != nil {
+			return nil, err
+		}
+	}
+	if p, err = AppendLen(p, len(x.Scores), BinDefault, false); err != nil {
+		return nil, err
+	}
+	for i6 := range x.Scores {
+		if p, err = AppendUint(p, uint64(x.Scores[i6]), BinVarint, false); err != nil {
+			return nil, err
+		}
+	}
+	p = EndVersion(p, start)
+	return p, err
+}
+
+// DecodeBin decodes x from p and returns the rest bytes.
+func (x *SamplePacket) DecodeBin(p []byte) ([]byte, error) {
+	var err error
+	*x = SamplePacket{}
+	ver, q, rest, err := ReadVersion(p)
+	if err != nil {
+		return nil, err
+	}
+	if q, err = x.Header.DecodeBin(q); err != nil {
+		return nil, err
+	}
+	var v7 uint64
+	if v7, q, err = ReadUint(q, BinUvarint, false, 64); err != nil {
+		return nil, err
+	}
+	x.ID = uint64(v7)
+	var v8 int64
+	if v8, q, err = ReadInt(q, BinI32, true, strconv.IntSize); err != nil {
+		return nil, err
+	}
+	x.Delta = int(v8)
+	var b9 []byte
+	if b9, q, err = ReadBytes(q, BinU8, false); err != nil {
+		return nil, err
+	}
+	x.Name = string(b9)
+	var b10 []byte
+	if b10, q, err = ReadBytes(q, BinDefault, false); err != nil {
+		return nil, err
+	}
+	x.Payload = append([]byte(nil), b10...)
+	var n12 int
+	if n12, q, err = ReadLen(q, BinU16, true); err != nil {
+		return nil, err
+	}
+	if n12 > 0 {
+		x.Ports = make([]uint16, 0, min(n12, len(q)))
+		for i11 := 0; i11 < n12; i11++ {
+			x.Ports = append(x.Ports, *new(uint16))
+			l13 := len(q)
+			var v14 uint64
+			if v14, q, err = ReadUint(q, BinU16, true, 16); err != nil {
+				return nil, err
+			}
+			x.Ports[i11] = uint16(v14)
+			if err = CheckSliceElem(n12, l13-len(q), "[]uint16"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var v15 float64
+	if v15, q, err = ReadFloat(q, BinF32, false); err != nil {
+		return nil, err
+	}
+	x.Ratio = float32(v15)
+	var ok16 bool
+	if ok16, q, err = ReadBool(q); err != nil {
+		return nil, err
+	}
+	if ok16 {
+		x.Next = new(SampleHeader)
+		if q, err = (*x.Next).DecodeBin(q); err != nil {
+			return nil, err
+		}
+	}
+	var n18 int
+	if n18, q, err = ReadLen(q, BinDefault, false); err != nil {
+		return nil, err
+	}
+	if n18 > 0 {
+		x.Tags = make([]string, 0, min(n18, len(q)))
+		for i17 := 0; i17 < n18; i17++ {
+			x.Tags = append(x.Tags, *new(string))
+			l19 := len(q)
+			var b20 []byte
+			if b20, q, err = ReadBytes(q, BinDefault, false); err != nil {
+				return nil, err
+			}
+			x.Tags[i17] = string(b20)
+			if err = CheckSliceElem(n18, l19-len(q), "[]string"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if q, err = DecodeValue(q, &x.Timeout, ""); err != nil {
+		return nil, err
+	}
+	if ver >= 2 {
+		var ok21 bool
+		if ok21, q, err = ReadBool(q); err != nil {
+			return nil, err
+		}
+		if ok21 {
+			x.Note = new(string)
+			var b22 []byte
+			if b22, q, err = ReadBytes(q, BinDefault, false); err != nil {
+				return nil, err
+			}
+			(*x.Note) = string(b22)
+		}
+		var n24 int
+		if n24, q, err = ReadLen(q, BinDefault, false); err != nil {
+			return nil, err
+		}
+		if n24 > 0 {
+			x.Scores = make([]uint32, 0, min(n24, len(q)))
+			for i23 := 0; i23 < n24; i23++ {
+				x.Scores = append(x.Scores, *new(uint32))
+				l25 := len(q)
+				var v26 uint64
+				if v26, q, err = ReadUint(q, BinVarint, false, 32); err != nil {
+					return nil, err
+				}
+				x.Scores[i23] = uint32(v26)
+				if err = CheckSliceElem(n24, l25-len(q), "[]uint32"); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	_ = q
+	return rest, err
+}
+
+// AppendBin appends binary encoding of x to p.
+func (x *SampleMarks) AppendBin(p []byte) ([]byte, error) {
+	var err error
+	if p, err = AppendLen(p, len(x.Marks), BinDefault, false); err != nil {
+		return nil, err
+	}
+	for i27 := range x.Marks {
+		if p, err = AppendValue(p, x.Marks[i27], ""); err != nil {
+			return nil, err
+		}
+	}
+	if p, err = AppendLen(p, len(x.Counts), BinDefault, false); err != nil {
+		return nil, err
+	}
+	for i28 := range x.Counts {
+		if p, err = AppendUint(p, uint64(x.Counts[i28]), BinU32, false); err != nil {
+			return nil, err
+		}
+	}
+	return p, err
+}
+
+// DecodeBin decodes x from p and returns the rest bytes.
+func (x *SampleMarks) DecodeBin(p []byte) ([]byte, error) {
+	var err error
+	*x = SampleMarks{}
+	q := p
+	var n30 int
+	if n30, q, err = ReadLen(q, BinDefault, false); err != nil {
+		return nil, err
+	}
+	if n30 > 0 {
+		x.Marks = make([]struct{}, 0, min(n30, len(q)))
+		for i29 := 0; i29 < n30; i29++ {
+			x.Marks = append(x.Marks, *new(struct{}))
+			l31 := len(q)
+			if q, err = DecodeValue(q, &x.Marks[i29], ""); err != nil {
+				return nil, err
+			}
+			if err = CheckSliceElem(n30, l31-len(q), "[]struct{}"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var n33 int
+	if n33, q, err = ReadLen(q, BinDefault, false); err != nil {
+		return nil, err
+	}
+	if n33 > 0 {
+		x.Counts = make([]uint32, 0, min(n33, len(q)))
+		for i32 := 0; i32 < n33; i32++ {
+			x.Counts = append(x.Counts, *new(uint32))
+			l34 := len(q)
+			var v35 uint64
+			if v35, q, err = ReadUint(q, BinU32, false, 32); err != nil {
+				return nil, err
+			}
+			x.Counts[i32] = uint32(v35)
+			if err = CheckSliceElem(n33, l34-len(q), "[]uint32"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return q, err
+}
@@ -0,0 +1,40 @@
+package gstruct
+
+import (
+	"time"
+)
+
+//go:generate go run ./bingen-cmd -type SampleHeader,SamplePacket,SampleMarks binary_sample_test.go
+
+type (
+	SampleHeader struct {
+		_       struct{} `bin:"be"`
+		Magic   [4]byte
+		Length  int `bin:"u16"`
+		Flags   uint8
+		Checked bool
+	}
+
+	SamplePacket struct {
+		_       struct{} `bin:"version=2"`
+		Header  SampleHeader
+		ID      uint64 `bin:"uvarint"`
+		Delta   int    `bin:"i32,be"`
+		Name    string `bin:"len=u8"`
+		Payload []byte
+		Ports   []uint16 `bin:"be,len=u16"`
+		Ratio   float32
+		Next    *SampleHeader
+		Tags    []string
+		Timeout time.Duration
+		Note    *string  `bin:"since=2"`
+		Scores  []uint32 `bin:"varint,since=2"`
+		local   int
+		Skipped int `bin:"-"`
+	}
+
+	SampleMarks struct {
+		Marks  []struct{}
+		Counts []uint32
+	}
+)
@@ -0,0 +1,166 @@
+package gstruct
+
+import (
+	"bytes"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	// no generated methods, so they are encoded by reflection
+	rawHeader SampleHeader
+	rawPacket SamplePacket
+
+	packetV1 struct {
+		_    struct{} `bin:"version=1"`
+		ID   uint32
+		Name string
+	}
+
+	packetV2 struct {
+		_     struct{} `bin:"version=2"`
+		ID    uint32
+		Name  string
+		Email string `bin:"since=2"`
+	}
+)
+
+func samplePacket() SamplePacket {
+	note := "hi"
+	return SamplePacket{
+		Header:  SampleHeader{Magic: [4]byte{'G', 'S', 'T', 'R'}, Length: 513, Flags: 7, Checked: true},
+		ID:      300,
+		Delta:   -2,
+		Name:    "packet",
+		Payload: []byte{1, 2, 3},
+		Ports:   []uint16{80, 443},
+		Ratio:   0.5,
+		Next:    &SampleHeader{Length: 1},
+		Tags:    []string{"a", ""},
+		Timeout: 3 * time.Second,
+		Note:    &note,
+		Scores:  []uint32{1, 1000},
+	}
+}
+
+func TestPack(t *testing.T) {
+	h := rawHeader{Magic: [4]byte{'G', 'S', 'T', 'R'}, Length: 513, Flags: 7, Checked: true}
+	b, err := Pack(&h)
+	gtest.Assert(t, err)
+	expect := []byte{'G', 'S', 'T', 'R', 0x02, 0x01, 7, 1}
+	gtest.AssertTrue(t, bytes.Equal(b, expect), "Pack got %v but expect %v", b, expect)
+
+	p := rawPacket(samplePacket())
+	b, err = Pack(p)
+	gtest.Assert(t, err)
+	var p2 rawPacket
+	gtest.Assert(t, Unpack(b, &p2))
+	gtest.AssertTrue(t, reflect.DeepEqual(p, p2), "round trip got %+v", p2)
+
+	_, err = Pack(rawHeader{Length: 70000})
+	gtest.AssertTrue(t, err != nil, "70000 doesn't fit u16")
+	gtest.AssertTrue(t, Unpack(b[:len(b)-1], &p2) != nil, "truncated data should fail")
+	gtest.AssertTrue(t, Unpack(append(b, 0), &p2) != nil, "trailing data should fail")
+	_, err = Pack(struct{ M map[string]int }{})
+	gtest.AssertTrue(t, err != nil, "map is not supported")
+	_, err = Pack(struct {
+		A int `bin:"since=1"`
+	}{})
+	gtest.AssertTrue(t, err != nil, "since without version should fail")
+
+	buf := make([]byte, 8)
+	n, err := StructPack(&h, buf)
+	gtest.AssertTrue(t, err == nil && n == 8 && bytes.Equal(buf, expect), "StructPack got %d %v", n, err)
+	_, err = StructPack(&h, buf[:7])
+	gtest.AssertTrue(t, err == ErrShortBuffer, "StructPack into short buffer got %v", err)
+	var h2 rawHeader
+	gtest.Assert(t, StructUnpack(buf, &h2))
+	gtest.AssertTrue(t, h2 == h, "StructUnpack got %+v", h2)
+}
+
+func TestPack_Version(t *testing.T) {
+	v1 := packetV1{ID: 1, Name: "old"}
+	b1, err := Pack(v1)
+	gtest.Assert(t, err)
+	var v2 packetV2
+	gtest.Assert(t, Unpack(b1, &v2))
+	gtest.AssertTrue(t, v2.ID == 1 && v2.Name == "old" && v2.Email == "", "new reader of old data got %+v", v2)
+
+	b2, err := Pack(packetV2{ID: 2, Name: "new", Email: "a@b.c"})
+	gtest.Assert(t, err)
+	v1 = packetV1{}
+	rest, err := UnpackPrefix(append(b2, 9), &v1)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, v1.ID == 2 && v1.Name == "new" && bytes.Equal(rest, []byte{9}), "old reader of new data got %+v rest %v", v1, rest)
+}
+
+func TestUnpack_HostileLength(t *testing.T) {
+	// uvarint 2^31-1 followed by nothing
+	hostile := []byte{0xff, 0xff, 0xff, 0xff, 0x07}
+	var empties []struct{}
+	gtest.AssertTrue(t, Unpack(hostile, &empties) != nil, "zero size elements should be capped")
+	var nums []uint32
+	gtest.AssertTrue(t, Unpack(hostile, &nums) == ErrShortBuffer, "length over input should fail")
+	var nested [][]struct{}
+	gtest.AssertTrue(t, Unpack(append([]byte{1}, hostile...), &nested) != nil, "nested zero size elements should be capped")
+
+	empties = make([]struct{}, 1000)
+	b, err := Pack(empties)
+	gtest.Assert(t, err)
+	empties = nil
+	gtest.Assert(t, Unpack(b, &empties))
+	gtest.AssertTrue(t, len(empties) == 1000, "round trip got %d", len(empties))
+
+	// generated code has the same limits
+	var m SampleMarks
+	_, err = m.DecodeBin(hostile)
+	gtest.AssertTrue(t, err != nil, "generated decoder should cap zero size elements")
+	_, err = m.DecodeBin(append([]byte{0}, hostile...))
+	gtest.AssertTrue(t, err == ErrShortBuffer, "generated decoder got %v for length over input", err)
+	b, err = (&SampleMarks{Marks: make([]struct{}, 1000), Counts: []uint32{1}}).AppendBin(nil)
+	gtest.Assert(t, err)
+	m = SampleMarks{}
+	rest, err := m.DecodeBin(b)
+	gtest.AssertTrue(t, err == nil && len(rest) == 0 && len(m.Marks) == 1000 && len(m.Counts) == 1, "generated round trip got %d %v", len(m.Marks), err)
+}
+
+func TestGenerateBinary(t *testing.T) {
+	p := samplePacket()
+	gen, err := p.AppendBin(nil)
+	gtest.Assert(t, err)
+	raw, err := Pack(rawPacket(p))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, bytes.Equal(gen, raw), "generated code got %v but reflection got %v", gen, raw)
+
+	var p2 SamplePacket
+	rest, err := p2.DecodeBin(append(gen, 1))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, reflect.DeepEqual(p, p2) && len(rest) == 1, "generated decode got %+v", p2)
+
+	src, err := os.ReadFile("binary_sample_test.go")
+	gtest.Assert(t, err)
+	code, err := GenerateBinary("binary_sample_test.go", src, []string{"SampleHeader", "SamplePacket", "SampleMarks"})
+	gtest.Assert(t, err)
+	checkedIn, err := os.ReadFile("binary_sample_bin_test.go")
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, bytes.Equal(code, checkedIn), "binary_sample_bin_test.go is outdated, run go generate")
+}
+
+func BenchmarkPack(b *testing.B) {
+	p := samplePacket()
+	raw := rawPacket(p)
+	buf := make([]byte, 0, 256)
+	b.Run("reflect", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = AppendPack(buf[:0], &raw)
+		}
+	})
+	b.Run("generated", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = p.AppendBin(buf[:0])
+		}
+	})
+}
@@ -0,0 +1,345 @@
+package gstruct
+
+import (
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+)
+
+// Wire primitives of the binary codec, they are exported for code generated by GenerateBinary.
+
+type BinKind uint8
+
+const (
+	BinDefault BinKind = iota // inferred from Go type
+	BinBool
+	BinU8
+	BinU16
+	BinU32
+	BinU64
+	BinI8
+	BinI16
+	BinI32
+	BinI64
+	BinUvarint
+	BinVarint // zigzag
+	BinF32
+	BinF64
+)
+
+var (
+	ErrShortBuffer = gerrors.New("binary data is too short")
+	ErrOutOfRange  = gerrors.New("value out of range")
+
+	binKindNames = map[string]BinKind{
+		"bool": BinBool, "u8": BinU8, "u16": BinU16, "u32": BinU32, "u64": BinU64,
+		"i8": BinI8, "i16": BinI16, "i32": BinI32, "i64": BinI64,
+		"uvarint": BinUvarint, "varint": BinVarint, "f32": BinF32, "f64": BinF64,
+	}
+)
+
+func (k BinKind) String() string {
+	for name, v := range binKindNames {
+		if v == k {
+			return name
+		}
+	}
+	return "default"
+}
+
+// size returns bytes of fixed size integer kinds, 0 for others.
+func (k BinKind) size() int {
+	switch k {
+	case BinU8, BinI8:
+		return 1
+	case BinU16, BinI16:
+		return 2
+	case BinU32, BinI32:
+		return 4
+	case BinU64, BinI64:
+		return 8
+	default:
+		return 0
+	}
+}
+
+func (k BinKind) signed() bool {
+	return k >= BinI8 && k <= BinI64 || k == BinVarint
+}
+
+func (k BinKind) integer() bool {
+	return k >= BinU8 && k <= BinVarint
+}
+
+func appendFixed(p []byte, v uint64, size int, be bool) []byte {
+	switch size {
+	case 1:
+		return append(p, byte(v))
+	case 2:
+		if be {
+			return binary.BigEndian.AppendUint16(p, uint16(v))
+		}
+		return binary.LittleEndian.AppendUint16(p, uint16(v))
+	case 4:
+		if be {
+			return binary.BigEndian.AppendUint32(p, uint32(v))
+		}
+		return binary.LittleEndian.AppendUint32(p, uint32(v))
+	default:
+		if be {
+			return binary.BigEndian.AppendUint64(p, v)
+		}
+		return binary.LittleEndian.AppendUint64(p, v)
+	}
+}
+
+func readFixed(p []byte, size int, be bool) (uint64, []byte, error) {
+	if len(p) < size {
+		return 0, nil, ErrShortBuffer
+	}
+	var v uint64
+	switch size {
+	case 1:
+		v = uint64(p[0])
+	case 2:
+		if be {
+			v = uint64(binary.BigEndian.Uint16(p))
+		} else {
+			v = uint64(binary.LittleEndian.Uint16(p))
+		}
+	case 4:
+		if be {
+			v = uint64(binary.BigEndian.Uint32(p))
+		} else {
+			v = uint64(binary.LittleEndian.Uint32(p))
+		}
+	default:
+		if be {
+			v = binary.BigEndian.Uint64(p)
+		} else {
+			v = binary.LittleEndian.Uint64(p)
+		}
+	}
+	return v, p[size:], nil
+}
+
+// AppendUint appends unsigned v as integer kind k, it fails if v doesn't fit k.
+func AppendUint(p []byte, v uint64, k BinKind, be bool) ([]byte, error) {
+	switch {
+	case k == BinUvarint:
+		return binary.AppendUvarint(p, v), nil
+	case k == BinVarint:
+		if v > math.MaxInt64 {
+			return nil, gerrors.Wrap(ErrOutOfRange, k.String())
+		}
+		return binary.AppendVarint(p, int64(v)), nil
+	case k.size() > 0:
+		bits := k.size() * 8
+		if k.signed() {
+			bits--
+		}
+		if bits < 64 && v >= 1<<bits {
+			return nil, gerrors.Wrap(ErrOutOfRange, k.String())
+		}
+		return appendFixed(p, v, k.size(), be), nil
+	default:
+		return nil, gerrors.New("can't encode integer as %s", k)
+	}
+}
+
+// AppendInt appends signed v as integer kind k, it fails if v doesn't fit k.
+func AppendInt(p []byte, v int64, k BinKind, be bool) ([]byte, error) {
+	if !k.signed() {
+		if v < 0 {
+			return nil, gerrors.Wrap(ErrOutOfRange, k.String())
+		}
+		return AppendUint(p, uint64(v), k, be)
+	}
+	if k == BinVarint {
+		return binary.AppendVarint(p, v), nil
+	}
+	bits := k.size() * 8
+	if bits < 64 && (v < -1<<(bits-1) || v >= 1<<(bits-1)) {
+		return nil, gerrors.Wrap(ErrOutOfRange, k.String())
+	}
+	return appendFixed(p, uint64(v), k.size(), be), nil
+}
+
+// readRaw reads integer kind k, signed kinds are sign-extended into int64 bits.
+func readRaw(p []byte, k BinKind, be bool) (uint64, []byte, error) {
+	switch {
+	case k == BinUvarint:
+		v, n := binary.Uvarint(p)
+		if n <= 0 {
+			return 0, nil, ErrShortBuffer
+		}
+		return v, p[n:], nil
+	case k == BinVarint:
+		v, n := binary.Varint(p)
+		if n <= 0 {
+			return 0, nil, ErrShortBuffer
+		}
+		return uint64(v), p[n:], nil
+	case k.size() > 0:
+		v, rest, err := readFixed(p, k.size(), be)
+		if err != nil {
+			return 0, nil, err
+		}
+		if shift := 64 - k.size()*8; k.signed() && shift > 0 {
+			v = uint64(int64(v<<shift) >> shift)
+		}
+		return v, rest, nil
+	default:
+		return 0, nil, gerrors.New("can't decode integer as %s", k)
+	}
+}
+
+// ReadUint reads integer kind k into an unsigned integer of bits.
+func ReadUint(p []byte, k BinKind, be bool, bits int) (uint64, []byte, error) {
+	v, rest, err := readRaw(p, k, be)
+	if err != nil {
+		return 0, nil, err
+	}
+	if (k.signed() && int64(v) < 0) || (bits < 64 && v >= 1<<bits) {
+		return 0, nil, gerrors.Wrap(ErrOutOfRange, k.String())
+	}
+	return v, rest, nil
+}
+
+// ReadInt reads integer kind k into a signed integer of bits.
+func ReadInt(p []byte, k BinKind, be bool, bits int) (int64, []byte, error) {
+	v, rest, err := readRaw(p, k, be)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !k.signed() && v > math.MaxInt64 {
+		return 0, nil, gerrors.Wrap(ErrOutOfRange, k.String())
+	}
+	if n := int64(v); bits < 64 && (n < -1<<(bits-1) || n >= 1<<(bits-1)) {
+		return 0, nil, gerrors.Wrap(ErrOutOfRange, k.String())
+	}
+	return int64(v), rest, nil
+}
+
+func AppendFloat(p []byte, v float64, k BinKind, be bool) ([]byte, error) {
+	switch k {
+	case BinF32:
+		return appendFixed(p, uint64(math.Float32bits(float32(v))), 4, be), nil
+	case BinF64:
+		return appendFixed(p, math.Float64bits(v), 8, be), nil
+	default:
+		return nil, gerrors.New("can't encode float as %s", k)
+	}
+}
+
+func ReadFloat(p []byte, k BinKind, be bool) (float64, []byte, error) {
+	switch k {
+	case BinF32:
+		v, rest, err := readFixed(p, 4, be)
+		return float64(math.Float32frombits(uint32(v))), rest, err
+	case BinF64:
+		v, rest, err := readFixed(p, 8, be)
+		return math.Float64frombits(v), rest, err
+	default:
+		return 0, nil, gerrors.New("can't decode float as %s", k)
+	}
+}
+
+func AppendBool(p []byte, v bool) []byte {
+	if v {
+		return append(p, 1)
+	}
+	return append(p, 0)
+}
+
+func ReadBool(p []byte) (bool, []byte, error) {
+	if len(p) == 0 {
+		return false, nil, ErrShortBuffer
+	}
+	if p[0] > 1 {
+		return false, nil, gerrors.New("invalid bool byte %d", p[0])
+	}
+	return p[0] == 1, p[1:], nil
+}
+
+// AppendLen appends length prefix n as kind k, BinDefault is uvarint.
+func AppendLen(p []byte, n int, k BinKind, be bool) ([]byte, error) {
+	if k == BinDefault {
+		k = BinUvarint
+	}
+	return AppendUint(p, uint64(n), k, be)
+}
+
+func ReadLen(p []byte, k BinKind, be bool) (int, []byte, error) {
+	if k == BinDefault {
+		k = BinUvarint
+	}
+	n, rest, err := ReadUint(p, k, be, 31)
+	return int(n), rest, err
+}
+
+// CheckSliceElem is called after an element of a slice of length n is read with used bytes,
+// elements encoded to no bytes can't be bounded by input size, so their count is limited like Unpack does.
+func CheckSliceElem(n, used int, typ string) error {
+	if used == 0 && n > maxBinEmptyElems {
+		return gerrors.New("length %d of %s exceeds %d", n, typ, maxBinEmptyElems)
+	}
+	return nil
+}
+
+// AppendBytes appends b with length prefix of kind k.
+func AppendBytes(p []byte, b []byte, k BinKind, be bool) ([]byte, error) {
+	p, err := AppendLen(p, len(b), k, be)
+	if err != nil {
+		return nil, err
+	}
+	return append(p, b...), nil
+}
+
+func AppendString(p []byte, s string, k BinKind, be bool) ([]byte, error) {
+	p, err := AppendLen(p, len(s), k, be)
+	if err != nil {
+		return nil, err
+	}
+	return append(p, s...), nil
+}
+
+// ReadBytes reads bytes with length prefix of kind k, b shares memory with p.
+func ReadBytes(p []byte, k BinKind, be bool) (b, rest []byte, err error) {
+	n, rest, err := ReadLen(p, k, be)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rest) < n {
+		return nil, nil, ErrShortBuffer
+	}
+	return rest[:n:n], rest[n:], nil
+}
+
+// BeginVersion appends schema version of a versioned struct, call EndVersion with start after the body
+// is appended.
+func BeginVersion(p []byte, version uint64) (out []byte, start int) {
+	p = binary.AppendUvarint(p, version)
+	return p, len(p)
+}
+
+// EndVersion inserts body length before the body, so older readers can skip fields they don't know.
+func EndVersion(p []byte, start int) []byte {
+	n := len(p) - start
+	var hdr [binary.MaxVarintLen64]byte
+	k := binary.PutUvarint(hdr[:], uint64(n))
+	p = append(p, hdr[:k]...)
+	copy(p[start+k:], p[start:start+n])
+	copy(p[start:], hdr[:k])
+	return p
+}
+
+// ReadVersion reads schema version and body of a versioned struct.
+func ReadVersion(p []byte) (version uint64, body, rest []byte, err error) {
+	version, n := binary.Uvarint(p)
+	if n <= 0 {
+		return 0, nil, nil, ErrShortBuffer
+	}
+	body, rest, err = ReadBytes(p[n:], BinUvarint, false)
+	return version, body, rest, err
+}
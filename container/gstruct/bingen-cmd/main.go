@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/davidforest123/goutil/container/gstruct"
+	"os"
+	"strings"
+)
+
+// Generates AppendBin / DecodeBin methods of bin tagged structs into <file>_bin.go, e.g.
+// //go:generate go run github.com/davidforest123/goutil/container/gstruct/bingen-cmd -type Header,Packet $GOFILE
+func main() {
+	typeNames := flag.String("type", "", "comma separated struct type names, all structs of the file if empty")
+	output := flag.String("o", "", "output file name, default <file>_bin.go")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Example:\nbingen-cmd -type Header,Packet packet.go")
+		os.Exit(2)
+	}
+
+	src := flag.Arg(0)
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+	dst := *output
+	if dst == "" {
+		if strings.HasSuffix(src, "_test.go") {
+			dst = strings.TrimSuffix(src, "_test.go") + "_bin_test.go"
+		} else {
+			dst = strings.TrimSuffix(src, ".go") + "_bin.go"
+		}
+	}
+
+	code, err := os.ReadFile(src)
+	if err == nil {
+		code, err = gstruct.GenerateBinary(src, code, types)
+	}
+	if err == nil {
+		err = os.WriteFile(dst, code, 0644)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -1,27 +1,19 @@
 package gstruct
 
-// encode struct to bytes array or decode bytes array to struct
-// https://github.com/bitgoin/packer
-// https://github.com/zhuangsirui/binpacker
+// encode struct to bytes array or decode bytes array to struct, see binary.go for bin tags.
 
-// https://github.com/dxhbiz/codec/issues/1#issuecomment-331349108
-
-import (
-	"github.com/dxhbiz/codec"
-)
-
-func StructPack(v interface{}, p []byte) error {
-	p, err := codec.Encode(v)
+// StructPack encodes v into p and returns bytes written, it fails with ErrShortBuffer if p is too small.
+func StructPack(v interface{}, p []byte) (int, error) {
+	b, err := AppendPack(p[:0:len(p)], v)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if len(b) > len(p) { // capacity is limited, so growing means p is too small
+		return 0, ErrShortBuffer
 	}
-	return nil
+	return len(b), nil
 }
 
 func StructUnpack(p []byte, v interface{}) error {
-	err := codec.Decode(p, &v)
-	if err != nil {
-		return err
-	}
-	return nil
+	return Unpack(p, v)
 }
@@ -0,0 +1,547 @@
+package gstruct
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diff and patch work on JSON representation of values, so paths are JSON pointers of JSON field names
+// (e.g. "/servers/1/addr") and json tags are respected.
+// JSON Patch: RFC 6902, JSON Merge Patch: RFC 7386, JSON Pointer: RFC 6901.
+
+type (
+	// PatchOp is an operation of JSON Patch, Old is the replaced or removed value recorded by Diff for audit,
+	// it is not a part of RFC 6902 and is ignored by JSONPatch.
+	PatchOp struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		From  string `json:"from,omitempty"`
+		Value any    `json:"value,omitempty"`
+		Old   any    `json:"old,omitempty"`
+	}
+
+	Patch []PatchOp
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// MarshalJSON keeps "value" of add / replace / test even if it is null.
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	type plain PatchOp
+	if op.Value != nil || (op.Op != OpAdd && op.Op != OpReplace && op.Op != OpTest) {
+		return json.Marshal(plain(op))
+	}
+	return json.Marshal(struct {
+		plain
+		Value any `json:"value"`
+	}{plain: plain(op)})
+}
+
+// UnmarshalJSON keeps numbers of "value" and "old" as json.Number, float64 loses integers over 2^53.
+func (op *PatchOp) UnmarshalJSON(b []byte) error {
+	type plain PatchOp
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode((*plain)(op))
+}
+
+// toJSONValue converts v to generic JSON value: map[string]any, []any, string, json.Number, bool or nil.
+func toJSONValue(v any) (any, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	return decodeJSON(b)
+}
+
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, gerrors.New("trailing data after JSON value")
+	}
+	return out, nil
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if path[0] != '/' {
+		return nil, gerrors.New("invalid JSON pointer %s", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// Diff returns JSON Patch which turns a into b, a and b are values of the same type or raw JSON documents
+// as []byte. Arrays are compared element by element.
+func Diff(a, b any) (Patch, error) {
+	av, err := toJSONValue(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := toJSONValue(b)
+	if err != nil {
+		return nil, err
+	}
+	var p Patch
+	diffValue("", av, bv, &p)
+	return p, nil
+}
+
+func diffValue(path string, a, b any, p *Patch) {
+	switch at := a.(type) {
+	case map[string]any:
+		bt, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(at)+len(bt))
+		for k := range at {
+			keys = append(keys, k)
+		}
+		for k := range bt {
+			if _, ok := at[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			av, inA := at[k]
+			bv, inB := bt[k]
+			sub := path + "/" + escapePointer(k)
+			switch {
+			case !inB:
+				*p = append(*p, PatchOp{Op: OpRemove, Path: sub, Old: av})
+			case !inA:
+				*p = append(*p, PatchOp{Op: OpAdd, Path: sub, Value: bv})
+			default:
+				diffValue(sub, av, bv, p)
+			}
+		}
+		return
+	case []any:
+		bt, ok := b.([]any)
+		if !ok {
+			break
+		}
+		n := min(len(at), len(bt))
+		for i := 0; i < n; i++ {
+			diffValue(path+"/"+strconv.Itoa(i), at[i], bt[i], p)
+		}
+		for i := n; i < len(bt); i++ {
+			*p = append(*p, PatchOp{Op: OpAdd, Path: path + "/" + strconv.Itoa(i), Value: bt[i]})
+		}
+		for i := len(at) - 1; i >= n; i-- { // remove from the end so indexes stay valid
+			*p = append(*p, PatchOp{Op: OpRemove, Path: path + "/" + strconv.Itoa(i), Old: at[i]})
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*p = append(*p, PatchOp{Op: OpReplace, Path: path, Value: b, Old: a})
+	}
+}
+
+// JSONPatch applies RFC 6902 JSON Patch to JSON document doc, operations are applied in order and nothing
+// is returned if any of them fails.
+func JSONPatch(doc []byte, patch Patch) ([]byte, error) {
+	v, err := decodeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range patch {
+		if v, err = applyOp(v, op); err != nil {
+			return nil, gerrors.Wrap(err, "operation "+strconv.Itoa(i))
+		}
+	}
+	return json.Marshal(v)
+}
+
+func applyOp(doc any, op PatchOp) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		value, err := toJSONValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case OpAdd:
+			return addValue(doc, path, value)
+		case OpReplace:
+			if doc, err = removeValue(doc, path); err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, value)
+		default:
+			cur, err := getValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonValueEqual(cur, value) {
+				return nil, gerrors.New("test of %s failed", op.Path)
+			}
+			return doc, nil
+		}
+	case OpRemove:
+		return removeValue(doc, path)
+	case OpMove, OpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == OpCopy {
+			if value, err = toJSONValue(value); err != nil { // deep copy
+				return nil, err
+			}
+			return addValue(doc, path, value)
+		}
+		if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return nil, gerrors.New("can't move %s into its child %s", op.From, op.Path)
+		}
+		if doc, err = removeValue(doc, from); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	default:
+		return nil, gerrors.New("unknown patch operation %s", op.Op)
+	}
+}
+
+// jsonValueEqual reports whether generic JSON values are equal, numbers are compared by value so 1 equals 1.0.
+func jsonValueEqual(a, b any) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		an, aok := normalizeNumber(string(av))
+		bn, bok := normalizeNumber(string(bv))
+		if !aok || !bok {
+			return av == bv
+		}
+		return an == bn
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !jsonValueEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonValueEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// normalizeNumber converts JSON number n to "[-]digits e exponent" without leading and trailing zeros of digits,
+// so it is exact for any precision.
+func normalizeNumber(n string) (string, bool) {
+	neg := strings.HasPrefix(n, "-")
+	n = strings.TrimPrefix(n, "-")
+	mant, expStr, hasExp := strings.Cut(strings.ToLower(n), "e")
+	exp := 0
+	if hasExp {
+		var err error
+		if exp, err = strconv.Atoi(expStr); err != nil {
+			return "", false
+		}
+	}
+	intPart, frac, _ := strings.Cut(mant, ".")
+	digits := intPart + frac
+	exp -= len(frac)
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return "0", true // -0 equals 0
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed)
+	if neg {
+		trimmed = "-" + trimmed
+	}
+	return trimmed + "e" + strconv.Itoa(exp), true
+}
+
+func arrayIndex(token string, n int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && token[0] == '0') {
+		return 0, gerrors.New("invalid array index %s", token)
+	}
+	if i > n || (i == n && !allowEnd) {
+		return 0, gerrors.New("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for _, t := range path {
+		switch n := doc.(type) {
+		case map[string]any:
+			v, ok := n[t]
+			if !ok {
+				return nil, gerrors.New("member %s not found", t)
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(t, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = n[i]
+		default:
+			return nil, gerrors.New("can't index %s of a scalar", t)
+		}
+	}
+	return doc, nil
+}
+
+// update replaces the container at path with fn(container, last token), containers are rebuilt along the
+// path because slices may be reallocated.
+func update(doc any, path []string, fn func(parent any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	switch n := doc.(type) {
+	case map[string]any:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, gerrors.New("member %s not found", path[0])
+		}
+		nc, err := update(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = nc
+		return n, nil
+	case []any:
+		i, err := arrayIndex(path[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		nc, err := update(n[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = nc
+		return n, nil
+	default:
+		return nil, gerrors.New("can't index %s of a scalar", path[0])
+	}
+}
+
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent any, key string) (any, error) {
+		switch n := parent.(type) {
+		case map[string]any:
+			n[key] = value
+			return n, nil
+		case []any:
+			i, err := arrayIndex(key, len(n), true)
+			if err != nil {
+				return nil, err
+			}
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			return n, nil
+		default:
+			return nil, gerrors.New("can't add %s to a scalar", key)
+		}
+	})
+}
+
+func removeValue(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return update(doc, path, func(parent any, key string) (any, error) {
+		switch n := parent.(type) {
+		case map[string]any:
+			if _, ok := n[key]; !ok {
+				return nil, gerrors.New("member %s not found", key)
+			}
+			delete(n, key)
+			return n, nil
+		case []any:
+			i, err := arrayIndex(key, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(n[:i], n[i+1:]...), nil
+		default:
+			return nil, gerrors.New("can't remove %s from a scalar", key)
+		}
+	})
+}
+
+// MergePatch applies RFC 7386 JSON Merge Patch to JSON document doc.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	dv, err := decodeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	pv, err := decodeJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(dv, pv))
+}
+
+func mergeValue(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = map[string]any{}
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergeValue(tm[k], v)
+		}
+	}
+	return tm
+}
+
+// CreateMergePatch returns JSON Merge Patch which turns a into b, a and b are values of the same type or
+// raw JSON documents as []byte.
+func CreateMergePatch(a, b any) ([]byte, error) {
+	av, err := toJSONValue(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := toJSONValue(b)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeDiff(av, bv))
+}
+
+func mergeDiff(a, b any) any {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if !aok || !bok {
+		return b
+	}
+	out := map[string]any{}
+	for k, av := range am {
+		bv, ok := bm[k]
+		if !ok {
+			out[k] = nil
+		} else if !reflect.DeepEqual(av, bv) {
+			out[k] = mergeDiff(av, bv)
+		}
+	}
+	for k, bv := range bm {
+		if _, ok := am[k]; !ok {
+			out[k] = bv
+		}
+	}
+	return out
+}
+
+// Apply applies patch to v which is a pointer, patch is JSON Patch if it is a JSON array, otherwise it is
+// JSON Merge Patch. Fields which are removed by patch become zero values.
+func Apply(v any, patch []byte) error {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if trimmed := bytes.TrimSpace(patch); len(trimmed) > 0 && trimmed[0] == '[' {
+		var p Patch
+		if err := json.Unmarshal(patch, &p); err != nil {
+			return err
+		}
+		doc, err = JSONPatch(doc, p)
+	} else {
+		doc, err = MergePatch(doc, patch)
+	}
+	if err != nil {
+		return err
+	}
+	return unmarshalFresh(doc, v)
+}
+
+// ApplyTo applies p to v which is a pointer.
+func (p Patch) ApplyTo(v any) error {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if doc, err = JSONPatch(doc, p); err != nil {
+		return err
+	}
+	return unmarshalFresh(doc, v)
+}
+
+// unmarshalFresh decodes doc into a zero value, then sets v to it, so removed members don't keep old values.
+func unmarshalFresh(doc []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return gerrors.New("must receive a non-nil pointer, but received %T", v)
+	}
+	fresh := reflect.New(rv.Type().Elem())
+	if err := json.Unmarshal(doc, fresh.Interface()); err != nil {
+		return err
+	}
+	rv.Elem().Set(fresh.Elem())
+	return nil
+}
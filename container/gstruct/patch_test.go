@@ -0,0 +1,125 @@
+package gstruct
+
+import (
+	"encoding/json"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"reflect"
+	"testing"
+)
+
+type testDoc struct {
+	Name    string            `json:"name"`
+	Port    int               `json:"port"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Comment *string           `json:"comment,omitempty"`
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	av, err := decodeJSON(a)
+	gtest.Assert(t, err)
+	bv, err := decodeJSON(b)
+	gtest.Assert(t, err)
+	return reflect.DeepEqual(av, bv)
+}
+
+func TestDiff(t *testing.T) {
+	a := testDoc{Name: "web", Port: 80, Tags: []string{"a", "b", "c"}, Labels: map[string]string{"env": "dev", "a/b": "1"}}
+	comment := "new"
+	b := testDoc{Name: "web", Port: 8080, Tags: []string{"a", "x"}, Labels: map[string]string{"env": "prod"}, Comment: &comment}
+	p, err := Diff(a, b)
+	gtest.Assert(t, err)
+	got, err := json.Marshal(p)
+	gtest.Assert(t, err)
+	expect := `[{"op":"add","path":"/comment","value":"new"},
+		{"op":"remove","path":"/labels/a~1b","old":"1"},
+		{"op":"replace","path":"/labels/env","value":"prod","old":"dev"},
+		{"op":"replace","path":"/port","value":8080,"old":80},
+		{"op":"replace","path":"/tags/1","value":"x","old":"b"},
+		{"op":"remove","path":"/tags/2","old":"c"}]`
+	gtest.AssertTrue(t, jsonEqual(t, got, []byte(expect)), "Diff got %s", got)
+
+	c := a
+	gtest.Assert(t, p.ApplyTo(&c))
+	gtest.AssertTrue(t, reflect.DeepEqual(c, b), "Diff then ApplyTo got %+v", c)
+
+	mp, err := CreateMergePatch(a, b)
+	gtest.Assert(t, err)
+	d := a
+	d.Labels = map[string]string{"env": "dev", "a/b": "1"}
+	gtest.Assert(t, Apply(&d, mp))
+	gtest.AssertTrue(t, reflect.DeepEqual(d, b), "merge patch %s got %+v", mp, d)
+}
+
+func TestJSONPatch(t *testing.T) {
+	cases := []struct {
+		doc, patch, expect string
+	}{
+		// RFC 6902 appendix A
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":null}]`, `{"a":{"b":1},"c":{"b":null}}`},
+		// numbers are compared by value
+		{`{"a":[1,-0,2.50]}`, `[{"op":"test","path":"/a","value":[1.0,0,25e-1]}]`, `{"a":[1,-0,2.50]}`},
+	}
+	for _, c := range cases {
+		var p Patch
+		gtest.Assert(t, json.Unmarshal([]byte(c.patch), &p))
+		got, err := JSONPatch([]byte(c.doc), p)
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, jsonEqual(t, got, []byte(c.expect)), "patch %s got %s but expect %s", c.patch, got, c.expect)
+	}
+
+	errCases := []string{
+		`[{"op":"test","path":"/baz","value":"bar"}]`,
+		`[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+		`[{"op":"remove","path":"/nope"}]`,
+		`[{"op":"add","path":"/arr/5","value":1}]`,
+		`[{"op":"move","from":"/obj","path":"/obj/child"}]`,
+		`[{"op":"unknown","path":"/baz"}]`,
+		`[{"op":"test","path":"/arr/0","value":1.000000000000000000001}]`,
+	}
+	for _, c := range errCases {
+		var p Patch
+		gtest.Assert(t, json.Unmarshal([]byte(c), &p))
+		_, err := JSONPatch([]byte(`{"baz":"qux","arr":[1],"obj":{}}`), p)
+		gtest.AssertTrue(t, err != nil, "patch %s should fail", c)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// RFC 7386 section 3
+	doc := `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`
+	patch := `{"title":"Hello!","phoneNumber":"+01-123-456-7890","author":{"familyName":null},"tags":["example"]}`
+	expect := `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890"}`
+	got, err := MergePatch([]byte(doc), []byte(patch))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, jsonEqual(t, got, []byte(expect)), "MergePatch got %s", got)
+
+	created, err := CreateMergePatch([]byte(doc), []byte(expect))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, jsonEqual(t, created, []byte(patch)), "CreateMergePatch got %s", created)
+
+	d := testDoc{Name: "a", Port: 1, Tags: []string{"x"}}
+	gtest.Assert(t, Apply(&d, []byte(`{"port":2,"tags":null}`)))
+	gtest.AssertTrue(t, d.Name == "a" && d.Port == 2 && d.Tags == nil, "Apply merge patch got %+v", d)
+	gtest.Assert(t, Apply(&d, []byte(` [{"op":"replace","path":"/name","value":"b"}]`)))
+	gtest.AssertTrue(t, d.Name == "b", "Apply JSON patch got %+v", d)
+
+	// integers over 2^53 survive
+	var big struct {
+		ID uint64 `json:"id"`
+	}
+	gtest.Assert(t, Apply(&big, []byte(`[{"op":"replace","path":"/id","value":9007199254740993},{"op":"test","path":"/id","value":9007199254740993}]`)))
+	gtest.AssertTrue(t, big.ID == 9007199254740993, "Apply got %d", big.ID)
+}
@@ -0,0 +1,384 @@
+package gstruct
+
+import (
+	"errors"
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+Validation driven by `validate` struct tags, rules are separated by comma:
+required       value must not be zero, pointer must not be nil
+omitempty      skip other rules if value is zero
+min=N, max=N   numbers: value, strings: rune count, slices / maps: length, time.Duration: "1s" style
+len=N          exact rune count / length
+oneof=a b c    value is one of space separated items
+email, url, ip, ipv4, ipv6, cidr, hostname
+
+Nested structs, pointers to structs, and structs in slices / arrays / maps are validated recursively.
+Custom rules can be added by RegisterValidator.
+*/
+
+type (
+	// ValidatorFn checks value v with rule parameter, it returns error message if v is invalid.
+	ValidatorFn func(v reflect.Value, param string) error
+
+	FieldError struct {
+		Path  string // e.g. "Servers[1].Addr"
+		Rule  string
+		Param string
+		Err   error
+	}
+
+	// TagError is returned by ValidatorFn if the rule is misused, like an invalid parameter or a type the rule
+	// doesn't support, ValidateFields returns it as error instead of a FieldError.
+	TagError struct {
+		Msg string
+	}
+
+	// visitKey is a pointer or map already validated, it stops cycles like c.Next = c.
+	visitKey struct {
+		ptr uintptr
+		t   reflect.Type
+	}
+)
+
+var (
+	validators   = map[string]ValidatorFn{}
+	validatorsMu sync.RWMutex
+
+	durationType  = reflect.TypeOf(time.Duration(0))
+	hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+func init() {
+	for name, fn := range map[string]ValidatorFn{
+		"min":      validateMin,
+		"max":      validateMax,
+		"len":      validateLen,
+		"oneof":    validateOneOf,
+		"email":    validateEmail,
+		"url":      validateURL,
+		"ip":       validateIP(0),
+		"ipv4":     validateIP(4),
+		"ipv6":     validateIP(6),
+		"cidr":     validateCIDR,
+		"hostname": validateHostname,
+	} {
+		validators[name] = fn
+	}
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TagError) Error() string {
+	return e.Msg
+}
+
+// RegisterValidator adds or replaces rule name.
+func RegisterValidator(name string, fn ValidatorFn) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = fn
+}
+
+// Validate validates v by validate tags and joins all field errors into one error.
+func Validate(v any) error {
+	fieldErrs, err := ValidateFields(v)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		errs[i] = fe
+	}
+	return gerrors.JoinArray(errs)
+}
+
+// ValidateFields returns all invalid fields of v, error is returned only if v or tags are invalid.
+func ValidateFields(v any) ([]*FieldError, error) {
+	rv := reflect.ValueOf(v)
+	visited := map[visitKey]bool{}
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		visited[visitKey{ptr: rv.Pointer(), t: rv.Type()}] = true
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, gerrors.New("must receive a struct or a pointer to a struct, but received %T", v)
+	}
+	var errs []*FieldError
+	if err := validateValue(rv, "", &errs, visited); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// validateValue walks into structs, containers and pointers to validate nested struct fields,
+// every pointer and map is validated once at the first path it is found.
+func validateValue(v reflect.Value, path string, errs *[]*FieldError, visited map[visitKey]bool) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		key := visitKey{ptr: v.Pointer(), t: v.Type()}
+		if visited[key] {
+			return nil
+		}
+		visited[key] = true
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return validateValue(v.Elem(), path, errs, visited)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs, visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), errs, visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fpath := f.Name
+			if path != "" {
+				fpath = path + "." + f.Name
+			}
+			descend, err := validateField(v.Field(i), fpath, f.Tag.Get("validate"), errs)
+			if err != nil {
+				return err
+			}
+			if descend {
+				if err := validateValue(v.Field(i), fpath, errs, visited); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateField applies rules of tag to field v and reports whether nested values of v should be validated,
+// they are not if v is invalid or tag is "-".
+func validateField(v reflect.Value, path, tag string, errs *[]*FieldError) (bool, error) {
+	if tag == "-" {
+		return false, nil
+	}
+	if tag == "" {
+		return true, nil
+	}
+	rules := strings.Split(tag, ",")
+	for _, rule := range rules {
+		if rule == "omitempty" && v.IsZero() {
+			return true, nil
+		}
+	}
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "", "omitempty":
+			continue
+		case "required":
+			if v.IsZero() {
+				*errs = append(*errs, &FieldError{Path: path, Rule: name, Err: gerrors.New("is required")})
+				return false, nil
+			}
+			continue
+		}
+
+		validatorsMu.RLock()
+		fn, ok := validators[name]
+		validatorsMu.RUnlock()
+		if !ok {
+			return false, gerrors.New("unknown validate rule %s of %s", name, path)
+		}
+		ev := v
+		for ev.Kind() == reflect.Pointer {
+			if ev.IsNil() {
+				break
+			}
+			ev = ev.Elem()
+		}
+		if ev.Kind() == reflect.Pointer { // nil pointer which is not required
+			return true, nil
+		}
+		if err := fn(ev, param); err != nil {
+			var te *TagError
+			if errors.As(err, &te) {
+				return false, gerrors.New("invalid validate rule %s of %s: %s", rule, path, te.Msg)
+			}
+			*errs = append(*errs, &FieldError{Path: path, Rule: name, Param: param, Err: err})
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// measure returns the number which min / max / len compare with, and the parsed param.
+func measure(v reflect.Value, param string) (float64, float64, error) {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(param)
+		if err != nil {
+			return 0, 0, &TagError{Msg: "invalid duration " + param}
+		}
+		return float64(v.Int()), float64(d), nil
+	}
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, 0, &TagError{Msg: "invalid number " + param}
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), p, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), p, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), p, nil
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), p, nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), p, nil
+	default:
+		return 0, 0, &TagError{Msg: fmt.Sprintf("can't measure %s", v.Type())}
+	}
+}
+
+func sizeWord(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return "length "
+	}
+	return ""
+}
+
+func validateMin(v reflect.Value, param string) error {
+	n, p, err := measure(v, param)
+	if err == nil && n < p {
+		err = gerrors.New("%smust be at least %s", sizeWord(v), param)
+	}
+	return err
+}
+
+func validateMax(v reflect.Value, param string) error {
+	n, p, err := measure(v, param)
+	if err == nil && n > p {
+		err = gerrors.New("%smust be at most %s", sizeWord(v), param)
+	}
+	return err
+}
+
+func validateLen(v reflect.Value, param string) error {
+	n, p, err := measure(v, param)
+	if err == nil && n != p {
+		err = gerrors.New("%smust be %s", sizeWord(v), param)
+	}
+	return err
+}
+
+func validateOneOf(v reflect.Value, param string) error {
+	s := fmt.Sprint(v.Interface())
+	for _, item := range strings.Fields(param) {
+		if s == item {
+			return nil
+		}
+	}
+	return gerrors.New("must be one of [%s]", param)
+}
+
+func stringOf(v reflect.Value) (string, error) {
+	if v.Kind() != reflect.String {
+		return "", &TagError{Msg: fmt.Sprintf("rule needs a string but got %s", v.Type())}
+	}
+	return v.String(), nil
+}
+
+func validateEmail(v reflect.Value, _ string) error {
+	s, err := stringOf(v)
+	if err != nil {
+		return err
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return gerrors.New("invalid email %s", s)
+	}
+	return nil
+}
+
+func validateURL(v reflect.Value, _ string) error {
+	s, err := stringOf(v)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return gerrors.New("invalid url %s", s)
+	}
+	return nil
+}
+
+func validateIP(version int) ValidatorFn {
+	return func(v reflect.Value, _ string) error {
+		s, err := stringOf(v)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(s)
+		if ip == nil || (version == 4 && ip.To4() == nil) || (version == 6 && ip.To4() != nil) {
+			if version == 0 {
+				return gerrors.New("invalid ip %s", s)
+			}
+			return gerrors.New("invalid ipv%d %s", version, s)
+		}
+		return nil
+	}
+}
+
+func validateCIDR(v reflect.Value, _ string) error {
+	s, err := stringOf(v)
+	if err != nil {
+		return err
+	}
+	if _, _, err := net.ParseCIDR(s); err != nil {
+		return gerrors.New("invalid cidr %s", s)
+	}
+	return nil
+}
+
+func validateHostname(v reflect.Value, _ string) error {
+	s, err := stringOf(v)
+	if err != nil {
+		return err
+	}
+	if len(s) > 253 || !hostnameRegex.MatchString(s) {
+		return gerrors.New("invalid hostname %s", s)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package gstruct
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	testServer struct {
+		Addr    string `validate:"required,ip"`
+		Network string `validate:"omitempty,cidr"`
+	}
+
+	testConfig struct {
+		Name    string        `validate:"required,min=2,max=8"`
+		Email   string        `validate:"email"`
+		Home    string        `validate:"omitempty,url"`
+		Host    string        `validate:"hostname"`
+		Level   int           `validate:"min=1,max=5"`
+		Mode    string        `validate:"oneof=dev prod"`
+		Timeout time.Duration `validate:"min=1s"`
+		Tags    []string      `validate:"len=2"`
+		Servers []testServer  `validate:"min=1"`
+		Backup  *testServer
+		Even    int `validate:"even"`
+	}
+)
+
+func TestValidate(t *testing.T) {
+	RegisterValidator("even", func(v reflect.Value, _ string) error {
+		if v.Int()%2 != 0 {
+			return gerrors.New("must be even")
+		}
+		return nil
+	})
+
+	ok := testConfig{
+		Name: "demo", Email: "a@b.com", Host: "api.example.com", Level: 3, Mode: "prod", Timeout: time.Second,
+		Tags: []string{"a", "b"}, Servers: []testServer{{Addr: "10.0.0.1", Network: "10.0.0.0/8"}}, Even: 2,
+	}
+	gtest.Assert(t, Validate(&ok))
+
+	bad := testConfig{
+		Name: "x", Email: "Bob <b@c.com>", Home: "not a url", Host: "-bad-", Level: 9, Mode: "test",
+		Timeout: time.Millisecond, Tags: []string{"a"}, Servers: []testServer{{Addr: "1.2.3.4"}, {Network: "10.0.0.0"}},
+		Backup: &testServer{Addr: "::1", Network: "bad"}, Even: 3,
+	}
+	fieldErrs, err := ValidateFields(bad)
+	gtest.Assert(t, err)
+	var paths []string
+	for _, fe := range fieldErrs {
+		paths = append(paths, fe.Path+":"+fe.Rule)
+	}
+	expect := "Name:min Email:email Home:url Host:hostname Level:max Mode:oneof Timeout:min Tags:len " +
+		"Servers[1].Addr:required Servers[1].Network:cidr Backup.Network:cidr Even:even"
+	gtest.AssertTrue(t, strings.Join(paths, " ") == expect, "invalid fields got %v", paths)
+
+	err = Validate(bad)
+	gtest.AssertTrue(t, err != nil && strings.Contains(err.Error(), "Servers[1].Addr: is required"), "Validate got %v", err)
+
+	_, err = ValidateFields(struct {
+		A int `validate:"nosuchrule"`
+	}{})
+	gtest.AssertTrue(t, err != nil, "unknown rule should fail")
+
+	fieldErrs, err = ValidateFields(struct {
+		A int `validate:"min=abc"`
+	}{A: 1})
+	gtest.AssertTrue(t, err != nil && len(fieldErrs) == 0, "invalid param should fail as tag error, got %v %v", fieldErrs, err)
+	err = Validate(struct {
+		A struct{} `validate:"max=1"`
+	}{})
+	gtest.AssertTrue(t, err != nil && strings.Contains(err.Error(), "can't measure"), "unsupported type got %v", err)
+
+	// pointer cycles are validated once
+	type node struct {
+		Name string `validate:"required"`
+		Next *node
+		Peer map[string]*node
+	}
+	n := &node{}
+	n.Next = n
+	n.Peer = map[string]*node{"self": n}
+	fieldErrs, err = ValidateFields(n)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, len(fieldErrs) == 1 && fieldErrs[0].Path == "Name", "cycle got %v", fieldErrs)
+}
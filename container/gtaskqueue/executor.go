@@ -0,0 +1,473 @@
+package gtaskqueue
+
+// Worker pool executor which runs registered handlers per task type, with retries, deadlines,
+// delayed tasks, dead-letter queue and optional persistence of pending tasks.
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"sync"
+	"time"
+)
+
+type (
+	// Handler executes one task, ctx is canceled when task.ExecTimeout is reached or executor is stopped.
+	Handler func(ctx context.Context, task *Task) error
+
+	RetryPolicy struct {
+		MaxRetries int           // Max retries after the first failed attempt.
+		MinBackoff time.Duration // Backoff before the first retry, doubled on every further retry.
+		MaxBackoff time.Duration // Upper bound of backoff, 0 means unlimited.
+	}
+
+	ExecutorConfig struct {
+		Workers            int
+		Retry              RetryPolicy
+		DefaultExecTimeout time.Duration // Used when Task.ExecTimeout is 0, 0 means no timeout.
+		MaxDeadLetters     int           // Oldest dead letters are discarded beyond this, 0 means unlimited.
+		PersistPath        string        // Journal file of pending tasks, empty means memory only.
+		OnDead             func(task *Task)
+	}
+
+	Executor struct {
+		cfg      ExecutorConfig
+		handlers map[string]Handler
+
+		mu         sync.Mutex
+		ready      readyHeap
+		delayed    delayedHeap
+		running    map[string]*Task
+		deadLetter []*Task
+		seq        uint64
+		signal     chan struct{} // closed and replaced when tasks are added or executor stops
+		stats      map[string]*TypeStatistic
+		started    bool
+		stopping   bool
+		journal    *journal
+
+		ctx    context.Context
+		cancel context.CancelFunc
+		wg     sync.WaitGroup
+	}
+)
+
+var (
+	ErrNoHandler       = gerrors.New("no handler registered for task type")
+	ErrExecutorStopped = gerrors.New("executor stopped")
+	ErrTaskPanic       = gerrors.New("task handler panic")
+)
+
+// NewExecutor creates an executor, if cfg.PersistPath is set, pending tasks saved by
+// previous process are restored and will run after Start.
+func NewExecutor(cfg ExecutorConfig) (*Executor, error) {
+	if cfg.Workers <= 0 {
+		return nil, gerrors.New("invalid executor workers %d", cfg.Workers)
+	}
+	e := &Executor{
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+		running:  make(map[string]*Task),
+		signal:   make(chan struct{}),
+		stats:    make(map[string]*TypeStatistic),
+	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+
+	if cfg.PersistPath != "" {
+		j, tasks, err := openJournal(cfg.PersistPath)
+		if err != nil {
+			return nil, err
+		}
+		e.journal = j
+		for _, t := range tasks {
+			e.enqueueLocked(t)
+		}
+	}
+	return e, nil
+}
+
+// Register sets handler of taskType, it should be called before Start.
+func (e *Executor) Register(taskType string, h Handler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers[taskType] = h
+}
+
+// Start launches worker goroutines.
+func (e *Executor) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.started {
+		return
+	}
+	e.started = true
+	for i := 0; i < e.cfg.Workers; i++ {
+		e.wg.Add(1)
+		go e.worker()
+	}
+}
+
+// NewTask creates a task with generated Id.
+func NewTask(taskType string, priority Priority, payload []byte) *Task {
+	return &Task{Id: newTaskId(), Type: taskType, PriorityValue: priority, Payload: payload}
+}
+
+// Submit adds a task which runs as soon as a worker is available, or at task.RunAt if it is set.
+func (e *Executor) Submit(task *Task) error {
+	if task.PriorityValue < PriorityHighest || task.PriorityValue > PriorityLowest {
+		return gerrors.New("invalid task priority %d", task.PriorityValue)
+	}
+	if task.Id == "" {
+		task.Id = newTaskId()
+	}
+	if task.CreateTime.IsZero() {
+		task.CreateTime = time.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopping {
+		return ErrExecutorStopped
+	}
+	if e.journal != nil {
+		if err := e.journal.add(task); err != nil {
+			return err
+		}
+	}
+	e.enqueueLocked(task)
+	return nil
+}
+
+// SubmitAt adds a task which runs not before runAt.
+func (e *Executor) SubmitAt(task *Task, runAt time.Time) error {
+	task.RunAt = runAt
+	return e.Submit(task)
+}
+
+// SubmitAfter adds a task which runs after delay.
+func (e *Executor) SubmitAfter(task *Task, delay time.Duration) error {
+	return e.SubmitAt(task, time.Now().Add(delay))
+}
+
+func (e *Executor) enqueueLocked(task *Task) {
+	e.seq++
+	item := &queuedTask{task: task, seq: e.seq}
+	if task.RunAt.After(time.Now()) {
+		heap.Push(&e.delayed, item)
+	} else {
+		heap.Push(&e.ready, item)
+	}
+	e.typeStatLocked(task.Type).Pending++
+	e.broadcastLocked()
+}
+
+func (e *Executor) broadcastLocked() {
+	close(e.signal)
+	e.signal = make(chan struct{})
+}
+
+func (e *Executor) typeStatLocked(taskType string) *TypeStatistic {
+	ts := e.stats[taskType]
+	if ts == nil {
+		ts = &TypeStatistic{}
+		e.stats[taskType] = ts
+	}
+	return ts
+}
+
+// next waits for a runnable task, it returns nil when executor is stopping.
+func (e *Executor) next() *Task {
+	for {
+		e.mu.Lock()
+		if e.stopping {
+			e.mu.Unlock()
+			return nil
+		}
+		now := time.Now()
+		for e.delayed.Len() > 0 && !e.delayed.items[0].task.RunAt.After(now) {
+			heap.Push(&e.ready, heap.Pop(&e.delayed))
+		}
+		if e.ready.Len() > 0 {
+			task := heap.Pop(&e.ready).(*queuedTask).task
+			task.Attempt++
+			task.ExecBeginTime = now
+			e.running[task.Id] = task
+			ts := e.typeStatLocked(task.Type)
+			ts.Pending--
+			ts.Running++
+			e.mu.Unlock()
+			return task
+		}
+
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if e.delayed.Len() > 0 {
+			timer = time.NewTimer(e.delayed.items[0].task.RunAt.Sub(now))
+			timerC = timer.C
+		}
+		ch := e.signal
+		e.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-timerC:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (e *Executor) worker() {
+	defer e.wg.Done()
+	for {
+		task := e.next()
+		if task == nil {
+			return
+		}
+		e.execute(task)
+	}
+}
+
+func (e *Executor) execute(task *Task) {
+	e.mu.Lock()
+	h := e.handlers[task.Type]
+	e.mu.Unlock()
+
+	var err error
+	if h == nil {
+		err = ErrNoHandler
+	} else {
+		timeout := task.ExecTimeout
+		if timeout == 0 {
+			timeout = e.cfg.DefaultExecTimeout
+		}
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(e.ctx, timeout)
+		} else {
+			ctx, cancel = context.WithCancel(e.ctx)
+		}
+		err = runHandler(ctx, h, task)
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			err = ErrorExecTimeout
+		}
+		cancel()
+	}
+	e.finish(task, err)
+}
+
+func runHandler(ctx context.Context, h Handler, task *Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = gerrors.Wrap(fmt.Errorf("%v", r), ErrTaskPanic.Error())
+		}
+	}()
+	return h(ctx, task)
+}
+
+func (e *Executor) finish(task *Task, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	task.ExecDoneTime = time.Now()
+	task.LastError = err
+	delete(e.running, task.Id)
+	ts := e.typeStatLocked(task.Type)
+	ts.Running--
+	ts.addExec(task.ExecDoneTime.Sub(task.ExecBeginTime), err)
+
+	if err == nil {
+		if e.journal != nil {
+			_ = e.journal.done(task.Id)
+		}
+		return
+	}
+
+	// Executor is stopping, keep the task in journal so it resumes after restart.
+	if e.stopping && e.ctx.Err() != nil {
+		return
+	}
+
+	if err != ErrNoHandler && task.Attempt <= e.cfg.Retry.MaxRetries {
+		ts.Retried++
+		task.RunAt = task.ExecDoneTime.Add(e.cfg.Retry.backoff(task.Attempt))
+		if e.journal != nil {
+			_ = e.journal.add(task)
+		}
+		e.enqueueLocked(task)
+		return
+	}
+
+	ts.Dead++
+	e.deadLetter = append(e.deadLetter, task)
+	if e.cfg.MaxDeadLetters > 0 && len(e.deadLetter) > e.cfg.MaxDeadLetters {
+		e.deadLetter = e.deadLetter[len(e.deadLetter)-e.cfg.MaxDeadLetters:]
+	}
+	if e.journal != nil {
+		_ = e.journal.done(task.Id)
+	}
+	if e.cfg.OnDead != nil {
+		go e.cfg.OnDead(task)
+	}
+}
+
+// backoff returns delay before retry after attempt failed attempts.
+func (rp RetryPolicy) backoff(attempt int) time.Duration {
+	d := rp.MinBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if rp.MaxBackoff > 0 && d >= rp.MaxBackoff {
+			break
+		}
+	}
+	if rp.MaxBackoff > 0 && d > rp.MaxBackoff {
+		d = rp.MaxBackoff
+	}
+	return d
+}
+
+// DeadLetters returns tasks which failed after all retries.
+func (e *Executor) DeadLetters() []*Task {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Task(nil), e.deadLetter...)
+}
+
+// Requeue moves a dead letter back to pending queue with attempts reset.
+func (e *Executor) Requeue(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, t := range e.deadLetter {
+		if t.Id != id {
+			continue
+		}
+		if e.journal != nil {
+			if err := e.journal.add(t); err != nil {
+				return err
+			}
+		}
+		e.deadLetter = append(e.deadLetter[:i], e.deadLetter[i+1:]...)
+		t.Attempt = 0
+		t.RunAt = time.Time{}
+		e.typeStatLocked(t.Type).Dead--
+		e.enqueueLocked(t)
+		return nil
+	}
+	return gerrors.ErrNotFound
+}
+
+// GetStatistic returns current and accumulated statistic since executor created.
+func (e *Executor) GetStatistic() *Statistic {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	res := &Statistic{Types: make(map[string]*TypeStatistic)}
+	res.Now2doSize = int64(e.ready.Len() + e.delayed.Len())
+	res.NowExecSize = int64(len(e.running))
+	var durSum time.Duration
+	var count int64
+	for k, v := range e.stats {
+		res.typeStatistic(k).merge(v)
+		res.LatelySuccessSize += v.Success
+		res.LatelyExecTimeoutSize += v.ExecTimeout
+		res.LatelyExecErrorSize += v.ExecError
+		durSum += v.execDurationSum
+		count += v.execCount
+	}
+	if count > 0 {
+		res.LatelyAvgExecDuration = durSum / time.Duration(count)
+	}
+	return res
+}
+
+// Stop stops taking tasks and waits running tasks to finish until ctx is done,
+// after which their contexts are canceled. Pending tasks stay in journal if persistence is enabled.
+func (e *Executor) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	if e.stopping {
+		e.mu.Unlock()
+		return nil
+	}
+	e.stopping = true
+	e.broadcastLocked()
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		e.cancel()
+		<-done
+	}
+	e.cancel()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.journal != nil {
+		if e2 := e.journal.close(); err == nil {
+			err = e2
+		}
+	}
+	return err
+}
+
+type (
+	queuedTask struct {
+		task *Task
+		seq  uint64
+	}
+
+	// readyHeap orders tasks by priority, then FIFO.
+	readyHeap struct {
+		items []*queuedTask
+	}
+
+	// delayedHeap orders tasks by RunAt.
+	delayedHeap struct {
+		items []*queuedTask
+	}
+)
+
+func (h *readyHeap) Len() int { return len(h.items) }
+func (h *readyHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if a.task.PriorityValue != b.task.PriorityValue {
+		return a.task.PriorityValue < b.task.PriorityValue
+	}
+	return a.seq < b.seq
+}
+func (h *readyHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *readyHeap) Push(x any)    { h.items = append(h.items, x.(*queuedTask)) }
+func (h *readyHeap) Pop() any {
+	n := len(h.items)
+	it := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	return it
+}
+
+func (h *delayedHeap) Len() int { return len(h.items) }
+func (h *delayedHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if !a.task.RunAt.Equal(b.task.RunAt) {
+		return a.task.RunAt.Before(b.task.RunAt)
+	}
+	return a.seq < b.seq
+}
+func (h *delayedHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *delayedHeap) Push(x any)    { h.items = append(h.items, x.(*queuedTask)) }
+func (h *delayedHeap) Pop() any {
+	n := len(h.items)
+	it := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	return it
+}
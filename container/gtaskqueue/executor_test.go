@@ -0,0 +1,143 @@
+package gtaskqueue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Retry(t *testing.T) {
+	e, err := NewExecutor(ExecutorConfig{
+		Workers: 2,
+		Retry:   RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	})
+	gtest.Assert(t, err)
+
+	var flakyCalls, badCalls int32
+	done := make(chan struct{})
+	e.Register("flaky", func(ctx context.Context, task *Task) error {
+		if atomic.AddInt32(&flakyCalls, 1) < 3 {
+			return errors.New("try again")
+		}
+		close(done)
+		return nil
+	})
+	e.Register("bad", func(ctx context.Context, task *Task) error {
+		atomic.AddInt32(&badCalls, 1)
+		panic("boom")
+	})
+	dead := make(chan *Task, 2)
+	e.cfg.OnDead = func(task *Task) { dead <- task }
+	e.Start()
+
+	gtest.Assert(t, e.Submit(NewTask("flaky", PriorityMedium, nil)))
+	gtest.Assert(t, e.Submit(NewTask("bad", PriorityMedium, nil)))
+	gtest.Assert(t, e.Submit(NewTask("unknown", PriorityMedium, nil)))
+	<-done
+	for i := 0; i < 2; i++ {
+		select {
+		case <-dead:
+		case <-time.After(time.Second):
+			t.Fatal("dead letter not reported")
+		}
+	}
+	gtest.Assert(t, e.Stop(context.Background()))
+
+	gtest.AssertTrue(t, atomic.LoadInt32(&badCalls) == 3, "bad should be tried 3 times but got %d", badCalls)
+	gtest.AssertTrue(t, len(e.DeadLetters()) == 2, "should be 2 dead letters but got %d", len(e.DeadLetters()))
+	st := e.GetStatistic()
+	gtest.AssertTrue(t, st.Types["flaky"].Success == 1 && st.Types["flaky"].Retried == 2, "flaky statistic %+v", *st.Types["flaky"])
+	gtest.AssertTrue(t, st.Types["bad"].Dead == 1 && st.Types["bad"].ExecError == 3, "bad statistic %+v", *st.Types["bad"])
+	gtest.AssertTrue(t, st.Types["unknown"].Dead == 1, "unknown statistic %+v", *st.Types["unknown"])
+}
+
+func TestExecutor_TimeoutAndDelay(t *testing.T) {
+	e, err := NewExecutor(ExecutorConfig{Workers: 1, DefaultExecTimeout: 10 * time.Millisecond})
+	gtest.Assert(t, err)
+	result := make(chan error, 1)
+	e.Register("slow", func(ctx context.Context, task *Task) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	e.cfg.OnDead = func(task *Task) { result <- task.LastError }
+	var ranAt atomic.Value
+	e.Register("later", func(ctx context.Context, task *Task) error {
+		ranAt.Store(time.Now())
+		return nil
+	})
+	e.Start()
+
+	submitAt := time.Now()
+	gtest.Assert(t, e.SubmitAfter(NewTask("later", PriorityLow, nil), 50*time.Millisecond))
+	gtest.Assert(t, e.Submit(NewTask("slow", PriorityHigh, nil)))
+	gtest.AssertTrue(t, <-result == ErrorExecTimeout, "slow task should time out")
+	time.Sleep(100 * time.Millisecond)
+	gtest.Assert(t, e.Stop(context.Background()))
+
+	v := ranAt.Load()
+	gtest.AssertTrue(t, v != nil, "delayed task didn't run")
+	gtest.AssertTrue(t, v.(time.Time).Sub(submitAt) >= 50*time.Millisecond, "delayed task ran too early")
+	gtest.AssertTrue(t, e.GetStatistic().Types["slow"].ExecTimeout == 1, "timeout should be counted")
+}
+
+func TestExecutor_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	e, err := NewExecutor(ExecutorConfig{Workers: 1, PersistPath: path})
+	gtest.Assert(t, err)
+	for _, p := range []string{"a", "b", "c"} {
+		gtest.Assert(t, e.Submit(NewTask("echo", PriorityMedium, []byte(p))))
+	}
+	// Never started, so all tasks are pending when process "exits".
+	gtest.Assert(t, e.Stop(context.Background()))
+
+	e, err = NewExecutor(ExecutorConfig{Workers: 1, PersistPath: path})
+	gtest.Assert(t, err)
+	got := make(chan string, 3)
+	e.Register("echo", func(ctx context.Context, task *Task) error {
+		got <- string(task.Payload)
+		return nil
+	})
+	e.Start()
+	s := ""
+	for i := 0; i < 3; i++ {
+		s += <-got
+	}
+	gtest.Assert(t, e.Stop(context.Background()))
+	gtest.AssertTrue(t, s == "abc", "should resume tasks abc in order but got %s", s)
+
+	e, err = NewExecutor(ExecutorConfig{Workers: 1, PersistPath: path})
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, e.GetStatistic().Now2doSize == 0, "finished tasks should not be restored")
+	gtest.Assert(t, e.Stop(context.Background()))
+}
+
+func TestJournal_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	add := func(id string) string {
+		return `{"op":"add","task":{"id":"` + id + `","type":"echo"}}` + "\n"
+	}
+
+	// torn tail is dropped
+	path := filepath.Join(dir, "torn.journal")
+	gtest.Assert(t, os.WriteFile(path, []byte(add("a")+add("b")+`{"op":"add","ta`), 0644))
+	j, tasks, err := openJournal(path)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, len(tasks) == 2 && tasks[1].Id == "b", "got %d tasks", len(tasks))
+	gtest.Assert(t, j.close())
+
+	// corrupted middle line keeps the file untouched
+	path = filepath.Join(dir, "middle.journal")
+	data := []byte(add("a") + "garbage\n" + add("b"))
+	gtest.Assert(t, os.WriteFile(path, data, 0644))
+	_, _, err = openJournal(path)
+	gtest.AssertTrue(t, err != nil, "corrupted middle line should fail")
+	b, err := os.ReadFile(path)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, bytes.Equal(b, data), "journal should not be compacted")
+}
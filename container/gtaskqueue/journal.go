@@ -0,0 +1,209 @@
+package gtaskqueue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/sys/gfs"
+	"os"
+	"time"
+)
+
+type (
+	// journal persists pending tasks of Executor as JSON lines, a task is added by an "add" record
+	// and removed by a "done" record, the last "add" of an Id wins.
+	journal struct {
+		path  string
+		f     *os.File
+		live  map[string]*persistTask
+		order []string // Ids in first added order, may contain removed Ids
+		dones int      // done records since last compaction
+	}
+
+	journalRecord struct {
+		Op   string       `json:"op"`
+		Id   string       `json:"id,omitempty"`
+		Task *persistTask `json:"task,omitempty"`
+	}
+
+	// persistTask is the persisted part of Task, DataPtr and LastError are not persisted.
+	persistTask struct {
+		Id            string        `json:"id"`
+		Type          string        `json:"type"`
+		PriorityValue Priority      `json:"priority"`
+		ExecTimeout   time.Duration `json:"exec_timeout"`
+		Payload       []byte        `json:"payload,omitempty"`
+		RunAt         time.Time     `json:"run_at"`
+		CreateTime    time.Time     `json:"create_time"`
+		Attempt       int           `json:"attempt"`
+	}
+)
+
+const (
+	journalOpAdd  = "add"
+	journalOpDone = "done"
+
+	journalCompactMinDones = 1024
+)
+
+func toPersistTask(t *Task) *persistTask {
+	return &persistTask{
+		Id:            t.Id,
+		Type:          t.Type,
+		PriorityValue: t.PriorityValue,
+		ExecTimeout:   t.ExecTimeout,
+		Payload:       t.Payload,
+		RunAt:         t.RunAt,
+		CreateTime:    t.CreateTime,
+		Attempt:       t.Attempt,
+	}
+}
+
+func (pt *persistTask) toTask() *Task {
+	return &Task{
+		Id:            pt.Id,
+		Type:          pt.Type,
+		PriorityValue: pt.PriorityValue,
+		ExecTimeout:   pt.ExecTimeout,
+		Payload:       pt.Payload,
+		RunAt:         pt.RunAt,
+		CreateTime:    pt.CreateTime,
+		Attempt:       pt.Attempt,
+	}
+}
+
+// openJournal replays journal at path and returns pending tasks in submit order.
+func openJournal(path string) (*journal, []*Task, error) {
+	j := &journal{path: path, live: make(map[string]*persistTask)}
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	if err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		line, broken := 0, 0
+		for sc.Scan() {
+			line++
+			if broken > 0 {
+				// compaction would drop the records after the broken line
+				f.Close()
+				return nil, nil, gerrors.New("journal %s is corrupted at line %d", path, broken)
+			}
+			rec := journalRecord{}
+			// A broken last line is the tail torn by a crash, it is dropped.
+			if json.Unmarshal(sc.Bytes(), &rec) != nil {
+				broken = line
+				continue
+			}
+			j.apply(&rec)
+		}
+		f.Close()
+		if err := sc.Err(); err != nil {
+			return nil, nil, gerrors.Wrap(err, "read journal "+path)
+		}
+	}
+
+	if err := j.compact(); err != nil {
+		return nil, nil, err
+	}
+	var tasks []*Task
+	for _, id := range j.order {
+		tasks = append(tasks, j.live[id].toTask())
+	}
+	return j, tasks, nil
+}
+
+func (j *journal) apply(rec *journalRecord) {
+	switch rec.Op {
+	case journalOpAdd:
+		if rec.Task == nil {
+			return
+		}
+		if _, ok := j.live[rec.Task.Id]; !ok {
+			j.order = append(j.order, rec.Task.Id)
+		}
+		j.live[rec.Task.Id] = rec.Task
+	case journalOpDone:
+		if _, ok := j.live[rec.Id]; ok {
+			delete(j.live, rec.Id)
+			j.dones++
+		}
+	}
+}
+
+func (j *journal) write(rec *journalRecord) error {
+	if j.f == nil {
+		return ErrExecutorStopped
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := j.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	j.apply(rec)
+	return nil
+}
+
+func (j *journal) add(t *Task) error {
+	return j.write(&journalRecord{Op: journalOpAdd, Task: toPersistTask(t)})
+}
+
+func (j *journal) done(id string) error {
+	if err := j.write(&journalRecord{Op: journalOpDone, Id: id}); err != nil {
+		return err
+	}
+	if j.dones >= journalCompactMinDones && j.dones >= len(j.live) {
+		return j.compact()
+	}
+	return nil
+}
+
+// compact rewrites journal with live tasks only and atomically replaces the old file.
+func (j *journal) compact() error {
+	var order []string
+	var buf bytes.Buffer
+	for _, id := range j.order {
+		pt, ok := j.live[id]
+		if !ok {
+			continue
+		}
+		order = append(order, id)
+		b, err := json.Marshal(&journalRecord{Op: journalOpAdd, Task: pt})
+		if err != nil {
+			return err
+		}
+		buf.Write(append(b, '\n'))
+	}
+	if err := gfs.BytesToFileAtomic(buf.Bytes(), j.path); err != nil {
+		return err
+	}
+
+	// keep appending to the new journal
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	if j.f != nil {
+		j.f.Close()
+	}
+	j.f = f
+	j.order = order
+	j.dones = 0
+	return nil
+}
+
+func (j *journal) close() error {
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Sync()
+	if e := j.f.Close(); err == nil {
+		err = e
+	}
+	j.f = nil
+	return err
+}
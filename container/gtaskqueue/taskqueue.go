@@ -3,6 +3,8 @@ package gtaskqueue
 // Task sender queue in memory with statistic function.
 
 import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/dsa/guuid"
 	"github.com/davidforest123/goutil/sys/gtime"
 	"sync"
 	"time"
@@ -28,17 +30,39 @@ var (
 
 type Task struct {
 	Id            string
+	Type          string // Executor dispatches task to the handler registered with the same type.
 	PriorityValue Priority
 	ExecTimeout   time.Duration
 	DataPtr       interface{}
+	Payload       []byte    // Unlike DataPtr, Payload is persisted by Executor.
+	RunAt         time.Time // Executor doesn't run task before RunAt.
 
 	// Statistics.
 	CreateTime    time.Time
 	ExecBeginTime time.Time
 	ExecDoneTime  time.Time
+	Attempt       int
 	LastError     error
 }
 
+// Statistic of one task type.
+type TypeStatistic struct {
+	Pending int64
+	Running int64
+
+	Success     int64
+	ExecTimeout int64
+	ExecError   int64
+	Retried     int64
+	Dead        int64
+
+	AvgExecDuration time.Duration
+	MaxExecDuration time.Duration
+
+	execDurationSum time.Duration
+	execCount       int64
+}
+
 type Statistic struct {
 	// 此刻排队任务个数
 	Now2doSize int64
@@ -57,6 +81,9 @@ type Statistic struct {
 	LatelyExecErrorSize int64
 	// 近期执行平均耗时
 	LatelyAvgExecDuration time.Duration
+
+	// 按任务类型统计
+	Types map[string]*TypeStatistic
 }
 
 type TaskQueue struct {
@@ -64,6 +91,7 @@ type TaskQueue struct {
 	exec     map[string]*Task
 	execLock sync.RWMutex
 	done     chan *Task
+	closed   chan struct{}
 
 	tempDataLock sync.RWMutex
 	// 近期的开始时间
@@ -78,37 +106,60 @@ type TaskQueue struct {
 	tempLatelyExecDurationSum time.Duration
 	// 近期执行的任务的次数，包括执行超时的在内
 	tempLatelyExecCount int64
+	// 按任务类型的近期统计
+	tempLatelyTypes map[string]*TypeStatistic
 }
 
 func New(autoResetStatistic time.Duration, size int) *TaskQueue {
 	q := TaskQueue{}
 	q.exec = make(map[string]*Task)
+	q.done = make(chan *Task, size)
+	q.tempLatelyStatisticBeginTime = time.Now()
+	q.tempLatelyTypes = make(map[string]*TypeStatistic)
+	q.closed = make(chan struct{})
 	for i := 0; i < PriorityCount; i++ {
 		q.to2Do[Priority(i)] = make(chan *Task, size)
 	}
 
 	// Auto reset lately statistic data.
 	go func() {
-		time.Sleep(autoResetStatistic)
-		q.tempDataLock.Lock()
-		q.tempLatelyStatisticBeginTime = time.Now()
-		q.tempLatelySuccessSize = 0
-		q.tempLatelyExecTimeoutSize = 0
-		q.tempLatelyExecErrorSize = 0
-		q.tempDataLock.Unlock()
+		for {
+			select {
+			case <-q.closed:
+				return
+			case <-time.After(autoResetStatistic):
+			}
+			q.tempDataLock.Lock()
+			q.tempLatelyStatisticBeginTime = time.Now()
+			q.tempLatelySuccessSize = 0
+			q.tempLatelyExecTimeoutSize = 0
+			q.tempLatelyExecErrorSize = 0
+			q.tempLatelyExecDurationSum = time.Duration(0)
+			q.tempLatelyExecCount = 0
+			q.tempLatelyTypes = make(map[string]*TypeStatistic)
+			q.tempDataLock.Unlock()
+		}
 	}()
 
 	// Every 2 seconds, auto check whether task timeout when executing.
 	go func() {
-		time.Sleep(time.Second * 2)
-
-		q.execLock.RLock()
-		execClone := q.exec
-		q.execLock.RUnlock()
-		for k := range execClone {
-			item := execClone[k]
-			if time.Now().Sub(item.ExecBeginTime) > item.ExecTimeout {
-				q.PushDone(item.Id, ErrorExecTimeout)
+		for {
+			select {
+			case <-q.closed:
+				return
+			case <-time.After(time.Second * 2):
+			}
+
+			var timeoutIds []string
+			q.execLock.RLock()
+			for _, item := range q.exec {
+				if item.ExecTimeout > 0 && time.Now().Sub(item.ExecBeginTime) > item.ExecTimeout {
+					timeoutIds = append(timeoutIds, item.Id)
+				}
+			}
+			q.execLock.RUnlock()
+			for _, id := range timeoutIds {
+				q.PushDone(id, ErrorExecTimeout)
 			}
 		}
 	}()
@@ -124,7 +175,13 @@ func (q *TaskQueue) GetStatistic() *Statistic {
 	res.LatelySuccessSize = q.tempLatelySuccessSize
 	res.LatelyExecTimeoutSize = q.tempLatelyExecTimeoutSize
 	res.LatelyExecErrorSize = q.tempLatelyExecErrorSize
-	res.LatelyAvgExecDuration = gtime.NsecToDuration(q.tempLatelyExecDurationSum.Nanoseconds() / q.tempLatelyExecCount)
+	if q.tempLatelyExecCount > 0 {
+		res.LatelyAvgExecDuration = gtime.NsecToDuration(q.tempLatelyExecDurationSum.Nanoseconds() / q.tempLatelyExecCount)
+	}
+	res.Types = make(map[string]*TypeStatistic)
+	for k, v := range q.tempLatelyTypes {
+		res.typeStatistic(k).merge(v)
+	}
 	q.tempDataLock.RUnlock()
 
 	for i := 0; i < PriorityCount; i++ {
@@ -132,6 +189,9 @@ func (q *TaskQueue) GetStatistic() *Statistic {
 	}
 	q.execLock.RLock()
 	res.NowExecSize += int64(len(q.exec))
+	for _, item := range q.exec {
+		res.typeStatistic(item.Type).Running++
+	}
 	q.execLock.RUnlock()
 
 	return &res
@@ -140,6 +200,7 @@ func (q *TaskQueue) GetStatistic() *Statistic {
 // Wait until pushed.
 func (q *TaskQueue) Push2doWait(priority Priority /*waitTimeout, */, execTimeout time.Duration, dataPtr interface{}) {
 	item := Task{}
+	item.Id = newTaskId()
 	item.PriorityValue = priority
 	item.ExecTimeout = execTimeout
 	item.DataPtr = dataPtr
@@ -164,6 +225,8 @@ func (q *TaskQueue) Pop2do() (*Task, error) {
 			}
 
 			// Push into exec map.
+			item.ExecBeginTime = time.Now()
+			item.Attempt++
 			q.execLock.Lock()
 			q.exec[item.Id] = item
 			q.execLock.Unlock()
@@ -199,6 +262,12 @@ func (q *TaskQueue) PushDone(id string, err error) {
 	} else {
 		q.tempLatelyExecErrorSize++
 	}
+	ts := q.tempLatelyTypes[item.Type]
+	if ts == nil {
+		ts = &TypeStatistic{}
+		q.tempLatelyTypes[item.Type] = ts
+	}
+	ts.addExec(item.ExecDoneTime.Sub(item.ExecBeginTime), err)
 	q.tempDataLock.Unlock()
 
 	// Push into done channel.
@@ -215,6 +284,7 @@ func (q *TaskQueue) PopDone() (*Task, error) {
 
 // Reset taskQueue.
 func (q *TaskQueue) Close() {
+	close(q.closed)
 	for i := 0; i < PriorityCount; i++ {
 		close(q.to2Do[i])
 	}
@@ -234,5 +304,53 @@ func (q *TaskQueue) Close() {
 	q.tempLatelyExecErrorSize = 0
 	q.tempLatelyExecDurationSum = time.Duration(0)
 	q.tempLatelyExecCount = 0
+	q.tempLatelyTypes = make(map[string]*TypeStatistic)
 	q.tempDataLock.Unlock()
 }
+
+func newTaskId() string {
+	return guuid.NewString(false, false)
+}
+
+func (s *Statistic) typeStatistic(taskType string) *TypeStatistic {
+	ts := s.Types[taskType]
+	if ts == nil {
+		ts = &TypeStatistic{}
+		s.Types[taskType] = ts
+	}
+	return ts
+}
+
+func (ts *TypeStatistic) addExec(d time.Duration, err error) {
+	ts.execDurationSum += d
+	ts.execCount++
+	ts.AvgExecDuration = ts.execDurationSum / time.Duration(ts.execCount)
+	if d > ts.MaxExecDuration {
+		ts.MaxExecDuration = d
+	}
+	if err == nil {
+		ts.Success++
+	} else if err == ErrorExecTimeout {
+		ts.ExecTimeout++
+	} else {
+		ts.ExecError++
+	}
+}
+
+func (ts *TypeStatistic) merge(other *TypeStatistic) {
+	ts.Pending += other.Pending
+	ts.Running += other.Running
+	ts.Success += other.Success
+	ts.ExecTimeout += other.ExecTimeout
+	ts.ExecError += other.ExecError
+	ts.Retried += other.Retried
+	ts.Dead += other.Dead
+	ts.execDurationSum += other.execDurationSum
+	ts.execCount += other.execCount
+	if ts.execCount > 0 {
+		ts.AvgExecDuration = ts.execDurationSum / time.Duration(ts.execCount)
+	}
+	if other.MaxExecDuration > ts.MaxExecDuration {
+		ts.MaxExecDuration = other.MaxExecDuration
+	}
+}
@@ -0,0 +1,77 @@
+package gtimeseries
+
+import (
+	"math"
+)
+
+// ACF returns autocorrelation of lags 0..nlags, ACF[0] is always 1.
+// Autocovariances are divided by n like statsmodels acf(adjusted=False).
+func ACF(values []float64, nlags int) []float64 {
+	n := len(values)
+	if nlags >= n {
+		nlags = n - 1
+	}
+	if nlags < 0 {
+		return nil
+	}
+	m := mean(values)
+	acov := make([]float64, nlags+1)
+	for k := range acov {
+		for t := 0; t+k < n; t++ {
+			acov[k] += (values[t] - m) * (values[t+k] - m)
+		}
+		acov[k] /= float64(n)
+	}
+	out := make([]float64, nlags+1)
+	for k := range out {
+		if acov[0] == 0 {
+			out[k] = math.NaN()
+			continue
+		}
+		out[k] = acov[k] / acov[0]
+	}
+	return out
+}
+
+// PACF returns partial autocorrelation of lags 0..nlags by Durbin-Levinson recursion on ACF,
+// which equals statsmodels pacf(method="ldb"), PACF[0] is always 1.
+func PACF(values []float64, nlags int) []float64 {
+	r := ACF(values, nlags)
+	if len(r) == 0 {
+		return nil
+	}
+	out := make([]float64, len(r))
+	out[0] = 1
+	phi := make([]float64, len(r)) // phi[j] of previous order
+	for k := 1; k < len(r); k++ {
+		num, den := r[k], 1.0
+		for j := 1; j < k; j++ {
+			num -= phi[j] * r[k-j]
+			den -= phi[j] * r[j]
+		}
+		pk := num / den
+		next := make([]float64, len(r))
+		for j := 1; j < k; j++ {
+			next[j] = phi[j] - pk*phi[k-j]
+		}
+		next[k] = pk
+		phi = next
+		out[k] = pk
+	}
+	return out
+}
+
+// ConfidenceBand returns the half width of approximate 95% confidence band of ACF/PACF under white noise.
+func ConfidenceBand(n int) float64 {
+	return 1.959963984540054 / math.Sqrt(float64(n))
+}
+
+// CloseValues returns close prices of ts, helpers in this package which accept []float64 can
+// be used on any TimeSeries with it.
+func CloseValues(ts TimeSeries) []float64 {
+	out := make([]float64, ts.Len())
+	for i := range out {
+		out[i] = ts.Close(i)
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package gtimeseries
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"sort"
+	"time"
+)
+
+// AlignMode decides the common time axis of aligned series.
+type AlignMode int
+
+const (
+	// AlignOuter uses the union of all times, missing bars are filled.
+	AlignOuter AlignMode = iota
+	// AlignInner uses times present in every series.
+	AlignInner
+)
+
+// commonAxis returns the sorted union or intersection of axes.
+func commonAxis(mode AlignMode, axes [][]time.Time) []time.Time {
+	count := make(map[int64]int)
+	first := make(map[int64]time.Time)
+	for _, axis := range axes {
+		seen := make(map[int64]bool, len(axis))
+		for _, t := range axis {
+			k := t.UnixNano()
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			count[k]++
+			if _, ok := first[k]; !ok {
+				first[k] = t
+			}
+		}
+	}
+	var out []time.Time
+	for k, n := range count {
+		if mode == AlignOuter || n == len(axes) {
+			out = append(out, first[k])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+// Align puts all series on a common time axis, missing bars of AlignOuter are filled by method,
+// leading missing bars which have no previous bar are always NaN.
+func Align(mode AlignMode, method FillMethod, series ...*Series) []*Series {
+	axes := make([][]time.Time, len(series))
+	for i, s := range series {
+		axes[i] = s.times
+	}
+	axis := commonAxis(mode, axes)
+
+	out := make([]*Series, len(series))
+	for i, s := range series {
+		o := NewSeries(len(axis))
+		j := 0 // index into s
+		for _, t := range axis {
+			for j < s.Len() && s.times[j].Before(t) {
+				j++
+			}
+			if j < s.Len() && s.times[j].Equal(t) {
+				_ = o.Append(s.Bar(j))
+				continue
+			}
+			if j == 0 || (method == FillLinear && j == s.Len()) {
+				_ = o.Append(fillBar(t, Bar{}, Bar{}, 0, FillNaN))
+				continue
+			}
+			prev := s.Bar(j - 1)
+			var next Bar
+			ratio := 0.0
+			if j < s.Len() {
+				next = s.Bar(j)
+				ratio = float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+			}
+			_ = o.Append(fillBar(t, prev, next, ratio, method))
+		}
+		out[i] = o
+	}
+	return out
+}
+
+// AlignFloats is the plain value version of Align, which generalises ghtml.XAxisSync.
+// times[i] and values[i] are one line, the result has one value slice per line on the common axis,
+// missing values are NaN for FillNaN, previous value for FillForward and interpolated for FillLinear.
+func AlignFloats(mode AlignMode, method FillMethod, times [][]time.Time, values [][]float64) ([]time.Time, [][]float64, error) {
+	if len(times) != len(values) {
+		return nil, nil, gerrors.Errorf("times count %d != values count %d", len(times), len(values))
+	}
+	series := make([]*Series, len(times))
+	for i := range times {
+		if len(times[i]) != len(values[i]) {
+			return nil, nil, gerrors.Errorf("line %d time len %d != values len %d", i, len(times[i]), len(values[i]))
+		}
+		bars := make([]Bar, len(times[i]))
+		for j := range bars {
+			v := values[i][j]
+			bars[j] = Bar{Time: times[i][j], Open: v, High: v, Low: v, Close: v}
+		}
+		s, err := NewSeriesFromBars(bars)
+		if err != nil {
+			return nil, nil, gerrors.Errorf("line %d: %s", i, err.Error())
+		}
+		series[i] = s
+	}
+
+	aligned := Align(mode, method, series...)
+	out := make([][]float64, len(aligned))
+	var axis []time.Time
+	for i, s := range aligned {
+		out[i] = s.Closes()
+		axis = s.Times()
+	}
+	return axis, out, nil
+}
@@ -0,0 +1,176 @@
+package gtimeseries
+
+import (
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var refValues = []float64{3.1, 2.4, 4.0, 3.3, 2.9, 3.8, 4.4, 3.0, 2.2, 3.5, 4.1, 3.9, 2.8, 3.3, 3.6, 2.7, 3.0, 4.2, 3.4, 2.9, 3.7, 3.2, 2.6, 3.9, 3.1}
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestADF(t *testing.T) {
+	// reference statistics are computed by exact rational least squares
+	cases := []struct {
+		lags       int
+		regression Regression
+		statistic  float64
+		nobs       int
+	}{
+		{1, RegressionConstant, -7.76578307385723, 23},
+		{0, RegressionTrend, -5.1137717536997, 24},
+		{2, RegressionNone, -0.39473591845883543, 22},
+	}
+	for _, c := range cases {
+		res, err := ADF(refValues, c.lags, c.regression)
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, near(res.Statistic, c.statistic, 1e-9) && res.NObs == c.nobs, "ADF %+v got %+v", c, res)
+	}
+	// too short for the lags, x would be empty
+	for _, values := range [][]float64{nil, {1}, {1, 2, 3}, refValues[:5]} {
+		_, err := ADF(values, 2, RegressionConstant)
+		gtest.AssertTrue(t, err != nil, "ADF of %d values should fail", len(values))
+		_, err = ADF(values, -1, RegressionTrend)
+		gtest.AssertTrue(t, err != nil, "ADF of %d values should fail", len(values))
+	}
+
+	// MacKinnon critical values for 100 observations with constant
+	c1, c5, c10 := adfCriticalValues(RegressionConstant, 100)
+	gtest.AssertTrue(t, near(c1, -3.4975, 1e-4) && near(c5, -2.8909, 1e-4) && near(c10, -2.5824, 1e-4), "critical values %f %f %f", c1, c5, c10)
+
+	rnd := rand.New(rand.NewSource(1))
+	noise := make([]float64, 500)
+	walk := make([]float64, 500)
+	for i := range noise {
+		noise[i] = rnd.NormFloat64()
+		if i > 0 {
+			walk[i] = walk[i-1] + noise[i]
+		}
+	}
+	res, err := ADF(noise, -1, RegressionConstant)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, res.Stationary(), "white noise should be stationary, %+v", res)
+	res, err = ADF(walk, -1, RegressionConstant)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, !res.Stationary(), "random walk should not be stationary, %+v", res)
+
+	k, err := KPSS(noise, -1, RegressionConstant)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, k.Stationary() && k.PValue == 0.1, "white noise should be stationary, %+v", k)
+	k, err = KPSS(walk, -1, RegressionConstant)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, !k.Stationary() && k.PValue == 0.01, "random walk should not be stationary, %+v", k)
+}
+
+func TestKPSS(t *testing.T) {
+	res, err := KPSS(refValues, 2, RegressionConstant)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, near(res.Statistic, 0.06885489696197153, 1e-9), "KPSS got %+v", res)
+	_, err = KPSS(refValues, 2, RegressionNone)
+	gtest.AssertTrue(t, err != nil, "KPSS without constant should fail")
+}
+
+func TestACF(t *testing.T) {
+	acf := ACF([]float64{1, 2, 3, 4, 5}, 4)
+	want := []float64{1, 0.4, -0.1, -0.4, -0.4}
+	for i := range want {
+		gtest.AssertTrue(t, near(acf[i], want[i], 1e-12), "acf got %v", acf)
+	}
+	pacf := PACF([]float64{1, 2, 3, 4, 5}, 2)
+	gtest.AssertTrue(t, near(pacf[1], 0.4, 1e-12) && near(pacf[2], -0.26/0.84, 1e-12), "pacf got %v", pacf)
+
+	// AR(1) with phi 0.7 has pacf cut off after lag 1
+	rnd := rand.New(rand.NewSource(2))
+	ar := make([]float64, 5000)
+	for i := 1; i < len(ar); i++ {
+		ar[i] = 0.7*ar[i-1] + rnd.NormFloat64()
+	}
+	pacf = PACF(ar, 3)
+	band := ConfidenceBand(len(ar))
+	gtest.AssertTrue(t, near(pacf[1], 0.7, 0.03) && math.Abs(pacf[2]) < band && math.Abs(pacf[3]) < band, "AR(1) pacf got %v", pacf)
+}
+
+func TestSTL(t *testing.T) {
+	period := 12
+	seasonal := func(i int) float64 { return 10 * math.Sin(2*math.Pi*float64(i)/float64(period)) }
+	values := make([]float64, 10*period)
+	for i := range values {
+		values[i] = 0.5*float64(i) + seasonal(i)
+	}
+
+	// linear trend and pure seasonal are recovered exactly
+	d, err := STL(values, STLOptions{Period: period})
+	gtest.Assert(t, err)
+	for i := range values {
+		gtest.AssertTrue(t, near(d.Seasonal[i], seasonal(i), 1e-6) && near(d.Trend[i], 0.5*float64(i), 1e-6), "STL %d got %f %f", i, d.Trend[i], d.Seasonal[i])
+	}
+
+	// robust STL leaves outlier in residual
+	values[50] += 40
+	d, err = STL(values, STLOptions{Period: period, Robust: true})
+	gtest.Assert(t, err)
+	maxErr := 0.0
+	for i := range values {
+		gtest.AssertTrue(t, near(d.Trend[i]+d.Seasonal[i]+d.Residual[i], values[i], 1e-9), "components should sum to values")
+		if i != 50 {
+			maxErr = math.Max(maxErr, math.Abs(d.Seasonal[i]-seasonal(i)))
+		}
+	}
+	gtest.AssertTrue(t, maxErr < 1 && d.Residual[50] > 35, "robust STL seasonal error %f, outlier residual %f", maxErr, d.Residual[50])
+
+	_, err = STL(values[:20], STLOptions{Period: period})
+	gtest.AssertTrue(t, err != nil, "too short series should fail")
+}
+
+func TestDetectors(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	values := make([]float64, 300)
+	for i := range values {
+		values[i] = 100 + rnd.NormFloat64()
+	}
+	values[200] = 120
+	detectors := map[string]Detector{
+		"zscore": NewZScoreDetector(50, 6),
+		"ewma":   NewEWMADetector(0.05, 6, 30),
+		"mad":    NewMADDetector(50, 6),
+	}
+	for name, d := range detectors {
+		got := DetectAnomalies(d, values)
+		gtest.AssertTrue(t, len(got) == 1 && got[0] == 200, "%s detected %v", name, got)
+	}
+
+	d := NewMADDetector(5, 3.5)
+	for _, v := range []float64{1, 2, 3, 4, 5} {
+		score, _ := d.Update(v)
+		gtest.AssertTrue(t, math.IsNaN(score), "score should be NaN in warmup")
+	}
+	// median 3, MAD 1
+	score, anomaly := d.Update(9)
+	gtest.AssertTrue(t, near(score, 0.6745*6, 1e-12) && anomaly, "mad score got %f", score)
+
+	// NaN gaps from FillNaN are skipped, other values score as if the gaps were not there
+	gappy := []float64{1, math.NaN(), 2, 3, math.NaN(), 2, 4, 3, math.NaN(), 2, 9}
+	for name, pair := range map[string][2]Detector{
+		"zscore": {NewZScoreDetector(3, 3.5), NewZScoreDetector(3, 3.5)},
+		"ewma":   {NewEWMADetector(0.3, 3.5, 3), NewEWMADetector(0.3, 3.5, 3)},
+		"mad":    {NewMADDetector(3, 3.5), NewMADDetector(3, 3.5)},
+	} {
+		var gapScores, cleanScores []float64
+		for i, v := range gappy {
+			score, anomaly := pair[0].Update(v)
+			if math.IsNaN(v) {
+				gtest.AssertTrue(t, score == 0 && !anomaly, "%s NaN at %d scored %f", name, i, score)
+				continue
+			}
+			gapScores = append(gapScores, score)
+			score, _ = pair[1].Update(v)
+			cleanScores = append(cleanScores, score)
+		}
+		gtest.AssertTrue(t, fmt.Sprint(gapScores) == fmt.Sprint(cleanScores), "%s scored %v, without gaps %v", name, gapScores, cleanScores)
+	}
+}
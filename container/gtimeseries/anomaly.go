@@ -0,0 +1,156 @@
+package gtimeseries
+
+import (
+	"math"
+	"sort"
+)
+
+type (
+	// Detector is a streaming anomaly detector, Update scores v against values seen before it,
+	// then learns v. Scores are NaN during warmup.
+	Detector interface {
+		Update(v float64) (score float64, anomaly bool)
+	}
+
+	// ZScoreDetector scores values by standard score in a rolling window.
+	ZScoreDetector struct {
+		window    *Rolling
+		threshold float64
+	}
+
+	// EWMADetector scores values by exponentially weighted mean and variance, it adapts to level shifts
+	// and needs no window memory.
+	EWMADetector struct {
+		alpha     float64
+		threshold float64
+		warmup    int
+		count     int
+		mean      float64
+		variance  float64
+	}
+
+	// MADDetector scores values by modified z-score 0.6745*(v-median)/MAD in a rolling window,
+	// it is robust to outliers inside the window.
+	MADDetector struct {
+		window    []float64 // ring buffer
+		sorted    []float64
+		head      int
+		threshold float64
+	}
+)
+
+// NewZScoreDetector creates a detector of window n, v is an anomaly if |z| > threshold.
+func NewZScoreDetector(n int, threshold float64) *ZScoreDetector {
+	return &ZScoreDetector{window: NewRolling(n), threshold: threshold}
+}
+
+// Update skips NaN values like gaps filled by FillNaN, they score 0 and are not pushed into the window.
+func (d *ZScoreDetector) Update(v float64) (float64, bool) {
+	if math.IsNaN(v) {
+		return 0, false
+	}
+	score := math.NaN()
+	if d.window.Full() {
+		std := d.window.Std(1)
+		score = (v - d.window.Mean()) / std
+		if std == 0 {
+			score = 0
+			if v != d.window.Mean() {
+				score = math.Copysign(math.Inf(1), v-d.window.Mean())
+			}
+		}
+	}
+	d.window.Push(v)
+	return score, math.Abs(score) > d.threshold
+}
+
+// NewEWMADetector creates a detector with smoothing factor alpha in (0, 1], scores of the first
+// warmup values are NaN.
+func NewEWMADetector(alpha, threshold float64, warmup int) *EWMADetector {
+	return &EWMADetector{alpha: alpha, threshold: threshold, warmup: warmup}
+}
+
+// Update skips NaN values, they score 0 and don't move mean or variance.
+func (d *EWMADetector) Update(v float64) (float64, bool) {
+	if math.IsNaN(v) {
+		return 0, false
+	}
+	score := math.NaN()
+	if d.count >= d.warmup && d.count > 1 {
+		std := math.Sqrt(d.variance)
+		score = (v - d.mean) / std
+		if std == 0 {
+			score = 0
+			if v != d.mean {
+				score = math.Copysign(math.Inf(1), v-d.mean)
+			}
+		}
+	}
+	if d.count == 0 {
+		d.mean = v
+	} else {
+		diff := v - d.mean
+		incr := d.alpha * diff
+		d.mean += incr
+		d.variance = (1 - d.alpha) * (d.variance + diff*incr)
+	}
+	d.count++
+	return score, math.Abs(score) > d.threshold
+}
+
+// NewMADDetector creates a detector of window n, 3.5 is the commonly used threshold.
+func NewMADDetector(n int, threshold float64) *MADDetector {
+	if n < 1 {
+		n = 1
+	}
+	return &MADDetector{window: make([]float64, 0, n), threshold: threshold}
+}
+
+// Update skips NaN values like gaps filled by FillNaN, they score 0 and are not learned.
+func (d *MADDetector) Update(v float64) (float64, bool) {
+	if math.IsNaN(v) {
+		// NaN can't be ordered in sorted window
+		return 0, false
+	}
+	score := math.NaN()
+	if len(d.window) == cap(d.window) {
+		med := median(d.sorted)
+		dev := make([]float64, len(d.sorted))
+		for i, x := range d.sorted {
+			dev[i] = math.Abs(x - med)
+		}
+		sort.Float64s(dev)
+		mad := median(dev)
+		score = 0.6745 * (v - med) / mad
+		if mad == 0 {
+			score = 0
+			if v != med {
+				score = math.Copysign(math.Inf(1), v-med)
+			}
+		}
+	}
+
+	if len(d.window) < cap(d.window) {
+		d.window = append(d.window, v)
+	} else {
+		old := d.window[d.head]
+		d.window[d.head] = v
+		d.head = (d.head + 1) % len(d.window)
+		i := sort.SearchFloat64s(d.sorted, old)
+		d.sorted = append(d.sorted[:i], d.sorted[i+1:]...)
+	}
+	i := sort.SearchFloat64s(d.sorted, v)
+	d.sorted = insertAt(d.sorted, i, v)
+	return score, math.Abs(score) > d.threshold
+}
+
+// DetectAnomalies feeds values to d and returns indexes of anomalies.
+func DetectAnomalies(d Detector, values []float64) []int {
+	var out []int
+	for i, v := range values {
+		if _, anomaly := d.Update(v); anomaly {
+			out = append(out, i)
+		}
+	}
+	return out
+}
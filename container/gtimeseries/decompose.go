@@ -0,0 +1,278 @@
+package gtimeseries
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+	"sort"
+)
+
+type (
+	// STLOptions configures STL decomposition, zero values use defaults of Cleveland et al. (1990).
+	STLOptions struct {
+		Period    int  // length of seasonal cycle, required, >= 2
+		Seasonal  int  // seasonal smoother span, odd and >= 3, default 7
+		Trend     int  // trend smoother span, odd, default the smallest odd >= 1.5*Period/(1-1.5/Seasonal)
+		LowPass   int  // low pass filter span, odd, default the smallest odd > Period
+		Robust    bool // downweight outliers with bisquare robustness weights
+		InnerIter int  // default 2, or 1 if Robust
+		OuterIter int  // default 0, or 15 if Robust
+	}
+
+	// Decomposition is an additive decomposition values = Trend + Seasonal + Residual.
+	Decomposition struct {
+		Trend    []float64
+		Seasonal []float64
+		Residual []float64
+	}
+)
+
+func nextOdd(x int) int {
+	if x%2 == 0 {
+		return x + 1
+	}
+	return x
+}
+
+// STL decomposes values by Seasonal-Trend decomposition using Loess (STL), local fits are of degree 1.
+func STL(values []float64, opts STLOptions) (*Decomposition, error) {
+	n := len(values)
+	np := opts.Period
+	if np < 2 {
+		return nil, gerrors.New("invalid STL period %d", np)
+	}
+	if n < 2*np {
+		return nil, gerrors.New("STL needs at least 2 periods but got %d values", n)
+	}
+	ns := opts.Seasonal
+	if ns == 0 {
+		ns = 7
+	}
+	if ns < 3 || ns%2 == 0 {
+		return nil, gerrors.New("STL seasonal span must be odd and >= 3")
+	}
+	nt := opts.Trend
+	if nt == 0 {
+		nt = nextOdd(int(math.Ceil(1.5 * float64(np) / (1 - 1.5/float64(ns)))))
+	}
+	nl := opts.LowPass
+	if nl == 0 {
+		nl = nextOdd(np + 1)
+	}
+	if nt%2 == 0 || nl%2 == 0 {
+		return nil, gerrors.New("STL trend and low pass spans must be odd")
+	}
+	inner, outer := opts.InnerIter, opts.OuterIter
+	if inner == 0 {
+		inner = 2
+		if opts.Robust {
+			inner = 1
+		}
+	}
+	if outer == 0 && opts.Robust {
+		outer = 15
+	}
+
+	trend := make([]float64, n)
+	seasonal := make([]float64, n)
+	rw := make([]float64, n)
+	for i := range rw {
+		rw[i] = 1
+	}
+	work := make([]float64, n)
+	for o := 0; o <= outer; o++ {
+		for it := 0; it < inner; it++ {
+			// detrend and smooth cycle-subseries, c has one extra cycle at each end
+			for i := range work {
+				work[i] = values[i] - trend[i]
+			}
+			c := cycleSubseries(work, rw, np, ns)
+			// low pass filter of c, which removes the trend part left in c
+			l := movingAverage(movingAverage(movingAverage(c, np), np), 3)
+			l = loessSmooth(l, nil, nl)
+			for i := range seasonal {
+				seasonal[i] = c[np+i] - l[i]
+			}
+			// deseasonalize and smooth trend
+			for i := range work {
+				work[i] = values[i] - seasonal[i]
+			}
+			trend = loessSmooth(work, rw, nt)
+		}
+		if o < outer {
+			for i := range work {
+				work[i] = values[i] - trend[i] - seasonal[i]
+			}
+			robustnessWeights(work, rw)
+		}
+	}
+
+	residual := make([]float64, n)
+	for i := range residual {
+		residual[i] = values[i] - trend[i] - seasonal[i]
+	}
+	return &Decomposition{Trend: trend, Seasonal: seasonal, Residual: residual}, nil
+}
+
+// cycleSubseries smooths every subseries of the same cycle position by loess, result is extended
+// by one cycle at both ends, its length is len(y)+2*np.
+func cycleSubseries(y, rw []float64, np, ns int) []float64 {
+	n := len(y)
+	c := make([]float64, n+2*np)
+	for j := 0; j < np; j++ {
+		k := (n-j-1)/np + 1
+		sy := make([]float64, k)
+		sw := make([]float64, k)
+		for m := 0; m < k; m++ {
+			sy[m] = y[j+m*np]
+			sw[m] = rw[j+m*np]
+		}
+		smooth := loessSmooth(sy, sw, ns)
+		for m := 0; m < k; m++ {
+			c[j+(m+1)*np] = smooth[m]
+		}
+		// extrapolate to position before the first and after the last point
+		if v, ok := loessAt(sy, sw, 0, ns); ok {
+			c[j] = v
+		} else {
+			c[j] = smooth[0]
+		}
+		if v, ok := loessAt(sy, sw, float64(k+1), ns); ok {
+			c[j+(k+1)*np] = v
+		} else {
+			c[j+(k+1)*np] = smooth[k-1]
+		}
+	}
+	return c
+}
+
+// movingAverage returns averages of every window of length w, result length is len(x)-w+1.
+func movingAverage(x []float64, w int) []float64 {
+	out := make([]float64, len(x)-w+1)
+	sum := 0.0
+	for i := 0; i < w; i++ {
+		sum += x[i]
+	}
+	out[0] = sum / float64(w)
+	for i := 1; i < len(out); i++ {
+		sum += x[i+w-1] - x[i-1]
+		out[i] = sum / float64(w)
+	}
+	return out
+}
+
+// loessSmooth evaluates loess of span q at every point of y, rw are robustness weights, nil means all 1.
+func loessSmooth(y, rw []float64, q int) []float64 {
+	out := make([]float64, len(y))
+	for i := range y {
+		if v, ok := loessAt(y, rw, float64(i+1), q); ok {
+			out[i] = v
+		} else {
+			out[i] = y[i]
+		}
+	}
+	return out
+}
+
+// loessAt estimates local linear fit of the q nearest neighbours at position x, positions of y are 1..len(y).
+// It reports false if all neighbour weights are zero.
+func loessAt(y, rw []float64, x float64, q int) (float64, bool) {
+	n := len(y)
+	left, right := 1, n
+	if q < n {
+		left = int(math.Floor(x)) - (q-1)/2
+		left = max(1, min(left, n-q+1))
+		for left > 1 && x-float64(left-1) < float64(left+q-1)-x {
+			left--
+		}
+		for left < n-q+1 && x-float64(left) > float64(left+q)-x {
+			left++
+		}
+		right = left + q - 1
+	}
+	h := math.Max(x-float64(left), float64(right)-x)
+	if q > n {
+		h += float64((q - n) / 2)
+	}
+
+	w := make([]float64, right-left+1)
+	total := 0.0
+	for j := left; j <= right; j++ {
+		r := math.Abs(float64(j) - x)
+		wj := 0.0
+		if r <= 0.999*h {
+			wj = 1
+			if r > 0.001*h {
+				u := r / h
+				wj = math.Pow(1-u*u*u, 3)
+			}
+			if rw != nil {
+				wj *= rw[j-1]
+			}
+		}
+		w[j-left] = wj
+		total += wj
+	}
+	if total <= 0 {
+		return 0, false
+	}
+	for i := range w {
+		w[i] /= total
+	}
+	if h > 0 {
+		a := 0.0
+		for j := left; j <= right; j++ {
+			a += w[j-left] * float64(j)
+		}
+		b := x - a
+		c := 0.0
+		for j := left; j <= right; j++ {
+			d := float64(j) - a
+			c += w[j-left] * d * d
+		}
+		if math.Sqrt(c) > 0.001*float64(n-1) {
+			b /= c
+			for j := left; j <= right; j++ {
+				w[j-left] *= b*(float64(j)-a) + 1
+			}
+		}
+	}
+	v := 0.0
+	for j := left; j <= right; j++ {
+		v += w[j-left] * y[j-1]
+	}
+	return v, true
+}
+
+// robustnessWeights sets bisquare weights of residuals r into rw.
+func robustnessWeights(r, rw []float64) {
+	abs := make([]float64, len(r))
+	for i, v := range r {
+		abs[i] = math.Abs(v)
+	}
+	sorted := append([]float64(nil), abs...)
+	sort.Float64s(sorted)
+	h := 6 * median(sorted)
+	for i, v := range abs {
+		switch {
+		case v <= 0.001*h:
+			rw[i] = 1
+		case v <= 0.999*h:
+			u := v / h
+			rw[i] = (1 - u*u) * (1 - u*u)
+		default:
+			rw[i] = 0
+		}
+	}
+}
+
+// median returns median of sorted values.
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return math.NaN()
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,88 @@
+package gtimeseries
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+	"time"
+)
+
+// FillMethod decides values of bars created for missing periods.
+type FillMethod int
+
+const (
+	// FillNaN fills all fields with NaN.
+	FillNaN FillMethod = iota
+	// FillForward creates flat bars at previous close with zero volume.
+	FillForward
+	// FillLinear creates flat bars at close price interpolated linearly between neighbours, volume is zero.
+	FillLinear
+)
+
+// Resample aggregates bars into coarser bars of period (e.g. 1m to 1h), buckets are aligned to
+// period boundaries counted from zero time in UTC, as time.Time.Truncate does.
+func (s *Series) Resample(period time.Duration) (*Series, error) {
+	if period <= 0 {
+		return nil, gerrors.New("invalid resample period %s", period)
+	}
+	return s.ResampleFunc(func(t time.Time) time.Time { return t.Truncate(period) })
+}
+
+// ResampleFunc is like Resample with custom bucket, bucketStart returns start time of the bucket of t
+// and must be monotonic, e.g. calendar days in a local timezone.
+// Open is the first open, High the max high, Low the min low, Close the last close and Volume the sum.
+func (s *Series) ResampleFunc(bucketStart func(t time.Time) time.Time) (*Series, error) {
+	out := NewSeries(0)
+	for i := range s.times {
+		start := bucketStart(s.times[i])
+		n := out.Len()
+		if n > 0 && out.times[n-1].Equal(start) {
+			out.high[n-1] = math.Max(out.high[n-1], s.high[i])
+			out.low[n-1] = math.Min(out.low[n-1], s.low[i])
+			out.close[n-1] = s.close[i]
+			out.volume[n-1] += s.volume[i]
+			continue
+		}
+		if err := out.Append(Bar{Time: start, Open: s.open[i], High: s.high[i], Low: s.low[i], Close: s.close[i], Volume: s.volume[i]}); err != nil {
+			return nil, gerrors.New("bucketStart is not monotonic at %s", s.times[i])
+		}
+	}
+	return out, nil
+}
+
+// FillGaps returns a copy of s where every missing period between the first and the last bar is
+// filled by method, bar times of s are expected to be multiples of period from the first bar.
+func (s *Series) FillGaps(period time.Duration, method FillMethod) (*Series, error) {
+	if period <= 0 {
+		return nil, gerrors.New("invalid fill period %s", period)
+	}
+	out := NewSeries(s.Len())
+	for i := range s.times {
+		if i > 0 {
+			prev := s.Bar(i - 1)
+			next := s.Bar(i)
+			gap := next.Time.Sub(prev.Time)
+			steps := int(gap / period)
+			for k := 1; k < steps; k++ {
+				t := prev.Time.Add(time.Duration(k) * period)
+				_ = out.Append(fillBar(t, prev, next, float64(t.Sub(prev.Time))/float64(gap), method))
+			}
+		}
+		_ = out.Append(s.Bar(i))
+	}
+	return out, nil
+}
+
+// fillBar creates a bar at t between prev and next, ratio is the position of t from prev to next.
+func fillBar(t time.Time, prev, next Bar, ratio float64, method FillMethod) Bar {
+	switch method {
+	case FillForward:
+		return Bar{Time: t, Open: prev.Close, High: prev.Close, Low: prev.Close, Close: prev.Close}
+	case FillLinear:
+		lerp := func(a, b float64) float64 { return a + (b-a)*ratio }
+		c := lerp(prev.Close, next.Close)
+		return Bar{Time: t, Open: c, High: c, Low: c, Close: c}
+	default:
+		nan := math.NaN()
+		return Bar{Time: t, Open: nan, High: nan, Low: nan, Close: nan, Volume: nan}
+	}
+}
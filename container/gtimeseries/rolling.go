@@ -0,0 +1,199 @@
+package gtimeseries
+
+import (
+	"math"
+)
+
+type (
+	// Rolling keeps statistics of the latest n values, every Push is amortized O(1).
+	Rolling struct {
+		window []float64 // ring buffer
+		head   int       // index of the oldest value
+		size   int
+		sum    float64
+		mean   float64 // Welford mean and sum of squared deviations, updated on push and pop
+		m2     float64
+		minQ   []int // indexes (monotonic sequence numbers) of candidate minimums, values increasing
+		maxQ   []int // indexes of candidate maximums, values decreasing
+		seq    int   // sequence number of next pushed value
+	}
+
+	// EMA is an exponential moving average, the first value is the SMA of the first n values.
+	EMA struct {
+		n     int
+		alpha float64
+		value float64
+		count int
+		sum   float64
+	}
+)
+
+func NewRolling(n int) *Rolling {
+	if n < 1 {
+		n = 1
+	}
+	return &Rolling{window: make([]float64, n)}
+}
+
+// Push adds v and drops the oldest value if window is full.
+func (r *Rolling) Push(v float64) {
+	n := len(r.window)
+	if r.size == n {
+		r.pop()
+	}
+	r.window[(r.head+r.size)%n] = v
+	r.size++
+	r.sum += v
+	delta := v - r.mean
+	r.mean += delta / float64(r.size)
+	r.m2 += delta * (v - r.mean)
+
+	for len(r.minQ) > 0 && r.valueOf(r.minQ[len(r.minQ)-1]) >= v {
+		r.minQ = r.minQ[:len(r.minQ)-1]
+	}
+	r.minQ = append(r.minQ, r.seq)
+	for len(r.maxQ) > 0 && r.valueOf(r.maxQ[len(r.maxQ)-1]) <= v {
+		r.maxQ = r.maxQ[:len(r.maxQ)-1]
+	}
+	r.maxQ = append(r.maxQ, r.seq)
+	r.seq++
+}
+
+// valueOf returns value pushed with sequence number seq, it must still be in window.
+func (r *Rolling) valueOf(seq int) float64 {
+	return r.window[seq%len(r.window)]
+}
+
+func (r *Rolling) pop() {
+	v := r.window[r.head]
+	oldest := r.seq - r.size
+	r.head = (r.head + 1) % len(r.window)
+	r.size--
+	r.sum -= v
+	if r.size == 0 {
+		r.mean, r.m2 = 0, 0
+	} else {
+		delta := v - r.mean
+		r.mean -= delta / float64(r.size)
+		r.m2 -= delta * (v - r.mean)
+		if r.m2 < 0 {
+			r.m2 = 0
+		}
+	}
+	if len(r.minQ) > 0 && r.minQ[0] == oldest {
+		r.minQ = r.minQ[1:]
+	}
+	if len(r.maxQ) > 0 && r.maxQ[0] == oldest {
+		r.maxQ = r.maxQ[1:]
+	}
+}
+
+// Full reports whether window holds n values.
+func (r *Rolling) Full() bool { return r.size == len(r.window) }
+func (r *Rolling) Len() int   { return r.size }
+func (r *Rolling) Sum() float64 {
+	return r.sum
+}
+
+func (r *Rolling) Mean() float64 {
+	if r.size == 0 {
+		return math.NaN()
+	}
+	return r.mean
+}
+
+// Std returns standard deviation with delta degrees of freedom ddof, like gnum.Std.
+func (r *Rolling) Std(ddof int) float64 {
+	if r.size-ddof <= 0 {
+		return math.NaN()
+	}
+	return math.Sqrt(r.m2 / float64(r.size-ddof))
+}
+
+func (r *Rolling) Min() float64 {
+	if r.size == 0 {
+		return math.NaN()
+	}
+	return r.valueOf(r.minQ[0])
+}
+
+func (r *Rolling) Max() float64 {
+	if r.size == 0 {
+		return math.NaN()
+	}
+	return r.valueOf(r.maxQ[0])
+}
+
+func NewEMA(n int) *EMA {
+	if n < 1 {
+		n = 1
+	}
+	return &EMA{n: n, alpha: 2 / float64(n+1)}
+}
+
+// Push adds v and returns current EMA, it returns NaN until n values are pushed.
+func (e *EMA) Push(v float64) float64 {
+	e.count++
+	if e.count < e.n {
+		e.sum += v
+		return math.NaN()
+	}
+	if e.count == e.n {
+		e.value = (e.sum + v) / float64(e.n)
+		return e.value
+	}
+	e.value += e.alpha * (v - e.value)
+	return e.value
+}
+
+func (e *EMA) Value() float64 {
+	if e.count < e.n {
+		return math.NaN()
+	}
+	return e.value
+}
+
+// rollingApply returns fn of every window of n values, the first n-1 results are NaN.
+func rollingApply(values []float64, n int, fn func(r *Rolling) float64) []float64 {
+	out := make([]float64, len(values))
+	r := NewRolling(n)
+	for i, v := range values {
+		r.Push(v)
+		if r.Full() {
+			out[i] = fn(r)
+		} else {
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// SMA returns simple moving average of window n.
+func SMA(values []float64, n int) []float64 {
+	return rollingApply(values, n, func(r *Rolling) float64 { return r.Mean() })
+}
+
+// RollingStd returns moving standard deviation of window n.
+func RollingStd(values []float64, n int, ddof int) []float64 {
+	return rollingApply(values, n, func(r *Rolling) float64 { return r.Std(ddof) })
+}
+
+// RollingMin returns moving minimum of window n.
+func RollingMin(values []float64, n int) []float64 {
+	return rollingApply(values, n, func(r *Rolling) float64 { return r.Min() })
+}
+
+// RollingMax returns moving maximum of window n.
+func RollingMax(values []float64, n int) []float64 {
+	return rollingApply(values, n, func(r *Rolling) float64 { return r.Max() })
+}
+
+// ExpMA returns exponential moving average of period n, the first n-1 results are NaN.
+func ExpMA(values []float64, n int) []float64 {
+	out := make([]float64, len(values))
+	e := NewEMA(n)
+	for i, v := range values {
+		out[i] = e.Push(v)
+	}
+	return out
+}
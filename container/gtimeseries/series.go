@@ -0,0 +1,199 @@
+package gtimeseries
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"sort"
+	"time"
+)
+
+type (
+	// Bar is one OHLCV record.
+	Bar struct {
+		Time   time.Time
+		Open   float64
+		High   float64
+		Low    float64
+		Close  float64
+		Volume float64
+	}
+
+	// Series is a columnar OHLCV series sorted by time, it implements TimeSeries.
+	// Times are unique and strictly increasing.
+	Series struct {
+		times  []time.Time
+		open   []float64
+		high   []float64
+		low    []float64
+		close  []float64
+		volume []float64
+	}
+)
+
+var ErrTimeNotIncreasing = gerrors.New("bar time must be after the last bar")
+
+// NewSeries creates an empty series with preallocated capacity.
+func NewSeries(capacity int) *Series {
+	return &Series{
+		times:  make([]time.Time, 0, capacity),
+		open:   make([]float64, 0, capacity),
+		high:   make([]float64, 0, capacity),
+		low:    make([]float64, 0, capacity),
+		close:  make([]float64, 0, capacity),
+		volume: make([]float64, 0, capacity),
+	}
+}
+
+// NewSeriesFromBars creates a series from bars in any order, bars with duplicate time are rejected.
+func NewSeriesFromBars(bars []Bar) (*Series, error) {
+	sorted := append([]Bar(nil), bars...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	s := NewSeries(len(sorted))
+	for _, b := range sorted {
+		if err := s.Append(b); err != nil {
+			return nil, gerrors.Errorf("duplicate bar time %s", b.Time)
+		}
+	}
+	return s, nil
+}
+
+// FromTimeSeries copies any TimeSeries implementation into a Series.
+func FromTimeSeries(ts TimeSeries) (*Series, error) {
+	bars := make([]Bar, ts.Len())
+	for i := range bars {
+		bars[i] = Bar{Time: ts.Time(i), Open: ts.Open(i), High: ts.High(i), Low: ts.Low(i), Close: ts.Close(i), Volume: ts.Volume(i)}
+	}
+	return NewSeriesFromBars(bars)
+}
+
+// Append adds b to the end, b.Time must be after the last bar.
+func (s *Series) Append(b Bar) error {
+	if n := len(s.times); n > 0 && !b.Time.After(s.times[n-1]) {
+		return ErrTimeNotIncreasing
+	}
+	s.times = append(s.times, b.Time)
+	s.open = append(s.open, b.Open)
+	s.high = append(s.high, b.High)
+	s.low = append(s.low, b.Low)
+	s.close = append(s.close, b.Close)
+	s.volume = append(s.volume, b.Volume)
+	return nil
+}
+
+// Upsert inserts b at its time position, or replaces the bar with the same time.
+// Appending is O(1), inserting in the middle is O(n).
+func (s *Series) Upsert(b Bar) {
+	i := s.Search(b.Time)
+	if i < len(s.times) && s.times[i].Equal(b.Time) {
+		s.set(i, b)
+		return
+	}
+	if i == len(s.times) {
+		_ = s.Append(b)
+		return
+	}
+	s.times = insertAt(s.times, i, b.Time)
+	s.open = insertAt(s.open, i, b.Open)
+	s.high = insertAt(s.high, i, b.High)
+	s.low = insertAt(s.low, i, b.Low)
+	s.close = insertAt(s.close, i, b.Close)
+	s.volume = insertAt(s.volume, i, b.Volume)
+}
+
+func insertAt[T any](s []T, i int, v T) []T {
+	var zero T
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
+
+func (s *Series) set(i int, b Bar) {
+	s.times[i] = b.Time
+	s.open[i] = b.Open
+	s.high[i] = b.High
+	s.low[i] = b.Low
+	s.close[i] = b.Close
+	s.volume[i] = b.Volume
+}
+
+func (s *Series) Len() int             { return len(s.times) }
+func (s *Series) Time(i int) time.Time { return s.times[i] }
+func (s *Series) Open(i int) float64   { return s.open[i] }
+func (s *Series) High(i int) float64   { return s.high[i] }
+func (s *Series) Low(i int) float64    { return s.low[i] }
+func (s *Series) Close(i int) float64  { return s.close[i] }
+func (s *Series) Volume(i int) float64 { return s.volume[i] }
+func (s *Series) Times() []time.Time   { return s.times }
+func (s *Series) Opens() []float64     { return s.open }
+func (s *Series) Highs() []float64     { return s.high }
+func (s *Series) Lows() []float64      { return s.low }
+func (s *Series) Closes() []float64    { return s.close }
+func (s *Series) Volumes() []float64   { return s.volume }
+
+// Bar returns the i-th bar.
+func (s *Series) Bar(i int) Bar {
+	return Bar{Time: s.times[i], Open: s.open[i], High: s.high[i], Low: s.low[i], Close: s.close[i], Volume: s.volume[i]}
+}
+
+// Last returns the last bar.
+func (s *Series) Last() (Bar, bool) {
+	if len(s.times) == 0 {
+		return Bar{}, false
+	}
+	return s.Bar(len(s.times) - 1), true
+}
+
+// Search returns index of the first bar whose time >= t, it returns Len() if there isn't one.
+func (s *Series) Search(t time.Time) int {
+	return sort.Search(len(s.times), func(i int) bool { return !s.times[i].Before(t) })
+}
+
+// IndexOf returns index of the bar at exactly t.
+func (s *Series) IndexOf(t time.Time) (int, bool) {
+	i := s.Search(t)
+	if i < len(s.times) && s.times[i].Equal(t) {
+		return i, true
+	}
+	return -1, false
+}
+
+// At returns the last bar whose time <= t, like the latest known bar at moment t.
+func (s *Series) At(t time.Time) (Bar, bool) {
+	i := s.Search(t)
+	if i < len(s.times) && s.times[i].Equal(t) {
+		return s.Bar(i), true
+	}
+	if i == 0 {
+		return Bar{}, false
+	}
+	return s.Bar(i - 1), true
+}
+
+// Between returns bars with from <= time < to, the result shares memory with s.
+func (s *Series) Between(from, to time.Time) *Series {
+	return s.Slice(s.Search(from), s.Search(to))
+}
+
+// Slice returns bars [i, j), the result shares memory with s, appending to it copies data.
+func (s *Series) Slice(i, j int) *Series {
+	return &Series{
+		times:  s.times[i:j:j],
+		open:   s.open[i:j:j],
+		high:   s.high[i:j:j],
+		low:    s.low[i:j:j],
+		close:  s.close[i:j:j],
+		volume: s.volume[i:j:j],
+	}
+}
+
+// Clone returns a deep copy.
+func (s *Series) Clone() *Series {
+	return &Series{
+		times:  append([]time.Time(nil), s.times...),
+		open:   append([]float64(nil), s.open...),
+		high:   append([]float64(nil), s.high...),
+		low:    append([]float64(nil), s.low...),
+		close:  append([]float64(nil), s.close...),
+		volume: append([]float64(nil), s.volume...),
+	}
+}
@@ -0,0 +1,106 @@
+package gtimeseries
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math"
+	"testing"
+	"time"
+)
+
+var _ TimeSeries = (*Series)(nil)
+
+func minuteBars(begin time.Time, closes ...float64) []Bar {
+	bars := make([]Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = Bar{Time: begin.Add(time.Duration(i) * time.Minute), Open: c, High: c + 1, Low: c - 1, Close: c, Volume: 1}
+	}
+	return bars
+}
+
+func TestSeries_AppendSearch(t *testing.T) {
+	begin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, err := NewSeriesFromBars(minuteBars(begin, 1, 2, 3, 4))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, s.Append(Bar{Time: begin}) == ErrTimeNotIncreasing, "append old time should fail")
+
+	i, ok := s.IndexOf(begin.Add(2 * time.Minute))
+	gtest.AssertTrue(t, ok && i == 2, "IndexOf got %d %v", i, ok)
+	b, ok := s.At(begin.Add(150 * time.Second))
+	gtest.AssertTrue(t, ok && b.Close == 3, "At got %+v", b)
+	_, ok = s.At(begin.Add(-time.Second))
+	gtest.AssertTrue(t, !ok, "At before first bar should fail")
+
+	s.Upsert(Bar{Time: begin.Add(90 * time.Second), Close: 2.5})
+	s.Upsert(Bar{Time: begin, Close: 0.5})
+	gtest.AssertTrue(t, s.Len() == 5 && s.Close(0) == 0.5 && s.Close(2) == 2.5, "Upsert got %v", s.Closes())
+	gtest.AssertTrue(t, s.Between(begin.Add(time.Minute), begin.Add(3*time.Minute)).Len() == 3, "Between len should be 3")
+}
+
+func TestSeries_Resample(t *testing.T) {
+	begin := time.Date(2024, 1, 1, 0, 58, 0, 0, time.UTC)
+	s, err := NewSeriesFromBars(minuteBars(begin, 5, 7, 3, 4, 6))
+	gtest.Assert(t, err)
+	h, err := s.Resample(time.Hour)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, h.Len() == 2, "should have 2 hour bars but got %d", h.Len())
+	gtest.AssertTrue(t, h.Bar(0) == Bar{Time: begin.Truncate(time.Hour), Open: 5, High: 8, Low: 4, Close: 7, Volume: 2}, "bar 0 %+v", h.Bar(0))
+	gtest.AssertTrue(t, h.Bar(1) == Bar{Time: begin.Add(2 * time.Minute), Open: 3, High: 7, Low: 2, Close: 6, Volume: 3}, "bar 1 %+v", h.Bar(1))
+}
+
+func TestSeries_FillGapsAndAlign(t *testing.T) {
+	begin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, err := NewSeriesFromBars([]Bar{{Time: begin, Close: 1}, {Time: begin.Add(3 * time.Minute), Close: 4}})
+	gtest.Assert(t, err)
+	filled, err := s.FillGaps(time.Minute, FillLinear)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, filled.Len() == 4 && filled.Close(1) == 2 && filled.Close(2) == 3, "linear fill got %v", filled.Closes())
+	filled, err = s.FillGaps(time.Minute, FillForward)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, filled.Close(2) == 1 && filled.Volume(2) == 0, "forward fill got %v", filled.Closes())
+
+	axis, values, err := AlignFloats(AlignOuter, FillForward,
+		[][]time.Time{{begin, begin.Add(2 * time.Minute)}, {begin.Add(time.Minute), begin.Add(2 * time.Minute)}},
+		[][]float64{{1, 3}, {20, 30}})
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, len(axis) == 3, "outer axis len should be 3 but got %d", len(axis))
+	gtest.AssertTrue(t, values[0][1] == 1 && math.IsNaN(values[1][0]) && values[1][1] == 20, "aligned values %v", values)
+
+	axis, _, err = AlignFloats(AlignInner, FillNaN,
+		[][]time.Time{{begin, begin.Add(2 * time.Minute)}, {begin.Add(time.Minute), begin.Add(2 * time.Minute)}},
+		[][]float64{{1, 3}, {20, 30}})
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, len(axis) == 1 && axis[0].Equal(begin.Add(2*time.Minute)), "inner axis %v", axis)
+}
+
+func TestRolling(t *testing.T) {
+	values := []float64{4, 2, 5, 1, 3, 6, 2}
+	n := 3
+	sma := SMA(values, n)
+	std := RollingStd(values, n, 1)
+	mins := RollingMin(values, n)
+	maxs := RollingMax(values, n)
+	for i := range values {
+		if i < n-1 {
+			gtest.AssertTrue(t, math.IsNaN(sma[i]) && math.IsNaN(mins[i]), "warmup %d should be NaN", i)
+			continue
+		}
+		w := values[i-n+1 : i+1]
+		sum, lo, hi := 0.0, math.Inf(1), math.Inf(-1)
+		for _, v := range w {
+			sum += v
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+		mean := sum / float64(n)
+		ss := 0.0
+		for _, v := range w {
+			ss += (v - mean) * (v - mean)
+		}
+		gtest.AssertTrue(t, math.Abs(sma[i]-mean) < 1e-9, "sma %d got %f want %f", i, sma[i], mean)
+		gtest.AssertTrue(t, math.Abs(std[i]-math.Sqrt(ss/float64(n-1))) < 1e-9, "std %d got %f", i, std[i])
+		gtest.AssertTrue(t, mins[i] == lo && maxs[i] == hi, "min/max %d got %f %f", i, mins[i], maxs[i])
+	}
+
+	ema := ExpMA([]float64{1, 2, 3, 4}, 3)
+	gtest.AssertTrue(t, math.IsNaN(ema[1]) && ema[2] == 2 && ema[3] == 3, "ema got %v", ema)
+}
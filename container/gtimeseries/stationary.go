@@ -1,6 +1,345 @@
 package gtimeseries
 
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+)
+
 /*
 Strict stationarity
 Weak stationarity
+
+A strictly stationary series has the same joint distribution after any time shift, a weakly stationary series
+only keeps constant mean and autocovariance. ADF and KPSS below test weak stationarity from opposite sides:
+ADF null hypothesis is a unit root (non-stationary), KPSS null hypothesis is stationarity.
 */
+
+// Regression is the deterministic part included in stationarity test regressions.
+type Regression int
+
+const (
+	// RegressionNone includes nothing.
+	RegressionNone Regression = iota
+	// RegressionConstant includes a constant, it tests level stationarity.
+	RegressionConstant
+	// RegressionTrend includes a constant and a linear trend, it tests trend stationarity.
+	RegressionTrend
+)
+
+type (
+	// ADFResult is the result of Augmented Dickey-Fuller test.
+	ADFResult struct {
+		Statistic  float64
+		Lags       int // lagged differences used in regression
+		NObs       int // observations used in regression
+		Critical1  float64
+		Critical5  float64
+		Critical10 float64
+	}
+
+	// KPSSResult is the result of Kwiatkowski-Phillips-Schmidt-Shin test.
+	KPSSResult struct {
+		Statistic   float64
+		Lags        int     // Newey-West bandwidth
+		PValue      float64 // interpolated from critical values, clamped to [0.01, 0.1]
+		Critical1   float64
+		Critical2_5 float64
+		Critical5   float64
+		Critical10  float64
+	}
+)
+
+// Stationary reports whether unit root is rejected at 5% significance.
+func (r *ADFResult) Stationary() bool {
+	return r.Statistic < r.Critical5
+}
+
+// Stationary reports whether stationarity is not rejected at 5% significance.
+func (r *KPSSResult) Stationary() bool {
+	return r.Statistic < r.Critical5
+}
+
+// ADF runs Augmented Dickey-Fuller test by regression
+// Δy[t] = deterministic + γ*y[t-1] + Σ β[i]*Δy[t-i] (i = 1..lags), statistic is t value of γ.
+// If maxLag < 0, the lag count is chosen by AIC from 0 to ceil(12*(n/100)^(1/4)) like statsmodels autolag "AIC",
+// otherwise maxLag lags are used. Critical values come from MacKinnon (2010) response surface.
+func ADF(values []float64, maxLag int, regression Regression) (*ADFResult, error) {
+	n := len(values)
+	lags := maxLag
+	if maxLag < 0 {
+		maxLag = int(math.Ceil(12 * math.Pow(float64(n)/100, 0.25)))
+		// leave at least some degrees of freedom
+		if limit := n/2 - int(regression) - 1; maxLag > limit {
+			maxLag = limit
+		}
+		if maxLag < 0 {
+			return nil, gerrors.New("too few observations %d for ADF", n)
+		}
+		bestAIC := math.Inf(1)
+		for l := 0; l <= maxLag; l++ {
+			x, y := adfDesign(values, l, maxLag, regression)
+			fit, err := ols(x, y)
+			if err != nil {
+				return nil, err
+			}
+			if aic := fit.aic(); aic < bestAIC {
+				bestAIC, lags = aic, l
+			}
+		}
+	}
+
+	// regression has n-lags-1 observations and lags+1+regression regressors
+	if n-lags-1 <= lags+int(regression)+2 {
+		return nil, gerrors.New("too few observations %d for ADF with %d lags", n, lags)
+	}
+	x, y := adfDesign(values, lags, lags, regression)
+	fit, err := ols(x, y)
+	if err != nil {
+		return nil, err
+	}
+	res := &ADFResult{
+		Statistic: fit.beta[0] / fit.se[0],
+		Lags:      lags,
+		NObs:      len(y),
+	}
+	res.Critical1, res.Critical5, res.Critical10 = adfCriticalValues(regression, len(y))
+	return res, nil
+}
+
+// adfDesign builds regressors [y[t-1], Δy[t-1..t-lags], deterministic...] and Δy[t],
+// the first skip+1 values are only used as lags so models with different lags share one sample.
+func adfDesign(values []float64, lags, skip int, regression Regression) ([][]float64, []float64) {
+	var x [][]float64
+	var y []float64
+	for t := skip + 1; t < len(values); t++ {
+		row := []float64{values[t-1]}
+		for i := 1; i <= lags; i++ {
+			row = append(row, values[t-i]-values[t-i-1])
+		}
+		if regression >= RegressionConstant {
+			row = append(row, 1)
+		}
+		if regression == RegressionTrend {
+			row = append(row, float64(t))
+		}
+		x = append(x, row)
+		y = append(y, values[t]-values[t-1])
+	}
+	return x, y
+}
+
+// adfCriticalValues returns 1%, 5%, 10% critical values for nobs observations.
+func adfCriticalValues(regression Regression, nobs int) (float64, float64, float64) {
+	// MacKinnon (2010) tau coefficients for one variable: β∞, β1, β2, β3
+	table := map[Regression][3][4]float64{
+		RegressionNone: {
+			{-2.56574, -2.2358, -3.627, 0},
+			{-1.94100, -0.2686, -3.365, 31.223},
+			{-1.61682, 0.2656, -2.714, 25.364},
+		},
+		RegressionConstant: {
+			{-3.43035, -6.5393, -16.786, -79.433},
+			{-2.86154, -2.8903, -4.234, -40.040},
+			{-2.56677, -1.5384, -2.809, 0},
+		},
+		RegressionTrend: {
+			{-3.95877, -9.0531, -28.428, -134.155},
+			{-3.41049, -4.3904, -9.036, -45.374},
+			{-3.12705, -2.5856, -3.925, -22.380},
+		},
+	}
+	coef := table[regression]
+	t := float64(nobs)
+	cv := func(c [4]float64) float64 {
+		return c[0] + c[1]/t + c[2]/(t*t) + c[3]/(t*t*t)
+	}
+	return cv(coef[0]), cv(coef[1]), cv(coef[2])
+}
+
+// KPSS runs Kwiatkowski-Phillips-Schmidt-Shin test with RegressionConstant or RegressionTrend.
+// If lags < 0, Newey-West bandwidth is ceil(12*(n/100)^(1/4)).
+func KPSS(values []float64, lags int, regression Regression) (*KPSSResult, error) {
+	n := len(values)
+	if regression != RegressionConstant && regression != RegressionTrend {
+		return nil, gerrors.New("KPSS supports RegressionConstant and RegressionTrend only")
+	}
+	if n < 3 {
+		return nil, gerrors.New("too few observations %d for KPSS", n)
+	}
+	if lags < 0 {
+		lags = int(math.Ceil(12 * math.Pow(float64(n)/100, 0.25)))
+	}
+	if lags >= n {
+		lags = n - 1
+	}
+
+	var resid []float64
+	if regression == RegressionConstant {
+		m := mean(values)
+		resid = make([]float64, n)
+		for i, v := range values {
+			resid[i] = v - m
+		}
+	} else {
+		x := make([][]float64, n)
+		for i := range x {
+			x[i] = []float64{1, float64(i)}
+		}
+		fit, err := ols(x, values)
+		if err != nil {
+			return nil, err
+		}
+		resid = fit.resid
+	}
+
+	eta, cum := 0.0, 0.0
+	for _, r := range resid {
+		cum += r
+		eta += cum * cum
+	}
+	eta /= float64(n) * float64(n)
+
+	s2 := 0.0
+	for _, r := range resid {
+		s2 += r * r
+	}
+	for i := 1; i <= lags; i++ {
+		cov := 0.0
+		for t := i; t < n; t++ {
+			cov += resid[t] * resid[t-i]
+		}
+		s2 += 2 * (1 - float64(i)/float64(lags+1)) * cov
+	}
+	s2 /= float64(n)
+
+	res := &KPSSResult{Statistic: eta / s2, Lags: lags}
+	crit := []float64{0.347, 0.463, 0.574, 0.739} // 10%, 5%, 2.5%, 1%
+	if regression == RegressionTrend {
+		crit = []float64{0.119, 0.146, 0.176, 0.216}
+	}
+	res.Critical10, res.Critical5, res.Critical2_5, res.Critical1 = crit[0], crit[1], crit[2], crit[3]
+	res.PValue = interpolate(res.Statistic, crit, []float64{0.10, 0.05, 0.025, 0.01})
+	return res, nil
+}
+
+// interpolate maps x by piecewise linear function through (xs[i], ys[i]), xs is increasing,
+// x out of range is clamped.
+func interpolate(x float64, xs, ys []float64) float64 {
+	if x <= xs[0] {
+		return ys[0]
+	}
+	for i := 1; i < len(xs); i++ {
+		if x <= xs[i] {
+			return ys[i-1] + (ys[i]-ys[i-1])*(x-xs[i-1])/(xs[i]-xs[i-1])
+		}
+	}
+	return ys[len(ys)-1]
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// olsFit is the result of ordinary least squares.
+type olsFit struct {
+	beta  []float64
+	se    []float64 // standard errors of beta
+	resid []float64
+	ssr   float64
+}
+
+// aic returns Akaike information criterion like statsmodels OLS.
+func (f *olsFit) aic() float64 {
+	n := float64(len(f.resid))
+	llf := -n / 2 * (math.Log(2*math.Pi) + math.Log(f.ssr/n) + 1)
+	return -2*llf + 2*float64(len(f.beta))
+}
+
+// ols fits y = x * beta by normal equations, x rows are observations.
+func ols(x [][]float64, y []float64) (*olsFit, error) {
+	n, k := len(x), len(x[0])
+	xtx := make([][]float64, k)
+	xty := make([]float64, k)
+	for i := range xtx {
+		xtx[i] = make([]float64, k)
+	}
+	for r := 0; r < n; r++ {
+		for i := 0; i < k; i++ {
+			xty[i] += x[r][i] * y[r]
+			for j := 0; j < k; j++ {
+				xtx[i][j] += x[r][i] * x[r][j]
+			}
+		}
+	}
+	inv, err := invert(xtx)
+	if err != nil {
+		return nil, err
+	}
+
+	fit := &olsFit{beta: make([]float64, k), se: make([]float64, k), resid: make([]float64, n)}
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			fit.beta[i] += inv[i][j] * xty[j]
+		}
+	}
+	for r := 0; r < n; r++ {
+		pred := 0.0
+		for i := 0; i < k; i++ {
+			pred += x[r][i] * fit.beta[i]
+		}
+		fit.resid[r] = y[r] - pred
+		fit.ssr += fit.resid[r] * fit.resid[r]
+	}
+	if n > k {
+		sigma2 := fit.ssr / float64(n-k)
+		for i := 0; i < k; i++ {
+			fit.se[i] = math.Sqrt(sigma2 * inv[i][i])
+		}
+	}
+	return fit, nil
+}
+
+// invert returns the inverse of square matrix m by Gauss-Jordan elimination with partial pivoting.
+func invert(m [][]float64) ([][]float64, error) {
+	k := len(m)
+	a := make([][]float64, k)
+	for i := range a {
+		a[i] = make([]float64, 2*k)
+		copy(a[i], m[i])
+		a[i][k+i] = 1
+	}
+	for col := 0; col < k; col++ {
+		pivot := col
+		for r := col + 1; r < k; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, gerrors.New("singular regression matrix")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		p := a[col][col]
+		for j := range a[col] {
+			a[col][j] /= p
+		}
+		for r := 0; r < k; r++ {
+			if r == col || a[r][col] == 0 {
+				continue
+			}
+			f := a[r][col]
+			for j := range a[r] {
+				a[r][j] -= f * a[col][j]
+			}
+		}
+	}
+	inv := make([][]float64, k)
+	for i := range inv {
+		inv[i] = a[i][k:]
+	}
+	return inv, nil
+}
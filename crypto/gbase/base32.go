@@ -0,0 +1,87 @@
+package gbase
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+)
+
+const (
+	// crockfordAlphabet is Crockford's base32 alphabet which excludes I, L, O and U.
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+var (
+	crockfordDecode [256]byte
+)
+
+func init() {
+	for i := range crockfordDecode {
+		crockfordDecode[i] = 0xff
+	}
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		c := crockfordAlphabet[i]
+		crockfordDecode[c] = byte(i)
+		if c >= 'A' && c <= 'Z' {
+			crockfordDecode[c+'a'-'A'] = byte(i)
+		}
+	}
+	// Crockford's decoding aliases
+	for _, c := range "oO" {
+		crockfordDecode[c] = 0
+	}
+	for _, c := range "iIlL" {
+		crockfordDecode[c] = 1
+	}
+}
+
+// Base32CrockfordEncode encodes message as a big endian number into ceil(len*8/5) upper case Crockford base32 chars,
+// unused high bits of the first char are zero, e.g. 16 bytes are encoded into 26 chars like ULID.
+func Base32CrockfordEncode(message []byte) string {
+	n := (len(message)*8 + 4) / 5
+	r := make([]byte, n)
+	bits, acc := 0, uint(0)
+	i := n - 1
+	for j := len(message) - 1; j >= 0; j-- {
+		acc |= uint(message[j]) << bits
+		bits += 8
+		for bits >= 5 {
+			r[i] = crockfordAlphabet[acc&31]
+			acc >>= 5
+			bits -= 5
+			i--
+		}
+	}
+	if i >= 0 {
+		r[i] = crockfordAlphabet[acc&31]
+	}
+	return string(r)
+}
+
+// Base32CrockfordDecode decodes s encoded by Base32CrockfordEncode, it is case-insensitive and
+// accepts O as 0, I and L as 1. The result has len(s)*5/8 bytes, error is returned if overflowed bits are not zero.
+func Base32CrockfordDecode(s string) ([]byte, error) {
+	n := len(s) * 5 / 8
+	r := make([]byte, n)
+	bits, acc := 0, uint(0)
+	i := n - 1
+	for j := len(s) - 1; j >= 0; j-- {
+		v := crockfordDecode[s[j]]
+		if v == 0xff {
+			return nil, gerrors.New("invalid base32 char %q", s[j])
+		}
+		acc |= uint(v) << bits
+		bits += 5
+		if bits >= 8 {
+			if i < 0 {
+				return nil, gerrors.New("base32 %s overflows %d bytes", s, n)
+			}
+			r[i] = byte(acc)
+			acc >>= 8
+			bits -= 8
+			i--
+		}
+	}
+	if acc != 0 {
+		return nil, gerrors.New("base32 %s overflows %d bytes", s, n)
+	}
+	return r, nil
+}
@@ -0,0 +1,56 @@
+package gbase
+
+import (
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"math"
+	"math/big"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var base62Decode [256]byte
+
+func init() {
+	for i := range base62Decode {
+		base62Decode[i] = 0xff
+	}
+	for i := 0; i < len(base62Alphabet); i++ {
+		base62Decode[base62Alphabet[i]] = byte(i)
+	}
+}
+
+// base62Len returns count of base62 chars which can hold n bytes.
+func base62Len(n int) int {
+	return int(math.Ceil(float64(n*8) / math.Log2(62)))
+}
+
+// Base62Encode encodes message as a big endian number into fixed width base62 string which is left padded with '0',
+// e.g. 20 bytes are encoded into 27 chars like KSUID, so encoded strings sort in the same order as messages.
+func Base62Encode(message []byte) string {
+	n := base62Len(len(message))
+	r := make([]byte, n)
+	x := new(big.Int).SetBytes(message)
+	radix, mod := big.NewInt(62), new(big.Int)
+	for i := n - 1; i >= 0; i-- {
+		x.DivMod(x, radix, mod)
+		r[i] = base62Alphabet[mod.Int64()]
+	}
+	return string(r)
+}
+
+// Base62Decode decodes s encoded by Base62Encode into n bytes.
+func Base62Decode(s string, n int) ([]byte, error) {
+	x, radix := new(big.Int), big.NewInt(62)
+	for i := 0; i < len(s); i++ {
+		v := base62Decode[s[i]]
+		if v == 0xff {
+			return nil, gerrors.New("invalid base62 char %q", s[i])
+		}
+		x.Mul(x, radix)
+		x.Add(x, big.NewInt(int64(v)))
+	}
+	if x.BitLen() > n*8 {
+		return nil, gerrors.New("base62 %s overflows %d bytes", s, n)
+	}
+	return x.FillBytes(make([]byte, n)), nil
+}
@@ -0,0 +1,198 @@
+package gpatricia
+
+// BitTree is a mutable binary patricia tree whose keys are bit strings, a key is given as bytes
+// and count of leading bits in use, so it can hold prefixes which are not byte aligned, e.g. IP prefix 10.0.0.0/12.
+// bits passed to methods must not be more than len(key)*8.
+// It is not safe for concurrent use.
+type BitTree[V any] struct {
+	root bitNode[V]
+	size int
+}
+
+type bitNode[V any] struct {
+	key   []byte // leading bits of key, the rest bits are zero
+	bits  int
+	child [2]*bitNode[V]
+	leaf  bool
+	val   V
+}
+
+func NewBitTree[V any]() *BitTree[V] {
+	return &BitTree[V]{}
+}
+
+func bitAt(key []byte, i int) int {
+	return int(key[i/8]>>(7-i%8)) & 1
+}
+
+// commonBits returns count of common leading bits of a and b, at most n.
+func commonBits(a, b []byte, n int) int {
+	for i := 0; i < n/8; i++ {
+		if x := a[i] ^ b[i]; x != 0 {
+			c := i * 8
+			for x&0x80 == 0 {
+				x <<= 1
+				c++
+			}
+			return c
+		}
+	}
+	for i := n / 8 * 8; i < n; i++ {
+		if bitAt(a, i) != bitAt(b, i) {
+			return i
+		}
+	}
+	return n
+}
+
+// truncate copies leading bits of key and clears the others.
+func truncate(key []byte, bits int) []byte {
+	r := make([]byte, (bits+7)/8)
+	copy(r, key)
+	if bits%8 != 0 {
+		r[len(r)-1] &= 0xff << (8 - bits%8)
+	}
+	return r
+}
+
+func (t *BitTree[V]) Len() int {
+	return t.size
+}
+
+// Insert sets value of leading bits of key, it returns false if the key already exists and its value is replaced.
+func (t *BitTree[V]) Insert(key []byte, bits int, v V) bool {
+	n := &t.root
+	for {
+		if n.bits == bits {
+			added := !n.leaf
+			n.leaf, n.val = true, v
+			if added {
+				t.size++
+			}
+			return added
+		}
+		b := bitAt(key, n.bits)
+		c := n.child[b]
+		if c == nil {
+			n.child[b] = &bitNode[V]{key: truncate(key, bits), bits: bits, leaf: true, val: v}
+			t.size++
+			return true
+		}
+		common := commonBits(c.key, key, min(c.bits, bits))
+		if common == c.bits {
+			n = c
+			continue
+		}
+		// c and key diverge at common, which is after n.bits because both go through child b
+		mid := &bitNode[V]{key: truncate(key, common), bits: common}
+		mid.child[bitAt(c.key, common)] = c
+		if common == bits {
+			mid.leaf, mid.val = true, v
+		} else {
+			mid.child[bitAt(key, common)] = &bitNode[V]{key: truncate(key, bits), bits: bits, leaf: true, val: v}
+		}
+		n.child[b] = mid
+		t.size++
+		return true
+	}
+}
+
+func (t *BitTree[V]) Get(key []byte, bits int) (V, bool) {
+	n := &t.root
+	for n != nil && n.bits < bits {
+		n = n.child[bitAt(key, n.bits)]
+		if n != nil && (n.bits > bits || commonBits(n.key, key, n.bits) < n.bits) {
+			n = nil
+		}
+	}
+	if n != nil && n.bits == bits && n.leaf {
+		return n.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete removes the key, it returns false if the key doesn't exist.
+func (t *BitTree[V]) Delete(key []byte, bits int) bool {
+	// path from root to the node of key
+	path := []*bitNode[V]{&t.root}
+	for n := &t.root; n.bits < bits; {
+		n = n.child[bitAt(key, n.bits)]
+		if n == nil || n.bits > bits || commonBits(n.key, key, n.bits) < n.bits {
+			return false
+		}
+		path = append(path, n)
+	}
+	n := path[len(path)-1]
+	if n.bits != bits || !n.leaf {
+		return false
+	}
+	var zero V
+	n.leaf, n.val = false, zero
+	t.size--
+
+	// nodes without value are kept only if they have two children, root is always kept
+	for i := len(path) - 1; i > 0; i-- {
+		n, parent := path[i], path[i-1]
+		if n.leaf || (n.child[0] != nil && n.child[1] != nil) {
+			break
+		}
+		c := n.child[0]
+		if c == nil {
+			c = n.child[1]
+		}
+		parent.child[bitAt(n.key, parent.bits)] = c
+		if c != nil {
+			break
+		}
+	}
+	return true
+}
+
+// LongestPrefix returns the longest key which is a prefix of leading bits of key.
+func (t *BitTree[V]) LongestPrefix(key []byte, bits int) (matched int, v V, ok bool) {
+	n := &t.root
+	for {
+		if n.leaf {
+			matched, v, ok = n.bits, n.val, true
+		}
+		if n.bits >= bits {
+			return
+		}
+		c := n.child[bitAt(key, n.bits)]
+		if c == nil || c.bits > bits || commonBits(c.key, key, c.bits) < c.bits {
+			return
+		}
+		n = c
+	}
+}
+
+// WalkPrefix calls fn for all keys starting with leading bits of prefix in ascending order, until fn returns false.
+// A key goes before keys which it is a prefix of, bit 0 goes before bit 1.
+// Key passed to fn must not be modified.
+func (t *BitTree[V]) WalkPrefix(prefix []byte, bits int, fn func(key []byte, bits int, v V) bool) {
+	n := &t.root
+	for n.bits < bits {
+		c := n.child[bitAt(prefix, n.bits)]
+		if c == nil || commonBits(c.key, prefix, min(c.bits, bits)) < min(c.bits, bits) {
+			return
+		}
+		n = c
+	}
+	walkBits(n, fn)
+}
+
+// Walk calls fn for all keys in ascending order, until fn returns false.
+func (t *BitTree[V]) Walk(fn func(key []byte, bits int, v V) bool) {
+	walkBits(&t.root, fn)
+}
+
+func walkBits[V any](n *bitNode[V], fn func(key []byte, bits int, v V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.leaf && !fn(n.key, n.bits, n.val) {
+		return false
+	}
+	return walkBits(n.child[0], fn) && walkBits(n.child[1], fn)
+}
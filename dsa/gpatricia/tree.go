@@ -0,0 +1,206 @@
+package gpatricia
+
+import (
+	"iter"
+	"sort"
+	"strings"
+)
+
+// Tree is a mutable radix tree which maps string keys to values of V, keys are compared byte by byte,
+// so any bytes can be used as key by string(b), e.g. URL paths.
+// It is not safe for concurrent use.
+type Tree[V any] struct {
+	root node[V]
+	size int
+}
+
+type node[V any] struct {
+	prefix   string     // edge label from parent, empty only for root
+	children []*node[V] // sorted by the first byte of prefix
+	leaf     bool
+	val      V
+}
+
+func NewTree[V any]() *Tree[V] {
+	return &Tree[V]{}
+}
+
+// child returns the index of child whose prefix starts with c, and whether it exists.
+func (n *node[V]) child(c byte) (int, bool) {
+	i := sort.Search(len(n.children), func(i int) bool { return n.children[i].prefix[0] >= c })
+	return i, i < len(n.children) && n.children[i].prefix[0] == c
+}
+
+func (n *node[V]) insertChild(i int, c *node[V]) {
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = c
+}
+
+// merge merges n with its only child if n holds no value.
+func (n *node[V]) merge() {
+	if n.leaf || len(n.children) != 1 || n.prefix == "" {
+		return
+	}
+	c := n.children[0]
+	n.prefix += c.prefix
+	n.children, n.leaf, n.val = c.children, c.leaf, c.val
+}
+
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+func (t *Tree[V]) Len() int {
+	return t.size
+}
+
+// Insert sets value of key, it returns false if key already exists and its value is replaced.
+func (t *Tree[V]) Insert(key string, v V) bool {
+	n := &t.root
+	for {
+		if key == "" {
+			added := !n.leaf
+			n.leaf, n.val = true, v
+			if added {
+				t.size++
+			}
+			return added
+		}
+		i, ok := n.child(key[0])
+		if !ok {
+			n.insertChild(i, &node[V]{prefix: key, leaf: true, val: v})
+			t.size++
+			return true
+		}
+		c := n.children[i]
+		l := commonPrefixLen(c.prefix, key)
+		if l == len(c.prefix) {
+			n, key = c, key[l:]
+			continue
+		}
+		// split edge of c at l
+		mid := &node[V]{prefix: c.prefix[:l], children: []*node[V]{c}}
+		c.prefix = c.prefix[l:]
+		n.children[i] = mid
+		if l == len(key) {
+			mid.leaf, mid.val = true, v
+		} else {
+			j, _ := mid.child(key[l])
+			mid.insertChild(j, &node[V]{prefix: key[l:], leaf: true, val: v})
+		}
+		t.size++
+		return true
+	}
+}
+
+func (t *Tree[V]) Get(key string) (V, bool) {
+	n := &t.root
+	for key != "" {
+		i, ok := n.child(key[0])
+		if !ok || !strings.HasPrefix(key, n.children[i].prefix) {
+			var zero V
+			return zero, false
+		}
+		n = n.children[i]
+		key = key[len(n.prefix):]
+	}
+	return n.val, n.leaf
+}
+
+// Delete removes key, it returns false if key doesn't exist.
+func (t *Tree[V]) Delete(key string) bool {
+	var parent *node[V]
+	n, idx := &t.root, 0
+	for key != "" {
+		i, ok := n.child(key[0])
+		if !ok || !strings.HasPrefix(key, n.children[i].prefix) {
+			return false
+		}
+		parent, n, idx = n, n.children[i], i
+		key = key[len(n.prefix):]
+	}
+	if !n.leaf {
+		return false
+	}
+	var zero V
+	n.leaf, n.val = false, zero
+	t.size--
+	if parent != nil && len(n.children) == 0 {
+		parent.children = append(parent.children[:idx], parent.children[idx+1:]...)
+		parent.merge()
+	} else {
+		n.merge()
+	}
+	return true
+}
+
+// LongestPrefix returns the longest key which is a prefix of s, e.g. route "/api/" for path "/api/users".
+func (t *Tree[V]) LongestPrefix(s string) (string, V, bool) {
+	var (
+		key   string
+		val   V
+		found bool
+	)
+	n, depth := &t.root, 0
+	for {
+		if n.leaf {
+			key, val, found = s[:depth], n.val, true
+		}
+		if depth == len(s) {
+			break
+		}
+		i, ok := n.child(s[depth])
+		if !ok || !strings.HasPrefix(s[depth:], n.children[i].prefix) {
+			break
+		}
+		n = n.children[i]
+		depth += len(n.prefix)
+	}
+	return key, val, found
+}
+
+// WalkPrefix calls fn for all keys starting with prefix in ascending order, until fn returns false.
+func (t *Tree[V]) WalkPrefix(prefix string, fn func(key string, v V) bool) {
+	n, key, rest := &t.root, "", prefix
+	for rest != "" {
+		i, ok := n.child(rest[0])
+		if !ok {
+			return
+		}
+		c := n.children[i]
+		l := commonPrefixLen(c.prefix, rest)
+		if l < len(rest) && l < len(c.prefix) {
+			return
+		}
+		// prefix may end in the middle of edge of c
+		n, key = c, key+c.prefix
+		rest = rest[l:]
+	}
+	walk(n, key, fn)
+}
+
+func walk[V any](n *node[V], key string, fn func(key string, v V) bool) bool {
+	if n.leaf && !fn(key, n.val) {
+		return false
+	}
+	for _, c := range n.children {
+		if !walk(c, key+c.prefix, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// All iterates all keys and values in ascending order of keys.
+func (t *Tree[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		walk(&t.root, "", yield)
+	}
+}
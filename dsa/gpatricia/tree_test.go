@@ -0,0 +1,224 @@
+package gpatricia
+
+import (
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math/rand"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	tr := NewTree[int]()
+	keys := []string{"foo", "far", "farther", "boo", "ba", "bar", "", "f"}
+	for i, k := range keys {
+		gtest.AssertTrue(t, tr.Insert(k, i), "insert %s", k)
+	}
+	gtest.AssertTrue(t, !tr.Insert("bar", 100), "replace bar")
+	gtest.AssertTrue(t, tr.Len() == len(keys), "len %d", tr.Len())
+
+	v, ok := tr.Get("bar")
+	gtest.AssertTrue(t, ok && v == 100, "get bar %d", v)
+	_, ok = tr.Get("fa")
+	gtest.AssertTrue(t, !ok, "get fa")
+
+	var all []string
+	for k := range tr.All() {
+		all = append(all, k)
+	}
+	sorted := slices.Clone(keys)
+	sort.Strings(sorted)
+	gtest.AssertTrue(t, slices.Equal(all, sorted), "all %v", all)
+
+	var fa []string
+	tr.WalkPrefix("fa", func(k string, _ int) bool { fa = append(fa, k); return true })
+	gtest.AssertTrue(t, slices.Equal(fa, []string{"far", "farther"}), "walk fa %v", fa)
+	fa = nil
+	tr.WalkPrefix("fart", func(k string, _ int) bool { fa = append(fa, k); return true })
+	gtest.AssertTrue(t, slices.Equal(fa, []string{"farther"}), "walk fart %v", fa)
+
+	k, _, ok := tr.LongestPrefix("farth")
+	gtest.AssertTrue(t, ok && k == "far", "longest farth %s", k)
+	k, _, ok = tr.LongestPrefix("zzz")
+	gtest.AssertTrue(t, ok && k == "", "longest zzz %s", k)
+
+	gtest.AssertTrue(t, tr.Delete("far"), "delete far")
+	gtest.AssertTrue(t, !tr.Delete("far"), "delete far twice")
+	gtest.AssertTrue(t, !tr.Delete("fart"), "delete fart")
+	k, _, _ = tr.LongestPrefix("farth")
+	gtest.AssertTrue(t, k == "f", "longest farth after delete %s", k)
+	_, ok = tr.Get("farther")
+	gtest.AssertTrue(t, ok, "get farther after delete")
+}
+
+// TestTree_Random compares Tree with a map after random inserts and deletes.
+func TestTree_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(41))
+	tr := NewTree[int]()
+	m := map[string]int{}
+	for i := 0; i < 20000; i++ {
+		b := make([]byte, rnd.Intn(6))
+		for j := range b {
+			b[j] = "abc/"[rnd.Intn(4)]
+		}
+		k := string(b)
+		if rnd.Intn(3) == 0 {
+			_, ok := m[k]
+			gtest.AssertTrue(t, tr.Delete(k) == ok, "delete %s", k)
+			delete(m, k)
+		} else {
+			_, ok := m[k]
+			gtest.AssertTrue(t, tr.Insert(k, i) == !ok, "insert %s", k)
+			m[k] = i
+		}
+	}
+	gtest.AssertTrue(t, tr.Len() == len(m), "len %d != %d", tr.Len(), len(m))
+	prev := ""
+	n := 0
+	for k, v := range tr.All() {
+		gtest.AssertTrue(t, n == 0 || k > prev, "order %s after %s", k, prev)
+		gtest.AssertTrue(t, m[k] == v, "value of %s", k)
+		prev = k
+		n++
+	}
+	gtest.AssertTrue(t, n == len(m), "count %d", n)
+}
+
+func TestTree_Route(t *testing.T) {
+	routes := NewTree[string]()
+	routes.Insert("/", "index")
+	routes.Insert("/api/", "api")
+	routes.Insert("/api/users/", "users")
+	for path, want := range map[string]string{
+		"/about":          "index",
+		"/api/orders/1":   "api",
+		"/api/users/1":    "users",
+		"/api/users":      "api",
+		"/api/users/1/ab": "users",
+	} {
+		_, h, ok := routes.LongestPrefix(path)
+		gtest.AssertTrue(t, ok && h == want, "%s routed to %s", path, h)
+	}
+}
+
+func TestBitTree(t *testing.T) {
+	tr := NewBitTree[string]()
+	for _, cidr := range []string{"10.0.0.0/8", "10.16.0.0/12", "10.16.1.0/24", "192.168.0.0/16", "0.0.0.0/0"} {
+		_, in, err := net.ParseCIDR(cidr)
+		gtest.Assert(t, err)
+		ones, _ := in.Mask.Size()
+		gtest.AssertTrue(t, tr.Insert(in.IP.To4(), ones, cidr), "insert %s", cidr)
+	}
+	lookup := func(ip string) string {
+		_, v, ok := tr.LongestPrefix(net.ParseIP(ip).To4(), 32)
+		gtest.AssertTrue(t, ok, "lookup %s", ip)
+		return v
+	}
+	gtest.AssertTrue(t, lookup("10.17.2.3") == "10.16.0.0/12", "10.17.2.3")
+	gtest.AssertTrue(t, lookup("10.16.1.200") == "10.16.1.0/24", "10.16.1.200")
+	gtest.AssertTrue(t, lookup("10.32.0.1") == "10.0.0.0/8", "10.32.0.1")
+	gtest.AssertTrue(t, lookup("8.8.8.8") == "0.0.0.0/0", "8.8.8.8")
+
+	var under []string
+	tr.WalkPrefix([]byte{10}, 8, func(_ []byte, _ int, v string) bool { under = append(under, v); return true })
+	gtest.AssertTrue(t, slices.Equal(under, []string{"10.0.0.0/8", "10.16.0.0/12", "10.16.1.0/24"}), "walk %v", under)
+
+	gtest.AssertTrue(t, tr.Delete([]byte{10, 16, 0, 0}, 12), "delete /12")
+	gtest.AssertTrue(t, !tr.Delete([]byte{10, 16, 0, 0}, 12), "delete /12 twice")
+	gtest.AssertTrue(t, lookup("10.17.2.3") == "10.0.0.0/8", "10.17.2.3 after delete")
+	gtest.AssertTrue(t, lookup("10.16.1.200") == "10.16.1.0/24", "10.16.1.200 after delete")
+	gtest.AssertTrue(t, tr.Len() == 4, "len %d", tr.Len())
+}
+
+func TestBitTree_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(41))
+	tr := NewBitTree[int]()
+	m := map[string]int{}
+	keyOf := func(b []byte, bits int) string { return fmt.Sprintf("%x/%d", truncate(b, bits), bits) }
+	for i := 0; i < 20000; i++ {
+		b := []byte{byte(rnd.Intn(4)) << 6, byte(rnd.Intn(256))}
+		bits := rnd.Intn(17)
+		k := keyOf(b, bits)
+		_, ok := m[k]
+		if rnd.Intn(3) == 0 {
+			gtest.AssertTrue(t, tr.Delete(b, bits) == ok, "delete %s", k)
+			delete(m, k)
+		} else {
+			gtest.AssertTrue(t, tr.Insert(b, bits, i) == !ok, "insert %s", k)
+			m[k] = i
+		}
+	}
+	gtest.AssertTrue(t, tr.Len() == len(m), "len %d != %d", tr.Len(), len(m))
+	n := 0
+	tr.Walk(func(key []byte, bits int, v int) bool {
+		gtest.AssertTrue(t, m[keyOf(key, bits)] == v, "value of %s", keyOf(key, bits))
+		got, ok := tr.Get(key, bits)
+		gtest.AssertTrue(t, ok && got == v, "get %s", keyOf(key, bits))
+		n++
+		return true
+	})
+	gtest.AssertTrue(t, n == len(m), "count %d", n)
+}
+
+var benchKeys = func() []string {
+	rnd := rand.New(rand.NewSource(1))
+	var keys []string
+	for i := 0; i < 1000; i++ {
+		var sb strings.Builder
+		for j := 0; j < 4+rnd.Intn(12); j++ {
+			sb.WriteByte(byte('a' + rnd.Intn(26)))
+		}
+		keys = append(keys, sb.String())
+	}
+	return keys
+}()
+
+func BenchmarkPatriciaTree_Match(b *testing.B) {
+	pt := NewPatriciaTreeString(benchKeys...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pt.Match(strings.NewReader(benchKeys[i%len(benchKeys)]))
+	}
+}
+
+func BenchmarkTree_Get(b *testing.B) {
+	tr := NewTree[struct{}]()
+	for _, k := range benchKeys {
+		tr.Insert(k, struct{}{})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Get(benchKeys[i%len(benchKeys)])
+	}
+}
+
+func BenchmarkPatriciaTree_MatchPrefix(b *testing.B) {
+	pt := NewPatriciaTreeString(benchKeys...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pt.MatchPrefix(strings.NewReader(benchKeys[i%len(benchKeys)] + "/tail"))
+	}
+}
+
+func BenchmarkTree_LongestPrefix(b *testing.B) {
+	tr := NewTree[struct{}]()
+	for _, k := range benchKeys {
+		tr.Insert(k, struct{}{})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.LongestPrefix(benchKeys[i%len(benchKeys)] + "/tail")
+	}
+}
+
+func BenchmarkTree_Insert(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		tr := NewTree[int]()
+		for j, k := range benchKeys {
+			tr.Insert(k, j)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package gsort
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/sys/gfs"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"unsafe"
+)
+
+/**
+External merge sort —— 外部排序
+Records are buffered in memory until the memory budget is used up, then the buffer is sorted and
+spilled to a temp file as a sorted run. After all records are added, runs are k-way merged with a heap.
+If there are more runs than MaxFanIn, groups of runs are merged into bigger runs first, so the count of
+open files is bounded too.
+Sorting is stable, records which compare equal keep the order they were added.
+
+Run file layout: uvarint length | encoded record, repeated.
+*/
+
+type (
+	// Codec converts records to bytes for spilled runs.
+	Codec[T any] interface {
+		Encode(v T) ([]byte, error)
+		Decode(b []byte) (T, error)
+	}
+
+	BytesCodec   struct{}
+	StringCodec  struct{}
+	StringsCodec struct{} // []string, e.g. csv records
+
+	// JsonCodec encodes records with encoding/json.
+	JsonCodec[T any] struct{}
+
+	ExternalOptions struct {
+		MemoryBudget int64  // approximate bytes of buffered records, default 64MB
+		MaxFanIn     int    // max runs merged at once, default 128
+		TempDir      string // default os.TempDir()
+		Dedup        bool   // only the first one of records which compare equal is kept
+	}
+
+	ExternalSorter[T any] struct {
+		cmp   func(a, b T) int
+		codec Codec[T]
+		opts  ExternalOptions
+		buf   []record[T]
+		used  int64
+		runs  []string
+		done  bool
+	}
+
+	record[T any] struct {
+		v T
+		b []byte
+	}
+
+	runReader[T any] struct {
+		f     *os.File
+		br    *bufio.Reader
+		codec Codec[T]
+	}
+
+	mergeItem[T any] struct {
+		rec record[T]
+		run int
+	}
+
+	mergeHeap[T any] struct {
+		items []mergeItem[T]
+		cmp   func(a, b T) int
+	}
+)
+
+const (
+	defaultMemoryBudget = 64 << 20
+	defaultMaxFanIn     = 128
+)
+
+var ErrSorterDone = gerrors.New("external sorter is already sorted or closed")
+
+func (BytesCodec) Encode(v []byte) ([]byte, error) {
+	return v, nil
+}
+
+func (BytesCodec) Decode(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+func (StringCodec) Encode(v string) ([]byte, error) {
+	return []byte(v), nil
+}
+
+func (StringCodec) Decode(b []byte) (string, error) {
+	return string(b), nil
+}
+
+func (StringsCodec) Encode(v []string) ([]byte, error) {
+	var b []byte
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	for _, s := range v {
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	return b, nil
+}
+
+func (StringsCodec) Decode(b []byte) ([]string, error) {
+	n, k := binary.Uvarint(b)
+	if k <= 0 || n > uint64(len(b)) {
+		return nil, gerrors.New("invalid strings record")
+	}
+	b = b[k:]
+	r := make([]string, n)
+	for i := range r {
+		l, k := binary.Uvarint(b)
+		if k <= 0 || l > uint64(len(b)-k) {
+			return nil, gerrors.New("invalid strings record")
+		}
+		r[i] = string(b[k : k+int(l)])
+		b = b[k+int(l):]
+	}
+	return r, nil
+}
+
+func (JsonCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JsonCodec[T]) Decode(b []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(b, &v)
+	return v, err
+}
+
+// NewExternalSorter creates an external sorter which orders records by cmp,
+// cmp returns negative if a < b, zero if a == b, positive if a > b, like cmp.Compare.
+func NewExternalSorter[T any](cmp func(a, b T) int, codec Codec[T], opts ExternalOptions) *ExternalSorter[T] {
+	if opts.MemoryBudget <= 0 {
+		opts.MemoryBudget = defaultMemoryBudget
+	}
+	if opts.MaxFanIn < 2 {
+		opts.MaxFanIn = defaultMaxFanIn
+	}
+	return &ExternalSorter[T]{cmp: cmp, codec: codec, opts: opts}
+}
+
+// Add adds a record, buffered records are spilled to a sorted run if memory budget is used up.
+// v is kept in memory until it is spilled, so it must not be modified after Add, e.g. bytes of bufio.Scanner.
+func (s *ExternalSorter[T]) Add(v T) error {
+	if s.done {
+		return ErrSorterDone
+	}
+	b, err := s.codec.Encode(v)
+	if err != nil {
+		return err
+	}
+	s.buf = append(s.buf, record[T]{v: v, b: b})
+	s.used += int64(len(b)) + int64(unsafe.Sizeof(record[T]{}))
+	if s.used >= s.opts.MemoryBudget {
+		return s.spill()
+	}
+	return nil
+}
+
+// Runs returns count of spilled runs so far.
+func (s *ExternalSorter[T]) Runs() int {
+	return len(s.runs)
+}
+
+// sortBuf sorts buffered records stably and drops duplicates if needed.
+func (s *ExternalSorter[T]) sortBuf() {
+	slices.SortStableFunc(s.buf, func(a, b record[T]) int { return s.cmp(a.v, b.v) })
+	if s.opts.Dedup {
+		s.buf = slices.CompactFunc(s.buf, func(a, b record[T]) bool { return s.cmp(a.v, b.v) == 0 })
+	}
+}
+
+func (s *ExternalSorter[T]) spill() error {
+	s.sortBuf()
+	f, err := gfs.NewTempFile(s.opts.TempDir)
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, f.Name())
+	bw := bufio.NewWriter(f)
+	for _, rec := range s.buf {
+		if err = writeRecord(bw, rec.b); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	clear(s.buf)
+	s.buf, s.used = s.buf[:0], 0
+	return err
+}
+
+func writeRecord(w *bufio.Writer, b []byte) error {
+	var lb [binary.MaxVarintLen64]byte
+	if _, err := w.Write(lb[:binary.PutUvarint(lb[:], uint64(len(b)))]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func openRun[T any](name string, codec Codec[T]) (*runReader[T], error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &runReader[T]{f: f, br: bufio.NewReader(f), codec: codec}, nil
+}
+
+// next returns io.EOF at the end of run.
+func (rr *runReader[T]) next() (record[T], error) {
+	n, err := binary.ReadUvarint(rr.br)
+	if err != nil {
+		return record[T]{}, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rr.br, b); err != nil {
+		return record[T]{}, gerrors.Wrap(err, "truncated run")
+	}
+	v, err := rr.codec.Decode(b)
+	return record[T]{v: v, b: b}, err
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.items) }
+
+// Less breaks ties by run index, runs are created in the order records were added, so merging is stable.
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	if c := h.cmp(h.items[i].rec.v, h.items[j].rec.v); c != 0 {
+		return c < 0
+	}
+	return h.items[i].run < h.items[j].run
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.items = append(h.items, x.(mergeItem[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	it := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return it
+}
+
+// merge k-way merges runs and calls emit for every record in order, until emit returns false.
+func (s *ExternalSorter[T]) merge(runs []string, emit func(rec record[T]) bool) error {
+	readers := make([]*runReader[T], 0, len(runs))
+	defer func() {
+		for _, rr := range readers {
+			_ = rr.f.Close()
+		}
+	}()
+	h := &mergeHeap[T]{cmp: s.cmp}
+	for i, name := range runs {
+		rr, err := openRun(name, s.codec)
+		if err != nil {
+			return err
+		}
+		readers = append(readers, rr)
+		rec, err := rr.next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		h.items = append(h.items, mergeItem[T]{rec: rec, run: i})
+	}
+	heap.Init(h)
+
+	var last *T
+	for h.Len() > 0 {
+		top := &h.items[0]
+		rec := top.rec
+		next, err := readers[top.run].next()
+		switch {
+		case err == io.EOF:
+			heap.Pop(h)
+		case err != nil:
+			return err
+		default:
+			top.rec = next
+			heap.Fix(h, 0)
+		}
+		if s.opts.Dedup && last != nil && s.cmp(*last, rec.v) == 0 {
+			continue
+		}
+		last = &rec.v
+		if !emit(rec) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// reduce merges consecutive groups of runs pass by pass until count of runs is not more than MaxFanIn,
+// merged runs keep the order of runs, so merging is still stable.
+func (s *ExternalSorter[T]) reduce() error {
+	for len(s.runs) > s.opts.MaxFanIn {
+		var merged []string
+		for len(s.runs) > 0 {
+			group := s.runs[:min(len(s.runs), s.opts.MaxFanIn)]
+			name, err := s.mergeToRun(group)
+			if name != "" {
+				merged = append(merged, name)
+			}
+			if err != nil {
+				s.runs = append(merged, s.runs...)
+				return err
+			}
+			for _, g := range group {
+				_ = os.Remove(g)
+			}
+			s.runs = s.runs[len(group):]
+		}
+		s.runs = merged
+	}
+	return nil
+}
+
+func (s *ExternalSorter[T]) mergeToRun(group []string) (string, error) {
+	f, err := gfs.NewTempFile(s.opts.TempDir)
+	if err != nil {
+		return "", err
+	}
+	bw := bufio.NewWriter(f)
+	var werr error
+	err = s.merge(group, func(rec record[T]) bool {
+		werr = writeRecord(bw, rec.b)
+		return werr == nil
+	})
+	if err == nil {
+		err = werr
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return f.Name(), err
+}
+
+// Sorted sorts all added records and iterates them in order, error is yielded as the last element if any.
+// Temp files are removed when iteration ends, records can't be added after Sorted.
+func (s *ExternalSorter[T]) Sorted() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if s.done {
+			var zero T
+			yield(zero, ErrSorterDone)
+			return
+		}
+		s.done = true
+		defer s.Close()
+
+		if len(s.runs) == 0 {
+			// everything fits in memory
+			s.sortBuf()
+			for _, rec := range s.buf {
+				if !yield(rec.v, nil) {
+					return
+				}
+			}
+			return
+		}
+		var err error
+		if len(s.buf) > 0 {
+			err = s.spill()
+		}
+		if err == nil {
+			err = s.reduce()
+		}
+		if err == nil {
+			err = s.merge(s.runs, func(rec record[T]) bool { return yield(rec.v, nil) })
+		}
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// Close removes temp files and drops buffered records, it is called by Sorted automatically.
+func (s *ExternalSorter[T]) Close() error {
+	s.done = true
+	var errs []error
+	for _, name := range s.runs {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	s.runs, s.buf, s.used = nil, nil, 0
+	return gerrors.JoinArray(errs)
+}
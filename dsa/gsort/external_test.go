@@ -0,0 +1,87 @@
+package gsort
+
+import (
+	"cmp"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+type testRecord struct {
+	Key int
+	Seq int
+}
+
+func TestExternalSorter(t *testing.T) {
+	dir := t.TempDir()
+	rnd := rand.New(rand.NewSource(40))
+
+	// small budget and fan-in forces many runs and multi-pass merging
+	s := NewExternalSorter(func(a, b testRecord) int { return cmp.Compare(a.Key, b.Key) },
+		JsonCodec[testRecord]{}, ExternalOptions{MemoryBudget: 4 << 10, MaxFanIn: 3, TempDir: dir})
+	var want []testRecord
+	for i := 0; i < 5000; i++ {
+		r := testRecord{Key: rnd.Intn(500), Seq: i}
+		want = append(want, r)
+		gtest.Assert(t, s.Add(r))
+	}
+	gtest.AssertTrue(t, s.Runs() > 3, "runs %d", s.Runs())
+	slices.SortStableFunc(want, func(a, b testRecord) int { return cmp.Compare(a.Key, b.Key) })
+
+	var got []testRecord
+	for r, err := range s.Sorted() {
+		gtest.Assert(t, err)
+		got = append(got, r)
+	}
+	// stable: equal keys keep the order they were added
+	gtest.AssertTrue(t, slices.Equal(got, want), "unstable or wrong order")
+	entries, err := os.ReadDir(dir)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, len(entries) == 0, "temp files left: %d", len(entries))
+	gtest.AssertTrue(t, s.Add(testRecord{}) == ErrSorterDone, "add after sorted")
+}
+
+func TestExternalSorter_Dedup(t *testing.T) {
+	for _, budget := range []int64{0, 256} { // in memory, spilled
+		s := NewExternalSorter(cmp.Compare[string], StringCodec{}, ExternalOptions{MemoryBudget: budget, Dedup: true, TempDir: t.TempDir()})
+		for i := 0; i < 1000; i++ {
+			gtest.Assert(t, s.Add(strconv.Itoa(i%37)))
+		}
+		var got []string
+		for v, err := range s.Sorted() {
+			gtest.Assert(t, err)
+			got = append(got, v)
+		}
+		gtest.AssertTrue(t, len(got) == 37 && slices.IsSorted(got), "budget %d: got %v", budget, got)
+	}
+}
+
+func TestExternalSorter_Break(t *testing.T) {
+	dir := t.TempDir()
+	s := NewExternalSorter(slices.Compare[[]byte], BytesCodec{}, ExternalOptions{MemoryBudget: 128, TempDir: dir})
+	for i := 9999; i >= 0; i-- {
+		gtest.Assert(t, s.Add([]byte(strconv.Itoa(i))))
+	}
+	for v, err := range s.Sorted() {
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, string(v) == "0", "got %s", v)
+		break
+	}
+	entries, err := os.ReadDir(dir)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, len(entries) == 0, "temp files left: %d", len(entries))
+}
+
+func TestStringsCodec(t *testing.T) {
+	in := []string{"a", "", "中文,\"quoted\"\n"}
+	b, err := StringsCodec{}.Encode(in)
+	gtest.Assert(t, err)
+	out, err := StringsCodec{}.Decode(b)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, slices.Equal(in, out), "got %q", out)
+	_, err = StringsCodec{}.Decode(b[:len(b)-1])
+	gtest.AssertTrue(t, err != nil, "truncated record")
+}
@@ -0,0 +1,125 @@
+package guuid
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freezeClock makes nowFunc return t until the returned func is called.
+func freezeClock(t time.Time) func() {
+	nowFunc = func() time.Time { return t }
+	return func() { nowFunc = time.Now }
+}
+
+func TestNewV5(t *testing.T) {
+	u := NewV5(NamespaceDNS, "www.example.com")
+	gtest.AssertTrue(t, u.String() == "2ed6657d-e927-568b-95e1-2665a8aea6a2", "got %s", u.String())
+	u2, err := ParseShort(ShortString(u))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, u2 == u, "short %s", ShortString(u))
+}
+
+func TestNewV7(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	defer freezeClock(now)()
+
+	g := &V7Generator{}
+	prev := g.New()
+	for i := 0; i < 10000; i++ { // overflows the 12 bits counter several times
+		u := g.New()
+		gtest.AssertTrue(t, bytes.Compare(u[:], prev[:]) > 0 && u.String() > prev.String(), "not monotonic %s <= %s", u, prev)
+		gtest.AssertTrue(t, u.Version() == 7 && u.Variant().String() == "RFC4122", "version %d", u.Version())
+		prev = u
+	}
+	first, _ := Parse(NewV7().String())
+	ts, err := TimeOf(first)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, ts.Equal(now), "time %s", ts)
+	_, err = TimeOf(NewV5(NamespaceURL, "x"))
+	gtest.AssertTrue(t, err != nil, "time of v5")
+
+	// leading zero bytes survive base58
+	var zero UUID
+	zero[15] = 1
+	u, err := ParseShort(ShortString(zero))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, u == zero, "short %s", ShortString(zero))
+}
+
+func TestULID(t *testing.T) {
+	u, err := ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, u.String() == "01ARZ3NDEKTSV4RRFFQ69G5FAV", "got %s", u.String())
+	gtest.AssertTrue(t, u.Ms() == 1469922850259, "ms %d", u.Ms())
+	lower, err := ParseULID(strings.ToLower("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, lower == u, "lower case")
+	_, err = ParseULID("81ARZ3NDEKTSV4RRFFQ69G5FAV") // overflows 128 bits
+	gtest.AssertTrue(t, err != nil, "overflow")
+	_, err = ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAU")
+	gtest.AssertTrue(t, err != nil, "invalid char")
+
+	now := time.UnixMilli(1700000000123)
+	defer freezeClock(now)()
+	g := &ULIDGenerator{}
+	prev := g.New()
+	for i := 0; i < 1000; i++ {
+		u := g.New()
+		gtest.AssertTrue(t, u.String() > prev.String() && u.Time().Equal(now), "not monotonic %s <= %s", u, prev)
+		prev = u
+	}
+	// randomness overflow moves to the next millisecond
+	for i := 6; i < 16; i++ {
+		g.last[i] = 0xff
+	}
+	gtest.AssertTrue(t, g.New().Ms() == now.UnixMilli()+1, "overflow")
+}
+
+func TestKSUID(t *testing.T) {
+	k, err := ParseKSUID("0ujtsYcgvSTl8PAuAdqWYSMnLOv")
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, k.String() == "0ujtsYcgvSTl8PAuAdqWYSMnLOv", "got %s", k.String())
+	gtest.AssertTrue(t, k.Time().Unix() == 107608047+KSUIDEpoch, "time %s", k.Time())
+	gtest.AssertTrue(t, fmt.Sprintf("%X", k.Payload()) == "B5A1CD34B5F99D1154FB6853345C9735", "payload %X", k.Payload())
+	_, err = ParseKSUID("zzzzzzzzzzzzzzzzzzzzzzzzzzz")
+	gtest.AssertTrue(t, err != nil, "overflow")
+
+	now := time.Unix(1700000000, 0)
+	defer freezeClock(now)()
+	g := &KSUIDGenerator{}
+	prev := g.New()
+	for i := 0; i < 1000; i++ {
+		k := g.New()
+		gtest.AssertTrue(t, k.String() > prev.String() && k.Time().Equal(now), "not monotonic %s <= %s", k, prev)
+		prev = k
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	defer freezeClock(now)()
+	_, err := NewSnowflake(SnowflakeOptions{WorkerID: 1024})
+	gtest.AssertTrue(t, err != nil, "worker id out of bits")
+
+	s, err := NewSnowflake(SnowflakeOptions{WorkerID: 5, SequenceBits: 4})
+	gtest.Assert(t, err)
+	prev := int64(0)
+	for i := 0; i < 40; i++ { // sequence of 4 bits overflows
+		id, err := s.Next()
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, id > prev, "not monotonic %d <= %d", id, prev)
+		ts, worker, seq := s.Decompose(id)
+		gtest.AssertTrue(t, worker == 5 && seq == int64(i%16), "worker %d seq %d", worker, seq)
+		gtest.AssertTrue(t, ts.Equal(now.Add(time.Duration(i/16)*time.Millisecond)), "time %s", ts)
+		b32 := SnowflakeBase32(id)
+		gtest.AssertTrue(t, len(b32) == 13 && b32 > SnowflakeBase32(prev), "base32 %s", b32)
+		parsed, err := ParseSnowflakeBase32(b32)
+		gtest.Assert(t, err)
+		gtest.AssertTrue(t, parsed == id, "parsed %d", parsed)
+		prev = id
+	}
+}
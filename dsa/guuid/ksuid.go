@@ -0,0 +1,85 @@
+package guuid
+
+import (
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/crypto/gbase"
+	"sync"
+	"time"
+)
+
+type (
+	// KSUID is 32 bits seconds since KSUID epoch and 128 bits randomness, its string form is 27 chars of
+	// base62 which sorts in the same order as time, see https://github.com/segmentio/ksuid.
+	KSUID [20]byte
+
+	// KSUIDGenerator generates monotonic KSUIDs, in the same second the payload of the previous KSUID
+	// is incremented by 1, if it overflows the timestamp runs ahead of the clock by 1s.
+	KSUIDGenerator struct {
+		mu   sync.Mutex
+		last KSUID
+	}
+)
+
+// KSUIDEpoch is 2014-05-13T16:53:20Z, timestamp 0 of KSUID.
+const KSUIDEpoch = 1400000000
+
+var defaultKSUID KSUIDGenerator
+
+// NewKSUID generates monotonic KSUID by the default generator.
+func NewKSUID() KSUID {
+	return defaultKSUID.New()
+}
+
+func (g *KSUIDGenerator) New() KSUID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ts := uint32(nowFunc().Unix() - KSUIDEpoch)
+	if last := binary.BigEndian.Uint32(g.last[:4]); ts > last || g.last == (KSUID{}) {
+		binary.BigEndian.PutUint32(g.last[:4], ts)
+		randBytes(g.last[4:])
+	} else if !increment(g.last[4:]) {
+		binary.BigEndian.PutUint32(g.last[:4], last+1)
+		randBytes(g.last[4:])
+	}
+	return g.last
+}
+
+// ParseKSUID parses 27 chars base62 KSUID string.
+func ParseKSUID(s string) (KSUID, error) {
+	var k KSUID
+	if len(s) != 27 {
+		return k, gerrors.New("invalid ksuid length %d of %s", len(s), s)
+	}
+	b, err := gbase.Base62Decode(s, len(k))
+	if err != nil {
+		return k, gerrors.Wrap(err, "invalid ksuid")
+	}
+	copy(k[:], b)
+	return k, nil
+}
+
+func (k KSUID) String() string {
+	return gbase.Base62Encode(k[:])
+}
+
+func (k KSUID) Time() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(k[:4]))+KSUIDEpoch, 0)
+}
+
+// Payload returns the random part of k.
+func (k KSUID) Payload() []byte {
+	return k[4:]
+}
+
+func (k KSUID) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *KSUID) UnmarshalText(b []byte) error {
+	v, err := ParseKSUID(string(b))
+	if err == nil {
+		*k = v
+	}
+	return err
+}
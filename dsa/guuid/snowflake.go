@@ -0,0 +1,117 @@
+package guuid
+
+import (
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/crypto/gbase"
+	"github.com/davidforest123/goutil/sys/gmachineid"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+/*
+Snowflake ID is a positive int64 which consists of:
+| 0 | timestamp in milliseconds since epoch | worker id | sequence in the millisecond |
+Default layout is 41 bits timestamp, 10 bits worker id and 12 bits sequence like Twitter.
+*/
+
+type (
+	SnowflakeOptions struct {
+		Epoch        time.Time // default 2020-01-01T00:00:00Z
+		WorkerBits   int       // default 10
+		SequenceBits int       // default 12
+		WorkerID     int64
+	}
+
+	// Snowflake generates monotonic snowflake IDs, if the sequence overflows or the clock goes backwards,
+	// the timestamp runs ahead of the clock instead of blocking, so IDs never go backwards.
+	Snowflake struct {
+		mu      sync.Mutex
+		opts    SnowflakeOptions
+		epochMs int64
+		tsBits  int
+		lastMs  int64
+		seq     int64
+	}
+)
+
+var defaultSnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// MachineWorkerID derives a worker id of bits from machine id of gmachineid.
+func MachineWorkerID(bits int) (int64, error) {
+	id, err := gmachineid.Get()
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return int64(h.Sum64() & (1<<bits - 1)), nil
+}
+
+func NewSnowflake(opts SnowflakeOptions) (*Snowflake, error) {
+	if opts.Epoch.IsZero() {
+		opts.Epoch = defaultSnowflakeEpoch
+	}
+	if opts.WorkerBits == 0 {
+		opts.WorkerBits = 10
+	}
+	if opts.SequenceBits == 0 {
+		opts.SequenceBits = 12
+	}
+	tsBits := 63 - opts.WorkerBits - opts.SequenceBits
+	if opts.WorkerBits < 0 || opts.SequenceBits < 1 || tsBits < 32 {
+		return nil, gerrors.New("invalid snowflake bits, worker %d, sequence %d", opts.WorkerBits, opts.SequenceBits)
+	}
+	if opts.WorkerID < 0 || opts.WorkerID >= 1<<opts.WorkerBits {
+		return nil, gerrors.New("worker id %d out of %d bits", opts.WorkerID, opts.WorkerBits)
+	}
+	return &Snowflake{opts: opts, epochMs: opts.Epoch.UnixMilli(), tsBits: tsBits, lastMs: -1}, nil
+}
+
+func (s *Snowflake) Next() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ms := nowFunc().UnixMilli() - s.epochMs
+	if ms < 0 {
+		return 0, gerrors.New("clock is before snowflake epoch %s", s.opts.Epoch.String())
+	}
+	if ms > s.lastMs {
+		s.lastMs, s.seq = ms, 0
+	} else if s.seq++; s.seq >= 1<<s.opts.SequenceBits {
+		s.lastMs, s.seq = s.lastMs+1, 0
+	}
+	if s.lastMs >= 1<<s.tsBits {
+		return 0, gerrors.New("snowflake timestamp overflows %d bits", s.tsBits)
+	}
+	return s.lastMs<<(s.opts.WorkerBits+s.opts.SequenceBits) | s.opts.WorkerID<<s.opts.SequenceBits | s.seq, nil
+}
+
+// Decompose splits id into generation time, worker id and sequence.
+func (s *Snowflake) Decompose(id int64) (t time.Time, workerID, seq int64) {
+	seq = id & (1<<s.opts.SequenceBits - 1)
+	workerID = id >> s.opts.SequenceBits & (1<<s.opts.WorkerBits - 1)
+	ms := id >> (s.opts.WorkerBits + s.opts.SequenceBits)
+	return time.UnixMilli(ms + s.epochMs), workerID, seq
+}
+
+// SnowflakeBase32 encodes id to 13 chars Crockford base32 which sorts in the same order as id.
+func SnowflakeBase32(id int64) string {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(id))
+	return gbase.Base32CrockfordEncode(b[:])
+}
+
+func ParseSnowflakeBase32(s string) (int64, error) {
+	if len(s) != 13 {
+		return 0, gerrors.New("invalid snowflake base32 length %d of %s", len(s), s)
+	}
+	b, err := gbase.Base32CrockfordDecode(s)
+	if err != nil {
+		return 0, err
+	}
+	if b[0]&0x80 != 0 {
+		return 0, gerrors.New("snowflake base32 %s overflows int64", s)
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
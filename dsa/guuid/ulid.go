@@ -0,0 +1,101 @@
+package guuid
+
+import (
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/crypto/gbase"
+	"sync"
+	"time"
+)
+
+type (
+	// ULID is 48 bits milliseconds timestamp and 80 bits randomness, its string form is 26 chars of
+	// Crockford base32 which sorts in the same order as time, see https://github.com/ulid/spec.
+	ULID [16]byte
+
+	// ULIDGenerator generates monotonic ULIDs, in the same millisecond the randomness of the previous ULID
+	// is incremented by 1, if it overflows the timestamp runs ahead of the clock by 1ms.
+	ULIDGenerator struct {
+		mu   sync.Mutex
+		last ULID
+	}
+)
+
+var defaultULID ULIDGenerator
+
+// increment increments big endian b by 1, it returns false if b overflows to zero.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func putMs(u *ULID, ms int64) {
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(u[:6], ts[2:])
+}
+
+// NewULID generates monotonic ULID by the default generator.
+func NewULID() ULID {
+	return defaultULID.New()
+}
+
+func (g *ULIDGenerator) New() ULID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := nowFunc().UnixMilli()
+	if ms > g.last.Ms() {
+		putMs(&g.last, ms)
+		randBytes(g.last[6:])
+	} else if !increment(g.last[6:]) {
+		putMs(&g.last, g.last.Ms()+1)
+		randBytes(g.last[6:])
+	}
+	return g.last
+}
+
+// ParseULID parses ULID string, it is case-insensitive.
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+	if len(s) != 26 {
+		return u, gerrors.New("invalid ulid length %d of %s", len(s), s)
+	}
+	b, err := gbase.Base32CrockfordDecode(s)
+	if err != nil {
+		return u, gerrors.Wrap(err, "invalid ulid")
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+func (u ULID) String() string {
+	return gbase.Base32CrockfordEncode(u[:])
+}
+
+// Ms returns milliseconds timestamp of u.
+func (u ULID) Ms() int64 {
+	var ts [8]byte
+	copy(ts[2:], u[:6])
+	return int64(binary.BigEndian.Uint64(ts[:]))
+}
+
+func (u ULID) Time() time.Time {
+	return time.UnixMilli(u.Ms())
+}
+
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *ULID) UnmarshalText(b []byte) error {
+	v, err := ParseULID(string(b))
+	if err == nil {
+		*u = v
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+package guuid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/crypto/gbase"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+type (
+	UUID = uuid.UUID
+
+	// V7Generator generates UUIDv7 which is ordered by generation time, UUIDs generated in the same millisecond
+	// are ordered by a 12 bits counter in rand_a field, which starts from a random value every millisecond.
+	// If the counter overflows, the timestamp runs ahead of the clock by 1ms, so UUIDs never go backwards.
+	V7Generator struct {
+		mu     sync.Mutex
+		lastMs int64
+		seq    uint16
+	}
+)
+
+var (
+	NamespaceDNS  = uuid.NameSpaceDNS
+	NamespaceURL  = uuid.NameSpaceURL
+	NamespaceOID  = uuid.NameSpaceOID
+	NamespaceX500 = uuid.NameSpaceX500
+
+	defaultV7 V7Generator
+
+	// nowFunc is replaced in tests.
+	nowFunc = time.Now
+)
+
+// randBytes fills b with crypto random bytes.
+func randBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+}
+
+// NewV5 generates name based UUIDv5 by SHA-1, same namespace and name always generate the same UUID.
+func NewV5(namespace UUID, name string) UUID {
+	return uuid.NewSHA1(namespace, []byte(name))
+}
+
+// NewV7 generates time ordered UUIDv7 by the default generator.
+func NewV7() UUID {
+	return defaultV7.New()
+}
+
+func (g *V7Generator) New() UUID {
+	g.mu.Lock()
+	ms := nowFunc().UnixMilli()
+	if ms > g.lastMs {
+		var b [2]byte
+		randBytes(b[:])
+		// start from the lower half, leave room for the counter
+		g.lastMs, g.seq = ms, binary.BigEndian.Uint16(b[:])&0x7ff
+	} else if g.seq++; g.seq > 0xfff {
+		g.lastMs, g.seq = g.lastMs+1, 0
+	}
+	ms, seq := g.lastMs, g.seq
+	g.mu.Unlock()
+
+	var u UUID
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(u[:6], ts[2:])
+	u[6] = 0x70 | byte(seq>>8)
+	u[7] = byte(seq)
+	randBytes(u[8:])
+	u[8] = u[8]&0x3f | 0x80 // variant RFC 9562
+	return u
+}
+
+// Parse parses UUID in standard form "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", with or without dashes.
+func Parse(s string) (UUID, error) {
+	return uuid.Parse(s)
+}
+
+// TimeOf returns the timestamp of UUIDv7 in milliseconds precision.
+func TimeOf(u UUID) (time.Time, error) {
+	if u.Version() != 7 {
+		return time.Time{}, gerrors.New("uuid %s is version %d, not 7", u.String(), u.Version())
+	}
+	var ts [8]byte
+	copy(ts[2:], u[:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(ts[:]))), nil
+}
+
+// ShortString encodes u to base58 string of at most 22 chars.
+func ShortString(u UUID) string {
+	return gbase.Base58BtcStyleEncode(u[:])
+}
+
+// ParseShort parses UUID encoded by ShortString.
+func ParseShort(s string) (UUID, error) {
+	var u UUID
+	if s == "" || !gbase.IsBase58BtcStyle(s) {
+		return u, gerrors.New("invalid short uuid %s", s)
+	}
+	b, err := gbase.Base58BtcStyleDecode(s)
+	if err != nil {
+		return u, err
+	}
+	if len(b) > len(u) {
+		return u, gerrors.New("short uuid %s overflows 16 bytes", s)
+	}
+	copy(u[len(u)-len(b):], b)
+	return u, nil
+}
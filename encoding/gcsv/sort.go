@@ -0,0 +1,71 @@
+package gcsv
+
+import (
+	"encoding/csv"
+	"github.com/davidforest123/goutil/dsa/gsort"
+	"io"
+	"strings"
+)
+
+// SortStream sorts csv records read from r into w by cmp with memory bounded by opts.MemoryBudget,
+// records are spilled to temp files by gsort.ExternalSorter, so csv larger than memory can be sorted.
+// If hasHeader is true, the first record is written first as is.
+func SortStream(r io.Reader, w io.Writer, hasHeader bool, cmp func(a, b []string) int, opts gsort.ExternalOptions) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = false
+	cw := csv.NewWriter(w)
+
+	if hasHeader {
+		headers, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+	}
+
+	sorter := gsort.NewExternalSorter(cmp, gsort.StringsCodec{}, opts)
+	defer sorter.Close()
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := sorter.Add(record); err != nil {
+			return err
+		}
+	}
+	for record, err := range sorter.Sorted() {
+		if err != nil {
+			return err
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// CompareColumn compares records by column idx as strings, missing column is less than any value.
+func CompareColumn(idx int) func(a, b []string) int {
+	return func(a, b []string) int {
+		switch {
+		case idx >= len(a) && idx >= len(b):
+			return 0
+		case idx >= len(a):
+			return -1
+		case idx >= len(b):
+			return 1
+		}
+		return strings.Compare(a[idx], b[idx])
+	}
+}
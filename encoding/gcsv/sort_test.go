@@ -0,0 +1,18 @@
+package gcsv
+
+import (
+	"bytes"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"github.com/davidforest123/goutil/dsa/gsort"
+	"strings"
+	"testing"
+)
+
+func TestSortStream(t *testing.T) {
+	in := "name,city\ntom,\"new\nyork\"\njack,beijing\namy,paris\njack,tokyo\n"
+	out := bytes.NewBuffer(nil)
+	err := SortStream(strings.NewReader(in), out, true, CompareColumn(0), gsort.ExternalOptions{MemoryBudget: 64, TempDir: t.TempDir()})
+	gtest.Assert(t, err)
+	want := "name,city\namy,paris\njack,beijing\njack,tokyo\ntom,\"new\nyork\"\n"
+	gtest.AssertTrue(t, out.String() == want, "got %q", out.String())
+}
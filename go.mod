@@ -1,6 +1,6 @@
 module github.com/davidforest123/goutil
 
-go 1.22.2
+go 1.23
 
 require (
 	github.com/AdguardTeam/dnsproxy v0.68.0
@@ -30,15 +30,12 @@ require (
 	github.com/d5/tengo/v2 v2.13.0
 	github.com/domainr/whois v0.1.0
 	github.com/dop251/goja v0.0.0-20221118162653-d4bf6fde1b86
-	github.com/dxhbiz/codec v0.0.1
 	github.com/emersion/go-imap v1.2.1
 	github.com/emersion/go-message v0.16.0
-	github.com/emirpasic/gods v1.18.1
 	github.com/ethereum/go-ethereum v1.10.26
 	github.com/extrame/xls v0.0.1
 	github.com/fatih/structs v1.1.0
 	github.com/frankenbeanies/randhex v0.0.0-20191121050539-48f4de439ea4
-	github.com/gabriel-vasile/mimetype v1.4.1
 	github.com/getlantern/appdir v0.0.0-20200615192800-a0ef1968f4da
 	github.com/getlantern/osversion v0.0.0-20230401075644-c2a30e73c451
 	github.com/getlantern/pac v0.0.0-20161019162755-5534aa917168
@@ -54,7 +51,6 @@ require (
 	github.com/google/uuid v1.4.0
 	github.com/gorilla/websocket v1.5.0
 	github.com/goware/urlx v0.3.2
-	github.com/h2non/filetype v1.1.3
 	github.com/hako/durafmt v0.0.0-20210608085754-5c1018a4e16b
 	github.com/jackpal/gateway v1.0.7
 	github.com/jbenet/go-base58 v0.0.0-20150317085156-6237cf65f3a6
@@ -87,7 +83,6 @@ require (
 	github.com/r3labs/diff v1.1.0
 	github.com/radovskyb/watcher v1.0.7
 	github.com/richardlehane/characterize v1.0.0
-	github.com/ross-spencer/sfclassic v0.0.0-20190809170605-5e8ee763688f
 	github.com/saintfish/chardet v0.0.0-20120816061221-3af4cd4741ca
 	github.com/samuel/go-zookeeper v0.0.0-20201211165307-7117e9ea2414
 	github.com/sekrat/aescrypter v1.0.0
@@ -114,7 +109,6 @@ require (
 	github.com/xtaci/kcp-go v5.4.20+incompatible
 	github.com/xtaci/smux v1.5.17
 	github.com/yeka/zip v0.0.0-20180914125537-d046722c6feb
-	github.com/yl2chen/cidranger v1.0.2
 	go.mongodb.org/mongo-driver v1.11.0
 	golang.org/x/crypto v0.21.0
 	golang.org/x/net v0.23.0
@@ -213,11 +207,6 @@ require (
 	github.com/power-devops/perfstat v0.0.0-20210106213030-5aafc221ea8c // indirect
 	github.com/quic-go/qpack v0.4.0 // indirect
 	github.com/quic-go/quic-go v0.41.0 // indirect
-	github.com/richardlehane/match v1.0.0 // indirect
-	github.com/richardlehane/mscfb v1.0.1 // indirect
-	github.com/richardlehane/msoleps v1.0.1 // indirect
-	github.com/richardlehane/siegfried v1.7.12-0.20190401190115-99b4106927c7 // indirect
-	github.com/richardlehane/xmldetect v1.0.0 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/robotn/gohook v0.31.3 // indirect
 	github.com/robotn/xgb v0.0.0-20190912153532-2cb92d044934 // indirect
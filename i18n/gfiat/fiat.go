@@ -4,6 +4,23 @@ import (
 	"golang.org/x/text/currency"
 )
 
+// Currency is ISO 4217 currency metadata.
+type Currency struct {
+	Code       string // ISO 4217 alphabetic code, e.g. "USD"
+	MinorUnits int    // digits after decimal point, e.g. 2 for USD, 0 for JPY
+}
+
+// isoMinorUnits lists ISO 4217 minor units which are not 2, CLDR digits used by x/text differ from ISO
+// for some currencies, so ISO values are kept here.
+var isoMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+	// funds and precious metals have no minor unit in ISO 4217
+	"XAG": 0, "XAU": 0, "XBA": 0, "XBB": 0, "XBC": 0, "XBD": 0, "XDR": 0, "XPD": 0, "XPT": 0, "XSU": 0, "XUA": 0,
+}
+
 func ParseFiat(s string) (string, error) {
 	_, err := currency.ParseISO(s)
 	if err != nil {
@@ -11,3 +28,37 @@ func ParseFiat(s string) (string, error) {
 	}
 	return s, nil
 }
+
+// ParseCurrency parses ISO 4217 code case-insensitively.
+func ParseCurrency(code string) (Currency, error) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return Currency{}, err
+	}
+	c := Currency{Code: unit.String(), MinorUnits: 2}
+	if mu, ok := isoMinorUnits[c.Code]; ok {
+		c.MinorUnits = mu
+	}
+	return c, nil
+}
+
+func MustParseCurrency(code string) Currency {
+	c, err := ParseCurrency(code)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// MinorUnits returns ISO 4217 minor units of code.
+func MinorUnits(code string) (int, error) {
+	c, err := ParseCurrency(code)
+	if err != nil {
+		return 0, err
+	}
+	return c.MinorUnits, nil
+}
+
+func (c Currency) String() string {
+	return c.Code
+}
@@ -157,12 +157,9 @@ func (c *connMsg) Read() ([]byte, error) {
 }
 
 func (c *connMsg) Write(data []byte) error {
-	maxUint16, err := gnum.Max(uint16(0))
-	if err != nil {
-		return err
-	}
-	if len(data) > int(maxUint16.(uint16)) {
-		return gerrors.New("msg data length %d is bigger than max limit %d", len(data), maxUint16.(uint16))
+	maxUint16 := gnum.MaxValue[uint16]()
+	if len(data) > int(maxUint16) {
+		return gerrors.New("msg data length %d is bigger than max limit %d", len(data), maxUint16)
 	}
 
 	msg, err := newMsg(data)
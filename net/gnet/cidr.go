@@ -2,27 +2,76 @@ package gnet
 
 import (
 	"github.com/davidforest123/goutil/basic/gerrors"
-	"github.com/yl2chen/cidranger"
+	"github.com/davidforest123/goutil/dsa/gpatricia"
 	"net"
 )
 
 type (
-	// CidrRanger is a fast IP to CIDR lookup.
+	// CidrRanger is a fast IP to CIDR lookup backed by patricia trees, one for IPv4 and one for IPv6.
 	CidrRanger struct {
-		rg cidranger.Ranger
+		v4 *gpatricia.BitTree[net.IPNet]
+		v6 *gpatricia.BitTree[net.IPNet]
 	}
 )
 
 func NewCidrRanger() *CidrRanger {
-	return &CidrRanger{rg: cidranger.NewPCTrieRanger()}
+	return &CidrRanger{v4: gpatricia.NewBitTree[net.IPNet](), v6: gpatricia.NewBitTree[net.IPNet]()}
+}
+
+// tree returns the tree and key bytes of ip.
+func (cr *CidrRanger) tree(ip net.IP) (*gpatricia.BitTree[net.IPNet], []byte, error) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return cr.v4, ip4, nil
+	}
+	if len(ip) == net.IPv6len {
+		return cr.v6, ip, nil
+	}
+	return nil, nil, gerrors.New("invalid ip %s", ip.String())
+}
+
+func (cr *CidrRanger) network(in net.IPNet) (*gpatricia.BitTree[net.IPNet], []byte, int, error) {
+	t, key, err := cr.tree(in.IP)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	ones, bits := in.Mask.Size()
+	if bits != len(key)*8 {
+		return nil, nil, 0, gerrors.New("invalid mask of %s", in.String())
+	}
+	return t, key, ones, nil
 }
 
 func (cr *CidrRanger) Insert(in net.IPNet) error {
-	return cr.rg.Insert(cidranger.NewBasicRangerEntry(in))
+	t, key, ones, err := cr.network(in)
+	if err != nil {
+		return err
+	}
+	t.Insert(key, ones, in)
+	return nil
+}
+
+// Remove removes network in, it returns false if in was not inserted.
+func (cr *CidrRanger) Remove(in net.IPNet) (bool, error) {
+	t, key, ones, err := cr.network(in)
+	if err != nil {
+		return false, err
+	}
+	return t.Delete(key, ones), nil
 }
 
 func (cr *CidrRanger) Contains(ip net.IP) (bool, error) {
-	return cr.rg.Contains(ip)
+	_, ok, err := cr.Lookup(ip)
+	return ok, err
+}
+
+// Lookup returns the most specific network which contains ip.
+func (cr *CidrRanger) Lookup(ip net.IP) (net.IPNet, bool, error) {
+	t, key, err := cr.tree(ip)
+	if err != nil {
+		return net.IPNet{}, false, err
+	}
+	_, in, ok := t.LongestPrefix(key, len(key)*8)
+	return in, ok, nil
 }
 
 // Extracts IP mask from CIDR address.
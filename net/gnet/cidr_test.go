@@ -0,0 +1,32 @@
+package gnet
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"net"
+	"testing"
+)
+
+func TestCidrRanger(t *testing.T) {
+	cr := NewCidrRanger()
+	for _, cidr := range []string{"10.0.0.0/8", "10.1.0.0/16", "2001:db8::/32"} {
+		_, in, err := net.ParseCIDR(cidr)
+		gtest.Assert(t, err)
+		gtest.Assert(t, cr.Insert(*in))
+	}
+	in, ok, err := cr.Lookup(net.ParseIP("10.1.2.3"))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, ok && in.String() == "10.1.0.0/16", "lookup 10.1.2.3: %s", in.String())
+	ok, err = cr.Contains(net.ParseIP("11.0.0.1"))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, !ok, "11.0.0.1 is contained")
+	ok, err = cr.Contains(net.ParseIP("2001:db8::1"))
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, ok, "2001:db8::1 is not contained")
+
+	_, in16, _ := net.ParseCIDR("10.1.0.0/16")
+	removed, err := cr.Remove(*in16)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, removed, "remove 10.1.0.0/16")
+	in, _, _ = cr.Lookup(net.ParseIP("10.1.2.3"))
+	gtest.AssertTrue(t, in.String() == "10.0.0.0/8", "lookup after remove: %s", in.String())
+}
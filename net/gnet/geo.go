@@ -1,6 +1,7 @@
 package gnet
 
 import (
+	"github.com/davidforest123/goutil/container/ggeo"
 	"github.com/davidforest123/goutil/net/ghttp"
 	"github.com/davidforest123/goutil/sys/gfs"
 	"github.com/mohong122/ip2region/binding/golang/ip2region"
@@ -24,6 +25,9 @@ type IpGeo struct {
 	ISP      string
 }
 
+// GeoLocator finds coordinates of g from a source other than ip2region, such as a city table keyed by CityId.
+type GeoLocator func(g *IpGeo) (ggeo.LatLng, bool)
+
 type GeoFinder struct {
 	finder *ip2region.Ip2Region
 }
@@ -76,6 +80,19 @@ func (gf *GeoFinder) GetByIPString(s string) (*IpGeo, error) {
 	return gf.GetByIP(ip.Raw())
 }
 
+// LatLng converts the location to ggeo.LatLng by locate because ip2region has no coordinates,
+// ok is false if locate is nil, locate doesn't know g or returns invalid coordinates.
+func (g *IpGeo) LatLng(locate GeoLocator) (ggeo.LatLng, bool) {
+	if locate == nil {
+		return ggeo.LatLng{}, false
+	}
+	p, ok := locate(g)
+	if !ok || !p.Valid() {
+		return ggeo.LatLng{}, false
+	}
+	return p, true
+}
+
 func (gf *GeoFinder) Close() {
 	gf.finder.Close()
 }
@@ -0,0 +1,26 @@
+package gnet
+
+import (
+	"github.com/davidforest123/goutil/basic/gtest"
+	"github.com/davidforest123/goutil/container/ggeo"
+	"testing"
+)
+
+func TestIpGeo_LatLng(t *testing.T) {
+	cities := map[int64]ggeo.LatLng{
+		1: {Lat: 39.9042, Lng: 116.4074},
+		2: {Lat: 91, Lng: 0},
+	}
+	locate := func(g *IpGeo) (ggeo.LatLng, bool) {
+		p, ok := cities[g.CityId]
+		return p, ok
+	}
+	p, ok := (&IpGeo{CityId: 1}).LatLng(locate)
+	gtest.AssertTrue(t, ok && p == cities[1], "got %v %v", p, ok)
+	_, ok = (&IpGeo{CityId: 2}).LatLng(locate)
+	gtest.AssertTrue(t, !ok, "invalid coordinates")
+	_, ok = (&IpGeo{CityId: 3}).LatLng(locate)
+	gtest.AssertTrue(t, !ok, "unknown city")
+	p, ok = (&IpGeo{CityId: 1}).LatLng(nil)
+	gtest.AssertTrue(t, !ok && p == ggeo.LatLng{}, "nil locate got %v %v", p, ok)
+}
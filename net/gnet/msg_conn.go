@@ -90,7 +90,7 @@ func (mc *MsgConn) Read(b []byte) (int, error) {
 
 func (mc *MsgConn) Write(b []byte) (int, error) {
 	if uint64(len(b)) > uint64(msgLenMax) {
-		return 0, gerrors.New("len(b) %d > msgLenSize")
+		return 0, gerrors.New("len(b) %d > msgLenMax %d", len(b), msgLenMax)
 	}
 	msgLenBuf, err := gbytes.NumToBytes(msgLenType(len(b)))
 	if err != nil {
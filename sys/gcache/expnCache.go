@@ -1,3 +1,434 @@
 package gcache
 
+// Expiring in-memory cache with LRU eviction, loaders and statistics.
+//
+// Reference
 // https://github.com/muesli/cache2go
+
+import (
+	"context"
+	"github.com/davidforest123/goutil/basic/gerrors"
+	"github.com/davidforest123/goutil/container/gmap"
+	"github.com/davidforest123/goutil/sys/gtime"
+	"sync"
+	"time"
+)
+
+type (
+	// EvictReason tells why an item left the cache.
+	EvictReason int
+
+	// Loader loads value of a missing key, ttl 0 means the default TTL of cache.
+	Loader[K comparable, V any] func(ctx context.Context, key K) (value V, ttl time.Duration, err error)
+
+	Options[K comparable, V any] struct {
+		Clock      gtime.Clock   // Default is system clock, use gtime.MockClock in tests.
+		DefaultTTL time.Duration // 0 means never expire.
+		Sliding    bool          // Every Get extends expiration of the item by its TTL.
+		MaxEntries int           // 0 means unlimited.
+		MaxBytes   int64         // 0 means unlimited, Sizer is required if it is set.
+		Sizer      func(key K, value V) int64
+		Loader     Loader[K, V]
+		OnEvict    func(key K, value V, reason EvictReason)
+		// Interval to purge expired items in background, 0 means expired items are only removed
+		// when accessed or evicted by capacity.
+		JanitorInterval time.Duration
+	}
+
+	Stats struct {
+		Hits        uint64
+		Misses      uint64
+		Loads       uint64
+		LoadErrors  uint64
+		Evictions   uint64 // Evicted because of MaxEntries or MaxBytes.
+		Expirations uint64
+		Entries     int
+		Bytes       int64
+	}
+
+	Cache[K comparable, V any] struct {
+		opts  Options[K, V]
+		clock gtime.Clock
+
+		mu    sync.Mutex
+		items *gmap.OrderedMap[K, *item[V]] // oldest is the least recently used
+		bytes int64
+		stats Stats
+		calls map[K]*loadCall[V]
+
+		stop chan struct{}
+		once sync.Once
+	}
+
+	item[V any] struct {
+		value    V
+		ttl      time.Duration
+		expireAt time.Time // zero means never expire
+		size     int64
+	}
+
+	loadCall[V any] struct {
+		done     chan struct{} // closed when loader returns
+		value    V
+		err      error
+		canceled bool // err comes from canceled context of the leader, waiters load again
+	}
+
+	// evicted is an item removed under lock whose callback is called after unlock.
+	evicted[K comparable, V any] struct {
+		key    K
+		value  V
+		reason EvictReason
+	}
+)
+
+const (
+	EvictReasonExpired EvictReason = iota
+	EvictReasonCapacity
+	EvictReasonDeleted
+	EvictReasonReplaced
+)
+
+var (
+	ErrNotFound = gerrors.New("cache key not found")
+	ErrNoLoader = gerrors.New("cache has no loader")
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictReasonExpired:
+		return "expired"
+	case EvictReasonCapacity:
+		return "capacity"
+	case EvictReasonDeleted:
+		return "deleted"
+	case EvictReasonReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+func New[K comparable, V any](opts Options[K, V]) (*Cache[K, V], error) {
+	if opts.MaxEntries < 0 || opts.MaxBytes < 0 || opts.DefaultTTL < 0 {
+		return nil, gerrors.New("invalid cache options")
+	}
+	if opts.MaxBytes > 0 && opts.Sizer == nil {
+		return nil, gerrors.New("MaxBytes requires Sizer")
+	}
+	c := &Cache[K, V]{
+		opts:  opts,
+		clock: opts.Clock,
+		items: gmap.NewOrderedMap[K, *item[V]](),
+		calls: make(map[K]*loadCall[V]),
+		stop:  make(chan struct{}),
+	}
+	if c.clock == nil {
+		c.clock = gtime.GetSysClock()
+	}
+	if opts.JanitorInterval > 0 {
+		go c.janitor(opts.JanitorInterval)
+	}
+	return c, nil
+}
+
+func (c *Cache[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.DeleteExpired()
+		}
+	}
+}
+
+// Close stops the background janitor.
+func (c *Cache[K, V]) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+func (c *Cache[K, V]) notify(evs []evicted[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, ev := range evs {
+		c.opts.OnEvict(ev.key, ev.value, ev.reason)
+	}
+}
+
+func (c *Cache[K, V]) removeLocked(key K, it *item[V], reason EvictReason, evs []evicted[K, V]) []evicted[K, V] {
+	c.items.Delete(key)
+	c.bytes -= it.size
+	switch reason {
+	case EvictReasonExpired:
+		c.stats.Expirations++
+	case EvictReasonCapacity:
+		c.stats.Evictions++
+	}
+	return append(evs, evicted[K, V]{key: key, value: it.value, reason: reason})
+}
+
+func (it *item[V]) expired(now time.Time) bool {
+	return !it.expireAt.IsZero() && !now.Before(it.expireAt)
+}
+
+// Set inserts or replaces key with the default TTL.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.opts.DefaultTTL)
+}
+
+// SetWithTTL inserts or replaces key, ttl 0 means never expire.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	evs := c.setLocked(key, value, ttl)
+	c.mu.Unlock()
+	c.notify(evs)
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V, ttl time.Duration) []evicted[K, V] {
+	var evs []evicted[K, V]
+	if old, ok := c.items.Get(key); ok {
+		evs = c.removeLocked(key, old, EvictReasonReplaced, evs)
+	}
+	it := &item[V]{value: value, ttl: ttl}
+	if ttl > 0 {
+		it.expireAt = c.clock.Now().Add(ttl)
+	}
+	if c.opts.Sizer != nil {
+		it.size = c.opts.Sizer(key, value)
+	}
+	c.items.Set(key, it)
+	c.bytes += it.size
+
+	// Expired items are evicted before live ones.
+	if c.overLimitLocked() {
+		now := c.clock.Now()
+		for k, v := range c.items.All() {
+			if k != key && v.expired(now) {
+				evs = c.removeLocked(k, v, EvictReasonExpired, evs)
+			}
+		}
+	}
+	for c.overLimitLocked() {
+		k, v, ok := c.items.Oldest()
+		if !ok || k == key {
+			break
+		}
+		evs = c.removeLocked(k, v, EvictReasonCapacity, evs)
+	}
+	return evs
+}
+
+func (c *Cache[K, V]) overLimitLocked() bool {
+	return (c.opts.MaxEntries > 0 && c.items.Len() > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes)
+}
+
+// getLocked returns live item of key, expired item is removed.
+func (c *Cache[K, V]) getLocked(key K, evs []evicted[K, V]) (*item[V], []evicted[K, V]) {
+	it, ok := c.items.Get(key)
+	if !ok {
+		return nil, evs
+	}
+	now := c.clock.Now()
+	if it.expired(now) {
+		return nil, c.removeLocked(key, it, EvictReasonExpired, evs)
+	}
+	c.items.MoveToBack(key)
+	if c.opts.Sliding && it.ttl > 0 {
+		it.expireAt = now.Add(it.ttl)
+	}
+	return it, evs
+}
+
+// Get returns value of key, it doesn't call loader.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	it, evs := c.getLocked(key, nil)
+	if it != nil {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	c.mu.Unlock()
+	c.notify(evs)
+	if it == nil {
+		var zero V
+		return zero, false
+	}
+	return it.value, true
+}
+
+// Has reports whether key exists and is not expired, it doesn't update recency or statistics.
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, ok := c.items.Get(key)
+	return ok && !it.expired(c.clock.Now())
+}
+
+// GetOrLoad returns value of key, or loads it with Options.Loader if it is missing.
+// Concurrent calls for the same missing key share one loader call, every caller waits with its own ctx.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K) (V, error) {
+	if c.opts.Loader == nil {
+		var zero V
+		return zero, ErrNoLoader
+	}
+	return c.GetOrLoadWith(ctx, key, c.opts.Loader)
+}
+
+// GetOrLoadWith is like GetOrLoad with a specified loader.
+func (c *Cache[K, V]) GetOrLoadWith(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	c.mu.Lock()
+	it, evs := c.getLocked(key, nil)
+	if it != nil {
+		c.stats.Hits++
+		c.mu.Unlock()
+		c.notify(evs)
+		return it.value, nil
+	}
+	c.stats.Misses++
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		c.notify(evs)
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+		if call.canceled {
+			return c.GetOrLoadWith(ctx, key, loader)
+		}
+		return call.value, call.err
+	}
+	call := &loadCall[V]{done: make(chan struct{})}
+	c.calls[key] = call
+	c.stats.Loads++
+	c.mu.Unlock()
+	c.notify(evs)
+
+	value, ttl, err := c.runLoader(ctx, key, loader)
+	call.value, call.err = value, err
+	call.canceled = err != nil && ctx.Err() != nil
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	evs = nil
+	if err != nil {
+		c.stats.LoadErrors++
+	} else {
+		if ttl == 0 {
+			ttl = c.opts.DefaultTTL
+		}
+		evs = c.setLocked(key, value, ttl)
+	}
+	c.mu.Unlock()
+	close(call.done)
+	c.notify(evs)
+	return value, err
+}
+
+func (c *Cache[K, V]) runLoader(ctx context.Context, key K, loader Loader[K, V]) (value V, ttl time.Duration, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = gerrors.New("cache loader panic: %v", r)
+		}
+	}()
+	return loader(ctx, key)
+}
+
+// Delete removes key, it returns false if key doesn't exist.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	it, ok := c.items.Get(key)
+	var evs []evicted[K, V]
+	if ok {
+		evs = c.removeLocked(key, it, EvictReasonDeleted, nil)
+	}
+	c.mu.Unlock()
+	c.notify(evs)
+	return ok
+}
+
+// DeleteExpired removes all expired items, it returns count of removed items.
+func (c *Cache[K, V]) DeleteExpired() int {
+	c.mu.Lock()
+	now := c.clock.Now()
+	var evs []evicted[K, V]
+	for k, v := range c.items.All() {
+		if v.expired(now) {
+			evs = c.removeLocked(k, v, EvictReasonExpired, evs)
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evs)
+	return len(evs)
+}
+
+// TTL returns remaining time to live of key, 0 means never expire.
+func (c *Cache[K, V]) TTL(key K) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, ok := c.items.Get(key)
+	if !ok {
+		return 0, false
+	}
+	if it.expireAt.IsZero() {
+		return 0, true
+	}
+	remain := it.expireAt.Sub(c.clock.Now())
+	if remain <= 0 {
+		return 0, false
+	}
+	return remain, true
+}
+
+// Len returns count of items, expired items not purged yet are included.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items.Len()
+}
+
+// Keys returns keys from the least to the most recently used, expired items are excluded.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	keys := make([]K, 0, c.items.Len())
+	for k, v := range c.items.All() {
+		if !v.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Clear removes all items without calling OnEvict.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items.Clear()
+	c.bytes = 0
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.items.Len()
+	s.Bytes = c.bytes
+	return s
+}
+
+// HitRate returns Hits / (Hits + Misses).
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
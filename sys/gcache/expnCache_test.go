@@ -0,0 +1,168 @@
+package gcache
+
+import (
+	"context"
+	"errors"
+	"github.com/davidforest123/goutil/basic/gtest"
+	"github.com/davidforest123/goutil/sys/gtime"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_Expiration(t *testing.T) {
+	clock := gtime.NewMockClock(time.Now(), time.UTC)
+	var reasons []EvictReason
+	c, err := New[string, int](Options[string, int]{
+		Clock:      clock,
+		DefaultTTL: time.Minute,
+		Sliding:    true,
+		OnEvict:    func(key string, value int, reason EvictReason) { reasons = append(reasons, reason) },
+	})
+	gtest.Assert(t, err)
+	defer c.Close()
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, 0)
+	clock.MockAdd(50 * time.Second)
+	_, ok := c.Get("a")
+	gtest.AssertTrue(t, ok, "a should not expire yet")
+	clock.MockAdd(50 * time.Second)
+	_, ok = c.Get("a")
+	gtest.AssertTrue(t, ok, "sliding expiration should extend a")
+	clock.MockAdd(61 * time.Second)
+	_, ok = c.Get("a")
+	gtest.AssertTrue(t, !ok, "a should expire")
+	_, ok = c.Get("b")
+	gtest.AssertTrue(t, ok, "b should never expire")
+	gtest.AssertTrue(t, len(reasons) == 1 && reasons[0] == EvictReasonExpired, "evict reasons %v", reasons)
+
+	st := c.Stats()
+	gtest.AssertTrue(t, st.Hits == 3 && st.Misses == 1 && st.Expirations == 1, "stats %+v", st)
+}
+
+func TestCache_Capacity(t *testing.T) {
+	c, err := New[string, string](Options[string, string]{
+		MaxEntries: 3,
+		MaxBytes:   10,
+		Sizer:      func(key string, value string) int64 { return int64(len(value)) },
+	})
+	gtest.Assert(t, err)
+	c.Set("a", "1111")
+	c.Set("b", "2222")
+	c.Get("a")
+	c.Set("c", "33") // 10 bytes
+	gtest.AssertTrue(t, c.Len() == 3, "len should be 3 but got %d", c.Len())
+	c.Set("d", "4") // over MaxEntries, b is least recently used
+	gtest.AssertTrue(t, !c.Has("b") && c.Has("a"), "b should be evicted, keys %v", c.Keys())
+	c.Set("e", "55555") // over MaxBytes
+	gtest.AssertTrue(t, !c.Has("a") && c.Has("c"), "only a should be evicted, keys %v", c.Keys())
+	st := c.Stats()
+	gtest.AssertTrue(t, st.Evictions == 2 && st.Bytes == 8, "stats %+v", st)
+}
+
+func TestCache_Loader(t *testing.T) {
+	var calls int32
+	start := make(chan struct{})
+	c, err := New[string, int](Options[string, int]{
+		Loader: func(ctx context.Context, key string) (int, time.Duration, error) {
+			atomic.AddInt32(&calls, 1)
+			<-start
+			if key == "bad" {
+				return 0, 0, errors.New("load fail")
+			}
+			return len(key), 0, nil
+		},
+	})
+	gtest.Assert(t, err)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad(context.Background(), "hello")
+			if err != nil || v != 5 {
+				t.Errorf("GetOrLoad got %d, %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(start)
+	wg.Wait()
+	gtest.AssertTrue(t, atomic.LoadInt32(&calls) == 1, "loader should be called once but called %d times", calls)
+
+	_, err = c.GetOrLoad(context.Background(), "bad")
+	gtest.AssertTrue(t, err != nil, "load error should be returned")
+	gtest.AssertTrue(t, !c.Has("bad"), "failed load should not be cached")
+	gtest.AssertTrue(t, c.Stats().LoadErrors == 1, "load errors should be 1")
+}
+
+func TestCache_LoadCancel(t *testing.T) {
+	var calls int32
+	c, err := New[string, int](Options[string, int]{
+		Loader: func(ctx context.Context, key string) (int, time.Duration, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-ctx.Done()
+				return 0, 0, ctx.Err()
+			}
+			return len(key), 0, nil
+		},
+	})
+	gtest.Assert(t, err)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad(leaderCtx, "hello")
+		leaderDone <- err
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// waiter gives up with its own ctx
+	waiterCtx, cancelWaiter := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelWaiter()
+	_, err = c.GetOrLoad(waiterCtx, "hello")
+	gtest.AssertTrue(t, errors.Is(err, context.DeadlineExceeded), "waiter should time out but got %v", err)
+
+	// waiter doesn't inherit context error of the leader, it loads again
+	waiterDone := make(chan error)
+	go func() {
+		v, err := c.GetOrLoad(context.Background(), "hello")
+		if err == nil && v != 5 {
+			err = errors.New("wrong value")
+		}
+		waiterDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelLeader()
+	gtest.AssertTrue(t, errors.Is(<-leaderDone, context.Canceled), "leader should be canceled")
+	gtest.Assert(t, <-waiterDone)
+	gtest.AssertTrue(t, atomic.LoadInt32(&calls) == 2, "loader should be called twice but called %d times", calls)
+}
+
+func TestCache_Snapshot(t *testing.T) {
+	clock := gtime.NewMockClock(time.Now(), time.UTC)
+	c, err := New[string, int](Options[string, int]{Clock: clock})
+	gtest.Assert(t, err)
+	c.SetWithTTL("short", 1, time.Second)
+	c.SetWithTTL("long", 2, time.Hour)
+	c.Set("forever", 3)
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	gtest.Assert(t, c.SaveSnapshot(path))
+
+	clock.MockAdd(time.Minute)
+	c2, err := New[string, int](Options[string, int]{Clock: clock})
+	gtest.Assert(t, err)
+	n, err := c2.LoadSnapshot(path)
+	gtest.Assert(t, err)
+	gtest.AssertTrue(t, n == 2, "should load 2 items but got %d", n)
+	ttl, ok := c2.TTL("long")
+	gtest.AssertTrue(t, ok && ttl == 59*time.Minute, "long ttl should be 59m but got %v", ttl)
+	v, ok := c2.Get("forever")
+	gtest.AssertTrue(t, ok && v == 3, "forever should be loaded")
+}
@@ -0,0 +1,72 @@
+package gcache
+
+import (
+	"github.com/davidforest123/goutil/container/gob"
+	"github.com/davidforest123/goutil/sys/gfs"
+	"os"
+	"time"
+)
+
+// snapshotEntry is the persisted form of an item, K and V are encoded with encoding/gob,
+// so interface typed keys or values need gob.Register.
+type snapshotEntry[K comparable, V any] struct {
+	Key      K
+	Value    V
+	TTL      time.Duration
+	ExpireAt time.Time
+}
+
+// SaveSnapshot writes all live items to path atomically, so a restarted process can warm up with LoadSnapshot.
+func (c *Cache[K, V]) SaveSnapshot(path string) error {
+	c.mu.Lock()
+	now := c.clock.Now()
+	entries := make([]snapshotEntry[K, V], 0, c.items.Len())
+	for k, v := range c.items.All() {
+		if !v.expired(now) {
+			entries = append(entries, snapshotEntry[K, V]{Key: k, Value: v.value, TTL: v.ttl, ExpireAt: v.expireAt})
+		}
+	}
+	c.mu.Unlock()
+
+	b, err := gob.Encode(entries)
+	if err != nil {
+		return err
+	}
+	return gfs.BytesToFileAtomic(b, path)
+}
+
+// LoadSnapshot inserts items saved by SaveSnapshot, items expired since then are skipped.
+// Recency order is restored and capacity limits apply. It returns count of loaded items.
+func (c *Cache[K, V]) LoadSnapshot(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var entries []snapshotEntry[K, V]
+	if err := gob.Decode(b, &entries); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	now := c.clock.Now()
+	var evs []evicted[K, V]
+	n := 0
+	for _, e := range entries {
+		ttl := e.TTL
+		if !e.ExpireAt.IsZero() {
+			ttl = e.ExpireAt.Sub(now)
+			if ttl <= 0 {
+				continue
+			}
+		}
+		evs = append(evs, c.setLocked(e.Key, e.Value, ttl)...)
+		// Sliding expiration needs the original TTL, not the remaining one.
+		if it, ok := c.items.Get(e.Key); ok {
+			it.ttl = e.TTL
+		}
+		n++
+	}
+	c.mu.Unlock()
+	c.notify(evs)
+	return n, nil
+}
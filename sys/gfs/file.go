@@ -10,6 +10,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -203,6 +204,41 @@ func BytesToFile(data []byte, filename string) error {
 	return nil
 }
 
+// BytesToFileAtomic writes data to a temp file in the same directory, syncs and renames it to filename,
+// so filename always contains either old or new content even if process crashes.
+func BytesToFileAtomic(data []byte, filename string) error {
+	dir := filepath.Dir(filename)
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	// Sync directory so the rename itself is durable, not every platform supports it.
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
 func StringToFile(data string, filename string) error {
 	return BytesToFile([]byte(data), filename)
 }
@@ -3,6 +3,7 @@ package gfs
 import (
 	"github.com/davidforest123/goutil/basic/gerrors"
 	"github.com/google/uuid"
+	"os"
 	"runtime"
 )
 
@@ -14,3 +15,9 @@ func NewTempFilename() (string, error) {
 		return "/tmp/" + uuid.New().String() + ".temp", nil
 	}
 }
+
+// NewTempFile creates and opens a new temp file in dir, os.TempDir() is used if dir is empty.
+// Caller should close and remove it after use.
+func NewTempFile(dir string) (*os.File, error) {
+	return os.CreateTemp(dir, "*.temp")
+}